@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package audiometa
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime returns the zero time where the platform's access time is
+// not read, which RestoreModTime passes on to leave it unchanged.
+func accessTime(os.FileInfo) time.Time {
+	return time.Time{}
+}
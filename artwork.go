@@ -1,6 +1,14 @@
 package audiometa
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -53,3 +61,231 @@ const (
 	RawTagCounter = types.RawTagCounter
 	RawTagURL     = types.RawTagURL
 )
+
+// imageMIMETypes are the image types SetArtwork accepts: those FLAC
+// PICTURE blocks, ID3 APIC frames and M4A covr atoms are read with.
+var imageMIMETypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/bmp":  true,
+	"image/tiff": true,
+	"image/webp": true,
+}
+
+// SetArtwork replaces the file's artwork with art. Later calls to
+// ExtractArtwork return the new set, and the next Save writes it to the
+// file in place of the embedded images. Passing no images removes them all.
+//
+// Each image must have Data and a MIMEType of image/jpeg, image/png,
+// image/gif, image/bmp, image/tiff or image/webp; otherwise an error is
+// returned and the artwork is left unchanged. Size is set from Data. If no
+// image is a front cover, the first one left as ArtworkOther (the zero
+// Type) becomes ArtworkFrontCover, so a single cover needs no Type.
+//
+// Example:
+//
+//	cover, _ := os.ReadFile("cover.jpg")
+//	err := file.SetArtwork([]audiometa.Artwork{{MIMEType: "image/jpeg", Data: cover}})
+func (f *File) SetArtwork(art []Artwork) error {
+	images := make([]Artwork, len(art))
+	hasFront := false
+	for i, a := range art {
+		a, err := checkArtwork(a)
+		if err != nil {
+			return fmt.Errorf("set artwork: image %d: %w", i, err)
+		}
+		hasFront = hasFront || a.Type == ArtworkFrontCover
+		images[i] = a
+	}
+
+	if !hasFront {
+		for i := range images {
+			if images[i].Type == ArtworkOther {
+				images[i].Type = ArtworkFrontCover
+				break
+			}
+		}
+	}
+
+	f.artwork = images
+	f.artworkDirty = true
+	f.HasEmbeddedArtwork = len(f.artwork) > 0
+	return nil
+}
+
+// AddArtwork adds an image to the file's artwork, replacing any images of
+// the same Type, so adding a front cover swaps out the old one. The
+// embedded artwork is loaded first if ExtractArtwork hasn't been called,
+// and the image is checked as by SetArtwork. The change is written by the
+// next Save. Returns an error for files opened with
+// WithArtworkMetadataOnly, whose other images have no Data to keep, or
+// when WithMaxArtworkSize skipped an image that Save would then drop.
+//
+// Example:
+//
+//	cover, _ := os.ReadFile("cover.jpg")
+//	err := file.AddArtwork(audiometa.Artwork{
+//		MIMEType: "image/jpeg",
+//		Type:     audiometa.ArtworkFrontCover,
+//		Data:     cover,
+//	})
+func (f *File) AddArtwork(a Artwork) error {
+	a, err := checkArtwork(a)
+	if err != nil {
+		return fmt.Errorf("add artwork: %w", err)
+	}
+	if f.artworkMetadataOnly && !f.artworkDirty {
+		return errors.New("add artwork: images were loaded without data (WithArtworkMetadataOnly)")
+	}
+	art, err := f.ExtractArtwork()
+	if err != nil {
+		return err
+	}
+	if f.artworkSkipped && !f.artworkDirty {
+		return errors.New("add artwork: images over the WithMaxArtworkSize limit were not loaded")
+	}
+
+	images := make([]Artwork, 0, len(art)+1)
+	for _, existing := range art {
+		if existing.Type != a.Type {
+			images = append(images, existing)
+		}
+	}
+	f.artwork = append(images, a)
+	f.artworkDirty = true
+	f.HasEmbeddedArtwork = len(f.artwork) > 0
+	return nil
+}
+
+// checkArtwork validates an image for SetArtwork and AddArtwork, returning
+// it with its MIME type lowercased and Size set.
+func checkArtwork(a Artwork) (Artwork, error) {
+	a.MIMEType = strings.ToLower(a.MIMEType)
+	if !imageMIMETypes[a.MIMEType] {
+		return Artwork{}, fmt.Errorf("unsupported MIME type %q", a.MIMEType)
+	}
+	if len(a.Data) == 0 {
+		return Artwork{}, errors.New("no image data")
+	}
+	a.Size = len(a.Data)
+	return a, nil
+}
+
+// RemoveArtwork removes the images for which remove returns true and
+// reports how many were removed. The embedded artwork is loaded first if
+// ExtractArtwork hasn't been called. As with SetArtwork, the change is
+// written by the next Save; nothing is marked for writing if no image
+// matched. Returns an error for files opened with WithArtworkMetadataOnly,
+// whose images have no Data left to write back, or when WithMaxArtworkSize
+// skipped an image.
+//
+// Example:
+//
+//	// Keep only the front cover
+//	n, err := file.RemoveArtwork(func(a audiometa.Artwork) bool {
+//		return a.Type != audiometa.ArtworkFrontCover
+//	})
+func (f *File) RemoveArtwork(remove func(Artwork) bool) (int, error) {
+	if f.artworkMetadataOnly && !f.artworkDirty {
+		return 0, errors.New("remove artwork: images were loaded without data (WithArtworkMetadataOnly)")
+	}
+	art, err := f.ExtractArtwork()
+	if err != nil {
+		return 0, err
+	}
+	if f.artworkSkipped && !f.artworkDirty {
+		return 0, errors.New("remove artwork: images over the WithMaxArtworkSize limit were not loaded")
+	}
+
+	kept := make([]Artwork, 0, len(art))
+	for _, a := range art {
+		if !remove(a) {
+			kept = append(kept, a)
+		}
+	}
+	removed := len(art) - len(kept)
+	if removed > 0 {
+		f.artwork = kept
+		f.artworkDirty = true
+		f.HasEmbeddedArtwork = len(f.artwork) > 0
+	}
+	return removed, nil
+}
+
+// ArtworkModified reports whether SetArtwork, AddArtwork or RemoveArtwork
+// changed the artwork since the file was opened or last saved.
+func (f *File) ArtworkModified() bool {
+	return f.artworkDirty
+}
+
+// WriteArtworkTo copies the image data of the artwork at index, in
+// ExtractArtwork order, to w and returns the number of bytes written.
+//
+// Images stored as is in the file (FLAC, M4A, and MP3 without ID3v2
+// unsynchronisation) are copied straight from it without loading Data, so
+// a batch job saving covers to disk never holds a whole image in memory.
+// Other images are loaded with ExtractArtwork and written from Data, as
+// are images already loaded or set with SetArtwork or AddArtwork.
+//
+// Example:
+//
+//	out, _ := os.Create("cover.jpg")
+//	defer out.Close()
+//	_, err := file.WriteArtworkTo(out, 0)
+func (f *File) WriteArtworkTo(w io.Writer, index int) (int64, error) {
+	images, err := f.locateArtwork()
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(images) {
+		return 0, fmt.Errorf("write artwork: index %d out of range for %d images", index, len(images))
+	}
+
+	art := images[index]
+	offset, length, ok := types.ArtworkLocation(art)
+	if art.Data == nil && !ok {
+		if f.artworkMetadataOnly {
+			return 0, errors.New("write artwork: image was loaded without data (WithArtworkMetadataOnly)")
+		}
+		// The stored bytes need decoding
+		if images, err = f.ExtractArtwork(); err != nil {
+			return 0, err
+		}
+		if index >= len(images) {
+			return 0, fmt.Errorf("write artwork: index %d out of range for %d images", index, len(images))
+		}
+		art = images[index]
+	}
+	if art.Data != nil {
+		n, err := w.Write(art.Data)
+		return int64(n), err
+	}
+
+	n, err := io.Copy(w, io.NewSectionReader(f.reader, offset, length))
+	if err == nil && n < length {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// locateArtwork returns the images ExtractArtwork would, described from
+// their headers where the parser can, so that WriteArtworkTo can copy them
+// from their locations. Images are only cached if loaded by ExtractArtwork.
+func (f *File) locateArtwork() ([]Artwork, error) {
+	extractor, ok := f.parser.(ArtworkMetadataExtractor)
+	if f.artwork != nil || f.artworkMetadataOnly || !ok {
+		return f.ExtractArtwork()
+	}
+
+	ctx := registry.WithLegacyCharset(context.Background(), f.legacyCharset)
+	images, err := extractor.ExtractArtworkMetadata(ctx, f.reader, f.Size, f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("extract artwork metadata: %w", err)
+	}
+
+	// Keep the indexes of ExtractArtwork, which skips images over the limit
+	return slices.DeleteFunc(images, func(a Artwork) bool {
+		return f.maxArtworkSize > 0 && a.Size > f.maxArtworkSize
+	}), nil
+}
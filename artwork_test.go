@@ -0,0 +1,308 @@
+package audiometa_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simonhull/audiometa"
+)
+
+// createFLACWithPictures builds a FLAC file with STREAMINFO and one PNG
+// PICTURE block per type.
+func createFLACWithPictures(pictureTypes ...audiometa.ArtworkType) []byte {
+	block := func(buf *bytes.Buffer, header byte, payload []byte) {
+		buf.WriteByte(header)
+		buf.Write([]byte{byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload))})
+		buf.Write(payload)
+	}
+
+	streamInfo := make([]byte, 34)
+	binary.BigEndian.PutUint64(streamInfo[10:18], 44100<<44|1<<41|15<<36|44100)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("fLaC")
+	header := byte(0x00)
+	if len(pictureTypes) == 0 {
+		header |= 0x80
+	}
+	block(buf, header, streamInfo)
+
+	for i, pt := range pictureTypes {
+		pic := &bytes.Buffer{}
+		binary.Write(pic, binary.BigEndian, uint32(pt))
+		binary.Write(pic, binary.BigEndian, uint32(len("image/png")))
+		pic.WriteString("image/png")
+		binary.Write(pic, binary.BigEndian, uint32(0))   // description
+		binary.Write(pic, binary.BigEndian, [4]uint32{}) // width, height, depth, colors
+		binary.Write(pic, binary.BigEndian, uint32(4))
+		pic.WriteString("\x89PNG")
+
+		header := byte(0x06)
+		if i == len(pictureTypes)-1 {
+			header |= 0x80
+		}
+		block(buf, header, pic.Bytes())
+	}
+	buf.WriteString("\xFF\xF8audio")
+	return buf.Bytes()
+}
+
+func openTestFile(t *testing.T, name string, data []byte, opts ...audiometa.Option) *audiometa.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := audiometa.Open(path, opts...)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestFile_SetArtwork(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkBackCover))
+
+	err := file.SetArtwork([]audiometa.Artwork{
+		{MIMEType: "image/jpeg", Data: []byte("\xFF\xD8jpeg")},
+		{MIMEType: "IMAGE/PNG", Data: []byte("\x89PNG"), Type: audiometa.ArtworkArtist},
+	})
+	if err != nil {
+		t.Fatalf("SetArtwork failed: %v", err)
+	}
+	if !file.ArtworkModified() {
+		t.Error("expected ArtworkModified after SetArtwork")
+	}
+
+	art, err := file.ExtractArtwork()
+	if err != nil {
+		t.Fatalf("ExtractArtwork failed: %v", err)
+	}
+	if len(art) != 2 {
+		t.Fatalf("ExtractArtwork() returned %d images, want the 2 set", len(art))
+	}
+	if art[0].Type != audiometa.ArtworkFrontCover || art[0].Size != 6 {
+		t.Errorf("first image = %v, want a 6-byte front cover by default", art[0])
+	}
+	if art[1].Type != audiometa.ArtworkArtist || art[1].MIMEType != "image/png" {
+		t.Errorf("second image = %v, want its type kept and MIME type normalized", art[1])
+	}
+	if n, _ := file.ArtworkCount(); n != 2 {
+		t.Errorf("ArtworkCount() = %d, want 2", n)
+	}
+
+	// Clearing leaves an empty set rather than reloading from disk
+	if err := file.SetArtwork(nil); err != nil {
+		t.Fatal(err)
+	}
+	if art, _ := file.ExtractArtwork(); len(art) != 0 {
+		t.Errorf("ExtractArtwork() after clearing = %v, want none", art)
+	}
+
+	if err := file.Save(); err != nil {
+		t.Fatalf("Save() with cleared artwork failed: %v", err)
+	}
+	if file.ArtworkModified() {
+		t.Error("ArtworkModified() after Save = true, want false")
+	}
+	reopened, err := audiometa.Open(file.Path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	if n, _ := reopened.ArtworkCount(); n != 0 {
+		t.Errorf("ArtworkCount() after saving no artwork = %d, want 0", n)
+	}
+}
+
+func TestFile_AddArtwork(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover, audiometa.ArtworkBackCover))
+
+	cover := audiometa.Artwork{MIMEType: "image/jpeg", Type: audiometa.ArtworkFrontCover, Data: []byte("\xFF\xD8jpeg")}
+	if err := file.AddArtwork(cover); err != nil {
+		t.Fatalf("AddArtwork failed: %v", err)
+	}
+	if err := file.AddArtwork(audiometa.Artwork{MIMEType: "text/plain", Data: []byte("x")}); err == nil {
+		t.Error("expected error for a non-image")
+	}
+	if err := file.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reopened, err := audiometa.Open(file.Path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	art, err := reopened.ExtractArtwork()
+	if err != nil {
+		t.Fatalf("ExtractArtwork failed: %v", err)
+	}
+	if len(art) != 2 {
+		t.Fatalf("ExtractArtwork() returned %d images, want the back cover and the new front cover", len(art))
+	}
+	for _, a := range art {
+		if a.Type == audiometa.ArtworkFrontCover && a.MIMEType != "image/jpeg" {
+			t.Errorf("front cover = %v, want it replaced by the JPEG", a)
+		}
+	}
+}
+
+func TestFile_SetArtwork_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		art  audiometa.Artwork
+	}{
+		{"no MIME type", audiometa.Artwork{Data: []byte("data")}},
+		{"not an image", audiometa.Artwork{MIMEType: "text/plain", Data: []byte("data")}},
+		{"no data", audiometa.Artwork{MIMEType: "image/png"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+			if err := file.SetArtwork([]audiometa.Artwork{tt.art}); err == nil {
+				t.Fatal("expected error")
+			}
+			if file.ArtworkModified() {
+				t.Error("a rejected SetArtwork should not mark the artwork modified")
+			}
+			if art, _ := file.ExtractArtwork(); len(art) != 1 {
+				t.Errorf("ExtractArtwork() = %v, want the embedded image", art)
+			}
+		})
+	}
+}
+
+func TestFile_RemoveArtwork(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(
+		audiometa.ArtworkFrontCover, audiometa.ArtworkBackCover, audiometa.ArtworkMedia))
+
+	n, err := file.RemoveArtwork(func(a audiometa.Artwork) bool { return a.Type != audiometa.ArtworkFrontCover })
+	if err != nil {
+		t.Fatalf("RemoveArtwork failed: %v", err)
+	}
+	if n != 2 || !file.ArtworkModified() {
+		t.Errorf("removed %d, modified %v; want 2 and true", n, file.ArtworkModified())
+	}
+	art, _ := file.ExtractArtwork()
+	if len(art) != 1 || art[0].Type != audiometa.ArtworkFrontCover {
+		t.Errorf("ExtractArtwork() = %v, want only the front cover", art)
+	}
+
+	// No match leaves the artwork unmodified
+	file = openTestFile(t, "other.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+	if n, err := file.RemoveArtwork(func(audiometa.Artwork) bool { return false }); n != 0 || err != nil {
+		t.Errorf("RemoveArtwork() = %d, %v; want 0, nil", n, err)
+	}
+	if file.ArtworkModified() {
+		t.Error("expected no modification when nothing matched")
+	}
+}
+
+func TestFile_RemoveArtwork_HasArtwork(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+
+	if _, err := file.RemoveArtwork(func(audiometa.Artwork) bool { return true }); err != nil {
+		t.Fatalf("RemoveArtwork failed: %v", err)
+	}
+	if file.HasArtwork() {
+		t.Error("HasArtwork() = true after removing every image")
+	}
+	if err := file.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if file.HasArtwork() {
+		t.Error("HasArtwork() = true after saving without artwork")
+	}
+
+	reopened, err := audiometa.Open(file.Path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.HasArtwork() {
+		t.Error("HasArtwork() = true for the saved file")
+	}
+
+	// Clearing with SetArtwork works the same
+	file = openTestFile(t, "other.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+	if err := file.SetArtwork(nil); err != nil {
+		t.Fatalf("SetArtwork failed: %v", err)
+	}
+	if file.HasArtwork() {
+		t.Error("HasArtwork() = true after SetArtwork(nil)")
+	}
+}
+
+func TestFile_RemoveArtwork_MetadataOnly(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover),
+		audiometa.WithArtworkMetadataOnly())
+
+	if _, err := file.RemoveArtwork(func(audiometa.Artwork) bool { return true }); err == nil {
+		t.Error("expected error removing artwork loaded without data")
+	}
+}
+
+func TestFile_ExtractArtwork_MaxArtworkSize(t *testing.T) {
+	data := createFLACWithPictures(audiometa.ArtworkFrontCover, audiometa.ArtworkBackCover)
+
+	// The images hold 4 bytes each
+	file := openTestFile(t, "song.flac", data, audiometa.WithMaxArtworkSize(4))
+	artwork, err := file.ExtractArtwork()
+	if err != nil || len(artwork) != 2 {
+		t.Fatalf("ExtractArtwork() = %d images, %v; want 2", len(artwork), err)
+	}
+
+	file = openTestFile(t, "song.flac", data, audiometa.WithMaxArtworkSize(3))
+	artwork, err = file.ExtractArtwork()
+	if err != nil || len(artwork) != 0 {
+		t.Fatalf("ExtractArtwork() = %d images, %v; want 0", len(artwork), err)
+	}
+	skipped := 0
+	for _, w := range file.Warnings {
+		if w.Stage == "artwork" {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Errorf("expected 2 artwork warnings, got %d: %v", skipped, file.Warnings)
+	}
+
+	// Save would drop the skipped images, so editing is refused
+	if _, err := file.RemoveArtwork(func(audiometa.Artwork) bool { return true }); err == nil {
+		t.Error("expected error removing artwork after images were skipped")
+	}
+}
+
+func TestFile_WriteArtworkTo(t *testing.T) {
+	data := createFLACWithPictures(audiometa.ArtworkFrontCover, audiometa.ArtworkBackCover)
+
+	tests := []struct {
+		name string
+		opts []audiometa.Option
+	}{
+		{"from the file", nil},
+		{"metadata only", []audiometa.Option{audiometa.WithArtworkMetadataOnly()}},
+		{"preloaded", []audiometa.Option{audiometa.WithArtworkPreload()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := openTestFile(t, "song.flac", data, tt.opts...)
+
+			var buf bytes.Buffer
+			n, err := file.WriteArtworkTo(&buf, 1)
+			if err != nil || n != 4 || buf.String() != "\x89PNG" {
+				t.Errorf("WriteArtworkTo() = %d, %v, wrote %q; want 4, nil, \"\\x89PNG\"", n, err, buf.String())
+			}
+			if _, err := file.WriteArtworkTo(&buf, 2); err == nil {
+				t.Error("expected error for index out of range")
+			}
+		})
+	}
+}
@@ -8,6 +8,56 @@ import (
 // Re-exporting from internal/types to maintain public API.
 type AudioInfo = types.AudioInfo
 
+// LoopInfo is an alias to types.LoopInfo.
+// Re-exporting from internal/types to maintain public API.
+type LoopInfo = types.LoopInfo
+
+// SeekPoint is an alias to types.SeekPoint.
+// Re-exporting from internal/types to maintain public API.
+type SeekPoint = types.SeekPoint
+
+// SampleFormat is an alias to types.SampleFormat.
+// Re-exporting from internal/types to maintain public API.
+type SampleFormat = types.SampleFormat
+
+// Re-export sample format constants.
+const (
+	SampleFormatUnknown = types.SampleFormatUnknown
+	SampleSignedInt     = types.SampleSignedInt
+	SampleUnsignedInt   = types.SampleUnsignedInt
+	SampleFloat         = types.SampleFloat
+)
+
+// Endianness is an alias to types.Endianness.
+// Re-exporting from internal/types to maintain public API.
+type Endianness = types.Endianness
+
+// Re-export endianness constants.
+const (
+	EndianUnknown = types.EndianUnknown
+	EndianLittle  = types.EndianLittle
+	EndianBig     = types.EndianBig
+)
+
+// MediaType is an alias to types.MediaType.
+// Re-exporting from internal/types to maintain public API.
+type MediaType = types.MediaType
+
+// Re-export media type constants.
+const (
+	MediaTypeUnknown    = types.MediaTypeUnknown
+	MediaTypeMusic      = types.MediaTypeMusic
+	MediaTypeAudiobook  = types.MediaTypeAudiobook
+	MediaTypePodcast    = types.MediaTypePodcast
+	MediaTypeMusicVideo = types.MediaTypeMusicVideo
+	MediaTypeMovie      = types.MediaTypeMovie
+	MediaTypeTVShow     = types.MediaTypeTVShow
+	MediaTypeBooklet    = types.MediaTypeBooklet
+	MediaTypeRingtone   = types.MediaTypeRingtone
+	MediaTypeITunesU    = types.MediaTypeITunesU
+	MediaTypeOther      = types.MediaTypeOther
+)
+
 // ReplayGainInfo is an alias to types.ReplayGainInfo for backwards compatibility.
 // Re-exporting from internal/types to maintain public API.
 type ReplayGainInfo = types.ReplayGainInfo
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"log/slog"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/simonhull/audiometa"
@@ -90,3 +92,245 @@ func TestParse_UnsupportedFormat(t *testing.T) {
 		t.Errorf("expected UnsupportedFormatError, got %T", err)
 	}
 }
+
+func TestParse_MaxFileSize(t *testing.T) {
+	data := createSimpleM4B()
+
+	tmpFile, err := os.CreateTemp("", "test*.m4b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.Write(data)
+	tmpFile.Close()
+
+	t.Run("over limit", func(t *testing.T) {
+		_, err := audiometa.Open(tmpFile.Name(), audiometa.WithMaxFileSize(int64(len(data)-1)))
+
+		var tooLarge *audiometa.FileTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("expected FileTooLargeError, got %v", err)
+		}
+		if tooLarge.Size != int64(len(data)) {
+			t.Errorf("expected reported size %d, got %d", len(data), tooLarge.Size)
+		}
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		file, err := audiometa.Open(tmpFile.Name(), audiometa.WithMaxFileSize(int64(len(data))))
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		file.Close()
+	})
+}
+
+func TestOpen_WithLegacyCharset(t *testing.T) {
+	// ID3v2.3 tag with a Latin-1-labelled TIT2 holding Windows-1252 quotes
+	text := []byte{0x00, 0x93, 'H', 'i', 0x94}
+	frame := append([]byte{'T', 'I', 'T', '2', 0, 0, 0, byte(len(text)), 0, 0}, text...)
+	data := append([]byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0, 0, 0, byte(len(frame))}, frame...)
+	data = append(data, 0xFF, 0xFB, 0x90, 0x00)
+
+	tmpFile, err := os.CreateTemp("", "test*.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.Write(data)
+	tmpFile.Close()
+
+	tests := []struct {
+		name string
+		opts []audiometa.Option
+		want string
+	}{
+		{"default latin1", nil, "\u0093Hi\u0094"},
+		{"windows-1252", []audiometa.Option{audiometa.WithLegacyCharset(audiometa.Windows1252)}, "“Hi”"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := audiometa.Open(tmpFile.Name(), tt.opts...)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer file.Close()
+
+			if file.Tags.Title != tt.want {
+				t.Errorf("expected title %q, got %q", tt.want, file.Tags.Title)
+			}
+		})
+	}
+}
+
+// closeTracker is an io.ReaderAt and io.Closer that records Close calls.
+type closeTracker struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestOpenReaderAt(t *testing.T) {
+	data := createFLACWithPictures(audiometa.ArtworkFrontCover)
+	r := &closeTracker{Reader: bytes.NewReader(data)}
+
+	file, err := audiometa.OpenReaderAt(r, int64(len(data)), "album/song.flac")
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+	if file.Path != "album/song.flac" || file.Format != audiometa.FormatFLAC {
+		t.Errorf("Path = %q, Format = %v; want the given name and FLAC", file.Path, file.Format)
+	}
+	if art, err := file.ExtractArtwork(); err != nil || len(art) != 1 {
+		t.Errorf("ExtractArtwork() = %d images, %v; want 1", len(art), err)
+	}
+	if err := file.Save(); err == nil {
+		t.Error("expected Save to fail without a path on disk")
+	}
+
+	if err := file.Close(); err != nil || r.closed {
+		t.Errorf("Close() = %v, closed reader = %v; want the caller's reader left open", err, r.closed)
+	}
+}
+
+func TestWithRawTags(t *testing.T) {
+	mp3 := createSimpleMP3("Title")
+	if got := openTestFile(t, "plain.mp3", mp3).RawTags(); got != nil {
+		t.Errorf("RawTags() without WithRawTags = %v, want nil", got)
+	}
+
+	raw := openTestFile(t, "song.mp3", mp3, audiometa.WithRawTags()).RawTags()
+	want := audiometa.RawTag{Key: "TIT2", Encoding: "ISO-8859-1", Value: []byte("\x00Title"), Type: audiometa.RawTagText}
+	if got := raw["TIT2"]; len(got) != 1 || got[0].Key != want.Key || got[0].Encoding != want.Encoding ||
+		!bytes.Equal(got[0].Value, want.Value) || got[0].Type != want.Type {
+		t.Errorf("RawTags()[TIT2] = %+v, want [%+v]", got, want)
+	}
+
+	flac := createFLACWithPictures(audiometa.ArtworkFrontCover, audiometa.ArtworkBackCover)
+	pictures := openTestFile(t, "song.flac", flac, audiometa.WithRawTags()).RawTags()["PICTURE"]
+	if len(pictures) != 2 {
+		t.Fatalf("RawTags()[PICTURE] has %d entries, want 2", len(pictures))
+	}
+	for _, p := range pictures {
+		if p.Type != audiometa.RawTagImage || !bytes.HasSuffix(p.Value, []byte("\x89PNG")) {
+			t.Errorf("PICTURE = %v, want the whole block as an image", p)
+		}
+	}
+}
+
+func TestWithStrictStages(t *testing.T) {
+	// A DSF header whose fmt chunk names an unknown format: a technical
+	// warning, with nothing else wrong
+	technical := make([]byte, 80)
+	copy(technical, "DSD ")
+	copy(technical[28:], "fmt ")
+	technical[44] = 1
+
+	// A FLAC stream under an .mp3 name: a detect warning
+	detect := createFLACWithPictures()
+
+	tests := []struct {
+		name    string
+		file    string
+		data    []byte
+		opts    []audiometa.Option
+		wantErr bool
+	}{
+		{"other stage tolerated", "song.dsf", technical, []audiometa.Option{audiometa.WithStrictStages("metadata")}, false},
+		{"named stage fatal", "song.dsf", technical, []audiometa.Option{audiometa.WithStrictStages("metadata", "technical")}, true},
+		{"stages accumulate", "song.mp3", detect, []audiometa.Option{audiometa.WithStrictStages("metadata"), audiometa.WithStrictStages("detect")}, true},
+		{"strict parsing is every stage", "song.dsf", technical, []audiometa.Option{audiometa.WithStrictParsing()}, true},
+		{"not strict", "song.mp3", detect, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := audiometa.OpenReaderAt(bytes.NewReader(tt.data), int64(len(tt.data)), tt.file, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenReaderAt() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && len(file.Warnings) == 0 {
+				t.Error("expected the tolerated warning to be kept")
+			}
+		})
+	}
+}
+
+func TestWithStrictParsing_InfoNotes(t *testing.T) {
+	// A blank ID3v1 tag, genre 255 being none, is noted at SeverityInfo
+	id3v1 := append([]byte("TAG"), make([]byte, 125)...)
+	id3v1[127] = 255
+	data := append(createSimpleMP3("Song"), id3v1...)
+
+	file := openTestFile(t, "song.mp3", data, audiometa.WithStrictParsing())
+	if len(file.Warnings) != 1 || file.Warnings[0].Severity != audiometa.SeverityInfo {
+		t.Errorf("Warnings = %v, want the blank ID3v1 note kept", file.Warnings)
+	}
+}
+
+// createSimpleDSF creates a one-second stereo DSD64 file.
+func createSimpleDSF() []byte {
+	const rate = 2822400
+	dataSize := 2 * 4096
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("DSD ")
+	binary.Write(buf, binary.LittleEndian, uint64(28))
+	binary.Write(buf, binary.LittleEndian, uint64(28+52+12+dataSize))
+	binary.Write(buf, binary.LittleEndian, uint64(0)) // No ID3v2 tag
+
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint64(52))
+	binary.Write(buf, binary.LittleEndian, uint32(1)) // Format version
+	binary.Write(buf, binary.LittleEndian, uint32(0)) // DSD raw
+	binary.Write(buf, binary.LittleEndian, uint32(2)) // Stereo
+	binary.Write(buf, binary.LittleEndian, uint32(2)) // Channels
+	binary.Write(buf, binary.LittleEndian, uint32(rate))
+	binary.Write(buf, binary.LittleEndian, uint32(1))    // Bits per sample
+	binary.Write(buf, binary.LittleEndian, uint64(rate)) // Samples per channel
+	binary.Write(buf, binary.LittleEndian, uint32(4096)) // Block size per channel
+	binary.Write(buf, binary.LittleEndian, uint32(0))    // Reserved
+
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint64(12+dataSize))
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
+
+func TestOpen_StrictDSF(t *testing.T) {
+	// The DSD rate is far above any PCM rate, and still valid
+	file := openTestFile(t, "song.dsf", createSimpleDSF(), audiometa.WithStrictParsing())
+
+	if file.Audio.Codec != "DSD" || file.Audio.SampleRate != 2822400 {
+		t.Errorf("Audio = %s at %d Hz, want DSD at 2822400 Hz", file.Audio.Codec, file.Audio.SampleRate)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	// A FLAC stream under an .mp3 name: a detect warning
+	data := createFLACWithPictures()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := audiometa.OpenReaderAt(bytes.NewReader(data), int64(len(data)), "song.mp3", audiometa.WithLogger(logger)); err != nil {
+		t.Fatalf("OpenReaderAt() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"msg=\"parsing file\" path=song.mp3 format=FLAC",
+		"msg=\"metadata block\" format=FLAC key=STREAMINFO offset=4",
+		"stage=detect severity=warning",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -1,9 +1,53 @@
 package audiometa
 
 import (
+	"context"
+	"slices"
+
 	"github.com/simonhull/audiometa/internal/types"
 )
 
 // Chapter is an alias to types.Chapter for backwards compatibility.
 // Re-exporting from internal/types to maintain public API.
 type Chapter = types.Chapter
+
+// ParseChapters extracts only the chapter markers from an audio file.
+//
+// This is a focused alternative to Open for chapter-centric tools. The
+// format is detected as usual, but only the structures that carry chapters
+// are read (ID3v2 CHAP frames, the M4A chapter track or chpl atom, the FLAC
+// CUESHEET block). General tags, technical info and artwork are skipped.
+//
+// Formats whose chapters depend on the rest of the metadata (Ogg CHAPTER
+// comments need the stream duration) fall back to a full parse.
+//
+// Options apply as they do to Open, so WithMaxFileSize, WithLogger and the
+// strict modes behave the same; WithArtworkPreload is ignored.
+//
+// Returns an empty slice if the file has no chapters.
+//
+// Example:
+//
+//	chapters, err := audiometa.ParseChapters("audiobook.m4b")
+//	if err != nil {
+//		return err
+//	}
+//	for _, ch := range chapters {
+//		fmt.Printf("%s: %s\n", ch.StartTime, ch.Title)
+//	}
+func ParseChapters(path string, opts ...Option) ([]Chapter, error) {
+	opts = append(slices.Clip(opts), func(o *openOptions) {
+		o.chaptersOnly = true
+		o.preloadArtwork = false
+	})
+
+	file, err := openWithContext(context.Background(), path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	_ = file.Close()
+	if file.Chapters == nil {
+		return []Chapter{}, nil
+	}
+	return file.Chapters, nil
+}
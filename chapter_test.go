@@ -0,0 +1,121 @@
+package audiometa_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+)
+
+// writeAtom writes a basic atom (size + type + payload) to buf.
+func writeAtom(buf *bytes.Buffer, atomType string, payload []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(8+len(payload)))
+	buf.WriteString(atomType)
+	buf.Write(payload)
+}
+
+// createM4BWithChpl creates an M4B with an mvhd duration and Nero chpl chapters.
+func createM4BWithChpl(t *testing.T) string {
+	t.Helper()
+
+	// mvhd: 90 seconds at timescale 1000
+	mvhd := &bytes.Buffer{}
+	binary.Write(mvhd, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(mvhd, binary.BigEndian, uint32(0)) // creation time
+	binary.Write(mvhd, binary.BigEndian, uint32(0)) // modification time
+	binary.Write(mvhd, binary.BigEndian, uint32(1000))
+	binary.Write(mvhd, binary.BigEndian, uint32(90000))
+
+	// chpl: version 1, flags, reserved, count, then (start, title) pairs
+	chpl := &bytes.Buffer{}
+	chpl.Write([]byte{1, 0, 0, 0})
+	binary.Write(chpl, binary.BigEndian, uint32(0))
+	chpl.WriteByte(2)
+	for _, ch := range []struct {
+		start uint64
+		title string
+	}{{0, "Opening"}, {300_000_000, "Middle"}} {
+		binary.Write(chpl, binary.BigEndian, ch.start)
+		chpl.WriteByte(byte(len(ch.title)))
+		chpl.WriteString(ch.title)
+	}
+
+	udta := &bytes.Buffer{}
+	writeAtom(udta, "chpl", chpl.Bytes())
+
+	moov := &bytes.Buffer{}
+	writeAtom(moov, "mvhd", mvhd.Bytes())
+	writeAtom(moov, "udta", udta.Bytes())
+
+	buf := &bytes.Buffer{}
+	writeAtom(buf, "ftyp", []byte("M4B \x00\x00\x00\x00M4B "))
+	writeAtom(buf, "moov", moov.Bytes())
+
+	tmpFile, err := os.CreateTemp("", "chapters*.m4b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	return tmpFile.Name()
+}
+
+func TestParseChapters_M4B(t *testing.T) {
+	path := createM4BWithChpl(t)
+	defer os.Remove(path)
+
+	chapters, err := audiometa.ParseChapters(path)
+	if err != nil {
+		t.Fatalf("ParseChapters failed: %v", err)
+	}
+
+	if len(chapters) != 2 {
+		t.Fatalf("expected 2 chapters, got %d", len(chapters))
+	}
+	if chapters[0].Title != "Opening" || chapters[1].Title != "Middle" {
+		t.Errorf("unexpected titles: %q, %q", chapters[0].Title, chapters[1].Title)
+	}
+	if chapters[1].StartTime != 30*time.Second {
+		t.Errorf("expected second chapter at 30s, got %v", chapters[1].StartTime)
+	}
+	if chapters[1].EndTime != 90*time.Second {
+		t.Errorf("expected last chapter to end at file duration 90s, got %v", chapters[1].EndTime)
+	}
+}
+
+func TestParseChapters_NoChapters(t *testing.T) {
+	path := createTestM4BFile(t)
+	defer os.Remove(path)
+
+	chapters, err := audiometa.ParseChapters(path)
+	if err != nil {
+		t.Fatalf("ParseChapters failed: %v", err)
+	}
+	if chapters == nil || len(chapters) != 0 {
+		t.Errorf("expected an empty slice, got %#v", chapters)
+	}
+}
+
+func TestParseChapters_FileNotFound(t *testing.T) {
+	if _, err := audiometa.ParseChapters("/nonexistent/book.m4b"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestParseChapters_Options(t *testing.T) {
+	path := createM4BWithChpl(t)
+	defer os.Remove(path)
+
+	_, err := audiometa.ParseChapters(path, audiometa.WithMaxFileSize(16))
+	var tooLarge *audiometa.FileTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected FileTooLargeError, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package audiometa
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VirtualTrack is one logical track of a single-file album.
+//
+// A FLAC album rip with an embedded CUESHEET holds every track in one
+// file. SplitByCuesheet turns the cue points into VirtualTracks so each
+// can be listed and displayed like a separate file. No audio is decoded;
+// StartTime and EndTime locate the track within Source.
+type VirtualTrack struct {
+	// Source is the file the track belongs to, shared by all its tracks.
+	Source *File
+
+	// Tags are the source tags with Title, Artist, TrackNumber and
+	// TrackTotal replaced by the track's own values.
+	Tags Tags
+
+	Title     string
+	Performer string
+	Number    int // 1-based position among the cuesheet's audio tracks
+	StartTime time.Duration
+	EndTime   time.Duration
+}
+
+// Duration returns the length of the track.
+func (t *VirtualTrack) Duration() time.Duration {
+	return t.EndTime - t.StartTime
+}
+
+// SplitByCuesheet returns a VirtualTrack for each track in the file's
+// embedded CUESHEET.
+//
+// Titles and performers are taken, in order of preference, from
+// per-track comments ("TITLE[3]", "PERFORMER[3]"), CUE_TRACKnn_TITLE
+// style comments, a CUESHEET text comment, and finally the cue chapter
+// title and album artist.
+//
+// Returns ErrNoCuesheet if the file is not a FLAC file or has no cue
+// tracks.
+//
+// Example:
+//
+//	tracks, err := file.SplitByCuesheet()
+//	if err != nil {
+//		return err
+//	}
+//	for _, t := range tracks {
+//		fmt.Printf("%02d. %s - %s (%s)\n", t.Number, t.Performer, t.Title, t.Duration())
+//	}
+func (f *File) SplitByCuesheet() ([]*VirtualTrack, error) {
+	if f.Format != FormatFLAC || len(f.Chapters) == 0 {
+		return nil, fmt.Errorf("%s: %w", f.Path, ErrNoCuesheet)
+	}
+
+	cueText := parseCueText(f.Tags.GetBest("CUESHEET", "cuesheet"))
+
+	tracks := make([]*VirtualTrack, len(f.Chapters))
+	for i, ch := range f.Chapters {
+		number := ch.Index
+		if number == 0 {
+			number = i + 1
+		}
+
+		title := firstNonEmpty(
+			f.trackComment("TITLE", number),
+			cueText[number].title,
+			ch.Title,
+		)
+		performer := firstNonEmpty(
+			f.trackComment("PERFORMER", number),
+			f.trackComment("ARTIST", number),
+			cueText[number].performer,
+			f.Tags.Artist,
+			f.Tags.AlbumArtist,
+		)
+
+		end := ch.EndTime
+		if end == 0 {
+			end = f.Audio.Duration
+		}
+
+		tags := *f.Tags.Clone()
+		tags.Title = title
+		if performer != "" && performer != tags.Artist {
+			tags.Artist = performer
+			tags.Artists = []string{performer}
+		}
+		tags.TrackNumber = number
+		tags.TrackTotal = len(f.Chapters)
+
+		tracks[i] = &VirtualTrack{
+			Source:    f,
+			Tags:      tags,
+			Title:     title,
+			Performer: performer,
+			Number:    number,
+			StartTime: ch.StartTime,
+			EndTime:   end,
+		}
+	}
+
+	return tracks, nil
+}
+
+// trackComment looks up a per-track comment in the common album-rip
+// spellings: FIELD[n] and CUE_TRACKnn_FIELD.
+func (f *File) trackComment(field string, number int) string {
+	lower := strings.ToLower(field)
+	return f.Tags.GetBest(
+		fmt.Sprintf("%s[%d]", field, number),
+		fmt.Sprintf("%s[%d]", lower, number),
+		fmt.Sprintf("CUE_TRACK%02d_%s", number, field),
+		fmt.Sprintf("cue_track%02d_%s", number, lower),
+	)
+}
+
+// cueTextTrack holds the per-track fields read from cue sheet text.
+type cueTextTrack struct {
+	title     string
+	performer string
+}
+
+// parseCueText extracts TITLE and PERFORMER for each TRACK of a cue sheet
+// stored as text (the CUESHEET comment written by many rippers). Album-level
+// entries before the first TRACK are ignored.
+func parseCueText(text string) map[int]cueTextTrack {
+	if text == "" {
+		return nil
+	}
+
+	tracks := make(map[int]cueTextTrack)
+	current := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		arg = strings.TrimSpace(arg)
+
+		switch strings.ToUpper(command) {
+		case "TRACK":
+			numText, _, _ := strings.Cut(arg, " ")
+			n, err := strconv.Atoi(numText)
+			if err != nil {
+				current = 0
+				continue
+			}
+			current = n
+		case "TITLE":
+			if current > 0 {
+				t := tracks[current]
+				t.title = unquoteCue(arg)
+				tracks[current] = t
+			}
+		case "PERFORMER":
+			if current > 0 {
+				t := tracks[current]
+				t.performer = unquoteCue(arg)
+				tracks[current] = t
+			}
+		}
+	}
+
+	return tracks
+}
+
+// unquoteCue strips the double quotes around a cue sheet string argument.
+func unquoteCue(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
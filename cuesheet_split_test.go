@@ -0,0 +1,105 @@
+package audiometa_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// newCueAlbum builds a single-file FLAC album with three cue chapters.
+func newCueAlbum() *audiometa.File {
+	return &audiometa.File{File: types.File{
+		Format: types.FormatFLAC,
+		Tags:   types.Tags{Album: "Live Set", Artist: "The Band"},
+		Audio:  types.AudioInfo{Duration: 9 * time.Minute},
+		Chapters: []types.Chapter{
+			{Index: 1, Title: "Track 01", StartTime: 0, EndTime: 3 * time.Minute},
+			{Index: 2, Title: "Track 02", StartTime: 3 * time.Minute, EndTime: 6 * time.Minute},
+			{Index: 3, Title: "Track 03", StartTime: 6 * time.Minute},
+		},
+	}}
+}
+
+func TestFile_SplitByCuesheet(t *testing.T) {
+	file := newCueAlbum()
+	file.Tags.Set("TITLE[1]", "Opening")
+	file.Tags.Set("PERFORMER[1]", "Guest Singer")
+	file.Tags.Set("CUESHEET", `PERFORMER "The Band"
+TITLE "Live Set"
+FILE "album.flac" WAVE
+  TRACK 01 AUDIO
+    TITLE "Ignored In Favour Of Comment"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Middle"
+    PERFORMER "Other Act"
+    INDEX 01 03:00:00
+`)
+
+	tracks, err := file.SplitByCuesheet()
+	if err != nil {
+		t.Fatalf("SplitByCuesheet() error: %v", err)
+	}
+	if len(tracks) != 3 {
+		t.Fatalf("expected 3 tracks, got %d", len(tracks))
+	}
+
+	tests := []struct {
+		title     string
+		performer string
+		start     time.Duration
+		end       time.Duration
+	}{
+		{"Opening", "Guest Singer", 0, 3 * time.Minute},
+		{"Middle", "Other Act", 3 * time.Minute, 6 * time.Minute},
+		{"Track 03", "The Band", 6 * time.Minute, 9 * time.Minute},
+	}
+
+	for i, tt := range tests {
+		track := tracks[i]
+		if track.Title != tt.title || track.Performer != tt.performer {
+			t.Errorf("track %d = %q by %q, want %q by %q", i+1, track.Title, track.Performer, tt.title, tt.performer)
+		}
+		if track.StartTime != tt.start || track.EndTime != tt.end {
+			t.Errorf("track %d spans %v-%v, want %v-%v", i+1, track.StartTime, track.EndTime, tt.start, tt.end)
+		}
+		if track.Source != file {
+			t.Errorf("track %d does not reference its source file", i+1)
+		}
+		if track.Tags.Title != tt.title || track.Tags.TrackNumber != i+1 || track.Tags.TrackTotal != 3 {
+			t.Errorf("track %d tags = %q %d/%d", i+1, track.Tags.Title, track.Tags.TrackNumber, track.Tags.TrackTotal)
+		}
+		if track.Tags.Album != "Live Set" {
+			t.Errorf("track %d should inherit album, got %q", i+1, track.Tags.Album)
+		}
+	}
+
+	// Per-track tags must not leak into the source
+	if file.Tags.Title != "" {
+		t.Errorf("source tags modified: Title = %q", file.Tags.Title)
+	}
+}
+
+func TestFile_SplitByCuesheet_NoCuesheet(t *testing.T) {
+	tests := []struct {
+		name string
+		file *audiometa.File
+	}{
+		{"flac without chapters", &audiometa.File{File: types.File{Format: types.FormatFLAC}}},
+		{"non-flac with chapters", &audiometa.File{File: types.File{
+			Format:   types.FormatMP3,
+			Chapters: []types.Chapter{{Index: 1, Title: "Intro"}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.file.SplitByCuesheet(); !errors.Is(err, audiometa.ErrNoCuesheet) {
+				t.Errorf("expected ErrNoCuesheet, got %v", err)
+			}
+		})
+	}
+}
@@ -70,6 +70,19 @@
 //		}
 //	}()
 //
+// Read from a non-seekable source such as an HTTP response body:
+//
+//	file, err := audiometa.OpenStream(resp.Body, audiometa.FormatUnknown,
+//		audiometa.WithStreamSize(resp.ContentLength))
+//
+// Sniff the format of a stream before deciding what to do with it:
+//
+//	format, body, err := audiometa.DetectFormatReader(req.Body)
+//
+// Read from memory or any other io.ReaderAt, such as an embed.FS file:
+//
+//	file, err := audiometa.OpenReaderAt(bytes.NewReader(data), int64(len(data)), "song.flac")
+//
 // Iterate over raw tags:
 //
 //	for key, values := range file.Tags.All() {
@@ -91,6 +104,9 @@
 //		}
 //	}
 //
+// Each warning has a Severity. File.HasErrors reports whether any is
+// SeverityError, meaning part of the file was corrupt or truncated.
+//
 // # Performance
 //
 // audiometa is designed for speed:
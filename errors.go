@@ -1,9 +1,15 @@
 package audiometa
 
 import (
+	"errors"
+
 	"github.com/simonhull/audiometa/internal/types"
 )
 
+// ErrNoCuesheet is returned by File.SplitByCuesheet when the file has no
+// embedded cue sheet to split on.
+var ErrNoCuesheet = errors.New("no cuesheet")
+
 // OutOfBoundsError is an alias to types.OutOfBoundsError for backwards compatibility.
 // Re-exporting from internal/types to maintain public API.
 type OutOfBoundsError = types.OutOfBoundsError
@@ -16,6 +22,27 @@ type UnsupportedFormatError = types.UnsupportedFormatError
 // Re-exporting from internal/types to maintain public API.
 type CorruptedFileError = types.CorruptedFileError
 
+// FileTooLargeError is an alias to types.FileTooLargeError.
+// Returned by Open when a file exceeds the WithMaxFileSize cap.
+type FileTooLargeError = types.FileTooLargeError
+
+// ParserNotLinkedError is an alias to types.ParserNotLinkedError.
+// Returned when a format is detected but its parser package isn't imported.
+type ParserNotLinkedError = types.ParserNotLinkedError
+
+// ErrParserNotLinked matches any ParserNotLinkedError via errors.Is.
+var ErrParserNotLinked = types.ErrParserNotLinked
+
 // Warning is an alias to types.Warning for backwards compatibility.
 // Re-exporting from internal/types to maintain public API.
 type Warning = types.Warning
+
+// Severity is an alias to types.Severity.
+type Severity = types.Severity
+
+// Warning severities, from least to most serious.
+const (
+	SeverityInfo    = types.SeverityInfo
+	SeverityWarning = types.SeverityWarning
+	SeverityError   = types.SeverityError
+)
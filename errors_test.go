@@ -1,6 +1,8 @@
 package audiometa
 
 import (
+	"errors"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -86,3 +88,41 @@ func TestCorruptedFileError_Error(t *testing.T) {
 		t.Errorf("error should contain 'corrupted file', got: %s", msg)
 	}
 }
+
+func TestNoParserError(t *testing.T) {
+	t.Run("known parser package", func(t *testing.T) {
+		err := noParserError("song.flac", FormatFLAC)
+
+		if !errors.Is(err, ErrParserNotLinked) {
+			t.Fatalf("expected ErrParserNotLinked, got %v", err)
+		}
+		var notLinked *ParserNotLinkedError
+		if !errors.As(err, &notLinked) || notLinked.Format != FormatFLAC {
+			t.Fatalf("expected ParserNotLinkedError for FLAC, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "internal/flac") {
+			t.Errorf("error should name the package to import, got: %s", err)
+		}
+	})
+
+	t.Run("format without a parser", func(t *testing.T) {
+		err := noParserError("song.xyz", FormatUnknown)
+
+		if errors.Is(err, ErrParserNotLinked) {
+			t.Errorf("formats with no parser package should not report ErrParserNotLinked")
+		}
+		var unsupported *UnsupportedFormatError
+		if !errors.As(err, &unsupported) {
+			t.Errorf("expected UnsupportedFormatError, got %T", err)
+		}
+	})
+}
+
+func TestSupportedFormats(t *testing.T) {
+	formats := SupportedFormats()
+	for _, want := range []Format{FormatFLAC, FormatMP3, FormatM4A, FormatM4B, FormatOgg, FormatOpus, FormatWAV, FormatAIFF} {
+		if !slices.Contains(formats, want) {
+			t.Errorf("SupportedFormats() = %v, missing %v", formats, want)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package audiometa
+
+// AccessTime exposes accessTime to the external tests.
+var AccessTime = accessTime
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"runtime"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -14,10 +16,15 @@ import (
 	"github.com/simonhull/audiometa/internal/types"
 
 	// Register built-in format parsers.
+	_ "github.com/simonhull/audiometa/internal/aiff"
+	_ "github.com/simonhull/audiometa/internal/dsf"
 	_ "github.com/simonhull/audiometa/internal/flac"
 	_ "github.com/simonhull/audiometa/internal/m4a"
+	_ "github.com/simonhull/audiometa/internal/mkv"
 	_ "github.com/simonhull/audiometa/internal/mp3"
 	_ "github.com/simonhull/audiometa/internal/ogg"
+	_ "github.com/simonhull/audiometa/internal/wav"
+	_ "github.com/simonhull/audiometa/internal/wavpack"
 )
 
 // File represents an opened audio file with parsed metadata.
@@ -28,9 +35,23 @@ import (
 type File struct {
 	types.File
 
-	reader  io.ReaderAt
-	parser  FormatParser
-	artwork []Artwork
+	reader       io.ReaderAt
+	parser       FormatParser
+	artwork      []Artwork
+	artworkDirty bool // Set by SetArtwork, AddArtwork and RemoveArtwork, for Save
+
+	// Tags as read, for formats that save artwork but not tags, so Save
+	// can tell whether they were edited
+	savedTags *types.Tags
+
+	artworkMetadataOnly bool    // Set by WithArtworkMetadataOnly
+	legacyCharset       Charset // Set by WithLegacyCharset, for APIC descriptions
+	maxArtworkSize      int     // Set by WithMaxArtworkSize
+	artworkSkipped      bool    // An image over maxArtworkSize was left out of artwork
+
+	modTime         time.Time // Modification time at Open, for RestoreModTime
+	accessTime      time.Time // Access time at Open, for RestoreModTime
+	preserveModTime bool      // Set by WithPreserveModTime
 }
 
 // Open opens an audio file and reads its metadata.
@@ -83,24 +104,54 @@ func openWithContext(ctx context.Context, path string, opts ...Option) (*File, e
 	}
 	size := stat.Size()
 
+	// Reject oversized files before reading anything
+	if options.maxFileSize > 0 && size > options.maxFileSize {
+		_ = f.Close()
+		return nil, &types.FileTooLargeError{Path: path, Size: size, Limit: options.maxFileSize}
+	}
+
 	// Parse with the reader
-	typesFile, err := openReader(ctx, f, size, path, options)
+	parsed, err := openReader(ctx, f, size, path, FormatUnknown, options)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	file, err := newFile(parsed, f, options)
 	if err != nil {
 		_ = f.Close()
 		return nil, err
 	}
+	file.modTime = stat.ModTime()
+	file.accessTime = accessTime(stat)
+	return file, nil
+}
 
-	// Wrap in File struct
+// newFile wraps a parse result read through r in a File, then applies the
+// options that act on the parsed file: strict parsing and artwork preload.
+func newFile(parsed *parsedFile, r io.ReaderAt, options *openOptions) (*File, error) {
 	file := &File{
-		File:   *typesFile.file,
-		reader: f,
-		parser: typesFile.parser,
+		File:   *parsed.file,
+		reader: r,
+		parser: parsed.parser,
+
+		artworkMetadataOnly: options.artworkMetadataOnly,
+		legacyCharset:       options.legacyCharset,
+		maxArtworkSize:      options.maxArtworkSize,
+
+		preserveModTime: options.preserveModTime,
+	}
+	if _, ok := file.parser.(TagWriter); !ok {
+		if _, ok := file.parser.(ArtworkWriter); ok {
+			file.savedTags = file.Tags.Clone()
+		}
 	}
 
-	// Check strict parsing mode
-	if options.strictParsing && len(file.Warnings) > 0 {
-		_ = f.Close()
-		return nil, fmt.Errorf("strict parsing failed: %s", file.Warnings[0].Message)
+	// Check strict parsing mode; informational notes are never fatal
+	for _, w := range file.Warnings {
+		if w.Severity != SeverityInfo && options.strict(w.Stage) {
+			return nil, fmt.Errorf("strict parsing failed: %s", w.Message)
+		}
 	}
 
 	// Preload artwork if requested
@@ -126,42 +177,94 @@ type parsedFile struct {
 	parser FormatParser
 }
 
-// openReader opens from an io.ReaderAt (internal, for testing).
-func openReader(ctx context.Context, r io.ReaderAt, size int64, path string, options *openOptions) (*parsedFile, error) {
+// openReader opens from an io.ReaderAt (internal, for testing). A hint
+// other than FormatUnknown skips format detection.
+func openReader(ctx context.Context, r io.ReaderAt, size int64, path string, hint types.Format, options *openOptions) (*parsedFile, error) {
+	// Parsers read through a SafeReader bounded by size, so capping size
+	// bounds every read, including those of parsers trusting size fields
+	if options.maxFileSize > 0 && size > options.maxFileSize {
+		return nil, &types.FileTooLargeError{Path: path, Size: size, Limit: options.maxFileSize}
+	}
+
+	ctx = registry.WithLogger(ctx, options.logger)
+	log := registry.Logger(ctx)
+
 	// Detect format
-	format, err := types.DetectFormat(r, size, path)
-	if err != nil {
-		return nil, err
+	format := hint
+	var detectWarnings []types.Warning
+	if format == types.FormatUnknown {
+		var err error
+		if format, detectWarnings, err = types.DetectFormatWarnings(r, size, path); err != nil {
+			return nil, err
+		}
 	}
+	log.LogAttrs(ctx, slog.LevelDebug, "parsing file",
+		slog.String("path", path), slog.String("format", format.String()), slog.Int64("size", size))
 
 	// Find parser for this format
 	parser := findParser(format)
 	if parser == nil {
-		return nil, &types.UnsupportedFormatError{
-			Path:   path,
-			Reason: fmt.Sprintf("no parser available for format %s", format),
-		}
+		return nil, noParserError(path, format)
 	}
 
 	// Parse metadata; parsers check ctx at major boundaries.
-	file, err := parser.Parse(ctx, r, size, path)
+	ctx = registry.WithLegacyCharset(ctx, options.legacyCharset)
+	ctx = registry.WithArtistSeparators(ctx, options.artistSeparators)
+	ctx = registry.WithRawTags(ctx, options.rawTags)
+	file, err := parse(ctx, parser, format, r, size, path, options)
 	if err != nil {
-		return nil, fmt.Errorf("parse %s: %w", format, err)
+		return nil, err
 	}
 
 	// Set file-level fields
 	file.Path = path
-	file.Format = format
+	// The M4A parser may promote an audiobook with a generic brand to M4B
+	if format != types.FormatM4A || file.Format != types.FormatM4B {
+		file.Format = format
+	}
 	file.Size = size
+	file.Warnings = append(detectWarnings, file.Warnings...)
+
+	// Strict mode also distrusts implausible technical values
+	if options.strict("validation") && !options.chaptersOnly {
+		file.Warnings = append(file.Warnings, file.Audio.Validate()...)
+	}
+
+	for _, w := range file.Warnings {
+		log.LogAttrs(ctx, slog.LevelDebug, w.Message,
+			slog.String("format", format.String()), slog.String("stage", w.Stage),
+			slog.String("severity", w.Severity.String()), slog.Int64("offset", w.Offset))
+	}
 
-	// Apply option: ignore warnings
+	// Apply option: ignore warnings, otherwise collapse repeats
 	if options.ignoreWarnings {
 		file.Warnings = nil
+	} else {
+		file.Warnings = types.CompactWarnings(file.Warnings, options.maxWarnings)
 	}
 
 	return &parsedFile{file: file, parser: parser}, nil
 }
 
+// parse runs the format's parser over r. With options.chaptersOnly, a
+// parser that can extract chapters alone does so and the rest of the File
+// stays empty.
+func parse(ctx context.Context, parser FormatParser, format types.Format, r io.ReaderAt, size int64, path string, options *openOptions) (*types.File, error) {
+	if extractor, ok := parser.(ChapterExtractor); ok && options.chaptersOnly {
+		chapters, err := extractor.ExtractChapters(ctx, r, size, path)
+		if err != nil {
+			return nil, fmt.Errorf("extract chapters %s: %w", format, err)
+		}
+		return &types.File{Chapters: chapters}, nil
+	}
+
+	file, err := parser.Parse(ctx, r, size, path)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", format, err)
+	}
+	return file, nil
+}
+
 // Close releases resources held by the file.
 //
 // After Close is called, the File should not be used.
@@ -194,16 +297,63 @@ func (f *File) ExtractArtwork() ([]Artwork, error) {
 	return f.ExtractArtworkContext(context.Background())
 }
 
+// ArtworkCount returns the number of embedded images without loading them.
+//
+// Only headers are scanned (FLAC PICTURE blocks, M4A covr data atoms, MP3
+// APIC frames, Ogg METADATA_BLOCK_PICTURE comments), which makes this cheap
+// enough for an "N images" badge in a library view. If artwork has already
+// been loaded, the cached count is returned.
+//
+// Example:
+//
+//	if n, err := file.ArtworkCount(); err == nil && n > 1 {
+//		fmt.Printf("%d images\n", n)
+//	}
+func (f *File) ArtworkCount() (int, error) {
+	if f.artwork != nil {
+		return len(f.artwork), nil
+	}
+
+	counter, ok := f.parser.(ArtworkCounter)
+	if !ok {
+		// Fall back to a full extraction for parsers without a header scan
+		artwork, err := f.ExtractArtwork()
+		return len(artwork), err
+	}
+
+	n, err := counter.CountArtwork(context.Background(), f.reader, f.Size, f.Path)
+	if err != nil {
+		return 0, fmt.Errorf("count artwork: %w", err)
+	}
+	return n, nil
+}
+
 // ExtractArtworkContext is the cancellable form of ExtractArtwork.
 //
 // Use this when you want to bound how long artwork extraction can take,
-// for example when scanning many files in a worker pool.
+// for example when scanning many files in a worker pool. The context is
+// checked between images and between chunks of each image read, and
+// ctx.Err() is returned on cancellation. Nothing is cached in that case.
 func (f *File) ExtractArtworkContext(ctx context.Context) ([]Artwork, error) {
 	// Return cached artwork if already loaded
 	if f.artwork != nil {
 		return f.artwork, nil
 	}
 
+	ctx = registry.WithLegacyCharset(ctx, f.legacyCharset)
+	ctx = registry.WithMaxArtworkSize(ctx, f.maxArtworkSize)
+
+	if f.artworkMetadataOnly {
+		if extractor, ok := f.parser.(ArtworkMetadataExtractor); ok {
+			artwork, err := extractor.ExtractArtworkMetadata(ctx, f.reader, f.Size, f.Path)
+			if err != nil {
+				return nil, fmt.Errorf("extract artwork metadata: %w", err)
+			}
+			f.artwork = artwork
+			return artwork, nil
+		}
+	}
+
 	// Check if parser supports artwork extraction
 	extractor, ok := f.parser.(ArtworkExtractor)
 	if !ok {
@@ -217,6 +367,28 @@ func (f *File) ExtractArtworkContext(ctx context.Context) ([]Artwork, error) {
 		return nil, fmt.Errorf("extract artwork: %w", err)
 	}
 
+	kept := artwork[:0]
+	for i, art := range artwork {
+		if f.maxArtworkSize > 0 && art.Data == nil && art.Size > f.maxArtworkSize {
+			// The extractor left the data of an image over the limit unread
+			f.Warnings = append(f.Warnings, types.Warning{
+				Stage:   "artwork",
+				Message: fmt.Sprintf("skipped image %d: %d bytes exceeds the %d-byte artwork size limit", i+1, art.Size, f.maxArtworkSize),
+			})
+			f.artworkSkipped = true
+			continue
+		}
+		if art.Size == 0 {
+			art.Size = len(art.Data)
+		}
+		if f.artworkMetadataOnly {
+			// No header-only path for this parser; drop the bytes here
+			art.Data = nil
+		}
+		kept = append(kept, art)
+	}
+	artwork = kept
+
 	// Cache for future calls
 	f.artwork = artwork
 
@@ -249,6 +421,41 @@ func OpenContext(ctx context.Context, path string, opts ...Option) (*File, error
 	return openWithContext(ctx, path, opts...)
 }
 
+// OpenReaderAt reads metadata from r, which holds size bytes, without
+// touching the filesystem: a memory-mapped file, a zip entry, or a file
+// in an embed.FS. name becomes File.Path and takes part in format
+// detection by extension, as Open's path does.
+//
+// The caller keeps ownership of r; Close on the returned File does not
+// close it, even if r is an io.Closer. Artwork is read through r as with
+// Open, so r must stay usable until the File is no longer needed. Save is
+// not supported.
+//
+// Example:
+//
+//	//go:embed testdata/song.flac
+//	var song []byte
+//
+//	file, err := audiometa.OpenReaderAt(bytes.NewReader(song), int64(len(song)), "song.flac")
+func OpenReaderAt(r io.ReaderAt, size int64, name string, opts ...Option) (*File, error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	parsed, err := openReader(context.Background(), r, size, name, FormatUnknown, options)
+	if err != nil {
+		return nil, err
+	}
+	return newFile(parsed, readerAtOnly{r}, options)
+}
+
+// readerAtOnly hides every method of an io.ReaderAt but ReadAt, so
+// File.Close leaves a caller's reader open.
+type readerAtOnly struct {
+	io.ReaderAt
+}
+
 // OpenMany opens multiple audio files concurrently.
 //
 // Files are parsed in parallel using up to runtime.NumCPU() goroutines.
@@ -328,6 +535,18 @@ type FormatParser = registry.FormatParser
 // Re-exporting from internal/registry to maintain public API.
 type ArtworkExtractor = registry.ArtworkExtractor
 
+// ArtworkMetadataExtractor is an alias to registry.ArtworkMetadataExtractor.
+// Re-exporting from internal/registry to maintain public API.
+type ArtworkMetadataExtractor = registry.ArtworkMetadataExtractor
+
+// ArtworkCounter is an alias to registry.ArtworkCounter.
+// Re-exporting from internal/registry to maintain public API.
+type ArtworkCounter = registry.ArtworkCounter
+
+// ChapterExtractor is an alias to registry.ChapterExtractor.
+// Re-exporting from internal/registry to maintain public API.
+type ChapterExtractor = registry.ChapterExtractor
+
 // findParser returns the parser for a given format.
 //
 // Returns nil if no parser is registered for the format.
@@ -335,6 +554,50 @@ func findParser(format types.Format) FormatParser {
 	return registry.Get(format)
 }
 
+// parserPackages maps formats to the package whose init() registers their
+// parser. A detected format missing from the registry but present here
+// means the package wasn't linked; one absent here has no parser at all.
+var parserPackages = map[types.Format]string{
+	types.FormatFLAC:    "github.com/simonhull/audiometa/internal/flac",
+	types.FormatMP3:     "github.com/simonhull/audiometa/internal/mp3",
+	types.FormatM4A:     "github.com/simonhull/audiometa/internal/m4a",
+	types.FormatM4B:     "github.com/simonhull/audiometa/internal/m4a",
+	types.FormatOgg:     "github.com/simonhull/audiometa/internal/ogg",
+	types.FormatOpus:    "github.com/simonhull/audiometa/internal/ogg",
+	types.FormatWAV:     "github.com/simonhull/audiometa/internal/wav",
+	types.FormatAIFF:    "github.com/simonhull/audiometa/internal/aiff",
+	types.FormatWavPack: "github.com/simonhull/audiometa/internal/wavpack",
+	types.FormatMKA:     "github.com/simonhull/audiometa/internal/mkv",
+	types.FormatDSF:     "github.com/simonhull/audiometa/internal/dsf",
+}
+
+// noParserError explains why no parser was found for a detected format.
+func noParserError(path string, format types.Format) error {
+	if pkg, ok := parserPackages[format]; ok {
+		return &types.ParserNotLinkedError{Path: path, Format: format, Package: pkg}
+	}
+	return &types.UnsupportedFormatError{
+		Path:   path,
+		Reason: fmt.Sprintf("no parser available for format %s", format),
+	}
+}
+
+// SupportedFormats returns the formats that have a registered parser.
+//
+// Parsers register themselves from init(), so the result reflects which
+// format packages are linked into the binary. This package imports every
+// built-in one, so a format is only missing from a build that trims those
+// imports; Open then fails with an error matching ErrParserNotLinked that
+// names the package to import. Call it at startup to verify the formats a
+// service depends on are available:
+//
+//	if !slices.Contains(audiometa.SupportedFormats(), audiometa.FormatFLAC) {
+//		log.Fatal("FLAC parser not linked")
+//	}
+func SupportedFormats() []Format {
+	return registry.Formats()
+}
+
 // RegisterParser registers a parser for a format.
 // This is called by format packages during initialization (init functions).
 //
@@ -0,0 +1,98 @@
+package audiometa
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// Codec returns the audio codec name (e.g., "AAC", "FLAC", "MP3").
+//
+// Shortcut for f.Audio.Codec; the Audio field remains the full source of
+// technical information.
+func (f *File) Codec() string {
+	return f.Audio.Codec
+}
+
+// Lossless reports whether the audio uses lossless compression.
+//
+// Shortcut for f.Audio.Lossless.
+func (f *File) Lossless() bool {
+	return f.Audio.Lossless
+}
+
+// Duration returns the total playback duration.
+//
+// Shortcut for f.Audio.Duration. Returns 0 if the duration is unknown.
+func (f *File) Duration() time.Duration {
+	return f.Audio.Duration
+}
+
+// Bitrate returns the average bitrate in bits per second.
+//
+// Shortcut for f.Audio.Bitrate. Returns 0 if the bitrate is unknown.
+func (f *File) Bitrate() int {
+	return f.Audio.Bitrate
+}
+
+// ChaptersSeq returns an iterator over the file's chapters in playback order.
+//
+// This follows the same iterator pattern as Tags.All() and lets callers stop
+// early without copying the chapter list:
+//
+//	for ch := range file.ChaptersSeq() {
+//		if ch.StartTime > limit {
+//			break
+//		}
+//		fmt.Println(ch.Title)
+//	}
+//
+// Chapters are parsed during Open; the iterator yields them one at a time
+// rather than handing out the slice.
+func (f *File) ChaptersSeq() iter.Seq[Chapter] {
+	return func(yield func(Chapter) bool) {
+		for _, ch := range f.Chapters {
+			if !yield(ch) {
+				return
+			}
+		}
+	}
+}
+
+// HasChapters reports whether the file contains chapter markers.
+func (f *File) HasChapters() bool {
+	return len(f.Chapters) > 0
+}
+
+// HasArtwork reports whether the file contains embedded artwork.
+//
+// Presence is recorded while parsing metadata, and updated by SetArtwork,
+// AddArtwork and RemoveArtwork, so this is O(1) and never triggers artwork
+// loading. Call ExtractArtwork to get the images.
+func (f *File) HasArtwork() bool {
+	return f.HasEmbeddedArtwork || len(f.artwork) > 0
+}
+
+// HasLyrics reports whether the file contains lyrics.
+func (f *File) HasLyrics() bool {
+	return f.Tags.Lyrics != ""
+}
+
+// Comments returns every comment in the file, in file order: all COMM
+// frames (MP3), the ©cmt, desc and ldes atoms (M4A), or each COMMENT field
+// (Vorbis comments). Tags.Comment remains the primary one.
+//
+// A format that stores a single unlabeled comment (ID3v1) yields it as the
+// only entry. The returned slice is a copy.
+//
+// Example:
+//
+//	for _, c := range file.Comments() {
+//		fmt.Printf("[%s] %s: %s\n", c.Language, c.Description, c.Text)
+//	}
+func (f *File) Comments() []Comment {
+	if len(f.Tags.Comments) == 0 && f.Tags.Comment != "" {
+		return []Comment{{Text: f.Tags.Comment}}
+	}
+	return slices.Clone(f.Tags.Comments)
+}
@@ -0,0 +1,114 @@
+package audiometa_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestFile_AudioShortcuts(t *testing.T) {
+	file := &audiometa.File{File: types.File{
+		Audio: types.AudioInfo{
+			Codec:    "FLAC",
+			Lossless: true,
+			Duration: 3 * time.Minute,
+			Bitrate:  900_000,
+		},
+	}}
+
+	if got := file.Codec(); got != "FLAC" {
+		t.Errorf("Codec() = %q, want FLAC", got)
+	}
+	if !file.Lossless() {
+		t.Error("Lossless() = false, want true")
+	}
+	if got := file.Duration(); got != 3*time.Minute {
+		t.Errorf("Duration() = %v, want 3m", got)
+	}
+	if got := file.Bitrate(); got != 900_000 {
+		t.Errorf("Bitrate() = %d, want 900000", got)
+	}
+}
+
+func TestFile_ChaptersSeq(t *testing.T) {
+	file := &audiometa.File{File: types.File{
+		Chapters: []types.Chapter{
+			{Index: 1, Title: "One"},
+			{Index: 2, Title: "Two"},
+			{Index: 3, Title: "Three"},
+		},
+	}}
+
+	var titles []string
+	for ch := range file.ChaptersSeq() {
+		titles = append(titles, ch.Title)
+	}
+	if len(titles) != 3 || titles[0] != "One" || titles[2] != "Three" {
+		t.Errorf("ChaptersSeq() yielded %v, want [One Two Three]", titles)
+	}
+
+	// Early termination
+	count := 0
+	for range file.ChaptersSeq() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("expected iteration to stop after 1 chapter, got %d", count)
+	}
+
+	// No chapters
+	empty := &audiometa.File{}
+	for range empty.ChaptersSeq() {
+		t.Error("expected no chapters")
+	}
+}
+
+func TestFile_HasPredicates(t *testing.T) {
+	empty := &audiometa.File{}
+	if empty.HasChapters() || empty.HasArtwork() || empty.HasLyrics() {
+		t.Error("expected all predicates false for empty file")
+	}
+
+	file := &audiometa.File{File: types.File{
+		Chapters:           []types.Chapter{{Index: 1}},
+		Tags:               types.Tags{Lyrics: "la la la"},
+		HasEmbeddedArtwork: true,
+	}}
+	if !file.HasChapters() {
+		t.Error("HasChapters() = false, want true")
+	}
+	if !file.HasArtwork() {
+		t.Error("HasArtwork() = false, want true")
+	}
+	if !file.HasLyrics() {
+		t.Error("HasLyrics() = false, want true")
+	}
+}
+
+func TestFile_Comments(t *testing.T) {
+	comments := []types.Comment{
+		{Language: "eng", Text: "Recorded live"},
+		{Language: "eng", Description: "Review", Text: "Five stars"},
+	}
+	file := &audiometa.File{File: types.File{
+		Tags: types.Tags{Comment: "Recorded live", Comments: comments},
+	}}
+
+	got := file.Comments()
+	if len(got) != 2 || got[1] != comments[1] {
+		t.Errorf("Comments() = %+v, want %+v", got, comments)
+	}
+	got[0].Text = "changed"
+	if file.Tags.Comments[0].Text != "Recorded live" {
+		t.Error("modifying Comments() result changed the file")
+	}
+
+	// A lone ID3v1-style comment is still listed
+	file = &audiometa.File{File: types.File{Tags: types.Tags{Comment: "v1 comment"}}}
+	if got := file.Comments(); len(got) != 1 || got[0].Text != "v1 comment" {
+		t.Errorf("Comments() = %+v, want the single comment", got)
+	}
+}
@@ -1,6 +1,9 @@
 package audiometa
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"io"
 
 	"github.com/simonhull/audiometa/internal/types"
@@ -21,10 +24,77 @@ const (
 	FormatOpus    = types.FormatOpus
 	FormatWAV     = types.FormatWAV
 	FormatAIFF    = types.FormatAIFF
+	FormatWavPack = types.FormatWavPack
+	FormatMKA     = types.FormatMKA
+	FormatDSF     = types.FormatDSF
 )
 
-// DetectFormat is a wrapper around types.DetectFormat.
-// Maintains the public API while delegating to internal implementation.
+// DetectFormat determines the format of a file from its magic bytes,
+// using the extension of path as a tiebreaker. In order of precedence:
+//
+//  1. A recognized file signature decides the format, even when the
+//     extension names another one.
+//  2. A generic MP4 brand ("mp42", "isom") is read as M4A, or as M4B if
+//     the extension is .m4b.
+//  3. Without a signature, the extension decides, but only if the content
+//     looks like that format: MP4 atoms for .m4a and .m4b, such as moov
+//     before ftyp, and an MPEG frame header within the first 4 KiB for
+//     .mp3, as in a stream cut mid-frame.
+//
+// Open records a warning in File.Warnings when the extension conflicts
+// with the signature or is relied on in step 3.
 func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) {
 	return types.DetectFormat(r, size, path)
 }
+
+// detectPeekSize is how much of a stream DetectFormatReader examines,
+// enough for every signature DetectFormat checks. Below detectMinSize
+// bytes, the RIFF and FORM signatures can't be told apart from their
+// forms, so nothing is detected.
+const (
+	detectPeekSize = 64
+	detectMinSize  = 12
+)
+
+// DetectFormatReader determines the format of a stream from its magic
+// bytes, for callers holding an io.Reader rather than an io.ReaderAt,
+// such as an HTTP handler sniffing an upload.
+//
+// The first 64 bytes are peeked through a bufio.Reader, which is returned
+// in place of r: reading from it yields the whole stream, peeked bytes
+// included, so it can be passed on to OpenStream. Use the returned reader
+// even when detection fails.
+//
+// There is no extension to fall back on, so a stream only the extension
+// would identify is FormatUnknown, and an M4B audiobook with a generic
+// MP4 brand is FormatM4A. An ID3v2 tag is reported as MP3 even if a FLAC
+// stream follows it. A stream shorter than 12 bytes is FormatUnknown,
+// without an error.
+//
+// Example:
+//
+//	format, body, err := audiometa.DetectFormatReader(req.Body)
+//	if err != nil {
+//		http.Error(w, "unsupported audio format", http.StatusUnsupportedMediaType)
+//		return
+//	}
+//	file, err := audiometa.OpenStream(body, format)
+func DetectFormatReader(r io.Reader) (Format, io.Reader, error) {
+	br := bufio.NewReaderSize(r, detectPeekSize)
+	head, err := br.Peek(detectPeekSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return FormatUnknown, br, err
+	}
+	if len(head) < detectMinSize {
+		return FormatUnknown, br, nil
+	}
+
+	format, err := types.DetectFormat(bytes.NewReader(head), int64(len(head)), "")
+	return format, br, err
+}
+
+// FormatFromExtension returns the format a path's extension names, or
+// FormatUnknown. Case is ignored; both ".M4B" and ".m4b" name FormatM4B.
+func FormatFromExtension(path string) Format {
+	return types.FormatFromExtension(path)
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
 	"testing"
+	"testing/iotest"
 )
 
 // createMockM4B creates a minimal valid M4B/M4A file header.
@@ -133,6 +135,9 @@ func TestFormat_String(t *testing.T) {
 		{FormatOpus, "Opus"},
 		{FormatWAV, "WAV"},
 		{FormatAIFF, "AIFF"},
+		{FormatWavPack, "WavPack"},
+		{FormatMKA, "Matroska"},
+		{FormatDSF, "DSF"},
 		{FormatUnknown, "Unknown"},
 	}
 
@@ -156,6 +161,9 @@ func TestFormat_Extensions(t *testing.T) {
 		{FormatOpus, []string{".opus"}},
 		{FormatWAV, []string{".wav"}},
 		{FormatAIFF, []string{".aiff", ".aif"}},
+		{FormatWavPack, []string{".wv"}},
+		{FormatMKA, []string{".mka", ".mkv", ".webm"}},
+		{FormatDSF, []string{".dsf"}},
 		{FormatUnknown, nil},
 	}
 
@@ -270,3 +278,49 @@ func TestDetectFormat_AIFF(t *testing.T) {
 		t.Errorf("DetectFormat() = %v, want %v", format, FormatAIFF)
 	}
 }
+
+func TestDetectFormatReader(t *testing.T) {
+	flac := append([]byte("fLaC"), make([]byte, 100)...)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFormat Format
+		wantErr    bool
+	}{
+		{"FLAC", flac, FormatFLAC, false},
+		{"M4B", createMockM4B("M4B "), FormatM4B, false},
+		{"shorter than the peek", flac[:20], FormatFLAC, false},
+		{"too short to detect", flac[:8], FormatUnknown, false},
+		{"unsupported", append(createInvalidFile(), make([]byte, 32)...), FormatUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Hide bytes.Reader's other methods to get a plain io.Reader
+			format, r, err := DetectFormatReader(struct{ io.Reader }{bytes.NewReader(tt.data)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectFormatReader() error = %v, want error %v", err, tt.wantErr)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("DetectFormatReader() = %v, want %v", format, tt.wantFormat)
+			}
+
+			replayed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if !bytes.Equal(replayed, tt.data) {
+				t.Errorf("returned reader yields %d bytes, want the whole %d-byte stream", len(replayed), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestDetectFormatReader_ReadError(t *testing.T) {
+	errRead := errors.New("connection reset")
+	_, _, err := DetectFormatReader(io.MultiReader(bytes.NewReader([]byte("fLaC")), iotest.ErrReader(errRead)))
+	if !errors.Is(err, errRead) {
+		t.Errorf("DetectFormatReader() error = %v, want %v", err, errRead)
+	}
+}
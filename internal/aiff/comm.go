@@ -0,0 +1,165 @@
+// Package aiff provides AIFF and AIFF-C audio file parsing.
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Minimum COMM chunk sizes.
+const (
+	commSizeAIFF = 18 // channels(2) + frames(4) + sample size(2) + sample rate(10)
+	commSizeAIFC = 22 // AIFF fields + compression type(4), followed by a Pascal string
+)
+
+// commChunk holds the decoded fields of a COMM (common) chunk.
+type commChunk struct {
+	CompressionType string // 4-char code; "NONE" for plain AIFF
+	CompressionName string // Human-readable name (AIFF-C only)
+	SampleRate      float64
+	NumSampleFrames uint32
+	NumChannels     int
+	SampleSize      int
+}
+
+// parseCommChunk decodes a COMM chunk.
+//
+// COMM structure (big-endian):
+//
+//	[2 bytes]  number of channels
+//	[4 bytes]  number of sample frames
+//	[2 bytes]  sample size (bits)
+//	[10 bytes] sample rate (80-bit IEEE 754 extended)
+//	AIFF-C only:
+//	[4 bytes]  compression type
+//	[pstring]  compression name
+func parseCommChunk(sr *binutil.SafeReader, offset, size int64, aifc bool) (commChunk, error) {
+	minSize := int64(commSizeAIFF)
+	if aifc {
+		minSize = commSizeAIFC
+	}
+	if size < minSize {
+		return commChunk{}, fmt.Errorf("COMM chunk too small: %d bytes (need %d)", size, minSize)
+	}
+
+	data := make([]byte, size)
+	if err := sr.ReadAt(data, offset, "COMM chunk"); err != nil {
+		return commChunk{}, err
+	}
+
+	comm := commChunk{
+		NumChannels:     int(binary.BigEndian.Uint16(data[0:2])),
+		NumSampleFrames: binary.BigEndian.Uint32(data[2:6]),
+		SampleSize:      int(binary.BigEndian.Uint16(data[6:8])),
+		SampleRate:      decodeExtended(data[8:18]),
+		CompressionType: "NONE",
+	}
+
+	if aifc {
+		comm.CompressionType = string(data[18:22])
+		comm.CompressionName = readPascalString(data[22:])
+	}
+
+	return comm, nil
+}
+
+// decodeExtended converts an 80-bit IEEE 754 extended precision float to float64.
+//
+// Layout: 1 sign bit, 15 exponent bits (bias 16383), 64-bit mantissa with an
+// explicit integer bit.
+func decodeExtended(b []byte) float64 {
+	if len(b) < 10 {
+		return 0
+	}
+
+	sign := b[0] & 0x80
+	exponent := int(binary.BigEndian.Uint16(b[0:2]) & 0x7FFF)
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+
+	if exponent == 0 && mantissa == 0 {
+		return 0
+	}
+	if exponent == 0x7FFF {
+		return math.Inf(1)
+	}
+
+	// value = mantissa * 2^(exponent - 16383 - 63)
+	value := math.Ldexp(float64(mantissa), exponent-16383-63)
+	if sign != 0 {
+		value = -value
+	}
+	return value
+}
+
+// readPascalString reads a count-prefixed string, ignoring any pad byte.
+func readPascalString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	n := int(b[0])
+	if n > len(b)-1 {
+		n = len(b) - 1
+	}
+	return strings.TrimRight(string(b[1:1+n]), "\x00 ")
+}
+
+// compressionCodecs maps AIFF-C compression types to codec names.
+//
+// "NONE", "twos", "sowt", "raw ", "in24", "in32" and the float types are all
+// uncompressed PCM; they differ only in sample layout or byte order.
+var compressionCodecs = map[string]struct {
+	codec        string
+	description  string
+	lossless     bool
+	sampleFormat types.SampleFormat
+	endianness   types.Endianness
+}{
+	"NONE": {"PCM", "Uncompressed PCM (big-endian)", true, types.SampleSignedInt, types.EndianBig},
+	"twos": {"PCM", "Uncompressed PCM (big-endian)", true, types.SampleSignedInt, types.EndianBig},
+	"sowt": {"PCM", "Uncompressed PCM (little-endian)", true, types.SampleSignedInt, types.EndianLittle},
+	"raw ": {"PCM", "Uncompressed PCM (unsigned 8-bit)", true, types.SampleUnsignedInt, types.EndianUnknown},
+	"in24": {"PCM", "Uncompressed PCM (24-bit)", true, types.SampleSignedInt, types.EndianBig},
+	"in32": {"PCM", "Uncompressed PCM (32-bit)", true, types.SampleSignedInt, types.EndianBig},
+	"fl32": {"PCM", "Uncompressed PCM (32-bit float)", true, types.SampleFloat, types.EndianBig},
+	"FL32": {"PCM", "Uncompressed PCM (32-bit float)", true, types.SampleFloat, types.EndianBig},
+	"fl64": {"PCM", "Uncompressed PCM (64-bit float)", true, types.SampleFloat, types.EndianBig},
+	"FL64": {"PCM", "Uncompressed PCM (64-bit float)", true, types.SampleFloat, types.EndianBig},
+	"alaw": {"A-law", "ITU-T G.711 A-law", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"ALAW": {"A-law", "ITU-T G.711 A-law", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"ulaw": {"μ-law", "ITU-T G.711 μ-law", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"ULAW": {"μ-law", "ITU-T G.711 μ-law", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"ima4": {"IMA ADPCM", "IMA 4:1 ADPCM", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"MAC3": {"MACE", "MACE 3-to-1", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"MAC6": {"MACE", "MACE 6-to-1", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"QDMC": {"QDesign", "QDesign Music", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"QDM2": {"QDesign", "QDesign Music 2", false, types.SampleFormatUnknown, types.EndianUnknown},
+	"GSM ": {"GSM", "GSM 06.10", false, types.SampleFormatUnknown, types.EndianUnknown},
+}
+
+// applyCompression sets codec fields from the COMM compression type.
+//
+// The compression name stored in the file takes precedence over the built-in
+// description, since it is what the encoder meant to display.
+func applyCompression(comm commChunk, audio *types.AudioInfo) {
+	info, ok := compressionCodecs[comm.CompressionType]
+	if !ok {
+		// Unknown compression - keep the 4-char code so it's not lost
+		audio.Codec = strings.TrimSpace(comm.CompressionType)
+		audio.CodecDescription = comm.CompressionName
+		return
+	}
+
+	audio.Codec = info.codec
+	audio.CodecDescription = info.description
+	if comm.CompressionName != "" {
+		audio.CodecDescription = comm.CompressionName
+	}
+	audio.Lossless = info.lossless
+	audio.SampleFormat = info.sampleFormat
+	audio.Endianness = info.endianness
+}
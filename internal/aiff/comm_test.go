@@ -0,0 +1,195 @@
+package aiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+	"testing"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// createCommData creates an AIFF-C COMM chunk payload.
+func createCommData(compression, name string) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint16(2))              // channels
+	binary.Write(buf, binary.BigEndian, uint32(44100))          // sample frames
+	binary.Write(buf, binary.BigEndian, uint16(16))             // sample size
+	buf.Write([]byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}) // 44100 Hz
+	buf.WriteString(compression)
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+	if (len(name)+1)%2 != 0 {
+		buf.WriteByte(0) // pad to even length
+	}
+	return buf.Bytes()
+}
+
+// encodeExtended encodes a positive integer sample rate as an 80-bit
+// extended float, normalizing the mantissa so its top bit is set.
+func encodeExtended(rate uint64) []byte {
+	shift := bits.LeadingZeros64(rate)
+	b := make([]byte, 10)
+	binary.BigEndian.PutUint16(b[0:2], uint16(16383+63-shift))
+	binary.BigEndian.PutUint64(b[2:10], rate<<shift)
+	return b
+}
+
+func TestDecodeExtended(t *testing.T) {
+	tests := []struct {
+		rate    uint64
+		encoded []byte
+	}{
+		{8000, []byte{0x40, 0x0B, 0xFA, 0, 0, 0, 0, 0, 0, 0}},
+		{22050, []byte{0x40, 0x0D, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}},
+		{44100, []byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}},
+		{48000, []byte{0x40, 0x0E, 0xBB, 0x80, 0, 0, 0, 0, 0, 0}},
+		{96000, []byte{0x40, 0x0F, 0xBB, 0x80, 0, 0, 0, 0, 0, 0}},
+		{192000, []byte{0x40, 0x10, 0xBB, 0x80, 0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		if got := encodeExtended(tt.rate); !bytes.Equal(got, tt.encoded) {
+			t.Errorf("encodeExtended(%d) = % X, want % X", tt.rate, got, tt.encoded)
+		}
+		if got := decodeExtended(tt.encoded); got != float64(tt.rate) {
+			t.Errorf("decodeExtended(% X) = %v, want exactly %d", tt.encoded, got, tt.rate)
+		}
+	}
+
+	if got := decodeExtended(make([]byte, 10)); got != 0 {
+		t.Errorf("decodeExtended(zero) = %v, want 0", got)
+	}
+	if got := decodeExtended([]byte{0x40, 0x0E}); got != 0 {
+		t.Errorf("decodeExtended(short) = %v, want 0", got)
+	}
+}
+
+func TestParseCommChunk_Sowt(t *testing.T) {
+	data := createCommData("sowt", "")
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.aifc")
+
+	comm, err := parseCommChunk(sr, 0, int64(len(data)), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if comm.CompressionType != "sowt" {
+		t.Errorf("expected compression type sowt, got %q", comm.CompressionType)
+	}
+	if comm.NumChannels != 2 || comm.SampleSize != 16 || comm.NumSampleFrames != 44100 {
+		t.Errorf("unexpected COMM fields: %+v", comm)
+	}
+
+	var audio types.AudioInfo
+	applyCompression(comm, &audio)
+
+	if audio.Codec != "PCM" {
+		t.Errorf("expected codec PCM, got %q", audio.Codec)
+	}
+	if audio.CodecDescription != "Uncompressed PCM (little-endian)" {
+		t.Errorf("unexpected codec description %q", audio.CodecDescription)
+	}
+	if !audio.Lossless {
+		t.Error("expected sowt to be lossless")
+	}
+}
+
+func TestApplyCompression(t *testing.T) {
+	tests := []struct {
+		compression     string
+		name            string
+		wantCodec       string
+		wantDescription string
+		wantLossless    bool
+	}{
+		{"NONE", "not compressed", "PCM", "not compressed", true},
+		{"twos", "", "PCM", "Uncompressed PCM (big-endian)", true},
+		{"ulaw", "µLaw 2:1", "μ-law", "µLaw 2:1", false},
+		{"alaw", "", "A-law", "ITU-T G.711 A-law", false},
+		{"ima4", "IMA 4:1", "IMA ADPCM", "IMA 4:1", false},
+		{"QDM2", "QDesign Music 2", "QDesign", "QDesign Music 2", false},
+		{"ABCD", "Mystery Codec", "ABCD", "Mystery Codec", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compression, func(t *testing.T) {
+			data := createCommData(tt.compression, tt.name)
+			sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.aifc")
+
+			comm, err := parseCommChunk(sr, 0, int64(len(data)), true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if comm.CompressionName != tt.name {
+				t.Errorf("expected compression name %q, got %q", tt.name, comm.CompressionName)
+			}
+
+			var audio types.AudioInfo
+			applyCompression(comm, &audio)
+
+			if audio.Codec != tt.wantCodec {
+				t.Errorf("expected codec %q, got %q", tt.wantCodec, audio.Codec)
+			}
+			if audio.CodecDescription != tt.wantDescription {
+				t.Errorf("expected description %q, got %q", tt.wantDescription, audio.CodecDescription)
+			}
+			if audio.Lossless != tt.wantLossless {
+				t.Errorf("expected lossless=%v, got %v", tt.wantLossless, audio.Lossless)
+			}
+		})
+	}
+}
+
+func TestApplyCompression_SampleFormat(t *testing.T) {
+	tests := []struct {
+		compression string
+		wantFormat  types.SampleFormat
+		wantEndian  types.Endianness
+	}{
+		{"NONE", types.SampleSignedInt, types.EndianBig},
+		{"sowt", types.SampleSignedInt, types.EndianLittle},
+		{"raw ", types.SampleUnsignedInt, types.EndianUnknown},
+		{"fl32", types.SampleFloat, types.EndianBig},
+		{"fl64", types.SampleFloat, types.EndianBig},
+		{"ulaw", types.SampleFormatUnknown, types.EndianUnknown},
+		{"ABCD", types.SampleFormatUnknown, types.EndianUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compression, func(t *testing.T) {
+			var audio types.AudioInfo
+			applyCompression(commChunk{CompressionType: tt.compression}, &audio)
+
+			if audio.SampleFormat != tt.wantFormat {
+				t.Errorf("expected sample format %v, got %v", tt.wantFormat, audio.SampleFormat)
+			}
+			if audio.Endianness != tt.wantEndian {
+				t.Errorf("expected endianness %v, got %v", tt.wantEndian, audio.Endianness)
+			}
+		})
+	}
+}
+
+func TestParseCommChunk_PlainAIFF(t *testing.T) {
+	data := createCommData("", "")[:commSizeAIFF]
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.aiff")
+
+	comm, err := parseCommChunk(sr, 0, int64(len(data)), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comm.CompressionType != "NONE" {
+		t.Errorf("expected NONE for plain AIFF, got %q", comm.CompressionType)
+	}
+}
+
+func TestParseCommChunk_TooSmall(t *testing.T) {
+	data := make([]byte, 10)
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.aiff")
+
+	if _, err := parseCommChunk(sr, 0, int64(len(data)), false); err == nil {
+		t.Error("expected error for truncated COMM chunk")
+	}
+}
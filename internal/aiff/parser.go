@@ -0,0 +1,189 @@
+package aiff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"time"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/mp3"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/riff"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Container names.
+const (
+	containerAIFF = "AIFF"
+	containerAIFC = "AIFF-C"
+)
+
+// ssndHeaderSize is the offset and block size that precede the sound data
+// in an SSND chunk.
+const ssndHeaderSize = 8
+
+// parser implements the audiometa.FormatParser interface for AIFF files.
+type parser struct{}
+
+// Parse parses an AIFF or AIFF-C file and extracts metadata.
+//
+// Audio properties come from the COMM chunk, with the duration computed
+// from its frame count. Tags come from the NAME, AUTH, "(c) ", ANNO and
+// COMT chunks and an ID3 chunk, merged by riff.MergeTags.
+func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path string) (*types.File, error) { //nolint:gocyclo // Chunk dispatch requires multiple conditional branches
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+	form, err := riff.ReadForm(sr)
+	if err != nil {
+		return nil, err
+	}
+	if form.ID != "FORM" || (form.Type != "AIFF" && form.Type != "AIFC") {
+		return nil, &types.CorruptedFileError{
+			Path:   path,
+			Reason: fmt.Sprintf("not an AIFF file: form %q type %q", form.ID, form.Type),
+		}
+	}
+	aifc := form.Type == "AIFC"
+
+	file := &types.File{
+		Path:   path,
+		Format: types.FormatAIFF,
+		Size:   size,
+	}
+	file.Audio.Container = containerAIFF
+	if aifc {
+		file.Audio.Container = containerAIFC
+	}
+
+	charset := registry.LegacyCharset(ctx)
+	log := registry.Logger(ctx)
+	var comm *commChunk
+	var soundSize int64 = -1
+	var id3Tags *types.Tags
+	native := types.NewTags()
+	hasNative := false
+
+	warn := func(what string, chunk riff.Chunk, err error) {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: fmt.Sprintf("failed to parse %s chunk: %v", what, err),
+			Err:     err,
+			Offset:  chunk.Offset,
+		})
+	}
+
+	scanner := form.Chunks(sr)
+	for chunk := range scanner.All() {
+		log.LogAttrs(ctx, slog.LevelDebug, "chunk",
+			slog.String("format", "AIFF"), slog.String("key", chunk.ID),
+			slog.Int64("offset", chunk.Offset), slog.Int64("size", chunk.Size))
+		switch chunk.ID {
+		case "COMM":
+			c, err := parseCommChunk(sr, chunk.Offset, chunk.Size, aifc)
+			if err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "technical",
+					Message: fmt.Sprintf("failed to parse COMM chunk: %v", err),
+					Err:     err,
+					Offset:  chunk.Offset,
+				})
+				continue
+			}
+			comm = &c
+
+		case "SSND":
+			soundSize = max(chunk.Size-ssndHeaderSize, 0)
+
+		case "NAME", "AUTH", "(c) ", "ANNO":
+			if err := parseTextChunk(sr, chunk, charset, native); err != nil {
+				warn(chunk.ID, chunk, err)
+			}
+			hasNative = true
+
+		case "COMT":
+			if err := parseCommentsChunk(sr, chunk, charset, native); err != nil {
+				warn("COMT", chunk, err)
+			}
+			hasNative = true
+
+		case "ID3 ", "id3 ":
+			scratch := &types.File{Path: path, Format: types.FormatAIFF, Size: size}
+			if err := mp3.ReadID3v2(ctx, sr, chunk.Offset, chunk.Size, scratch, charset); err != nil {
+				warn("ID3", chunk, err)
+				continue
+			}
+			id3Tags = &scratch.Tags
+			file.Chapters, file.ChapterSource = scratch.Chapters, scratch.ChapterSource
+			file.Audio.ReplayGain = scratch.Audio.ReplayGain
+			file.Warnings = append(file.Warnings, scratch.Warnings...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "technical",
+			Message:  fmt.Sprintf("truncated file: %v", err),
+			Severity: types.SeverityError,
+			Err:      err,
+		})
+	}
+
+	if comm == nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: "missing COMM chunk; audio properties unknown",
+		})
+	} else if err := applyComm(*comm, soundSize, &file.Audio); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: fmt.Sprintf("failed to read AIFF COMM chunk: %v", err),
+			Err:     err,
+		})
+	}
+
+	if !hasNative {
+		native = nil
+	}
+	riff.MergeTags(file, id3Tags, native)
+
+	return file, nil
+}
+
+// applyComm sets the audio properties from a COMM chunk and, for
+// compressed audio, the SSND data size (-1 if there is none).
+//
+// A sample rate that is not a positive number in int32 range, such as the
+// infinity an all-ones exponent decodes to, leaves SampleRate, Duration
+// and Bitrate unset and is returned as an error.
+func applyComm(comm commChunk, soundSize int64, audio *types.AudioInfo) error {
+	applyCompression(comm, audio)
+	audio.Channels = comm.NumChannels
+	if audio.Lossless {
+		// Compressed formats store a nominal sample size, not a bit depth
+		audio.BitDepth = comm.SampleSize
+	}
+
+	if math.IsNaN(comm.SampleRate) || comm.SampleRate <= 0 || comm.SampleRate >= math.MaxInt32 {
+		return fmt.Errorf("invalid sample rate %v", comm.SampleRate)
+	}
+	audio.SampleRate = int(comm.SampleRate + 0.5)
+	seconds := float64(comm.NumSampleFrames) / comm.SampleRate
+	audio.Duration = time.Duration(seconds * float64(time.Second))
+
+	switch {
+	case audio.Lossless:
+		audio.Bitrate = audio.SampleRate * audio.Channels * audio.BitDepth
+	case soundSize > 0 && seconds > 0:
+		audio.Bitrate = int(float64(soundSize*8) / seconds)
+	}
+	return nil
+}
+
+func init() {
+	registry.Register(types.FormatAIFF, &parser{})
+}
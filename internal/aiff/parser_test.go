@@ -0,0 +1,253 @@
+package aiff
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// chunk encodes an IFF chunk, padding odd payloads.
+func chunk(id string, payload []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(id)
+	binary.Write(buf, binary.BigEndian, uint32(len(payload)))
+	buf.Write(payload)
+	if len(payload)%2 == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// createAIFF wraps chunks in a FORM header of the given type.
+func createAIFF(formType string, chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	buf := &bytes.Buffer{}
+	buf.WriteString("FORM")
+	binary.Write(buf, binary.BigEndian, uint32(4+len(body)))
+	buf.WriteString(formType)
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// commChunkData encodes a COMM chunk. A non-empty compression makes it an
+// AIFF-C COMM chunk.
+func commChunkData(channels int, frames uint32, bits int, rate uint64, compression string) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint16(channels))
+	binary.Write(buf, binary.BigEndian, frames)
+	binary.Write(buf, binary.BigEndian, uint16(bits))
+	buf.Write(encodeExtended(rate))
+	if compression != "" {
+		buf.WriteString(compression)
+		buf.Write([]byte{0, 0}) // empty compression name, padded
+	}
+	return chunk("COMM", buf.Bytes())
+}
+
+// commentsChunk encodes a COMT chunk.
+func commentsChunk(texts ...string) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint16(len(texts)))
+	for _, text := range texts {
+		binary.Write(buf, binary.BigEndian, uint32(0)) // timestamp
+		binary.Write(buf, binary.BigEndian, uint16(0)) // marker ID
+		binary.Write(buf, binary.BigEndian, uint16(len(text)))
+		buf.WriteString(text)
+		if len(text)%2 == 1 {
+			buf.WriteByte(0)
+		}
+	}
+	return chunk("COMT", buf.Bytes())
+}
+
+func parseAIFF(t *testing.T, data []byte) *types.File {
+	t.Helper()
+	file, err := (&parser{}).Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.aiff")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	return file
+}
+
+func TestParse_AudioProperties(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		wantRate      int
+		wantBits      int
+		wantDuration  time.Duration
+		wantCodec     string
+		wantContainer string
+		wantBitrate   int
+	}{
+		{
+			name:     "AIFF 16-bit 44.1 kHz",
+			data:     createAIFF("AIFF", commChunkData(2, 88200, 16, 44100, "")),
+			wantRate: 44100, wantBits: 16, wantDuration: 2 * time.Second,
+			wantCodec: "PCM", wantContainer: "AIFF", wantBitrate: 1411200,
+		},
+		{
+			name:     "AIFF 24-bit 96 kHz",
+			data:     createAIFF("AIFF", commChunkData(2, 48000, 24, 96000, "")),
+			wantRate: 96000, wantBits: 24, wantDuration: 500 * time.Millisecond,
+			wantCodec: "PCM", wantContainer: "AIFF", wantBitrate: 4608000,
+		},
+		{
+			name:     "AIFF-C sowt 48 kHz",
+			data:     createAIFF("AIFC", commChunkData(2, 48000, 16, 48000, "sowt")),
+			wantRate: 48000, wantBits: 16, wantDuration: time.Second,
+			wantCodec: "PCM", wantContainer: "AIFF-C", wantBitrate: 1536000,
+		},
+		{
+			name: "AIFF-C ima4",
+			data: createAIFF("AIFC",
+				commChunkData(1, 44100, 16, 44100, "ima4"),
+				chunk("SSND", make([]byte, 8+22050))),
+			wantRate: 44100, wantBits: 0, wantDuration: time.Second,
+			wantCodec: "IMA ADPCM", wantContainer: "AIFF-C", wantBitrate: 176400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parseAIFF(t, tt.data)
+
+			a := file.Audio
+			if a.SampleRate != tt.wantRate || a.BitDepth != tt.wantBits {
+				t.Errorf("got %d Hz, %d bits; want %d Hz, %d bits", a.SampleRate, a.BitDepth, tt.wantRate, tt.wantBits)
+			}
+			if a.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", a.Duration, tt.wantDuration)
+			}
+			if a.Codec != tt.wantCodec || a.Container != tt.wantContainer {
+				t.Errorf("Codec = %q, Container = %q; want %q, %q", a.Codec, a.Container, tt.wantCodec, tt.wantContainer)
+			}
+			if a.Bitrate != tt.wantBitrate {
+				t.Errorf("Bitrate = %d, want %d", a.Bitrate, tt.wantBitrate)
+			}
+			if file.Format != types.FormatAIFF || len(file.Warnings) > 0 {
+				t.Errorf("Format = %v, warnings %v", file.Format, file.Warnings)
+			}
+		})
+	}
+}
+
+func TestParse_TextChunks(t *testing.T) {
+	data := createAIFF("AIFF",
+		commChunkData(2, 44100, 16, 44100, ""),
+		chunk("NAME", []byte("Song")),
+		chunk("AUTH", []byte("Artist")),
+		chunk("(c) ", []byte("2020 Label")),
+		chunk("ANNO", []byte("First note")),
+		chunk("ANNO", []byte("Second note")),
+		commentsChunk("Timestamped"),
+	)
+	tags := parseAIFF(t, data).Tags
+
+	if tags.Title != "Song" || tags.Artist != "Artist" || tags.Copyright != "2020 Label" {
+		t.Errorf("Title/Artist/Copyright = %q/%q/%q", tags.Title, tags.Artist, tags.Copyright)
+	}
+	if tags.Comment != "First note" {
+		t.Errorf("Comment = %q, want the first annotation", tags.Comment)
+	}
+	var texts []string
+	for _, c := range tags.Comments {
+		texts = append(texts, c.Text)
+	}
+	if want := []string{"First note", "Second note", "Timestamped"}; !slices.Equal(texts, want) {
+		t.Errorf("Comments = %q, want %q", texts, want)
+	}
+	if got := tags.Get("ANNO"); len(got) != 2 {
+		t.Errorf("raw ANNO = %q, want both annotations", got)
+	}
+}
+
+func TestParse_ID3Chunk(t *testing.T) {
+	// v2.3 tag with a TIT2 frame
+	tag := []byte("ID3\x03\x00\x00\x00\x00\x00\x14" +
+		"TIT2\x00\x00\x00\x0A\x00\x00\x00ID3 Title")
+	data := createAIFF("AIFF",
+		chunk("ID3 ", tag),
+		commChunkData(2, 44100, 16, 44100, ""),
+		chunk("NAME", []byte("NAME Title")),
+		chunk("AUTH", []byte("AUTH Artist")),
+	)
+	file := parseAIFF(t, data)
+
+	if file.Tags.Title != "ID3 Title" || file.Tags.Artist != "AUTH Artist" {
+		t.Errorf("Title = %q, Artist = %q; want ID3 title and AUTH artist", file.Tags.Title, file.Tags.Artist)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "metadata" {
+		t.Errorf("expected one metadata warning for the Title conflict, got %v", file.Warnings)
+	}
+}
+
+func TestParse_BadCommentsChunk(t *testing.T) {
+	comt := commentsChunk("Kept")
+	comt[9] = 2 // claims a second comment that isn't there
+	data := createAIFF("AIFF", commChunkData(1, 8000, 8, 8000, ""), comt)
+	file := parseAIFF(t, data)
+
+	if len(file.Tags.Comments) != 1 || file.Tags.Comment != "Kept" {
+		t.Errorf("Comments = %v, want the complete comment kept", file.Tags.Comments)
+	}
+	if len(file.Warnings) != 1 {
+		t.Errorf("expected one warning, got %v", file.Warnings)
+	}
+}
+
+func TestParse_MissingComm(t *testing.T) {
+	file := parseAIFF(t, createAIFF("AIFF", chunk("NAME", []byte("Song"))))
+	if len(file.Warnings) != 1 || file.Audio.SampleRate != 0 || file.Tags.Title != "Song" {
+		t.Errorf("expected a warning, no audio properties and the title, got %+v", file)
+	}
+}
+
+func TestParse_InvalidSampleRate(t *testing.T) {
+	comm := func(rate []byte) []byte {
+		data := commChunkData(2, 44100, 16, 44100, "")
+		copy(data[8+8:], rate)
+		return data
+	}
+
+	tests := []struct {
+		name string
+		rate []byte
+	}{
+		{"infinite", []byte{0x7F, 0xFF, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{"negative", []byte{0xC0, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}},
+		{"out of range", []byte{0x43, 0xFF, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{"zero", make([]byte, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parseAIFF(t, createAIFF("AIFF", comm(tt.rate)))
+
+			if file.Audio.SampleRate != 0 || file.Audio.Duration != 0 || file.Audio.Bitrate != 0 {
+				t.Errorf("SampleRate, Duration, Bitrate = %d, %v, %d; want none", file.Audio.SampleRate, file.Audio.Duration, file.Audio.Bitrate)
+			}
+			if file.Audio.Channels != 2 {
+				t.Errorf("Channels = %d, want 2 read regardless", file.Audio.Channels)
+			}
+			if len(file.Warnings) != 1 || file.Warnings[0].Stage != "technical" {
+				t.Errorf("Warnings = %v, want one technical warning", file.Warnings)
+			}
+		})
+	}
+}
+
+func TestParse_NotAIFF(t *testing.T) {
+	_, err := (&parser{}).Parse(context.Background(), bytes.NewReader(createAIFF("8SVX")), 12, "test.iff")
+
+	var corrupted *types.CorruptedFileError
+	if !errors.As(err, &corrupted) {
+		t.Errorf("Parse() error = %v, want CorruptedFileError", err)
+	}
+}
@@ -0,0 +1,90 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/riff"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// textChunks maps AIFF text chunk IDs to the Tags fields they set. Each
+// chunk's payload is the whole string.
+var textChunks = map[string]func(*types.Tags, string){
+	"NAME": func(t *types.Tags, v string) { t.Title = v },
+	"AUTH": func(t *types.Tags, v string) {
+		t.Artist = v
+		t.Artists = append(t.Artists, v)
+	},
+	"(c) ": func(t *types.Tags, v string) { t.Copyright = v },
+	"ANNO": func(t *types.Tags, v string) { addComment(t, types.Comment{Text: v}) },
+}
+
+// addComment appends c to the comments and keeps Comment on the first one.
+func addComment(t *types.Tags, c types.Comment) {
+	t.Comments = append(t.Comments, c)
+	if t.Comment == "" {
+		t.Comment = c.Text
+	}
+}
+
+// parseTextChunk applies a NAME, AUTH, "(c) " or ANNO chunk to tags and
+// stores its text raw under the chunk ID. ANNO may repeat; every other
+// chunk replaces an earlier one.
+func parseTextChunk(sr *binutil.SafeReader, chunk riff.Chunk, charset types.Charset, tags *types.Tags) error {
+	data := make([]byte, chunk.Size)
+	if err := sr.ReadAt(data, chunk.Offset, chunk.ID+" chunk"); err != nil {
+		return err
+	}
+	value := riff.DecodeText(data, charset)
+	if value == "" {
+		return nil
+	}
+
+	textChunks[chunk.ID](tags, value)
+	if chunk.ID == "ANNO" {
+		tags.Set(chunk.ID, append(tags.Get(chunk.ID), value)...)
+	} else {
+		tags.Set(chunk.ID, value)
+	}
+	return nil
+}
+
+// parseCommentsChunk appends the comments of a COMT chunk to tags.
+//
+// COMT structure (big-endian):
+//
+//	[2 bytes] number of comments
+//	then per comment:
+//	[4 bytes] timestamp (seconds since 1904)
+//	[2 bytes] marker ID, 0 if none
+//	[2 bytes] text length
+//	[n bytes] text, padded to an even length
+func parseCommentsChunk(sr *binutil.SafeReader, chunk riff.Chunk, charset types.Charset, tags *types.Tags) error {
+	if chunk.Size < 2 {
+		return fmt.Errorf("COMT chunk too small: %d bytes", chunk.Size)
+	}
+	data := make([]byte, chunk.Size)
+	if err := sr.ReadAt(data, chunk.Offset, "COMT chunk"); err != nil {
+		return err
+	}
+
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	pos := 2
+	for i := range count {
+		if pos+8 > len(data) {
+			return fmt.Errorf("COMT comment %d of %d truncated at offset %d", i+1, count, chunk.Offset+int64(pos))
+		}
+		n := int(binary.BigEndian.Uint16(data[pos+6 : pos+8]))
+		pos += 8
+		if pos+n > len(data) {
+			return fmt.Errorf("COMT comment %d of %d declares %d bytes but only %d remain", i+1, count, n, len(data)-pos)
+		}
+		if text := riff.DecodeText(data[pos:pos+n], charset); text != "" {
+			addComment(tags, types.Comment{Text: text})
+		}
+		pos += n + n&1
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+// Package apev2 reads APEv2 tags, the trailing tag format of Monkey's
+// Audio and WavPack files that many MP3 taggers also write.
+//
+// An APEv2 tag is a list of key/value items framed by a 32-byte footer
+// (and, optionally, an identical header). It sits at the very end of the
+// file or just before a trailing ID3v1 tag.
+package apev2
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Tag framing sizes.
+const (
+	footerSize    = 32  // "APETAGEX" + version + size + count + flags + reserved
+	id3v1Size     = 128 // An ID3v1 tag may follow the APEv2 footer
+	itemHeaderLen = 8   // value size (4) + item flags (4), then the key
+)
+
+// preamble opens both the header and the footer.
+const preamble = "APETAGEX"
+
+// flagIsHeader is set in the flags of a header, clear in a footer.
+const flagIsHeader = 1 << 29
+
+// Item flags: bits 1-2 hold the value type. Bit 0 marks read-only items,
+// which only matters to writers.
+const (
+	itemTypeMask   = 0x6
+	itemTypeText   = 0x0 // UTF-8 text; NUL separates multiple values
+	itemTypeBinary = 0x2
+	itemTypeLink   = 0x4 // UTF-8 locator of external data
+)
+
+// Key length limits from the specification.
+const (
+	minKeyLen = 2
+	maxKeyLen = 255
+)
+
+// footer is the decoded APEv2 footer.
+type footer struct {
+	Version int   // 1000 (APEv1) or 2000
+	Size    int64 // Items plus footer, excluding any header
+	Count   uint32
+	Flags   uint32
+}
+
+// Parse reads an APEv2 tag ending at size, or just before a trailing ID3v1
+// tag, into file.Tags. A file without an APEv2 tag is not an error; Parse
+// returns nil and leaves file as is.
+//
+// Text items are stored raw under their key as written, with one value
+// per NUL-separated entry; see applyItem for the keys mapped to fields.
+// Binary "Cover Art" items only set HasEmbeddedArtwork, and other binary
+// items are skipped. Read-only items are read like any other.
+//
+// Footer layout (little-endian):
+//
+//	[8 bytes] "APETAGEX"
+//	[4 bytes] version (2000)
+//	[4 bytes] tag size, items plus footer
+//	[4 bytes] item count
+//	[4 bytes] flags
+//	[8 bytes] reserved
+func Parse(sr *binutil.SafeReader, size int64, file *types.File) error {
+	end := tagEnd(sr, size)
+	if end < footerSize {
+		return nil
+	}
+
+	f, ok, err := readFooter(sr, end-footerSize)
+	if err != nil || !ok {
+		return err
+	}
+
+	start := end - f.Size
+	if f.Size < footerSize || start < 0 {
+		return &types.CorruptedFileError{
+			Path:   sr.Path(),
+			Reason: fmt.Sprintf("APEv2 tag size %d does not fit in %d bytes", f.Size, end),
+			Offset: end - footerSize,
+		}
+	}
+
+	items := make([]byte, f.Size-footerSize)
+	if err := sr.ReadAt(items, start, "APEv2 items"); err != nil {
+		return err
+	}
+	return parseItems(items, f.Count, start, file)
+}
+
+// tagEnd returns the offset just past where an APEv2 footer would be:
+// before an ID3v1 tag if there is one, else the end of the file.
+func tagEnd(sr *binutil.SafeReader, size int64) int64 {
+	if size < id3v1Size {
+		return size
+	}
+	magic := make([]byte, 3)
+	if err := sr.ReadAt(magic, size-id3v1Size, "ID3v1 marker"); err == nil && string(magic) == "TAG" {
+		return size - id3v1Size
+	}
+	return size
+}
+
+// readFooter reads the footer at offset. ok is false when there is none.
+func readFooter(sr *binutil.SafeReader, offset int64) (footer, bool, error) {
+	data := make([]byte, footerSize)
+	if err := sr.ReadAt(data, offset, "APEv2 footer"); err != nil {
+		return footer{}, false, err
+	}
+	if string(data[0:8]) != preamble {
+		return footer{}, false, nil
+	}
+
+	f := footer{
+		Version: int(binary.LittleEndian.Uint32(data[8:12])),
+		Size:    int64(binary.LittleEndian.Uint32(data[12:16])),
+		Count:   binary.LittleEndian.Uint32(data[16:20]),
+		Flags:   binary.LittleEndian.Uint32(data[20:24]),
+	}
+	if f.Flags&flagIsHeader != 0 {
+		// A header where the footer belongs: the tag is unterminated
+		return footer{}, false, &types.CorruptedFileError{
+			Path:   sr.Path(),
+			Reason: "APEv2 header found in footer position",
+			Offset: offset,
+		}
+	}
+	return f, true, nil
+}
+
+// parseItems walks count items in data, which starts at file offset base.
+//
+// Item layout:
+//
+//	[4 bytes] value size
+//	[4 bytes] item flags
+//	[n bytes] key, ASCII, NUL-terminated
+//	[m bytes] value
+func parseItems(data []byte, count uint32, base int64, file *types.File) error {
+	pos := 0
+	for i := range count {
+		if pos+itemHeaderLen > len(data) {
+			return fmt.Errorf("APEv2 item %d of %d truncated at offset %d", i+1, count, base+int64(pos))
+		}
+		valueSize := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+		flags := binary.LittleEndian.Uint32(data[pos+4 : pos+8])
+
+		keyStart := pos + itemHeaderLen
+		keyEnd := keyStart
+		for keyEnd < len(data) && data[keyEnd] != 0 {
+			keyEnd++
+		}
+		key := string(data[keyStart:keyEnd])
+		if keyEnd == len(data) || !validKey(key) {
+			return fmt.Errorf("APEv2 item %d has an invalid key at offset %d", i+1, base+int64(keyStart))
+		}
+
+		valueStart := keyEnd + 1
+		if valueSize < 0 || valueSize > len(data)-valueStart {
+			return fmt.Errorf("APEv2 item %q declares %d bytes but only %d remain", key, valueSize, len(data)-valueStart)
+		}
+		value := data[valueStart : valueStart+valueSize]
+		pos = valueStart + valueSize
+
+		switch flags & itemTypeMask {
+		case itemTypeText, itemTypeLink:
+			applyItem(key, splitValues(value), file)
+		case itemTypeBinary:
+			if isCoverArt(key) {
+				file.HasEmbeddedArtwork = true
+			}
+		}
+	}
+	return nil
+}
+
+// validKey reports whether key is a legal item key: 2 to 255 printable
+// ASCII characters.
+func validKey(key string) bool {
+	if len(key) < minKeyLen || len(key) > maxKeyLen {
+		return false
+	}
+	for i := range len(key) {
+		if key[i] < 0x20 || key[i] > 0x7E {
+			return false
+		}
+	}
+	return true
+}
+
+// splitValues splits a text value on the NULs that separate multiple
+// values, dropping empty entries.
+func splitValues(value []byte) []string {
+	var values []string
+	start := 0
+	for i := 0; i <= len(value); i++ {
+		if i == len(value) || value[i] == 0 {
+			if i > start {
+				values = append(values, string(value[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return values
+}
@@ -0,0 +1,153 @@
+package apev2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// item is one APEv2 item for createTag.
+type item struct {
+	key   string
+	value string
+	flags uint32
+}
+
+// createTag builds an APEv2 tag with a header and footer.
+func createTag(items ...item) []byte {
+	body := &bytes.Buffer{}
+	for _, it := range items {
+		binary.Write(body, binary.LittleEndian, uint32(len(it.value)))
+		binary.Write(body, binary.LittleEndian, it.flags)
+		body.WriteString(it.key)
+		body.WriteByte(0)
+		body.WriteString(it.value)
+	}
+
+	frame := func(flags uint32) []byte {
+		buf := &bytes.Buffer{}
+		buf.WriteString(preamble)
+		binary.Write(buf, binary.LittleEndian, uint32(2000))
+		binary.Write(buf, binary.LittleEndian, uint32(body.Len()+footerSize))
+		binary.Write(buf, binary.LittleEndian, uint32(len(items)))
+		binary.Write(buf, binary.LittleEndian, flags)
+		buf.Write(make([]byte, 8))
+		return buf.Bytes()
+	}
+
+	tag := frame(1<<31 | flagIsHeader)
+	tag = append(tag, body.Bytes()...)
+	return append(tag, frame(1<<31)...)
+}
+
+func parseData(t *testing.T, data []byte) (*types.File, error) {
+	t.Helper()
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.ape")
+	file := &types.File{}
+	err := Parse(sr, int64(len(data)), file)
+	return file, err
+}
+
+func TestParse(t *testing.T) {
+	data := append([]byte("audio data"), createTag(
+		item{key: "Title", value: "Song"},
+		item{key: "ARTIST", value: "First\x00Second"},
+		item{key: "Album", value: "Album", flags: 1}, // read-only
+		item{key: "Album Artist", value: "Band"},
+		item{key: "Track", value: "3/12"},
+		item{key: "Disc", value: "1/2"},
+		item{key: "Year", value: "2004-05-06"},
+		item{key: "Genre", value: "Rock\x00Pop"},
+		item{key: "Comment", value: "Nice"},
+		item{key: "REPLAYGAIN_TRACK_GAIN", value: "-6.50 dB"},
+		item{key: "Mood", value: "Mellow"},
+		item{key: "Cover Art (Front)", value: "cover.jpg\x00\xFF\xD8", flags: itemTypeBinary},
+	)...)
+
+	file, err := parseData(t, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags := file.Tags
+	if tags.Title != "Song" || tags.Album != "Album" || tags.AlbumArtist != "Band" {
+		t.Errorf("Title/Album/AlbumArtist = %q/%q/%q", tags.Title, tags.Album, tags.AlbumArtist)
+	}
+	if tags.Artist != "First" || !slices.Equal(tags.Artists, []string{"First", "Second"}) {
+		t.Errorf("Artist = %q, Artists = %v", tags.Artist, tags.Artists)
+	}
+	if tags.TrackNumber != 3 || tags.TrackTotal != 12 || tags.DiscNumber != 1 || tags.DiscTotal != 2 {
+		t.Errorf("track %d/%d, disc %d/%d", tags.TrackNumber, tags.TrackTotal, tags.DiscNumber, tags.DiscTotal)
+	}
+	if tags.Year != 2004 || tags.Date != "2004-05-06" {
+		t.Errorf("Year = %d, Date = %q", tags.Year, tags.Date)
+	}
+	if !slices.Equal(tags.Genres, []string{"Rock", "Pop"}) || tags.Comment != "Nice" {
+		t.Errorf("Genres = %v, Comment = %q", tags.Genres, tags.Comment)
+	}
+	if rg := file.Audio.ReplayGain; rg == nil || rg.TrackGain != -6.5 {
+		t.Errorf("ReplayGain = %+v, want track gain -6.5", rg)
+	}
+	if got := tags.Get("Mood"); !slices.Equal(got, []string{"Mellow"}) {
+		t.Errorf("raw Mood = %v, want [Mellow]", got)
+	}
+	if !file.HasEmbeddedArtwork {
+		t.Error("expected HasEmbeddedArtwork from the cover art item")
+	}
+}
+
+func TestParse_BeforeID3v1(t *testing.T) {
+	id3v1 := make([]byte, id3v1Size)
+	copy(id3v1, "TAGv1 title")
+	data := append(createTag(item{key: "Title", value: "APE title"}), id3v1...)
+
+	file, err := parseData(t, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Tags.Title != "APE title" {
+		t.Errorf("Title = %q, want the APEv2 title", file.Tags.Title)
+	}
+}
+
+func TestParse_NoTag(t *testing.T) {
+	file, err := parseData(t, bytes.Repeat([]byte("not a tag "), 10))
+	if err != nil || file.Tags.Title != "" {
+		t.Errorf("Parse() = %v, %q; want no error and no tags", err, file.Tags.Title)
+	}
+}
+
+func TestParse_Corrupted(t *testing.T) {
+	tag := createTag(item{key: "Title", value: "Song"}, item{key: "Artist", value: "Band"})
+
+	tests := []struct {
+		name string
+		data func() []byte
+	}{
+		{"size beyond file", func() []byte {
+			data := slices.Clone(tag)
+			binary.LittleEndian.PutUint32(data[len(data)-20:], 1<<20)
+			return data
+		}},
+		{"value runs past the tag", func() []byte {
+			data := slices.Clone(tag)
+			binary.LittleEndian.PutUint32(data[footerSize:], 1000)
+			return data
+		}},
+		{"header in footer position", func() []byte {
+			return append([]byte("audio"), tag[:footerSize]...)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseData(t, tt.data()); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package apev2
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/parsing"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// applyItem maps a text item to Tags fields and stores it raw under its
+// key. Keys are case-insensitive; for single-value fields the first value
+// wins.
+func applyItem(key string, values []string, file *types.File) { //nolint:gocyclo // Complexity from many simple field mappings - intentionally kept together
+	if len(values) == 0 {
+		return
+	}
+	tags := &file.Tags
+	first := strings.TrimSpace(values[0])
+
+	switch strings.ToLower(key) {
+	case "title":
+		tags.Title = first
+	case "subtitle":
+		tags.Subtitle = first
+	case "artist":
+		tags.Artist = first
+		tags.Artists = append(tags.Artists, values...)
+	case "album":
+		tags.Album = first
+	case "album artist", "albumartist":
+		tags.AlbumArtist = first
+	case "year":
+		tags.Date = first
+		if len(first) >= 4 {
+			if year, err := strconv.Atoi(first[:4]); err == nil {
+				tags.Year = year
+			}
+		}
+	case "track":
+		tags.TrackNumber, tags.TrackTotal = parsePosition(first)
+	case "disc":
+		tags.DiscNumber, tags.DiscTotal = parsePosition(first)
+	case "genre":
+		tags.Genres = append(tags.Genres, values...)
+	case "comment":
+		for _, v := range values {
+			tags.Comments = append(tags.Comments, types.Comment{Text: v})
+		}
+		if tags.Comment == "" {
+			tags.Comment = first
+		}
+	case "composer":
+		tags.Composers = append(tags.Composers, values...)
+	case "lyrics":
+		tags.Lyrics = values[0]
+		if synced := types.ParseLRC(values[0]); synced != nil {
+			tags.SyncedLyrics = synced
+		}
+	case "publisher":
+		tags.Publisher = first
+	case "label":
+		tags.Label = first
+	case "copyright":
+		tags.Copyright = first
+	case "isrc":
+		tags.ISRC = first
+	case "barcode", "ean/upc":
+		tags.Barcode = first
+	case "catalog", "catalognumber":
+		tags.CatalogNumber = first
+	case "bpm":
+		tags.BPM = parsing.ParseBPM(first)
+	case "language":
+		tags.Language = first
+	case "encodedby", "encoder":
+		tags.Encoder = first
+	case "musicbrainz_trackid":
+		tags.MusicBrainzTrackID = first
+	case "musicbrainz_albumid":
+		tags.MusicBrainzAlbumID = first
+	case "musicbrainz_artistid":
+		tags.MusicBrainzArtistID = first
+	case "replaygain_track_gain", "replaygain_track_peak", "replaygain_album_gain", "replaygain_album_peak":
+		types.SetReplayGain(&file.Audio, key, first)
+	}
+
+	tags.Set(key, values...)
+}
+
+// isCoverArt reports whether a binary item holds a picture: "Cover Art
+// (Front)", "Cover Art (Back)" and so on.
+func isCoverArt(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), "cover art")
+}
+
+// parsePosition parses a "3" or "3/12" track or disc value.
+func parsePosition(value string) (number, total int) {
+	n, t, _ := strings.Cut(value, "/")
+	number, _ = strconv.Atoi(strings.TrimSpace(n))
+	total, _ = strconv.Atoi(strings.TrimSpace(t))
+	return number, total
+}
@@ -2,6 +2,7 @@
 package binary
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -29,6 +30,26 @@ func (sr *SafeReader) Path() string {
 	return sr.path
 }
 
+// Size returns the number of bytes readable through this reader.
+func (sr *SafeReader) Size() int64 {
+	return sr.size
+}
+
+// Section returns a reader over the n bytes starting at off, with offsets
+// relative to off. Use it to hand an embedded structure, such as an ID3v2
+// tag inside a WAV chunk, to code that expects it at offset 0.
+//
+// The section is clamped to the bytes this reader can reach.
+func (sr *SafeReader) Section(off, n int64) *SafeReader {
+	off = min(max(off, 0), sr.size)
+	n = min(max(n, 0), sr.size-off)
+	return &SafeReader{
+		r:    io.NewSectionReader(sr.r, off, n),
+		size: n,
+		path: sr.path,
+	}
+}
+
 // ReadAt reads bytes at the given offset with context for error messages.
 func (sr *SafeReader) ReadAt(b []byte, off int64, what string) error {
 	// Check bounds
@@ -55,6 +76,27 @@ func (sr *SafeReader) ReadAt(b []byte, off int64, what string) error {
 	return nil
 }
 
+// readChunkSize bounds each underlying read in ReadAtContext so a
+// cancellation is noticed between chunks of a large read.
+const readChunkSize = 1 << 20
+
+// ReadAtContext is like ReadAt but reads in chunks, checking ctx between
+// them. Use it for large payloads such as embedded images.
+//
+// Returns ctx.Err() if the context is done before the read completes.
+func (sr *SafeReader) ReadAtContext(ctx context.Context, b []byte, off int64, what string) error {
+	for start := 0; start < len(b); start += readChunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+readChunkSize, len(b))
+		if err := sr.ReadAt(b[start:end], off+int64(start), what); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Read reads a value of type T from the given offset.
 // T must be uint8, uint16, uint32, or uint64.
 func Read[T uint8 | uint16 | uint32 | uint64](sr *SafeReader, off int64, what string) (T, error) {
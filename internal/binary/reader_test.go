@@ -1,7 +1,10 @@
 package binary
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -260,3 +263,49 @@ func BenchmarkReader_Sequential(b *testing.B) {
 		}
 	}
 }
+
+func TestSafeReader_ReadAtContext(t *testing.T) {
+	data := make([]byte, readChunkSize*2+10)
+	data[len(data)-1] = 0xAB
+	sr := NewSafeReader(&mockReader{data: data}, int64(len(data)), "test.flac")
+
+	buf := make([]byte, len(data))
+	if err := sr.ReadAtContext(context.Background(), buf, 0, "picture data"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf[len(buf)-1] != 0xAB {
+		t.Error("last chunk not read")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sr.ReadAtContext(ctx, buf, 0, "picture data"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSafeReader_Section(t *testing.T) {
+	data := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	sr := NewSafeReader(&mockReader{data: data}, int64(len(data)), "test.wav")
+
+	section := sr.Section(2, 4)
+	if section.Size() != 4 || section.Path() != "test.wav" {
+		t.Errorf("unexpected section size %d, path %q", section.Size(), section.Path())
+	}
+
+	buf := make([]byte, 4)
+	if err := section.ReadAt(buf, 0, "section"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf, []byte{0x03, 0x04, 0x05, 0x06}) {
+		t.Errorf("section read = %v, want bytes 2-5", buf)
+	}
+	if err := section.ReadAt(buf[:1], 4, "past section"); err == nil {
+		t.Error("expected error reading past the section")
+	}
+
+	// A section running past the end is clamped
+	if got := sr.Section(6, 10).Size(); got != 2 {
+		t.Errorf("clamped section size = %d, want 2", got)
+	}
+}
@@ -0,0 +1,175 @@
+// Package dsf provides DSD Stream File (.dsf) audio file parsing.
+package dsf
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/mp3"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Codec and container names.
+const (
+	codecName     = "DSD"
+	containerName = "DSF"
+)
+
+// Chunk sizes. The DSD and fmt chunks are fixed-size and always come
+// first, in that order.
+const (
+	dsdChunkSize = 28
+	fmtChunkSize = 52
+)
+
+// dsdBaseRate is the DSD64 sampling frequency, 64 times the CD rate.
+// Higher rates are multiples of it and named after the multiple.
+const dsdBaseRate = 2822400
+
+// channelLayouts maps the fmt chunk's channel type to a layout name.
+var channelLayouts = map[uint32]string{
+	1: "mono",
+	2: "stereo",
+	3: "3.0",
+	4: "quad",
+	5: "4.0",
+	6: "5.0",
+	7: "5.1",
+}
+
+// parser implements the audiometa.FormatParser interface for DSF files.
+type parser struct{}
+
+// Parse parses a DSF file and extracts its audio properties and tags.
+//
+// Header layout (little-endian):
+//
+//	[4 bytes] "DSD "
+//	[8 bytes] DSD chunk size (28)
+//	[8 bytes] total file size
+//	[8 bytes] metadata pointer, the offset of an ID3v2 tag (0 if none)
+//	[4 bytes] "fmt "
+//	[8 bytes] fmt chunk size (52)
+//	[4 bytes] format version (1)
+//	[4 bytes] format ID (0: DSD raw)
+//	[4 bytes] channel type
+//	[4 bytes] channel count
+//	[4 bytes] sampling frequency
+//	[4 bytes] bits per sample (1: LSB first, 8: MSB first)
+//	[8 bytes] sample count, per channel
+//	[4 bytes] block size per channel (4096)
+//	[4 bytes] reserved
+func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path string) (*types.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+
+	header := make([]byte, dsdChunkSize+fmtChunkSize)
+	if err := sr.ReadAt(header, 0, "DSF header"); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "DSD " || string(header[28:32]) != "fmt " {
+		return nil, &types.CorruptedFileError{
+			Path:   path,
+			Reason: "missing DSD or fmt chunk",
+		}
+	}
+
+	log := registry.Logger(ctx)
+	log.LogAttrs(ctx, slog.LevelDebug, "chunk",
+		slog.String("format", "DSF"), slog.String("key", "DSD "),
+		slog.Int64("offset", 0), slog.Int64("size", dsdChunkSize))
+	log.LogAttrs(ctx, slog.LevelDebug, "chunk",
+		slog.String("format", "DSF"), slog.String("key", "fmt "),
+		slog.Int64("offset", dsdChunkSize), slog.Int64("size", fmtChunkSize))
+
+	file := &types.File{
+		Path:   path,
+		Format: types.FormatDSF,
+		Size:   size,
+	}
+	file.Audio.Container = containerName
+	file.Audio.Codec = codecName
+	file.Audio.Lossless = true
+
+	if registry.RawTagsEnabled(ctx) {
+		file.EnableRawTags()
+	}
+
+	if err := parseFmtChunk(header[dsdChunkSize:], &file.Audio); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: fmt.Sprintf("failed to read DSF fmt chunk: %v", err),
+			Err:     err,
+			Offset:  dsdChunkSize,
+		})
+	}
+
+	// The metadata pointer locates an ID3v2 tag that runs to the end of
+	// the file
+	if pointer := binary.LittleEndian.Uint64(header[20:28]); pointer != 0 {
+		if pointer < dsdChunkSize+fmtChunkSize || pointer >= uint64(size) {
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "metadata",
+				Message: fmt.Sprintf("DSF metadata pointer %d is outside the file", pointer),
+			})
+		} else {
+			log.LogAttrs(ctx, slog.LevelDebug, "chunk",
+				slog.String("format", "DSF"), slog.String("key", "ID3"),
+				slog.Int64("offset", int64(pointer)), slog.Int64("size", size-int64(pointer)))
+			if err := mp3.ReadID3v2(ctx, sr, int64(pointer), size-int64(pointer), file, registry.LegacyCharset(ctx)); err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "metadata",
+					Message: fmt.Sprintf("failed to parse ID3v2 tag: %v", err),
+					Err:     err,
+					Offset:  int64(pointer),
+				})
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// parseFmtChunk fills audio from the fmt chunk in data.
+//
+// DSD is 1-bit audio; bits per sample only tells how the bits are packed
+// into bytes, so BitDepth is always 1.
+func parseFmtChunk(data []byte, audio *types.AudioInfo) error {
+	if formatID := binary.LittleEndian.Uint32(data[16:20]); formatID != 0 {
+		return fmt.Errorf("unsupported DSF format ID %d", formatID)
+	}
+
+	audio.ChannelLayout = channelLayouts[binary.LittleEndian.Uint32(data[20:24])]
+	audio.Channels = int(binary.LittleEndian.Uint32(data[24:28]))
+	audio.SampleRate = int(binary.LittleEndian.Uint32(data[28:32]))
+	audio.BitDepth = 1
+	if bits := binary.LittleEndian.Uint32(data[32:36]); bits != 1 && bits != 8 {
+		return fmt.Errorf("invalid DSF bits per sample %d", bits)
+	}
+
+	if audio.SampleRate <= 0 {
+		return fmt.Errorf("invalid DSF sampling frequency %d", audio.SampleRate)
+	}
+	if audio.SampleRate%dsdBaseRate == 0 {
+		audio.CodecProfile = fmt.Sprintf("DSD%d", audio.SampleRate/dsdBaseRate*64)
+	}
+	audio.Bitrate = audio.SampleRate * audio.Channels
+
+	samples := binary.LittleEndian.Uint64(data[36:44])
+	seconds := float64(samples) / float64(audio.SampleRate)
+	audio.Duration = time.Duration(seconds * float64(time.Second))
+	return nil
+}
+
+func init() {
+	registry.Register(types.FormatDSF, &parser{})
+}
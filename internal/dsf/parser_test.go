@@ -0,0 +1,193 @@
+package dsf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// createDSF builds a DSF file with the given fmt chunk fields, dataSize
+// bytes of sample data and, if tag is non-nil, a trailing ID3v2 tag.
+func createDSF(channelType, channels, rate, bits uint32, samples uint64, dataSize int, tag []byte) []byte {
+	dataChunkSize := 12 + dataSize
+	fileSize := dsdChunkSize + fmtChunkSize + dataChunkSize + len(tag)
+	var pointer uint64
+	if tag != nil {
+		pointer = uint64(dsdChunkSize + fmtChunkSize + dataChunkSize)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("DSD ")
+	binary.Write(buf, binary.LittleEndian, uint64(dsdChunkSize))
+	binary.Write(buf, binary.LittleEndian, uint64(fileSize))
+	binary.Write(buf, binary.LittleEndian, pointer)
+
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint64(fmtChunkSize))
+	binary.Write(buf, binary.LittleEndian, uint32(1)) // Format version
+	binary.Write(buf, binary.LittleEndian, uint32(0)) // DSD raw
+	binary.Write(buf, binary.LittleEndian, channelType)
+	binary.Write(buf, binary.LittleEndian, channels)
+	binary.Write(buf, binary.LittleEndian, rate)
+	binary.Write(buf, binary.LittleEndian, bits)
+	binary.Write(buf, binary.LittleEndian, samples)
+	binary.Write(buf, binary.LittleEndian, uint32(4096)) // Block size per channel
+	binary.Write(buf, binary.LittleEndian, uint32(0))    // Reserved
+
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint64(dataChunkSize))
+	buf.Write(make([]byte, dataSize))
+
+	buf.Write(tag)
+	return buf.Bytes()
+}
+
+// createID3v2Tag builds an ID3v2.4 tag holding a UTF-8 TIT2 frame.
+func createID3v2Tag(title string) []byte {
+	body := append([]byte{0x03}, title...)
+	frame := []byte("TIT2")
+	frame = append(frame, 0, 0, 0, byte(len(body)), 0, 0)
+	frame = append(frame, body...)
+
+	tag := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, byte(len(frame))}
+	return append(tag, frame...)
+}
+
+func parse(t *testing.T, data []byte) *types.File {
+	t.Helper()
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.dsf")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return file
+}
+
+func TestParse_AudioInfo(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantRate     int
+		wantChannels int
+		wantLayout   string
+		wantProfile  string
+		wantDuration time.Duration
+	}{
+		{
+			name:         "DSD64 stereo",
+			data:         createDSF(2, 2, 2822400, 1, 2822400*3, 64, nil),
+			wantRate:     2822400,
+			wantChannels: 2,
+			wantLayout:   "stereo",
+			wantProfile:  "DSD64",
+			wantDuration: 3 * time.Second,
+		},
+		{
+			name:         "DSD256 5.1",
+			data:         createDSF(7, 6, 11289600, 8, 11289600/2, 64, nil),
+			wantRate:     11289600,
+			wantChannels: 6,
+			wantLayout:   "5.1",
+			wantProfile:  "DSD256",
+			wantDuration: 500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parse(t, tt.data)
+			audio := file.Audio
+
+			if file.Format != types.FormatDSF {
+				t.Errorf("Format = %v, want FormatDSF", file.Format)
+			}
+			if audio.Codec != "DSD" || !audio.Lossless || audio.BitDepth != 1 {
+				t.Errorf("Codec, Lossless, BitDepth = %q, %v, %d; want DSD, true, 1", audio.Codec, audio.Lossless, audio.BitDepth)
+			}
+			if audio.SampleRate != tt.wantRate || audio.Channels != tt.wantChannels {
+				t.Errorf("SampleRate, Channels = %d, %d; want %d, %d", audio.SampleRate, audio.Channels, tt.wantRate, tt.wantChannels)
+			}
+			if audio.ChannelLayout != tt.wantLayout {
+				t.Errorf("ChannelLayout = %q, want %q", audio.ChannelLayout, tt.wantLayout)
+			}
+			if audio.CodecProfile != tt.wantProfile {
+				t.Errorf("CodecProfile = %q, want %q", audio.CodecProfile, tt.wantProfile)
+			}
+			if audio.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", audio.Duration, tt.wantDuration)
+			}
+			if audio.Bitrate != tt.wantRate*tt.wantChannels {
+				t.Errorf("Bitrate = %d, want %d", audio.Bitrate, tt.wantRate*tt.wantChannels)
+			}
+			if !audio.IsHighRes() {
+				t.Error("IsHighRes() = false, want true for DSD")
+			}
+		})
+	}
+}
+
+func TestParse_ID3v2Tag(t *testing.T) {
+	file := parse(t, createDSF(2, 2, 2822400, 1, 2822400, 32, createID3v2Tag("Kind of Blue")))
+
+	if file.Tags.Title != "Kind of Blue" {
+		t.Errorf("Title = %q, want the ID3v2 TIT2 value", file.Tags.Title)
+	}
+	if len(file.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", file.Warnings)
+	}
+}
+
+func TestParse_DebugLog(t *testing.T) {
+	data := createDSF(2, 2, 2822400, 1, 2822400, 32, createID3v2Tag("Kind of Blue"))
+	var debug bytes.Buffer
+	ctx := registry.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	p := &parser{}
+	if _, err := p.Parse(ctx, bytes.NewReader(data), int64(len(data)), "test.dsf"); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for _, want := range []string{`key="DSD " offset=0 size=28`, `key="fmt " offset=28 size=52`, "key=ID3 offset=124", "key=TIT2"} {
+		if !strings.Contains(debug.String(), want) {
+			t.Errorf("debug log = %q, want it to contain %q", debug.String(), want)
+		}
+	}
+}
+
+func TestParse_BadMetadataPointer(t *testing.T) {
+	data := createDSF(2, 2, 2822400, 1, 2822400, 32, nil)
+	binary.LittleEndian.PutUint64(data[20:28], uint64(len(data)+100))
+
+	file := parse(t, data)
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "metadata" {
+		t.Errorf("Warnings = %v, want one metadata warning", file.Warnings)
+	}
+	if file.Audio.SampleRate != 2822400 {
+		t.Errorf("SampleRate = %d, want audio properties read regardless", file.Audio.SampleRate)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", []byte("DSD \x1C\x00\x00\x00")},
+		{"missing fmt chunk", bytes.Replace(createDSF(2, 2, 2822400, 1, 0, 0, nil), []byte("fmt "), []byte("junk"), 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &parser{}
+			if _, err := p.Parse(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "test.dsf"); err == nil {
+				t.Error("Parse() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -102,6 +102,9 @@ func parseCueSheet(sr *binary.SafeReader, offset int64, length uint32, file *typ
 
 	// Convert cuesheet to chapters
 	file.Chapters = cuesheetToChapters(cuesheet, file.Audio.SampleRate)
+	if len(file.Chapters) > 0 {
+		file.ChapterSource = "flac:cuesheet"
+	}
 
 	return nil
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/simonhull/audiometa/internal/binary"
 	"github.com/simonhull/audiometa/internal/parsing"
+	"github.com/simonhull/audiometa/internal/picture"
 	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 	"github.com/simonhull/audiometa/internal/vorbis"
@@ -27,6 +30,17 @@ const (
 	blockTypePicture       = 6
 )
 
+// blockTypeNames names the metadata block types for debug logging.
+var blockTypeNames = [...]string{"STREAMINFO", "PADDING", "APPLICATION", "SEEKTABLE", "VORBIS_COMMENT", "CUESHEET", "PICTURE"}
+
+// blockTypeName returns the name of a metadata block type, or its number.
+func blockTypeName(blockType uint8) string {
+	if int(blockType) < len(blockTypeNames) {
+		return blockTypeNames[blockType]
+	}
+	return strconv.Itoa(int(blockType))
+}
+
 // parser implements the audiometa.FormatParser interface for FLAC files.
 type parser struct{}
 
@@ -40,16 +54,9 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 	sr := binary.NewSafeReader(r, size, path)
 
 	// Verify FLAC magic bytes ("fLaC")
-	magic := make([]byte, 4)
-	if err := sr.ReadAt(magic, 0, "FLAC magic bytes"); err != nil {
-		return nil, fmt.Errorf("read FLAC magic: %w", err)
-	}
-	if string(magic) != "fLaC" {
-		return nil, &types.CorruptedFileError{
-			Path:   path,
-			Offset: 0,
-			Reason: "invalid FLAC magic bytes",
-		}
+	start, err := streamStart(sr, path)
+	if err != nil {
+		return nil, err
 	}
 
 	// Initialize file
@@ -61,25 +68,36 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		Audio:  types.AudioInfo{},
 	}
 
+	if registry.RawTagsEnabled(ctx) {
+		file.EnableRawTags()
+	}
+
+	if start > 0 {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: fmt.Sprintf("non-standard layout: %d-byte ID3v2 tag precedes the FLAC stream", start),
+		})
+	}
+
 	// Parse metadata blocks
-	offset := int64(4) // After "fLaC"
+	log := registry.Logger(ctx)
+	offset := start + 4 // After "fLaC"
 	for offset < size {
 		// Read metadata block header (4 bytes)
-		header, err := binary.Read[uint32](sr, offset, "metadata block header")
+		isLast, blockType, blockLength, err := readBlockHeader(sr, offset)
 		if err != nil {
 			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "metadata",
-				Message: fmt.Sprintf("failed to read metadata block header at offset %d: %v", offset, err),
-				Err:     err,
-				Offset:  offset,
+				Stage:    "metadata",
+				Message:  fmt.Sprintf("failed to read metadata block header at offset %d: %v", offset, err),
+				Severity: types.SeverityError,
+				Err:      err,
+				Offset:   offset,
 			})
 			break
 		}
-
-		// Parse header
-		isLast := (header >> 31) == 1
-		blockType := uint8((header >> 24) & 0x7F)
-		blockLength := int64(header & 0x00FFFFFF)
+		log.LogAttrs(ctx, slog.LevelDebug, "metadata block",
+			slog.String("format", "FLAC"), slog.String("key", blockTypeName(blockType)),
+			slog.Int64("offset", offset), slog.Int64("size", blockLength))
 
 		offset += 4 // Move past header
 
@@ -106,8 +124,20 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 			}
 
 		case blockTypePicture:
-			// Pictures are handled lazily via ExtractArtwork()
-			// For now, just skip
+			// Pictures are loaded lazily via ExtractArtwork(); only record
+			// presence, and check the header so a corrupt one is reported
+			file.HasEmbeddedArtwork = true
+			if _, err := parsePicture(ctx, sr, offset, blockLength, false); err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "artwork",
+					Message: fmt.Sprintf("failed to parse PICTURE: %v", err),
+					Err:     err,
+					Offset:  offset,
+				})
+			}
+			if file.RawTagsEnabled() {
+				addRawPicture(sr, offset, blockLength, file)
+			}
 
 		case blockTypePadding:
 			// Padding blocks are ignored
@@ -116,7 +146,14 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 			// Application blocks are ignored for now
 
 		case blockTypeSeekTable:
-			// Seek table is not needed for metadata extraction
+			if err := readSeekTable(sr, offset, blockLength, file); err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "technical",
+					Message: fmt.Sprintf("failed to parse SEEKTABLE: %v", err),
+					Err:     err,
+					Offset:  offset,
+				})
+			}
 
 		case blockTypeCueSheet:
 			if err := parseCueSheet(sr, offset, uint32(blockLength), file); err != nil {
@@ -165,35 +202,128 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 	return file, nil
 }
 
+// streamStart returns the offset of the "fLaC" magic. It is 0 for a
+// well-formed file, but some taggers prepend an ID3v2 tag, in which case the
+// stream starts right after it.
+func streamStart(sr *binary.SafeReader, path string) (int64, error) {
+	start := types.ID3v2TagSize(sr)
+
+	magic := make([]byte, 4)
+	if err := sr.ReadAt(magic, start, "FLAC magic bytes"); err != nil {
+		return 0, fmt.Errorf("read FLAC magic: %w", err)
+	}
+	if string(magic) != "fLaC" {
+		return 0, &types.CorruptedFileError{
+			Path:   path,
+			Offset: start,
+			Reason: "invalid FLAC magic bytes",
+		}
+	}
+	return start, nil
+}
+
+// readBlockHeader reads a 4-byte metadata block header.
+//
+// Header layout: [1 bit last-block flag][7 bits block type][24 bits length].
+func readBlockHeader(sr *binary.SafeReader, offset int64) (isLast bool, blockType uint8, length int64, err error) {
+	header, err := binary.Read[uint32](sr, offset, "metadata block header")
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return (header >> 31) == 1, uint8((header >> 24) & 0x7F), int64(header & 0x00FFFFFF), nil
+}
+
+// ExtractChapters extracts only CUESHEET chapters from a FLAC file.
+//
+// Only STREAMINFO (needed for sample-to-time conversion) and CUESHEET
+// blocks are decoded; Vorbis comments and pictures are skipped.
+func (p *parser) ExtractChapters(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Chapter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binary.NewSafeReader(r, size, path)
+
+	start, err := streamStart(sr, path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &types.File{Path: path, Size: size}
+
+	offset := start + 4
+	for offset < size {
+		isLast, blockType, blockLength, err := readBlockHeader(sr, offset)
+		if err != nil {
+			break
+		}
+		offset += 4
+
+		switch blockType {
+		case blockTypeStreamInfo:
+			if err := parseStreamInfo(sr, offset, blockLength, file); err != nil {
+				return nil, fmt.Errorf("parse STREAMINFO: %w", err)
+			}
+		case blockTypeCueSheet:
+			if err := parseCueSheet(sr, offset, uint32(blockLength), file); err != nil {
+				return nil, fmt.Errorf("parse CUESHEET: %w", err)
+			}
+		}
+
+		offset += blockLength
+		if isLast {
+			break
+		}
+	}
+
+	return file.Chapters, nil
+}
+
 // ExtractArtwork extracts embedded artwork from FLAC files.
 func (p *parser) ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	sr := binary.NewSafeReader(r, size, path)
+	return extractPictures(ctx, binary.NewSafeReader(r, size, path), size, true)
+}
+
+// ExtractArtworkMetadata describes PICTURE blocks from their headers, which
+// carry MIME type, dimensions and data length, without reading image data.
+func (p *parser) ExtractArtworkMetadata(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return extractPictures(ctx, binary.NewSafeReader(r, size, path), size, false)
+}
 
+// extractPictures parses every PICTURE block, loading image data only if
+// withData is set.
+func extractPictures(ctx context.Context, sr *binary.SafeReader, size int64, withData bool) ([]types.Artwork, error) {
 	var artwork []types.Artwork
 
-	// Skip FLAC magic
-	offset := int64(4)
+	// Skip FLAC magic and any ID3v2 tag before it
+	offset := types.ID3v2TagSize(sr) + 4
 
 	// Scan for PICTURE blocks
 	for offset < size {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// Read metadata block header
-		header, err := binary.Read[uint32](sr, offset, "metadata block header")
+		isLast, blockType, blockLength, err := readBlockHeader(sr, offset)
 		if err != nil {
 			break
 		}
 
-		isLast := (header >> 31) == 1
-		blockType := uint8((header >> 24) & 0x7F)
-		blockLength := int64(header & 0x00FFFFFF)
-
 		offset += 4
 
 		// If this is a PICTURE block, parse it
 		if blockType == blockTypePicture {
-			pic, err := parsePicture(sr, offset, blockLength)
+			pic, err := parsePicture(ctx, sr, offset, blockLength, withData)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			if err != nil {
 				// Skip this picture but continue
 				offset += blockLength
@@ -212,6 +342,45 @@ func (p *parser) ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64,
 	return artwork, nil
 }
 
+// CountArtwork counts PICTURE blocks from their block headers.
+func (p *parser) CountArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	sr := binary.NewSafeReader(r, size, path)
+
+	count := 0
+	offset := types.ID3v2TagSize(sr) + 4 // Skip FLAC magic and any ID3v2 tag
+	for offset < size {
+		isLast, blockType, blockLength, err := readBlockHeader(sr, offset)
+		if err != nil {
+			break
+		}
+
+		if blockType == blockTypePicture {
+			count++
+		}
+
+		offset += 4 + blockLength
+		if isLast {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+// readSeekTable reads the SEEKTABLE block into file.SeekPoints.
+func readSeekTable(sr *binary.SafeReader, offset, blockLength int64, file *types.File) error {
+	data := make([]byte, blockLength)
+	if err := sr.ReadAt(data, offset, "SEEKTABLE block"); err != nil {
+		return err
+	}
+	points, err := parseSeekTable(data)
+	file.SeekPoints = points
+	return err
+}
+
 // parseStreamInfo extracts audio info from STREAMINFO block.
 func parseStreamInfo(sr *binary.SafeReader, offset, blockLength int64, file *types.File) error {
 	// STREAMINFO is exactly 34 bytes
@@ -228,8 +397,12 @@ func parseStreamInfo(sr *binary.SafeReader, offset, blockLength int64, file *typ
 	// Parse fields (all big-endian)
 	// Bytes 0-1: Min block size (16 bits)
 	// Bytes 2-3: Max block size (16 bits)
-	// Bytes 4-6: Min frame size (24 bits)
-	// Bytes 7-9: Max frame size (24 bits)
+	// Bytes 4-6: Min frame size (24 bits, 0 = unknown)
+	// Bytes 7-9: Max frame size (24 bits, 0 = unknown)
+	file.Audio.MinBlockSize = int(data[0])<<8 | int(data[1])
+	file.Audio.MaxBlockSize = int(data[2])<<8 | int(data[3])
+	file.Audio.MinFrameSize = int(data[4])<<16 | int(data[5])<<8 | int(data[6])
+	file.Audio.MaxFrameSize = int(data[7])<<16 | int(data[8])<<8 | int(data[9])
 
 	// Bytes 10-17: Sample rate (20 bits), channels (3 bits), bits per sample (5 bits), total samples (36 bits)
 	// This is a bit-packed 64-bit value
@@ -251,6 +424,11 @@ func parseStreamInfo(sr *binary.SafeReader, offset, blockLength int64, file *typ
 	file.Audio.SampleRate = int(sampleRate)
 	file.Audio.Channels = int(channels)
 	file.Audio.BitDepth = int(bitsPerSample)
+	file.Audio.SampleFormat = types.SampleSignedInt
+	file.Audio.TotalSamples = totalSamples
+
+	// Bytes 18-33: MD5 of the unencoded audio
+	file.Audio.AudioMD5 = [16]byte(data[18:34])
 
 	// Calculate approximate bitrate (FLAC is variable bitrate)
 	// Use file size and duration for a rough estimate
@@ -315,97 +493,29 @@ func parseVorbisComment(sr *binary.SafeReader, offset, _ int64, file *types.File
 	return nil
 }
 
-// parsePicture extracts artwork from PICTURE block.
-func parsePicture(sr *binary.SafeReader, offset, _ int64) (types.Artwork, error) {
-	currentOffset := offset
-
-	// Read picture type (32-bit big-endian)
-	pictureType, err := binary.Read[uint32](sr, currentOffset, "picture type")
-	if err != nil {
-		return types.Artwork{}, err
-	}
-	currentOffset += 4
-
-	// Read MIME type length (32-bit big-endian)
-	mimeLength, err := binary.Read[uint32](sr, currentOffset, "MIME type length")
-	if err != nil {
-		return types.Artwork{}, err
-	}
-	currentOffset += 4
-
-	// Read MIME type string
-	mimeData := make([]byte, mimeLength)
-	if err := sr.ReadAt(mimeData, currentOffset, "MIME type"); err != nil {
-		return types.Artwork{}, err
-	}
-	mimeType := string(mimeData)
-	currentOffset += int64(mimeLength)
-
-	// Read description length (32-bit big-endian)
-	descLength, err := binary.Read[uint32](sr, currentOffset, "description length")
-	if err != nil {
-		return types.Artwork{}, err
-	}
-	currentOffset += 4
-
-	// Read description string (UTF-8)
-	descData := make([]byte, descLength)
-	if descLength > 0 {
-		if err := sr.ReadAt(descData, currentOffset, "description"); err != nil {
-			return types.Artwork{}, err
-		}
+// addRawPicture records a PICTURE block for File.RawTags.
+func addRawPicture(sr *binary.SafeReader, offset, length int64, file *types.File) {
+	block := make([]byte, length)
+	if err := sr.ReadAt(block, offset, "PICTURE block"); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: fmt.Sprintf("failed to read PICTURE block: %v", err),
+			Err:     err,
+			Offset:  offset,
+		})
+		return
 	}
-	description := string(descData)
-	currentOffset += int64(descLength)
-
-	// Read width, height, color depth, indexed colors (4 × 32-bit big-endian)
-	width, err := binary.Read[uint32](sr, currentOffset, "width")
-	if err != nil {
-		return types.Artwork{}, err
-	}
-	currentOffset += 4
-
-	height, err := binary.Read[uint32](sr, currentOffset, "height")
-	if err != nil {
-		return types.Artwork{}, err
-	}
-	currentOffset += 4
-
-	// Skip color depth and indexed colors (not used)
-	currentOffset += 8
+	file.AddRawTag(types.RawTag{Key: "PICTURE", Value: block, Type: types.RawTagImage})
+}
 
-	// Read picture data length (32-bit big-endian)
-	dataLength, err := binary.Read[uint32](sr, currentOffset, "picture data length")
+// parsePicture extracts artwork from PICTURE block, located in the file.
+// Image data is only read when withData is set; Size is always filled in.
+func parsePicture(ctx context.Context, sr *binary.SafeReader, offset, blockLength int64, withData bool) (types.Artwork, error) {
+	art, dataOffset, err := picture.Decode(ctx, sr, offset, blockLength, withData)
 	if err != nil {
 		return types.Artwork{}, err
 	}
-	currentOffset += 4
-
-	// Read picture data
-	pictureData := make([]byte, dataLength)
-	if err := sr.ReadAt(pictureData, currentOffset, "picture data"); err != nil {
-		return types.Artwork{}, err
-	}
-
-	// Map FLAC picture type to types.ArtworkType
-	var artType types.ArtworkType
-	switch pictureType {
-	case 3:
-		artType = types.ArtworkFrontCover
-	case 4:
-		artType = types.ArtworkBackCover
-	default:
-		artType = types.ArtworkOther
-	}
-
-	return types.Artwork{
-		Data:        pictureData,
-		MIMEType:    mimeType,
-		Type:        artType,
-		Description: description,
-		Width:       int(width),
-		Height:      int(height),
-	}, nil
+	return types.WithArtworkLocation(art, dataOffset, int64(art.Size)), nil
 }
 
 // init registers the FLAC parser.
@@ -6,8 +6,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
+	binutil "github.com/simonhull/audiometa/internal/binary"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -185,6 +188,10 @@ func TestParse_Success(t *testing.T) {
 		t.Errorf("expected 16-bit depth, got %d", file.Audio.BitDepth)
 	}
 
+	if file.Audio.SampleFormat != types.SampleSignedInt {
+		t.Errorf("expected signed integer samples, got %v", file.Audio.SampleFormat)
+	}
+
 	// Duration should be ~1 second (44100 samples at 44100 Hz)
 	expectedDuration := int64(1000000000) // 1 second in nanoseconds
 	if file.Audio.Duration.Nanoseconds() < expectedDuration*9/10 ||
@@ -487,3 +494,229 @@ func BenchmarkExtractArtwork(b *testing.B) {
 		}
 	}
 }
+
+func TestCountArtwork(t *testing.T) {
+	data := createMinimalFLAC("Test", "Artist", "Album")
+
+	// Clear the last-block flag on VORBIS_COMMENT (after magic + STREAMINFO)
+	data[4+4+34] &^= 0x80
+
+	// Append two PICTURE blocks; the payload is never inspected
+	payload := make([]byte, 64)
+	data = append(data, 0x06, 0x00, 0x00, byte(len(payload))) // PICTURE, not last
+	data = append(data, payload...)
+	data = append(data, 0x86, 0x00, 0x00, byte(len(payload))) // PICTURE, last
+	data = append(data, payload...)
+
+	p := &parser{}
+	n, err := p.CountArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+	if err != nil {
+		t.Fatalf("CountArtwork failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 pictures, got %d", n)
+	}
+}
+
+func TestParse_StreamInfoBlockAndFrameSizes(t *testing.T) {
+	tests := []struct {
+		name                       string
+		minBlock, maxBlock         uint16
+		minFrame, maxFrame         uint32
+		wantMinFrame, wantMaxFrame int
+	}{
+		{"unknown frame sizes", 4096, 4096, 0, 0, 0, 0},
+		{"known frame sizes", 4096, 4096, 14, 13004, 14, 13004},
+		{"variable block size", 1152, 4608, 0x000100, 0xFFFFFF, 0x000100, 0xFFFFFF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := createMinimalFLAC("Test", "Artist", "Album")
+
+			// STREAMINFO data starts after magic + block header
+			si := data[8:]
+			binary.BigEndian.PutUint16(si[0:], tt.minBlock)
+			binary.BigEndian.PutUint16(si[2:], tt.maxBlock)
+			si[4], si[5], si[6] = byte(tt.minFrame>>16), byte(tt.minFrame>>8), byte(tt.minFrame)
+			si[7], si[8], si[9] = byte(tt.maxFrame>>16), byte(tt.maxFrame>>8), byte(tt.maxFrame)
+
+			p := &parser{}
+			file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			if file.Audio.MinBlockSize != int(tt.minBlock) || file.Audio.MaxBlockSize != int(tt.maxBlock) {
+				t.Errorf("expected block sizes %d/%d, got %d/%d",
+					tt.minBlock, tt.maxBlock, file.Audio.MinBlockSize, file.Audio.MaxBlockSize)
+			}
+			if file.Audio.MinFrameSize != tt.wantMinFrame || file.Audio.MaxFrameSize != tt.wantMaxFrame {
+				t.Errorf("expected frame sizes %d/%d, got %d/%d",
+					tt.wantMinFrame, tt.wantMaxFrame, file.Audio.MinFrameSize, file.Audio.MaxFrameSize)
+			}
+			if file.Audio.SampleRate != 44100 {
+				t.Errorf("packed fields disturbed: sample rate %d", file.Audio.SampleRate)
+			}
+		})
+	}
+}
+
+func TestParse_StreamInfoTotalSamplesAndMD5(t *testing.T) {
+	data := createMinimalFLAC("Test", "Artist", "Album")
+	md5 := [16]byte{0xD4, 0x1D, 0x8C, 0xD9, 0x8F, 0x00, 0xB2, 0x04, 0xE9, 0x80, 0x09, 0x98, 0xEC, 0xF8, 0x42, 0x7E}
+	copy(data[8+18:], md5[:])
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Audio.TotalSamples != 44100 {
+		t.Errorf("TotalSamples = %d, want 44100", file.Audio.TotalSamples)
+	}
+	if file.Audio.AudioMD5 != md5 {
+		t.Errorf("AudioMD5 = %x, want %x", file.Audio.AudioMD5, md5)
+	}
+}
+
+func TestParse_ID3v2Prepended(t *testing.T) {
+	// ID3v2.3 header declaring a 20-byte tag body, then the FLAC stream
+	id3 := append([]byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 20}, make([]byte, 20)...)
+	data := append(id3, createMinimalFLAC("Test Song", "Test Artist", "Test Album")...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Tags.Title != "Test Song" {
+		t.Errorf("expected title 'Test Song', got %q", file.Tags.Title)
+	}
+	if file.Audio.SampleRate != 44100 {
+		t.Errorf("expected sample rate 44100, got %d", file.Audio.SampleRate)
+	}
+	if len(file.Warnings) != 1 || !strings.Contains(file.Warnings[0].Message, "ID3v2") {
+		t.Errorf("expected one non-standard layout warning, got %v", file.Warnings)
+	}
+
+	chapters, err := p.ExtractChapters(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+	if err != nil || len(chapters) != 0 {
+		t.Errorf("ExtractChapters() = %v, %v; want no chapters and no error", chapters, err)
+	}
+}
+
+// insertBlock inserts a metadata block of the given type after the
+// STREAMINFO block of a file from createMinimalFLAC.
+func insertBlock(data []byte, blockType byte, payload []byte) []byte {
+	const afterStreamInfo = 4 + 4 + 34
+	block := []byte{blockType, byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload))}
+	block = append(block, payload...)
+	return append(data[:afterStreamInfo:afterStreamInfo], append(block, data[afterStreamInfo:]...)...)
+}
+
+func TestParse_SeekTable(t *testing.T) {
+	seekPoint := func(sample, offset uint64, frameSamples uint16) []byte {
+		p := binary.BigEndian.AppendUint64(nil, sample)
+		p = binary.BigEndian.AppendUint64(p, offset)
+		return binary.BigEndian.AppendUint16(p, frameSamples)
+	}
+	table := append(seekPoint(0, 0, 4096), seekPoint(22050, 8192, 4096)...)
+	table = append(table, seekPoint(placeholderSample, 0, 0)...)
+
+	tests := []struct {
+		name         string
+		payload      []byte
+		wantPoints   []types.SeekPoint
+		wantWarnings int
+	}{
+		{
+			name:    "placeholders left out",
+			payload: table,
+			wantPoints: []types.SeekPoint{
+				{SampleNumber: 0, Offset: 0, FrameSamples: 4096},
+				{SampleNumber: 22050, Offset: 8192, FrameSamples: 4096},
+			},
+		},
+		{
+			name:         "partial point",
+			payload:      table[:seekPointSize+4],
+			wantPoints:   []types.SeekPoint{{SampleNumber: 0, Offset: 0, FrameSamples: 4096}},
+			wantWarnings: 1,
+		},
+		{name: "empty", payload: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := insertBlock(createMinimalFLAC("Test Song", "", ""), blockTypeSeekTable, tt.payload)
+
+			p := &parser{}
+			file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			if !slices.Equal(file.SeekPoints, tt.wantPoints) {
+				t.Errorf("SeekPoints = %v, want %v", file.SeekPoints, tt.wantPoints)
+			}
+			if len(file.Warnings) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want %d", file.Warnings, tt.wantWarnings)
+			}
+			if file.Tags.Title != "Test Song" {
+				t.Errorf("Title = %q, want the blocks after SEEKTABLE read", file.Tags.Title)
+			}
+		})
+	}
+}
+
+func TestParsePicture_OversizedLengths(t *testing.T) {
+	// PICTURE blocks whose MIME type, description and data lengths run
+	// past the block: nothing is allocated for them
+	picture := func(mimeLength, descLength, dataLength uint32) []byte {
+		block := binary.BigEndian.AppendUint32(nil, 3)
+		block = binary.BigEndian.AppendUint32(block, mimeLength)
+		if mimeLength <= 9 {
+			block = append(block, "image/png"[:mimeLength]...)
+			block = binary.BigEndian.AppendUint32(block, descLength)
+			block = append(block, make([]byte, 16)...) // Width, height, depth, colors
+			block = binary.BigEndian.AppendUint32(block, dataLength)
+		}
+		return block
+	}
+
+	tests := []struct {
+		name  string
+		block []byte
+		want  string
+	}{
+		{"MIME type", picture(0x9a9a9a9a, 0, 0), "MIME type length"},
+		{"description", picture(9, 0xffffffff, 0), "description length"},
+		{"data", picture(9, 0, 1<<30), "data length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("fLaC")
+			data = append(data, 0x80|blockTypePicture, 0, 0, byte(len(tt.block)))
+			data = append(data, tt.block...)
+			sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.flac")
+
+			_, err := parsePicture(context.Background(), sr, 8, int64(len(tt.block)), true)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parsePicture() error = %v, want %q", err, tt.want)
+			}
+
+			p := &parser{}
+			file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(file.Warnings) == 0 || file.Warnings[len(file.Warnings)-1].Stage != "artwork" {
+				t.Errorf("Warnings = %v, want an artwork warning", file.Warnings)
+			}
+		})
+	}
+}
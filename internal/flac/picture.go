@@ -0,0 +1,45 @@
+package flac
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/simonhull/audiometa/internal/imagesize"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// encodePicture serializes art as a PICTURE block payload. Width and
+// height are detected from JPEG and PNG data when left zero. Color depth
+// and the indexed color count are written as 0, meaning unknown.
+//
+// Payload layout (big-endian):
+//
+//	[4 bytes] picture type
+//	[4 bytes] MIME type length, then the MIME type
+//	[4 bytes] description length, then the UTF-8 description
+//	[4 bytes] width, height, color depth, indexed colors
+//	[4 bytes] data length, then the image data
+func encodePicture(art types.Artwork) ([]byte, error) {
+	width, height := art.Width, art.Height
+	if width == 0 && height == 0 {
+		width, height = imagesize.Dimensions(art.Data, art.MIMEType)
+	}
+
+	size := 32 + len(art.MIMEType) + len(art.Description) + len(art.Data)
+	if size > maxBlockLength {
+		return nil, fmt.Errorf("picture too large for a FLAC block: %d bytes", size)
+	}
+
+	pic := make([]byte, 0, size)
+	pic = binary.BigEndian.AppendUint32(pic, uint32(art.Type))
+	pic = binary.BigEndian.AppendUint32(pic, uint32(len(art.MIMEType)))
+	pic = append(pic, art.MIMEType...)
+	pic = binary.BigEndian.AppendUint32(pic, uint32(len(art.Description)))
+	pic = append(pic, art.Description...)
+	pic = binary.BigEndian.AppendUint32(pic, uint32(width))
+	pic = binary.BigEndian.AppendUint32(pic, uint32(height))
+	pic = binary.BigEndian.AppendUint32(pic, 0)
+	pic = binary.BigEndian.AppendUint32(pic, 0)
+	pic = binary.BigEndian.AppendUint32(pic, uint32(len(art.Data)))
+	return append(pic, art.Data...), nil
+}
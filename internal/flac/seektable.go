@@ -0,0 +1,44 @@
+package flac
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// seekPointSize is the size of one SEEKTABLE entry.
+const seekPointSize = 18
+
+// placeholderSample marks a SEEKTABLE entry reserved for later use by an
+// encoder, which points nowhere.
+const placeholderSample = 0xFFFFFFFFFFFFFFFF
+
+// parseSeekTable decodes a SEEKTABLE block payload, leaving placeholder
+// points out. A payload that is not a whole number of points is an error,
+// but the whole points before the partial one are still returned.
+//
+// Each seek point (big-endian):
+//
+//	[8 bytes] sample number of the first sample in the target frame
+//	[8 bytes] offset from the first frame header to the target frame header
+//	[2 bytes] number of samples in the target frame
+func parseSeekTable(data []byte) ([]types.SeekPoint, error) {
+	var points []types.SeekPoint
+	for p := data; len(p) >= seekPointSize; p = p[seekPointSize:] {
+		sample := binary.BigEndian.Uint64(p[0:8])
+		if sample == placeholderSample {
+			continue
+		}
+		points = append(points, types.SeekPoint{
+			SampleNumber: sample,
+			Offset:       binary.BigEndian.Uint64(p[8:16]),
+			FrameSamples: binary.BigEndian.Uint16(p[16:18]),
+		})
+	}
+
+	if len(data)%seekPointSize != 0 {
+		return points, fmt.Errorf("SEEKTABLE size %d is not a multiple of %d", len(data), seekPointSize)
+	}
+	return points, nil
+}
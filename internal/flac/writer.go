@@ -0,0 +1,222 @@
+package flac
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+	"github.com/simonhull/audiometa/internal/vorbis"
+)
+
+// defaultVendor is the vendor string for a VORBIS_COMMENT block written to
+// a file that had none. An existing vendor string is kept.
+const defaultVendor = "audiometa"
+
+// defaultPadding is the PADDING block size added when the metadata has to
+// grow, so the next few edits can be made in place.
+const defaultPadding = 4096
+
+// maxBlockLength is the largest payload a 24-bit block length can declare.
+const maxBlockLength = 1<<24 - 1
+
+// metadataBlock is one metadata block as found in the file.
+type metadataBlock struct {
+	blockType uint8
+	offset    int64 // Start of the payload, just past the header
+	length    int64
+}
+
+// WriteTags implements registry.TagWriter.
+//
+// The VORBIS_COMMENT block is replaced with one built from tags; every
+// other block except PADDING is copied byte for byte, in its original
+// order. A file without comments gets the new block after STREAMINFO.
+//
+// Existing padding absorbs the size change when it can, so the audio
+// frames stay where they are and the edit can be applied in place. When
+// the new metadata does not fit, the edit adds fresh padding and carries a
+// "write" warning that the audio data will move.
+func (p *parser) WriteTags(ctx context.Context, r io.ReaderAt, size int64, path string, tags *types.Tags) (*registry.TagEdit, error) {
+	return planMetadata(ctx, binary.NewSafeReader(r, size, path), tags, nil, false)
+}
+
+// WriteArtwork implements registry.ArtworkWriter.
+//
+// Every PICTURE block is dropped, and one block per image in art takes
+// the place of the first; a file without pictures gets them after its
+// other blocks. Tags are written as by WriteTags, or the VORBIS_COMMENT
+// block is copied as is when tags is nil.
+func (p *parser) WriteArtwork(ctx context.Context, r io.ReaderAt, size int64, path string, tags *types.Tags, art []types.Artwork) (*registry.TagEdit, error) {
+	pictures := make([][]byte, len(art))
+	for i, a := range art {
+		pic, err := encodePicture(a)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+		pictures[i] = encodeBlock(blockTypePicture, pic)
+	}
+	return planMetadata(ctx, binary.NewSafeReader(r, size, path), tags, pictures, true)
+}
+
+// planMetadata builds the edit that replaces the metadata blocks of the
+// file in sr. A nil tags keeps the comment block; replacePictures swaps
+// the PICTURE blocks for pictures, which are complete blocks.
+func planMetadata(ctx context.Context, sr *binary.SafeReader, tags *types.Tags, pictures [][]byte, replacePictures bool) (*registry.TagEdit, error) { //nolint:gocyclo // One pass over the blocks, each kind kept, replaced or dropped
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path := sr.Path()
+	start, err := streamStart(sr, path)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, audioOffset, err := readBlocks(sr, start+4)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 || blocks[0].blockType != blockTypeStreamInfo {
+		return nil, &types.CorruptedFileError{Path: path, Offset: start + 4, Reason: "first metadata block is not STREAMINFO"}
+	}
+
+	var comment []byte
+	if tags != nil {
+		vendor := defaultVendor
+		for _, b := range blocks {
+			if b.blockType == blockTypeVorbisComment {
+				if v, err := readVendor(sr, b); err == nil {
+					vendor = v
+				}
+				break
+			}
+		}
+		comment, err = vorbis.MarshalComments(vendor, vorbis.FormatComments(tags))
+		if err != nil {
+			return nil, err
+		}
+		if len(comment) > maxBlockLength {
+			return nil, fmt.Errorf("vorbis comments too large for a FLAC block: %d bytes", len(comment))
+		}
+	}
+
+	// Kept blocks, with the new comment block where the first old one was
+	// and likewise the new pictures
+	var kept [][]byte
+	hasComment := slices.ContainsFunc(blocks, func(b metadataBlock) bool { return b.blockType == blockTypeVorbisComment })
+	wroteComment, wrotePictures := false, false
+	for _, b := range blocks {
+		switch {
+		case b.blockType == blockTypePadding:
+		case b.blockType == blockTypeVorbisComment && comment != nil:
+			if !wroteComment {
+				kept = append(kept, encodeBlock(blockTypeVorbisComment, comment))
+				wroteComment = true
+			}
+		case b.blockType == blockTypePicture && replacePictures:
+			if !wrotePictures {
+				kept = append(kept, pictures...)
+				wrotePictures = true
+			}
+		default:
+			payload := make([]byte, b.length)
+			if err := sr.ReadAtContext(ctx, payload, b.offset, "metadata block"); err != nil {
+				return nil, err
+			}
+			kept = append(kept, encodeBlock(b.blockType, payload))
+
+			if b.blockType == blockTypeStreamInfo && !hasComment && comment != nil {
+				kept = append(kept, encodeBlock(blockTypeVorbisComment, comment))
+			}
+		}
+	}
+	if replacePictures && !wrotePictures {
+		kept = append(kept, pictures...)
+	}
+
+	used := int64(0)
+	for _, b := range kept {
+		used += int64(len(b))
+	}
+
+	edit := &registry.TagEdit{Offset: start + 4, Length: audioOffset - (start + 4)}
+	padding := int64(defaultPadding)
+	switch free := edit.Length - used; {
+	case free == 0:
+		padding = -1 // Exact fit, no padding block
+	case free >= 4:
+		padding = free - 4
+	default:
+		edit.Warnings = append(edit.Warnings, types.Warning{
+			Stage:    "write",
+			Message:  fmt.Sprintf("metadata grew past the available padding by %d bytes; audio frames will move and the whole file is rewritten", -free),
+			Severity: types.SeverityInfo,
+		})
+	}
+	if padding >= 0 {
+		kept = append(kept, encodeBlock(blockTypePadding, make([]byte, min(padding, maxBlockLength))))
+	}
+
+	// Only the final block carries the is-last flag
+	kept[len(kept)-1][0] |= 0x80
+	edit.Data = make([]byte, 0, used+4+max(padding, 0))
+	for _, b := range kept {
+		edit.Data = append(edit.Data, b...)
+	}
+
+	return edit, nil
+}
+
+// readBlocks walks the metadata blocks from offset, returning them and the
+// offset of the first audio frame.
+func readBlocks(sr *binary.SafeReader, offset int64) ([]metadataBlock, int64, error) {
+	var blocks []metadataBlock
+	for {
+		isLast, blockType, length, err := readBlockHeader(sr, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		if offset+4+length > sr.Size() {
+			return nil, 0, &types.CorruptedFileError{
+				Path:   sr.Path(),
+				Offset: offset,
+				Reason: fmt.Sprintf("metadata block of %d bytes runs past the end of the file", length),
+			}
+		}
+		blocks = append(blocks, metadataBlock{blockType: blockType, offset: offset + 4, length: length})
+		offset += 4 + length
+		if isLast {
+			return blocks, offset, nil
+		}
+	}
+}
+
+// readVendor reads the vendor string at the start of a VORBIS_COMMENT block.
+func readVendor(sr *binary.SafeReader, block metadataBlock) (string, error) {
+	length, err := binary.ReadLE[uint32](sr, block.offset, "vendor string length")
+	if err != nil {
+		return "", err
+	}
+	if int64(length) > block.length-4 {
+		return "", fmt.Errorf("vendor string of %d bytes overruns its block", length)
+	}
+	vendor := make([]byte, length)
+	if err := sr.ReadAt(vendor, block.offset+4, "vendor string"); err != nil {
+		return "", err
+	}
+	return string(vendor), nil
+}
+
+// encodeBlock prefixes payload with a metadata block header. The is-last
+// flag is left clear.
+func encodeBlock(blockType uint8, payload []byte) []byte {
+	n := len(payload)
+	block := make([]byte, 4, 4+n)
+	block[0] = blockType & 0x7F
+	block[1], block[2], block[3] = byte(n>>16), byte(n>>8), byte(n)
+	return append(block, payload...)
+}
@@ -0,0 +1,192 @@
+package flac
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// audioFrames stands in for the encoded audio after the metadata.
+const audioFrames = "\xFF\xF8audio frames"
+
+// createPaddedFLAC extends createMinimalFLAC with an APPLICATION block, a
+// PADDING block of the given size and some audio bytes.
+func createPaddedFLAC(padding int) []byte {
+	data := createMinimalFLAC("Old Title", "Old Artist", "Old Album")
+	data[42] &^= 0x80 // VORBIS_COMMENT is no longer the last block
+
+	data = append(data, encodeBlock(blockTypeApplication, []byte("testAPPDATA"))...)
+	padBlock := encodeBlock(blockTypePadding, make([]byte, padding))
+	padBlock[0] |= 0x80
+	data = append(data, padBlock...)
+	return append(data, audioFrames...)
+}
+
+// applyEdit returns data with edit applied.
+func applyEdit(data []byte, edit *registry.TagEdit) []byte {
+	out := append([]byte{}, data[:edit.Offset]...)
+	out = append(out, edit.Data...)
+	return append(out, data[edit.Offset+edit.Length:]...)
+}
+
+func writeAndReparse(t *testing.T, data []byte, tags *types.Tags) (*registry.TagEdit, *types.File, []byte) {
+	t.Helper()
+	p := &parser{}
+	edit, err := p.WriteTags(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac", tags)
+	if err != nil {
+		t.Fatalf("WriteTags() error: %v", err)
+	}
+
+	out := applyEdit(data, edit)
+	file, err := p.Parse(context.Background(), bytes.NewReader(out), int64(len(out)), "test.flac")
+	if err != nil {
+		t.Fatalf("Parse() after write error: %v", err)
+	}
+	return edit, file, out
+}
+
+func TestWriteTags_InPlace(t *testing.T) {
+	data := createPaddedFLAC(1024)
+	p := &parser{}
+	original, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := original.Tags.Clone()
+	tags.Title = "New Title"
+	tags.Genres = []string{"Jazz", "Blues"}
+	tags.Set("MOOD", "Mellow")
+
+	edit, file, out := writeAndReparse(t, data, tags)
+
+	if !edit.InPlace() || len(edit.Warnings) > 0 {
+		t.Errorf("expected an in-place edit without warnings, got %d -> %d bytes, %v", edit.Length, len(edit.Data), edit.Warnings)
+	}
+	if len(out) != len(data) || !bytes.HasSuffix(out, []byte(audioFrames)) {
+		t.Error("audio frames moved")
+	}
+	if got, want := maps.Collect(file.Tags.Fields()), maps.Collect(tags.Fields()); !maps.Equal(got, want) {
+		t.Errorf("round trip changed tags:\n got %v\nwant %v", got, want)
+	}
+	if got := file.Tags.GetFirst("MOOD"); got != "Mellow" {
+		t.Errorf("raw MOOD = %q, want Mellow", got)
+	}
+
+	// STREAMINFO and the APPLICATION block are copied byte for byte
+	if !bytes.Equal(out[:42], data[:42]) {
+		t.Error("STREAMINFO changed")
+	}
+	if !bytes.Contains(out, []byte("testAPPDATA")) {
+		t.Error("APPLICATION block lost")
+	}
+}
+
+func TestWriteTags_Grows(t *testing.T) {
+	data := createPaddedFLAC(8)
+	tags := &types.Tags{Title: strings.Repeat("Long title ", 20)}
+
+	edit, file, out := writeAndReparse(t, data, tags)
+
+	if edit.InPlace() {
+		t.Error("expected the metadata to grow")
+	}
+	if len(edit.Warnings) != 1 || edit.Warnings[0].Stage != "write" {
+		t.Errorf("expected one write warning, got %v", edit.Warnings)
+	}
+	if !bytes.HasSuffix(out, []byte(audioFrames)) {
+		t.Error("audio frames lost")
+	}
+	if file.Tags.Title != tags.Title {
+		t.Errorf("Title = %q, want %q", file.Tags.Title, tags.Title)
+	}
+
+	// New padding leaves room for the next edit
+	edit, _, _ = writeAndReparse(t, out, &types.Tags{Title: "Short"})
+	if !edit.InPlace() {
+		t.Error("expected the second edit to fit in the added padding")
+	}
+}
+
+func TestWriteTags_ExactFit(t *testing.T) {
+	// Same-length tags and no padding: the last block flag moves
+	data := createMinimalFLAC("Old Title", "Old Artist", "Old Album")
+	data = append(data, audioFrames...)
+
+	edit, file, out := writeAndReparse(t, data, &types.Tags{Title: "New Title", Artist: "New Artist", Album: "New Album"})
+
+	if !edit.InPlace() || len(edit.Warnings) > 0 {
+		t.Errorf("expected an exact in-place fit, got %d -> %d bytes", edit.Length, len(edit.Data))
+	}
+	if out[42]&0x80 == 0 {
+		t.Error("VORBIS_COMMENT should keep the is-last flag")
+	}
+	if file.Tags.Album != "New Album" {
+		t.Errorf("Album = %q, want %q", file.Tags.Album, "New Album")
+	}
+}
+
+func TestWriteTags_KeepsVendor(t *testing.T) {
+	data := bytes.Replace(createPaddedFLAC(256), []byte("audiometa"), []byte("libFLAC 1"), 1)
+	_, _, out := writeAndReparse(t, data, &types.Tags{Title: "New"})
+
+	if !bytes.Contains(out, []byte("libFLAC 1")) || bytes.Contains(out, []byte(defaultVendor)) {
+		t.Error("expected the original vendor string to be kept")
+	}
+}
+
+func TestWriteArtwork(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0DIHDR"), 0, 0, 0x01, 0xF4, 0, 0, 0x01, 0x2C)
+	data := createPaddedFLAC(1024)
+	p := &parser{}
+
+	art := []types.Artwork{
+		{MIMEType: "image/png", Type: types.ArtworkFrontCover, Description: "Front", Data: png},
+		{MIMEType: "image/jpeg", Type: types.ArtworkBackCover, Data: []byte("\xFF\xD8jpeg"), Width: 10, Height: 20},
+	}
+	edit, err := p.WriteArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.flac", nil, art)
+	if err != nil {
+		t.Fatalf("WriteArtwork() error: %v", err)
+	}
+	if !edit.InPlace() {
+		t.Error("expected the pictures to fit in the padding")
+	}
+	out := applyEdit(data, edit)
+
+	file, err := p.Parse(context.Background(), bytes.NewReader(out), int64(len(out)), "test.flac")
+	if err != nil {
+		t.Fatalf("Parse() after write error: %v", err)
+	}
+	if file.Tags.Title != "Old Title" {
+		t.Errorf("Title = %q, want the comments kept with nil tags", file.Tags.Title)
+	}
+
+	got, err := p.ExtractArtwork(context.Background(), bytes.NewReader(out), int64(len(out)), "test.flac")
+	if err != nil {
+		t.Fatalf("ExtractArtwork() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ExtractArtwork() returned %d images, want 2", len(got))
+	}
+	if got[0].Description != "Front" || got[0].Width != 500 || got[0].Height != 300 || !bytes.Equal(got[0].Data, png) {
+		t.Errorf("first image = %+v, want the PNG with detected 500x300", got[0])
+	}
+	if got[1].Type != types.ArtworkBackCover || got[1].Width != 10 || got[1].Height != 20 {
+		t.Errorf("second image = %+v, want the back cover with its given size", got[1])
+	}
+
+	// Writing again replaces the pictures rather than adding to them
+	edit, err = p.WriteArtwork(context.Background(), bytes.NewReader(out), int64(len(out)), "test.flac", nil, art[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = applyEdit(out, edit)
+	if got, _ := p.ExtractArtwork(context.Background(), bytes.NewReader(out), int64(len(out)), "test.flac"); len(got) != 1 {
+		t.Errorf("ExtractArtwork() after replacing = %d images, want 1", len(got))
+	}
+}
@@ -0,0 +1,47 @@
+// Package imagesize reads the dimensions of embedded artwork from its
+// image header, without decoding the image.
+package imagesize
+
+import "encoding/binary"
+
+// Dimensions extracts width/height from image data of the given MIME type.
+// Supports JPEG and PNG. Returns 0, 0 if unable to detect, including when
+// data holds only the start of the image and the header is cut off.
+func Dimensions(data []byte, mimeType string) (width, height int) {
+	switch mimeType {
+	case "image/jpeg":
+		return jpegDimensions(data)
+	case "image/png":
+		return pngDimensions(data)
+	default:
+		return 0, 0
+	}
+}
+
+// jpegDimensions extracts dimensions from the first SOF0-SOF2 marker of
+// JPEG data.
+func jpegDimensions(data []byte) (int, int) {
+	for i := range len(data) - 9 {
+		if data[i] != 0xFF {
+			continue
+		}
+		// SOF format: FF Cn [2 bytes length] [1 byte precision] [2 bytes height] [2 bytes width]
+		if marker := data[i+1]; marker == 0xC0 || marker == 0xC1 || marker == 0xC2 {
+			height := int(data[i+5])<<8 | int(data[i+6])
+			width := int(data[i+7])<<8 | int(data[i+8])
+			return width, height
+		}
+	}
+	return 0, 0
+}
+
+// pngDimensions extracts dimensions from the IHDR chunk that follows the
+// PNG signature.
+func pngDimensions(data []byte) (int, int) {
+	if len(data) < 24 || string(data[:8]) != "\x89PNG\r\n\x1a\n" {
+		return 0, 0
+	}
+	width := binary.BigEndian.Uint32(data[16:20])
+	height := binary.BigEndian.Uint32(data[20:24])
+	return int(width), int(height)
+}
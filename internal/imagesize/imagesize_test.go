@@ -0,0 +1,45 @@
+package imagesize
+
+import "testing"
+
+func TestDimensions(t *testing.T) {
+	png := []byte{
+		0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, // PNG signature
+		0x00, 0x00, 0x00, 0x0D, 0x49, 0x48, 0x44, 0x52, // IHDR chunk
+		0x00, 0x00, 0x02, 0x58, // width = 600
+		0x00, 0x00, 0x01, 0x90, // height = 400
+	}
+	jpeg := []byte{
+		0xFF, 0xD8, // SOI
+		0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00, // APP0
+		0xFF, 0xC2, 0x00, 0x11, 0x08, // SOF2, length, precision
+		0x01, 0xE0, // height = 480
+		0x02, 0x80, // width = 640
+		0x03,
+	}
+
+	tests := []struct {
+		name          string
+		data          []byte
+		mimeType      string
+		width, height int
+	}{
+		{"PNG", png, "image/png", 600, 400},
+		{"JPEG", jpeg, "image/jpeg", 640, 480},
+		{"truncated PNG", png[:20], "image/png", 0, 0},
+		{"truncated JPEG", jpeg[:12], "image/jpeg", 0, 0},
+		{"PNG labeled JPEG", png, "image/jpeg", 0, 0},
+		{"JPEG labeled PNG", jpeg, "image/png", 0, 0},
+		{"unsupported type", png, "image/gif", 0, 0},
+		{"empty", nil, "image/jpeg", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := Dimensions(tt.data, tt.mimeType)
+			if width != tt.width || height != tt.height {
+				t.Errorf("Dimensions() = %dx%d, want %dx%d", width, height, tt.width, tt.height)
+			}
+		})
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"os"
 	"testing"
 
+	audiobinary "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -388,3 +391,104 @@ func TestExtractArtwork_CorruptedImageData(t *testing.T) {
 		t.Error("data mismatch")
 	}
 }
+
+func TestCountArtwork(t *testing.T) {
+	img := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0xFF, 0xD9}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"three covers", createM4BWithMultipleCovers([][]byte{img, img, img}), 3},
+		{"one cover", createM4BWithMultipleCovers([][]byte{img}), 1},
+		{"no moov", createMockAtom("ftyp", []byte("M4A ")), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &parser{}
+			n, err := p.CountArtwork(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "test.m4b")
+			if err != nil {
+				t.Fatalf("CountArtwork failed: %v", err)
+			}
+			if n != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, n)
+			}
+		})
+	}
+}
+
+func TestExtractArtwork_Canceled(t *testing.T) {
+	img := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0xFF, 0xD9}
+	data := createM4BWithMultipleCovers([][]byte{img, img})
+	sr := audiobinary.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.m4b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	artwork, err := extractArtwork(ctx, sr, int64(len(data)), true)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if artwork != nil {
+		t.Errorf("expected no artwork on cancellation, got %d", len(artwork))
+	}
+}
+
+func TestExtractArtworkMetadata(t *testing.T) {
+	// PNG header followed by more data than the header probe reads
+	pngData := []byte{
+		0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, // PNG signature
+		0x00, 0x00, 0x00, 0x0D, 0x49, 0x48, 0x44, 0x52, // IHDR chunk
+		0x00, 0x00, 0x02, 0x58, // width = 600
+		0x00, 0x00, 0x01, 0x90, // height = 400
+	}
+	pngData = append(pngData, make([]byte, 2*imageHeaderProbe)...)
+
+	fileData := createM4BWithCover(pngData, 0x0E)
+
+	p := &parser{}
+	artwork, err := p.ExtractArtworkMetadata(context.Background(), bytes.NewReader(fileData), int64(len(fileData)), "test.m4b")
+	if err != nil {
+		t.Fatalf("ExtractArtworkMetadata failed: %v", err)
+	}
+	if len(artwork) != 1 {
+		t.Fatalf("expected 1 artwork, got %d", len(artwork))
+	}
+
+	art := artwork[0]
+	if art.Data != nil {
+		t.Errorf("expected nil Data, got %d bytes", len(art.Data))
+	}
+	if art.Size != len(pngData) {
+		t.Errorf("expected Size %d, got %d", len(pngData), art.Size)
+	}
+	if art.MIMEType != "image/png" || art.Width != 600 || art.Height != 400 {
+		t.Errorf("unexpected artwork metadata: %v", art)
+	}
+}
+
+func TestExtractArtwork_MaxArtworkSize(t *testing.T) {
+	small := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0xFF, 0xD9}
+	large := append([]byte{0xFF, 0xD8, 0xFF, 0xE0}, make([]byte, 100)...)
+	data := createM4BWithMultipleCovers([][]byte{large, small})
+
+	ctx := registry.WithMaxArtworkSize(context.Background(), 50)
+	p := &parser{}
+	artwork, err := p.ExtractArtwork(ctx, bytes.NewReader(data), int64(len(data)), "test.m4b")
+	if err != nil {
+		t.Fatalf("ExtractArtwork failed: %v", err)
+	}
+	if len(artwork) != 2 {
+		t.Fatalf("expected 2 artworks, got %d", len(artwork))
+	}
+
+	// The large image is described but not loaded
+	if artwork[0].Data != nil || artwork[0].Size != len(large) {
+		t.Errorf("large image: got %d bytes of data, Size %d; want none, Size %d", len(artwork[0].Data), artwork[0].Size, len(large))
+	}
+	if !bytes.Equal(artwork[1].Data, small) {
+		t.Error("small image data mismatch")
+	}
+}
@@ -2,6 +2,7 @@
 package m4a
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/simonhull/audiometa/internal/binary"
@@ -98,9 +99,22 @@ func readAtomHeader(sr *binary.SafeReader, offset int64) (*Atom, error) {
 
 // Returns the first matching atom or an error if not found.
 func findAtom(sr *binary.SafeReader, start, end int64, atomType string) (*Atom, error) {
+	return findAtomContext(context.Background(), sr, start, end, atomType)
+}
+
+// findAtomContext is like findAtom but checks ctx every ctxCheckInterval
+// atoms, returning ctx.Err() if it is done. Use it for containers a file
+// can fill with any number of atoms, such as the top level and moov.
+func findAtomContext(ctx context.Context, sr *binary.SafeReader, start, end int64, atomType string) (*Atom, error) {
 	offset := start
 
-	for offset < end {
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		atom, err := readAtomHeader(sr, offset)
 		if err != nil {
 			return nil, err
@@ -1,6 +1,8 @@
 package m4a
 
 import (
+	"context"
+	"slices"
 	"strings"
 
 	"github.com/simonhull/audiometa/internal/binary"
@@ -8,8 +10,53 @@ import (
 	"github.com/simonhull/audiometa/internal/types"
 )
 
+// promoteAudiobook reclassifies an M4A file as M4B when audiobookSignal
+// finds a reason to, with a warning giving it. Audiobooks are often
+// written with a generic brand ("mp42", "isom") that ftyp alone reads as
+// M4A.
+func promoteAudiobook(file *types.File, narrated bool) {
+	if file.Format != types.FormatM4A {
+		return
+	}
+	if reason := audiobookSignal(file, narrated); reason != "" {
+		file.Format = types.FormatM4B
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "detect",
+			Message:  "read as M4B rather than M4A: " + reason,
+			Severity: types.SeverityInfo,
+		})
+	}
+}
+
+// audiobookSignal returns the first sign that a parsed file is an
+// audiobook, or "" if there is none. A stik atom naming any other media
+// kind outweighs the rest. narrated reports a narrator read from the
+// file itself rather than copied from the composer.
+func audiobookSignal(file *types.File, narrated bool) string {
+	if kind := file.Audio.MediaType; kind != types.MediaTypeUnknown {
+		if kind == types.MediaTypeAudiobook {
+			return "stik media kind is Audiobook"
+		}
+		return ""
+	}
+
+	isAudiobookGenre := func(genre string) bool {
+		return strings.EqualFold(genre, "Audiobook") || strings.EqualFold(genre, "Audiobooks")
+	}
+	switch {
+	case file.ChapterSource != "":
+		return "has chapters (" + file.ChapterSource + ")"
+	case slices.ContainsFunc(file.Tags.Genres, isAudiobookGenre):
+		return "genre is Audiobook"
+	case narrated:
+		return "names a narrator"
+	default:
+		return ""
+	}
+}
+
 // parseAudiobookTags extracts narrator, series, publisher, etc. from custom atoms.
-func parseAudiobookTags(sr *binary.SafeReader, ilstAtom *Atom, file *types.File) error {
+func parseAudiobookTags(ctx context.Context, sr *binary.SafeReader, ilstAtom *Atom, file *types.File) error {
 	offset := ilstAtom.DataOffset()
 	end := offset + int64(ilstAtom.DataSize())
 
@@ -17,7 +64,13 @@ func parseAudiobookTags(sr *binary.SafeReader, ilstAtom *Atom, file *types.File)
 	customTags := make(map[string]string)
 
 	// Scan through ilst for custom atoms (----)
-	for offset < end {
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
 		atom, err := readAtomHeader(sr, offset)
 		if err != nil {
 			// Skip corrupted atoms
@@ -37,6 +90,18 @@ func parseAudiobookTags(sr *binary.SafeReader, ilstAtom *Atom, file *types.File)
 	}
 
 	// Apply fallbacks
+	// iTunes Sound Check stands in for ReplayGain the file doesn't have
+	if file.Audio.ReplayGain == nil {
+		for fieldName, value := range customTags {
+			if strings.EqualFold(fieldName, "iTunNORM") {
+				if gain, peak, ok := parsing.ParseSoundCheck(value); ok {
+					file.Audio.ReplayGain = &types.ReplayGainInfo{TrackGain: gain, TrackPeak: peak}
+				}
+				break
+			}
+		}
+	}
+
 	// If no custom Narrator atom, use Composer as fallback
 	if file.Tags.Narrator == "" && len(file.Tags.Composers) > 0 {
 		file.Tags.Narrator = file.Tags.Composers[0]
@@ -103,16 +168,9 @@ func parseCustomAtomWithTags(sr *binary.SafeReader, customAtom *Atom, file *type
 			}
 
 		case "data":
-			// Value - parse the data atom directly
-			// Skip version (1 byte) + flags (3 bytes) + reserved (4 bytes) = 8 bytes
-			valueOffset := atom.DataOffset() + 8
-			valueSize := int64(atom.DataSize()) - 8
-			if valueSize > 0 {
-				buf := make([]byte, valueSize)
-				if err := sr.ReadAt(buf, valueOffset, "data value"); err == nil {
-					value = strings.TrimRight(string(buf), "\x00")
-					value = strings.TrimSpace(value)
-				}
+			// Value - integer types (mvin from some writers) come back in decimal
+			if v, err := readDataValue(sr, atom); err == nil {
+				value = v
 			}
 		}
 
@@ -135,6 +193,10 @@ func mapAudiobookField(fieldName, value string, file *types.File) {
 	switch fieldName {
 	case "subtitle":
 		file.Tags.Subtitle = value
+	case "discsubtitle", "setsubtitle":
+		file.Tags.DiscSubtitle = value
+	case "lyrics", "syncedlyrics", "lrc":
+		setLyrics(value, file)
 	case "narrator":
 		file.Tags.Narrator = value
 	case "series":
@@ -161,6 +223,8 @@ func mapAudiobookField(fieldName, value string, file *types.File) {
 		if file.Tags.SeriesPart == "" {
 			file.Tags.SeriesPart = value
 		}
+	case "replaygain_track_gain", "replaygain_track_peak", "replaygain_album_gain", "replaygain_album_peak":
+		types.SetReplayGain(&file.Audio, fieldName, value)
 	}
 }
 
@@ -2,6 +2,7 @@ package m4a
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"testing"
 
@@ -63,7 +64,7 @@ func TestParseAudiobookTags_Narrator(t *testing.T) {
 	ilstAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseAudiobookTags(sr, ilstAtom, file)
+	err := parseAudiobookTags(context.Background(), sr, ilstAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -73,6 +74,57 @@ func TestParseAudiobookTags_Narrator(t *testing.T) {
 	}
 }
 
+func TestParseAudiobookTags_ReplayGain(t *testing.T) {
+	// Sound Check loudness 0xFA0 (4000) is 4× the reference: -6.02 dB
+	soundCheck := " 00000FA0 00000BB8 00000FA0 00000BB8 00024CA8 00024CA8 00004000 00003000 00024CA8 00024CA8"
+
+	tests := []struct {
+		name  string
+		atoms [][]byte
+		want  *types.ReplayGainInfo
+	}{
+		{
+			name: "replaygain atoms",
+			atoms: [][]byte{
+				createCustomAtom("com.apple.iTunes", "replaygain_track_gain", "-6.50 dB"),
+				createCustomAtom("com.apple.iTunes", "REPLAYGAIN_TRACK_PEAK", "0.988127"),
+				createCustomAtom("com.apple.iTunes", "replaygain_album_gain", "-7.10 dB"),
+				createCustomAtom("com.apple.iTunes", "replaygain_album_peak", "1.0"),
+			},
+			want: &types.ReplayGainInfo{TrackGain: -6.5, TrackPeak: 0.988127, AlbumGain: -7.1, AlbumPeak: 1},
+		},
+		{
+			name:  "sound check",
+			atoms: [][]byte{createCustomAtom("com.apple.iTunes", "iTunNORM", soundCheck)},
+			want:  &types.ReplayGainInfo{TrackGain: -6.020599913279624, TrackPeak: 0.5},
+		},
+		{
+			name: "replaygain preferred to sound check",
+			atoms: [][]byte{
+				createCustomAtom("com.apple.iTunes", "iTunNORM", soundCheck),
+				createCustomAtom("com.apple.iTunes", "replaygain_track_gain", "-3.00 dB"),
+			},
+			want: &types.ReplayGainInfo{TrackGain: -3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ilst := createMockAtom("ilst", bytes.Join(tt.atoms, nil))
+			sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+			ilstAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := parseAudiobookTags(context.Background(), sr, ilstAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if file.Audio.ReplayGain == nil || *file.Audio.ReplayGain != *tt.want {
+				t.Errorf("ReplayGain = %+v, want %+v", file.Audio.ReplayGain, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseAudiobookTags_Series(t *testing.T) {
 	seriesAtom := createCustomAtom("com.apple.iTunes", "Series", "The Expanse")
 	ilst := createMockAtom("ilst", seriesAtom)
@@ -81,7 +133,7 @@ func TestParseAudiobookTags_Series(t *testing.T) {
 	ilstAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseAudiobookTags(sr, ilstAtom, file)
+	err := parseAudiobookTags(context.Background(), sr, ilstAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -105,7 +157,7 @@ func TestParseAudiobookTags_MultipleFields(t *testing.T) {
 	ilstAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseAudiobookTags(sr, ilstAtom, file)
+	err := parseAudiobookTags(context.Background(), sr, ilstAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -138,7 +190,7 @@ func TestParseAudiobookTags_NarratorFallback(t *testing.T) {
 		},
 	}
 
-	err := parseAudiobookTags(sr, ilstAtom, file)
+	err := parseAudiobookTags(context.Background(), sr, ilstAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -160,7 +212,7 @@ func TestParseAudiobookTags_NoCustomAtoms(t *testing.T) {
 	ilstAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseAudiobookTags(sr, ilstAtom, file)
+	err := parseAudiobookTags(context.Background(), sr, ilstAtom, file)
 
 	// Should not error, just no audiobook tags extracted
 	if err != nil {
@@ -456,3 +508,83 @@ func TestResolveSeriesPart_FromPathParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAudiobookTags_IntegerMovementIndex(t *testing.T) {
+	// mvin as a custom atom holding an implicit-type 16-bit integer
+	custom := createCustomAtom("com.apple.iTunes", "mvin", "\x00\x03")
+	custom[len(custom)-2-5] = 0x00 // data type code: implicit instead of UTF-8
+	ilst := createMockAtom("ilst", custom)
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4b")
+	ilstAtom, _ := readAtomHeader(sr, 0)
+
+	file := &types.File{}
+	if err := parseAudiobookTags(context.Background(), sr, ilstAtom, file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Tags.SeriesPart != "3" {
+		t.Errorf("SeriesPart = %q, want %q", file.Tags.SeriesPart, "3")
+	}
+}
+
+func TestPromoteAudiobook(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     types.File
+		narrated bool
+		want     types.Format
+	}{
+		{"music", types.File{Format: types.FormatM4A, Tags: types.Tags{Genres: []string{"Rock"}}}, false, types.FormatM4A},
+		{"stik audiobook", types.File{Format: types.FormatM4A, Audio: types.AudioInfo{MediaType: types.MediaTypeAudiobook}}, false, types.FormatM4B},
+		{"chapters", types.File{Format: types.FormatM4A, ChapterSource: chapterSourceNero}, false, types.FormatM4B},
+		{"genre", types.File{Format: types.FormatM4A, Tags: types.Tags{Genres: []string{"audiobooks"}}}, false, types.FormatM4B},
+		{"narrator", types.File{Format: types.FormatM4A}, true, types.FormatM4B},
+		{"stik music outweighs chapters", types.File{Format: types.FormatM4A, Audio: types.AudioInfo{MediaType: types.MediaTypeMusic}, ChapterSource: chapterSourceQuickTime}, true, types.FormatM4A},
+		{"already M4B", types.File{Format: types.FormatM4B, Audio: types.AudioInfo{MediaType: types.MediaTypeAudiobook}}, false, types.FormatM4B},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := tt.file
+			wasM4A := file.Format == types.FormatM4A
+			promoteAudiobook(&file, tt.narrated)
+			if file.Format != tt.want {
+				t.Errorf("Format = %v, want %v", file.Format, tt.want)
+			}
+			if promoted := wasM4A && file.Format == types.FormatM4B; promoted != (len(file.Warnings) == 1) {
+				t.Errorf("promoted = %v but warnings = %v", promoted, file.Warnings)
+			}
+		})
+	}
+}
+
+func TestParse_PromotesAudiobook(t *testing.T) {
+	build := func(items ...[]byte) []byte {
+		ftyp := createMockAtom("ftyp", []byte("mp42\x00\x00\x00\x00isommp42"))
+		ilst := createMockAtom("ilst", bytes.Join(items, nil))
+		meta := createMockAtom("meta", append(make([]byte, 4), ilst...))
+		return append(ftyp, createMockAtom("moov", createMockAtom("udta", meta))...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want types.Format
+	}{
+		{"composer only", build(createMetadataItem([]byte("\xA9wrt"), "Composer")), types.FormatM4A},
+		{"narrator atom", build(createMetadataItem([]byte("\xA9nrt"), "Stephen Fry")), types.FormatM4B},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &parser{}
+			file, err := p.Parse(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "book.m4a")
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if file.Format != tt.want {
+				t.Errorf("Format = %v, want %v", file.Format, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package m4a
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Core Audio channel layout tags are (layout ID << 16) | channel count. Two
+// IDs defer to the rest of the chan atom instead of naming a layout.
+const (
+	layoutUseDescriptions = 0 // Channels listed one by one
+	layoutUseBitmap       = 1 // Channels given as a speaker bitmap
+)
+
+// channelLabelLFE is kAudioChannelLabel_LFEScreen, the LFE channel label.
+const channelLabelLFE = 4
+
+// channelBitLFE is the LFE speaker bit in a channel bitmap.
+const channelBitLFE = 1 << 3
+
+// channelLayoutNames maps Core Audio layout IDs (the high 16 bits of the
+// layout tag) to display names. The AAC_* aliases share these IDs.
+var channelLayoutNames = map[uint32]string{
+	100: "mono",
+	101: "stereo",
+	102: "stereo", // StereoHeadphones
+	103: "stereo", // MatrixStereo
+	104: "stereo", // MidSide
+	105: "stereo", // XY
+	106: "stereo", // Binaural
+	108: "quad",
+	109: "5.0", // Pentagonal
+	110: "6.0", // Hexagonal
+	111: "8.0", // Octagonal
+	113: "3.0", // MPEG_3_0_A
+	114: "3.0", // MPEG_3_0_B
+	115: "4.0", // MPEG_4_0_A
+	116: "4.0", // MPEG_4_0_B
+	117: "5.0", // MPEG_5_0_A
+	118: "5.0", // MPEG_5_0_B
+	119: "5.0", // MPEG_5_0_C
+	120: "5.0", // MPEG_5_0_D
+	121: "5.1", // MPEG_5_1_A
+	122: "5.1", // MPEG_5_1_B
+	123: "5.1", // MPEG_5_1_C
+	124: "5.1", // MPEG_5_1_D
+	125: "6.1", // MPEG_6_1_A
+	126: "7.1", // MPEG_7_1_A
+	127: "7.1", // MPEG_7_1_B
+	128: "7.1", // MPEG_7_1_C
+	141: "6.0", // AAC_6_0
+	142: "6.1", // AAC_6_1
+	143: "7.0", // AAC_7_0
+	144: "8.0", // AAC_Octagonal
+}
+
+// parseChannelLayout reads the chan atom among a sample entry's child boxes
+// and, when it names a layout, overrides the sample-entry channel count.
+//
+// chan layout (after 4 bytes version + flags):
+// [4 bytes] channel layout tag
+// [4 bytes] channel bitmap
+// [4 bytes] number of channel descriptions
+// [20 bytes each] label, flags, 3 coordinates
+func parseChannelLayout(sr *binary.SafeReader, sampleEntryOffset, sampleEntrySize int64, file *types.File) error {
+	start, err := sampleEntryChildOffset(sr, sampleEntryOffset)
+	if err != nil {
+		return err
+	}
+	end := sampleEntryOffset + sampleEntrySize
+	if end <= start {
+		return nil
+	}
+
+	chanAtom, err := findAtom(sr, start, end, "chan")
+	if err != nil {
+		return err
+	}
+	if chanAtom.DataSize() < 16 {
+		return nil
+	}
+
+	offset := chanAtom.DataOffset() + 4 // Skip version + flags
+	tag, err := binary.Read[uint32](sr, offset, "chan layout tag")
+	if err != nil {
+		return err
+	}
+	bitmap, err := binary.Read[uint32](sr, offset+4, "chan channel bitmap")
+	if err != nil {
+		return err
+	}
+	numDescriptions, err := binary.Read[uint32](sr, offset+8, "chan description count")
+	if err != nil {
+		return err
+	}
+
+	var channels, lfe int
+	layoutName := ""
+	switch layoutID := tag >> 16; layoutID {
+	case layoutUseBitmap:
+		channels = bits.OnesCount32(bitmap)
+		if bitmap&channelBitLFE != 0 {
+			lfe = 1
+		}
+	case layoutUseDescriptions:
+		// Bound the count by the atom so a corrupt value can't drive the loop
+		maxDescriptions := (chanAtom.DataSize() - 16) / 20
+		numDescriptions = uint32(min(uint64(numDescriptions), maxDescriptions))
+		for i := range int64(numDescriptions) {
+			label, err := binary.Read[uint32](sr, chanAtom.DataOffset()+16+i*20, "chan channel label")
+			if err != nil {
+				return err
+			}
+			if label == channelLabelLFE {
+				lfe++
+			}
+		}
+		channels = int(numDescriptions)
+	default:
+		channels = int(tag & 0xFFFF)
+		layoutName = channelLayoutNames[layoutID]
+	}
+
+	if channels == 0 {
+		return nil
+	}
+	if layoutName == "" {
+		layoutName = speakerLayoutName(channels, lfe)
+	}
+
+	file.Audio.Channels = channels
+	file.Audio.ChannelLayout = layoutName
+	return nil
+}
+
+// speakerLayoutName renders a channel count in the usual "5.1" notation,
+// with mono and stereo named when there is no LFE channel.
+func speakerLayoutName(channels, lfe int) string {
+	if lfe == 0 {
+		switch channels {
+		case 1:
+			return "mono"
+		case 2:
+			return "stereo"
+		}
+	}
+	return fmt.Sprintf("%d.%d", channels-lfe, lfe)
+}
+
+// sampleEntryChildOffset returns where the child boxes of an audio sample
+// entry begin. QuickTime sound description versions 1 and 2 extend the
+// fixed fields by 16 and 36 bytes.
+func sampleEntryChildOffset(sr *binary.SafeReader, sampleEntryOffset int64) (int64, error) {
+	version, err := binary.Read[uint16](sr, sampleEntryOffset+16, "sound description version")
+	if err != nil {
+		return 0, err
+	}
+
+	switch version {
+	case 1:
+		return sampleEntryOffset + alacSampleEntrySize + 16, nil
+	case 2:
+		return sampleEntryOffset + alacSampleEntrySize + 36, nil
+	default:
+		return sampleEntryOffset + alacSampleEntrySize, nil
+	}
+}
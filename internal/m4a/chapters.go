@@ -1,45 +1,67 @@
 package m4a
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
 	"time"
+	"unicode/utf8"
 
 	"github.com/simonhull/audiometa/internal/binary"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
+// Chapter sources reported in File.ChapterSource.
+const (
+	chapterSourceQuickTime = "m4a:quicktime"
+	chapterSourceNero      = "m4a:nero-chpl"
+)
+
 // Tries in order: QuickTime chapter tracks (tref) -> Nero chapters (chpl).
-func parseChapters(sr *binary.SafeReader, moovAtom *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
+//
+// The returned source names the mechanism that produced the chapters, for
+// File.ChapterSource.
+//
+// Cancellation of ctx is returned as ctx.Err() without trying the fallback.
+func parseChapters(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, fileDuration time.Duration) ([]types.Chapter, string, error) {
 	// Try QuickTime chapter tracks first (most common in professional audiobooks)
-	qtChapters, qtErr := parseQuickTimeChapters(sr, moovAtom, fileDuration)
+	qtChapters, qtErr := parseQuickTimeChapters(ctx, sr, moovAtom, fileDuration)
 	if qtErr == nil && len(qtChapters) > 0 {
-		return qtChapters, nil
+		return qtChapters, chapterSourceQuickTime, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
 	}
 
 	// Fall back to Nero chpl format
-	chplChapters, chplErr := parseChplChapters(sr, moovAtom, fileDuration)
+	chplChapters, chplErr := parseChplChapters(ctx, sr, moovAtom, fileDuration)
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
 	if chplErr == nil && len(chplChapters) > 0 {
-		return chplChapters, nil
+		return chplChapters, chapterSourceNero, nil
 	}
 
 	// If we got partial results, return them
 	if len(chplChapters) > 0 {
-		return chplChapters, nil
+		return chplChapters, chapterSourceNero, nil
 	}
 	if len(qtChapters) > 0 {
-		return qtChapters, nil
+		return qtChapters, chapterSourceQuickTime, nil
 	}
 
 	// If both formats failed with errors (not just "not found"), return the error
 	if qtErr != nil && chplErr != nil {
-		return nil, qtErr // Return first error as representative
+		return nil, "", qtErr // Return first error as representative
 	}
 
 	// No chapters found (not an error)
-	return nil, nil
+	return nil, "", nil
 }
 
 // parseChplChapters extracts chapter markers from the chpl atom (Nero format).
-func parseChplChapters(sr *binary.SafeReader, moovAtom *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
+func parseChplChapters(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
 	// Find udta atom (user data)
 	// Path: moov -> udta
 	udtaAtom, err := findAtom(sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "udta")
@@ -87,6 +109,12 @@ func parseChplChapters(sr *binary.SafeReader, moovAtom *Atom, fileDuration time.
 
 	// Read each chapter
 	for i := range chapterCount {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		// Read start time (8 bytes, in 100-nanosecond units)
 		startTime100ns, err := binary.Read[uint64](sr, offset, "chapter start time")
 		if err != nil {
@@ -142,29 +170,40 @@ func parseChplChapters(sr *binary.SafeReader, moovAtom *Atom, fileDuration time.
 }
 
 // Format: trak -> tref -> chap references a text track with chapter names.
-func parseQuickTimeChapters(sr *binary.SafeReader, moovAtom *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
+func parseQuickTimeChapters(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
 	// Step 1: Find the chapter track reference
-	chapterTrackID := findChapterTrackReference(sr, moovAtom)
+	referrer, chapterTrackID := findChapterReferrer(ctx, sr, moovAtom)
 	if chapterTrackID == 0 {
 		return nil, nil
 	}
 
 	// Step 2: Find the chapter track by ID
-	chapterTrak := findTrackByID(sr, moovAtom, chapterTrackID)
+	chapterTrak := findTrackByID(ctx, sr, moovAtom, chapterTrackID)
 	if chapterTrak == nil {
 		return nil, nil
 	}
 
 	// Step 3: Parse the text track
-	return parseTextTrackChapters(sr, chapterTrak, fileDuration)
+	return parseTextTrackChapters(ctx, sr, chapterTrak, referrer, fileDuration)
 }
 
 // findChapterTrackReference finds the tref->chap atom and returns the chapter track ID.
-func findChapterTrackReference(sr *binary.SafeReader, moovAtom *Atom) uint32 {
+func findChapterTrackReference(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom) uint32 {
+	_, trackID := findChapterReferrer(ctx, sr, moovAtom)
+	return trackID
+}
+
+// findChapterReferrer finds the trak carrying a tref->chap atom (normally the
+// audio track) and returns it along with the referenced chapter track ID.
+func findChapterReferrer(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom) (*Atom, uint32) {
 	offset := moovAtom.DataOffset()
 	end := offset + int64(moovAtom.DataSize())
 
-	for offset < end {
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			break
+		}
+
 		trakAtom, err := readAtomHeader(sr, offset)
 		if err != nil {
 			break
@@ -173,14 +212,14 @@ func findChapterTrackReference(sr *binary.SafeReader, moovAtom *Atom) uint32 {
 		if trakAtom.Type == "trak" {
 			trackID := extractChapterTrackID(sr, trakAtom)
 			if trackID != 0 {
-				return trackID
+				return trakAtom, trackID
 			}
 		}
 
 		offset += int64(trakAtom.Size)
 	}
 
-	return 0
+	return nil, 0
 }
 
 // extractChapterTrackID reads the chapter track ID from tref->chap if present.
@@ -204,11 +243,15 @@ func extractChapterTrackID(sr *binary.SafeReader, trakAtom *Atom) uint32 {
 }
 
 // findTrackByID finds a trak atom with the specified track ID.
-func findTrackByID(sr *binary.SafeReader, moovAtom *Atom, targetID uint32) *Atom {
+func findTrackByID(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, targetID uint32) *Atom {
 	offset := moovAtom.DataOffset()
 	end := offset + int64(moovAtom.DataSize())
 
-	for offset < end {
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			break
+		}
+
 		trakAtom, err := readAtomHeader(sr, offset)
 		if err != nil {
 			break
@@ -256,7 +299,9 @@ func readTrackID(sr *binary.SafeReader, trakAtom *Atom) uint32 {
 }
 
 // parseTextTrackChapters extracts chapter information from a text track.
-func parseTextTrackChapters(sr *binary.SafeReader, trakAtom *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
+// referrer is the trak whose tref points at the chapter track; its timescale
+// is used instead when the chapter track's own one overshoots fileDuration.
+func parseTextTrackChapters(ctx context.Context, sr *binary.SafeReader, trakAtom, referrer *Atom, fileDuration time.Duration) ([]types.Chapter, error) {
 	// Find mdia -> minf -> stbl (sample table)
 	mdiaAtom, err := findAtom(sr, trakAtom.DataOffset(), trakAtom.DataOffset()+int64(trakAtom.DataSize()), "mdia")
 	if err != nil {
@@ -284,6 +329,9 @@ func parseTextTrackChapters(sr *binary.SafeReader, trakAtom *Atom, fileDuration
 	if err != nil {
 		return nil, err
 	}
+	if retimed, _ := retimeChapters(sr, stblAtom, referrer, chapterTimes, timescale, fileDuration); retimed != nil {
+		chapterTimes = retimed
+	}
 
 	// Parse sample sizes
 	sampleSizes, err := parseSampleSizes(sr, stblAtom)
@@ -297,8 +345,25 @@ func parseTextTrackChapters(sr *binary.SafeReader, trakAtom *Atom, fileDuration
 		return nil, err
 	}
 
+	// Recover offsets written relative to the original mdat by re-muxers
+	if base := chapterTextBase(sr, chunkOffsets, sampleSizes); base != 0 {
+		for i := range chunkOffsets {
+			chunkOffsets[i] += uint64(base)
+		}
+	}
+
+	// Locate each text sample within its chunk
+	stsc, err := parseSampleToChunk(sr, stblAtom)
+	if err != nil {
+		return nil, err
+	}
+	sampleOffsets := computeSampleOffsets(chunkOffsets, sampleSizes, stsc)
+
 	// Build chapters from text samples
-	chapters := buildChaptersFromText(sr, chapterTimes, sampleSizes, chunkOffsets)
+	chapters, err := buildChaptersFromText(ctx, sr, chapterTimes, sampleSizes, sampleOffsets)
+	if err != nil {
+		return nil, err
+	}
 
 	// Calculate end times
 	calculateChapterEndTimes(chapters, fileDuration)
@@ -307,27 +372,15 @@ func parseTextTrackChapters(sr *binary.SafeReader, trakAtom *Atom, fileDuration
 }
 
 // parseTrackTimescale extracts the timescale from the mdhd atom.
+// Missing or implausible timescales fall back to defaultTimescale.
 func parseTrackTimescale(sr *binary.SafeReader, mdiaAtom *Atom) (uint32, error) {
-	mdhdAtom, err := findAtom(sr, mdiaAtom.DataOffset(), mdiaAtom.DataOffset()+int64(mdiaAtom.DataSize()), "mdhd")
-	if err != nil {
+	if _, err := findAtom(sr, mdiaAtom.DataOffset(), mdiaAtom.DataOffset()+int64(mdiaAtom.DataSize()), "mdhd"); err != nil {
 		return 0, err
 	}
 
-	mdhdOffset := mdhdAtom.DataOffset()
-	version, err := binary.Read[uint8](sr, mdhdOffset, "mdhd version")
-	if err != nil {
-		return 1000, nil
-	}
-
-	var timescale uint32
-	if version == 1 {
-		timescale, err = binary.Read[uint32](sr, mdhdOffset+20, "timescale")
-	} else {
-		timescale, err = binary.Read[uint32](sr, mdhdOffset+12, "timescale")
-	}
-
-	if err != nil || timescale == 0 {
-		return 1000, nil
+	timescale, err := readMdhdTimescale(sr, mdiaAtom)
+	if err != nil || !validTimescale(timescale) {
+		return defaultTimescale, nil
 	}
 
 	return timescale, nil
@@ -408,76 +461,309 @@ func parseSampleSizes(sr *binary.SafeReader, stblAtom *Atom) ([]uint32, error) {
 
 // parseChunkOffsets extracts chunk offsets from stco or co64 atom.
 func parseChunkOffsets(sr *binary.SafeReader, stblAtom *Atom) ([]uint64, error) {
-	stcoAtom, err := findAtom(sr, stblAtom.DataOffset(), stblAtom.DataOffset()+int64(stblAtom.DataSize()), "stco")
-	if err != nil {
-		// Try co64 for 64-bit offsets
-		stcoAtom, err = findAtom(sr, stblAtom.DataOffset(), stblAtom.DataOffset()+int64(stblAtom.DataSize()), "co64")
+	offsets, _, err := selectChunkOffsets(sr, stblAtom)
+	return offsets, err
+}
+
+// selectChunkOffsets picks the chunk offset table to trust when reading
+// chapter samples, returning a description of any inconsistency found.
+//
+// co64 is preferred over stco when both are present and either the file is
+// larger than 4GB or the stco offsets are implausible (decreasing or past
+// the end of the file), which is what wrapped 32-bit offsets look like.
+func selectChunkOffsets(sr *binary.SafeReader, stblAtom *Atom) ([]uint64, string, error) {
+	start, end := stblAtom.DataOffset(), stblAtom.DataOffset()+int64(stblAtom.DataSize())
+	stcoAtom, stcoErr := findAtom(sr, start, end, "stco")
+	co64Atom, co64Err := findAtom(sr, start, end, "co64")
+
+	if stcoErr != nil {
+		if co64Err != nil {
+			return nil, "", co64Err
+		}
+		offsets, err := readChunkOffsets(sr, co64Atom)
+		return offsets, "", err
+	}
+
+	stcoOffsets, err := readChunkOffsets(sr, stcoAtom)
+	if err != nil && co64Err != nil {
+		return nil, "", err
+	}
+
+	largeFile := sr.Size() > math.MaxUint32
+	plausible := err == nil && plausibleChunkOffsets(stcoOffsets, sr.Size())
+
+	if co64Err != nil {
+		if !plausible {
+			return stcoOffsets, "stco chunk offsets are out of order or beyond end of file; offsets may have wrapped past 4GB", nil
+		}
+		return stcoOffsets, "", nil
+	}
+
+	if largeFile || !plausible {
+		co64Offsets, err := readChunkOffsets(sr, co64Atom)
 		if err != nil {
-			return nil, err
+			return stcoOffsets, "", err
 		}
+		return co64Offsets, "chapter track has both stco and co64 chunk offsets; using co64", nil
 	}
 
-	offset := stcoAtom.DataOffset() + 4 // Skip version + flags
+	return stcoOffsets, "chapter track has both stco and co64 chunk offsets; using stco", nil
+}
+
+// readChunkOffsets reads the entries of an stco (32-bit) or co64 (64-bit)
+// table. A read error stops the walk and the entries read so far are
+// returned.
+func readChunkOffsets(sr *binary.SafeReader, tableAtom *Atom) ([]uint64, error) {
+	offset := tableAtom.DataOffset() + 4 // Skip version + flags
 	chunkCount, err := binary.Read[uint32](sr, offset, "chunk count")
 	if err != nil {
 		return nil, err
 	}
 	offset += 4
 
-	chunkOffsets := make([]uint64, chunkCount)
-	for i := range chunkCount {
+	entrySize := int64(4)
+	if tableAtom.Type == "co64" {
+		entrySize = 8
+	}
+
+	// Don't trust the count further than the table can hold
+	maxEntries := (int64(tableAtom.DataSize()) - 8) / entrySize
+	if maxEntries < 0 {
+		maxEntries = 0
+	}
+	chunkOffsets := make([]uint64, 0, min(int64(chunkCount), maxEntries))
+
+	for range chunkCount {
+		var chunkOffset uint64
 		var readErr error
-		if stcoAtom.Type == "co64" {
-			chunkOffsets[i], readErr = binary.Read[uint64](sr, offset, "chunk offset")
-			offset += 8
+		if entrySize == 8 {
+			chunkOffset, readErr = binary.Read[uint64](sr, offset, "chunk offset")
 		} else {
-			offset32, readErr := binary.Read[uint32](sr, offset, "chunk offset")
-			if readErr != nil {
-				break // Return partial results
-			}
-			chunkOffsets[i] = uint64(offset32)
-			offset += 4
+			var offset32 uint32
+			offset32, readErr = binary.Read[uint32](sr, offset, "chunk offset")
+			chunkOffset = uint64(offset32)
 		}
 		if readErr != nil {
 			break // Return partial results
 		}
+		chunkOffsets = append(chunkOffsets, chunkOffset)
+		offset += entrySize
 	}
 
 	return chunkOffsets, nil
 }
 
-// buildChaptersFromText reads text samples and builds chapter list.
-// Handles both single-chunk (all samples in one chunk) and multi-chunk layouts.
-func buildChaptersFromText(sr *binary.SafeReader, chapterTimes []time.Duration, sampleSizes []uint32, chunkOffsets []uint64) []types.Chapter {
-	chapters := make([]types.Chapter, 0, len(chapterTimes))
+// plausibleChunkOffsets reports whether offsets are non-decreasing and
+// within the file.
+func plausibleChunkOffsets(offsets []uint64, fileSize int64) bool {
+	var prev uint64
+	for _, off := range offsets {
+		if off < prev || off >= uint64(fileSize) {
+			return false
+		}
+		prev = off
+	}
+	return true
+}
+
+// checkChunkOffsets warns when the chapter track's chunk offset tables are
+// inconsistent, since wrong offsets turn chapter titles into garbage.
+func checkChunkOffsets(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, file *types.File) {
+	chapterTrackID := findChapterTrackReference(ctx, sr, moovAtom)
+	if chapterTrackID == 0 {
+		return
+	}
+
+	chapterTrak := findTrackByID(ctx, sr, moovAtom, chapterTrackID)
+	if chapterTrak == nil {
+		return
+	}
+
+	stblAtom := trakSampleTable(sr, chapterTrak)
+	if stblAtom == nil {
+		return
+	}
+
+	offsets, issue, err := selectChunkOffsets(sr, stblAtom)
+	if err != nil {
+		return
+	}
+	if issue != "" {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "chapters",
+			Message: issue,
+			Offset:  stblAtom.Offset,
+		})
+	}
 
+	sampleSizes, err := parseSampleSizes(sr, stblAtom)
+	if err != nil {
+		return
+	}
+	if base := chapterTextBase(sr, offsets, sampleSizes); base != 0 {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "chapters",
+			Message:  fmt.Sprintf("chapter chunk offsets are relative to mdat, rebased by %d bytes", base),
+			Severity: types.SeverityInfo,
+			Offset:   stblAtom.Offset,
+		})
+	}
+}
+
+// chapterTextBase returns the amount to add to chapter chunk offsets.
+//
+// Some tools that rewrite the moov (for example appending a new one at the
+// end of the file) leave chunk offsets relative to the start of the mdat
+// payload instead of the file. If the first text sample does not decode at
+// its stated offset but does decode relative to the first mdat, the mdat
+// data offset is returned; otherwise 0.
+func chapterTextBase(sr *binary.SafeReader, chunkOffsets []uint64, sampleSizes []uint32) int64 {
 	if len(chunkOffsets) == 0 || len(sampleSizes) == 0 {
-		return chapters
+		return 0
 	}
 
-	// Calculate sample offsets
-	// If there's only one chunk, all samples are sequential within it
-	// If there are multiple chunks, we need sample-to-chunk mapping (simplified: assume 1:1)
-	sampleOffsets := make([]uint64, len(sampleSizes))
+	offset, size := int64(chunkOffsets[0]), sampleSizes[0]
+	if _, ok := readChapterTitle(sr, offset, size); ok {
+		return 0
+	}
+
+	mdatAtom, err := findAtom(sr, 0, sr.Size(), "mdat")
+	if err != nil {
+		return 0
+	}
+
+	base := mdatAtom.DataOffset()
+	if _, ok := readChapterTitle(sr, offset+base, size); ok {
+		return base
+	}
+	return 0
+}
 
-	if len(chunkOffsets) == 1 {
-		// All samples are in a single chunk - calculate offsets by summing sizes
-		currentOffset := chunkOffsets[0]
-		for i := range sampleSizes {
-			sampleOffsets[i] = currentOffset
-			currentOffset += uint64(sampleSizes[i])
+// trakSampleTable returns the trak's mdia/minf/stbl atom, or nil.
+func trakSampleTable(sr *binary.SafeReader, trakAtom *Atom) *Atom {
+	atom := trakAtom
+	for _, child := range []string{"mdia", "minf", "stbl"} {
+		next, err := findAtom(sr, atom.DataOffset(), atom.DataOffset()+int64(atom.DataSize()), child)
+		if err != nil {
+			return nil
 		}
-	} else {
-		// Multiple chunks - assume 1:1 mapping (one sample per chunk)
-		// This is a simplification; proper handling would parse stsc atom
-		for i := 0; i < len(chunkOffsets) && i < len(sampleSizes); i++ {
-			sampleOffsets[i] = chunkOffsets[i]
+		atom = next
+	}
+	return atom
+}
+
+// stscEntry is one run of the sample-to-chunk table: from FirstChunk
+// (1-based) until the next entry's, every chunk holds SamplesPerChunk
+// samples.
+type stscEntry struct {
+	FirstChunk      uint32
+	SamplesPerChunk uint32
+}
+
+// stscEntrySize is first chunk (4) + samples per chunk (4) + sample
+// description index (4).
+const stscEntrySize = 12
+
+// parseSampleToChunk reads the stsc atom. Returns nil entries without an
+// error when the atom is missing, leaving computeSampleOffsets to guess the
+// layout.
+//
+// stsc structure:
+//
+//	[4 bytes] version + flags
+//	[4 bytes] entry count
+//	then per entry:
+//	[4 bytes] first chunk (1-based)
+//	[4 bytes] samples per chunk
+//	[4 bytes] sample description index
+func parseSampleToChunk(sr *binary.SafeReader, stblAtom *Atom) ([]stscEntry, error) {
+	stscAtom, err := findAtom(sr, stblAtom.DataOffset(), stblAtom.DataOffset()+int64(stblAtom.DataSize()), "stsc")
+	if err != nil {
+		return nil, nil //nolint:nilerr // Older writers omit stsc from text tracks
+	}
+
+	offset := stscAtom.DataOffset() + 4 // Skip version + flags
+	entryCount, err := binary.Read[uint32](sr, offset, "stsc entry count")
+	if err != nil {
+		return nil, err
+	}
+	offset += 4
+
+	// Don't trust the count further than the table can hold
+	maxEntries := max((int64(stscAtom.DataSize())-8)/stscEntrySize, 0)
+	entries := make([]stscEntry, 0, min(int64(entryCount), maxEntries))
+
+	for range entryCount {
+		firstChunk, err := binary.Read[uint32](sr, offset, "stsc first chunk")
+		if err != nil {
+			break // Return partial results
+		}
+		samplesPerChunk, err := binary.Read[uint32](sr, offset+4, "stsc samples per chunk")
+		if err != nil {
+			break // Return partial results
+		}
+		entries = append(entries, stscEntry{FirstChunk: firstChunk, SamplesPerChunk: samplesPerChunk})
+		offset += stscEntrySize
+	}
+
+	return entries, nil
+}
+
+// computeSampleOffsets returns the file offset of each sample: its chunk's
+// offset plus the sizes of the samples before it in that chunk. Samples
+// beyond the last chunk get no offset, so the result may be shorter than
+// sampleSizes.
+//
+// Without an stsc table, a single chunk is taken to hold every sample and
+// multiple chunks one sample each, which is how most writers lay out text
+// tracks when they omit it.
+func computeSampleOffsets(chunkOffsets []uint64, sampleSizes []uint32, stsc []stscEntry) []uint64 {
+	if len(stsc) == 0 {
+		perChunk := uint32(1)
+		if len(chunkOffsets) == 1 {
+			perChunk = uint32(len(sampleSizes))
+		}
+		stsc = []stscEntry{{FirstChunk: 1, SamplesPerChunk: perChunk}}
+	}
+
+	offsets := make([]uint64, 0, len(sampleSizes))
+	entry := 0
+	for chunk, chunkOffset := range chunkOffsets {
+		if len(offsets) == len(sampleSizes) {
+			break
+		}
+		for entry+1 < len(stsc) && uint32(chunk+1) >= stsc[entry+1].FirstChunk {
+			entry++
+		}
+
+		pos := chunkOffset
+		for range stsc[entry].SamplesPerChunk {
+			if len(offsets) == len(sampleSizes) {
+				break
+			}
+			offsets = append(offsets, pos)
+			pos += uint64(sampleSizes[len(offsets)-1])
 		}
 	}
 
+	return offsets
+}
+
+// buildChaptersFromText reads text samples and builds chapter list.
+// Chapter indices are contiguous even when invalid samples are skipped.
+// The only error is ctx.Err(), checked every ctxCheckInterval samples.
+func buildChaptersFromText(ctx context.Context, sr *binary.SafeReader, chapterTimes []time.Duration, sampleSizes []uint32, sampleOffsets []uint64) ([]types.Chapter, error) {
+	chapters := make([]types.Chapter, 0, len(chapterTimes))
+
 	maxSamples := min(len(sampleOffsets), len(sampleSizes), len(chapterTimes))
 
 	for i := range maxSamples {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		sampleSize := sampleSizes[i]
 		if sampleSize == 0 || sampleSize >= 10000 {
 			continue // Skip invalid sizes
@@ -486,34 +772,44 @@ func buildChaptersFromText(sr *binary.SafeReader, chapterTimes []time.Duration,
 		title := extractChapterTitle(sr, int64(sampleOffsets[i]), sampleSize)
 
 		chapter := types.Chapter{
-			Index:     i + 1,
+			Index:     len(chapters) + 1,
 			Title:     title,
 			StartTime: chapterTimes[i],
 		}
 		chapters = append(chapters, chapter)
 	}
 
-	return chapters
+	return chapters, nil
 }
 
 // extractChapterTitle reads and decodes a chapter title from a text sample.
 func extractChapterTitle(sr *binary.SafeReader, chunkOffset int64, sampleSize uint32) string {
-	textBuf := make([]byte, sampleSize)
-	if err := sr.ReadAt(textBuf, chunkOffset, "chapter text"); err != nil {
-		return ""
-	}
+	title, _ := readChapterTitle(sr, chunkOffset, sampleSize)
+	return title
+}
 
+// readChapterTitle reads a text sample and reports whether it looks like a
+// real one: an in-bounds, non-empty length prefix followed by UTF-8 (or
+// UTF-16 with a BOM). The title is returned even when ok is false.
+func readChapterTitle(sr *binary.SafeReader, chunkOffset int64, sampleSize uint32) (title string, ok bool) {
 	// Text samples have a 2-byte length prefix
-	if sampleSize < 2 {
-		return ""
+	if sampleSize < 2 || chunkOffset < 0 {
+		return "", false
+	}
+
+	textBuf := make([]byte, sampleSize)
+	if err := sr.ReadAt(textBuf, chunkOffset, "chapter text"); err != nil {
+		return "", false
 	}
 
 	textLen := int(textBuf[0])<<8 | int(textBuf[1])
 	if textLen <= 0 || textLen > len(textBuf)-2 {
-		return ""
+		return "", false
 	}
 
-	return string(textBuf[2 : 2+textLen])
+	text := textBuf[2 : 2+textLen]
+	valid := utf8.Valid(text) || bytes.HasPrefix(text, []byte{0xFE, 0xFF}) || bytes.HasPrefix(text, []byte{0xFF, 0xFE})
+	return string(text), valid
 }
 
 // calculateChapterEndTimes sets the EndTime for each chapter.
@@ -2,7 +2,10 @@ package m4a
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -61,10 +64,13 @@ func TestParseChapters_Success(t *testing.T) {
 	// File duration: 180 seconds
 	fileDuration := 180 * time.Second
 
-	chapters, err := parseChapters(sr, moovAtom, fileDuration)
+	chapters, source, err := parseChapters(context.Background(), sr, moovAtom, fileDuration)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if source != chapterSourceNero {
+		t.Errorf("expected source %q, got %q", chapterSourceNero, source)
+	}
 
 	if len(chapters) != 3 {
 		t.Fatalf("expected 3 chapters, got %d", len(chapters))
@@ -117,7 +123,7 @@ func TestParseChapters_EmptyTitles(t *testing.T) {
 	sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4b")
 	moovAtom, _ := readAtomHeader(sr, 0)
 
-	chapters, err := parseChapters(sr, moovAtom, 100*time.Second)
+	chapters, _, err := parseChapters(context.Background(), sr, moovAtom, 100*time.Second)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -147,7 +153,7 @@ func TestParseChapters_SingleChapter(t *testing.T) {
 	sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4b")
 	moovAtom, _ := readAtomHeader(sr, 0)
 
-	chapters, err := parseChapters(sr, moovAtom, 3600*time.Second)
+	chapters, _, err := parseChapters(context.Background(), sr, moovAtom, 3600*time.Second)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -173,7 +179,7 @@ func TestParseChapters_NoChpl(t *testing.T) {
 	sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4b")
 	moovAtom, _ := readAtomHeader(sr, 0)
 
-	chapters, err := parseChapters(sr, moovAtom, 100*time.Second)
+	chapters, _, err := parseChapters(context.Background(), sr, moovAtom, 100*time.Second)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -191,7 +197,7 @@ func TestParseChapters_NoUdta(t *testing.T) {
 	sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4b")
 	moovAtom, _ := readAtomHeader(sr, 0)
 
-	chapters, err := parseChapters(sr, moovAtom, 100*time.Second)
+	chapters, _, err := parseChapters(context.Background(), sr, moovAtom, 100*time.Second)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -201,3 +207,269 @@ func TestParseChapters_NoUdta(t *testing.T) {
 		t.Errorf("expected 0 chapters, got %d", len(chapters))
 	}
 }
+
+// createChunkOffsetAtom creates an stco (32-bit) or co64 (64-bit) atom.
+func createChunkOffsetAtom(atomType string, offsets ...uint64) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(buf, binary.BigEndian, uint32(len(offsets)))
+	for _, off := range offsets {
+		if atomType == "co64" {
+			binary.Write(buf, binary.BigEndian, off)
+		} else {
+			binary.Write(buf, binary.BigEndian, uint32(off))
+		}
+	}
+	return createMockAtom(atomType, buf.Bytes())
+}
+
+func TestSelectChunkOffsets(t *testing.T) {
+	tests := []struct {
+		name      string
+		tables    [][]byte
+		want      []uint64
+		wantIssue bool
+	}{
+		{
+			name:   "stco only",
+			tables: [][]byte{createChunkOffsetAtom("stco", 10, 20)},
+			want:   []uint64{10, 20},
+		},
+		{
+			name:   "co64 only",
+			tables: [][]byte{createChunkOffsetAtom("co64", 10, 20)},
+			want:   []uint64{10, 20},
+		},
+		{
+			name: "both present, stco plausible",
+			tables: [][]byte{
+				createChunkOffsetAtom("stco", 10, 20),
+				createChunkOffsetAtom("co64", 30, 40),
+			},
+			want:      []uint64{10, 20},
+			wantIssue: true,
+		},
+		{
+			name: "both present, stco wrapped",
+			tables: [][]byte{
+				createChunkOffsetAtom("stco", 40, 10),
+				createChunkOffsetAtom("co64", 10, 40),
+			},
+			want:      []uint64{10, 40},
+			wantIssue: true,
+		},
+		{
+			name:      "stco beyond end of file",
+			tables:    [][]byte{createChunkOffsetAtom("stco", 10, 1<<20)},
+			want:      []uint64{10, 1 << 20},
+			wantIssue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stbl := createMockAtom("stbl", bytes.Join(tt.tables, nil))
+			sr := audiobinary.NewSafeReader(bytes.NewReader(stbl), int64(len(stbl)), "test.m4b")
+			stblAtom, _ := readAtomHeader(sr, 0)
+
+			offsets, issue, err := selectChunkOffsets(sr, stblAtom)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(offsets) != len(tt.want) {
+				t.Fatalf("expected offsets %v, got %v", tt.want, offsets)
+			}
+			for i := range offsets {
+				if offsets[i] != tt.want[i] {
+					t.Errorf("offset %d: expected %d, got %d", i, tt.want[i], offsets[i])
+				}
+			}
+
+			if got := issue != ""; got != tt.wantIssue {
+				t.Errorf("expected issue=%v, got %q", tt.wantIssue, issue)
+			}
+		})
+	}
+}
+
+func TestReadChunkOffsets_Truncated(t *testing.T) {
+	// stco claims 3 entries but only holds 1; the 32-bit read error must
+	// stop the walk rather than leave zero offsets behind
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(buf, binary.BigEndian, uint32(3)) // entry count
+	binary.Write(buf, binary.BigEndian, uint32(10))
+	stco := createMockAtom("stco", buf.Bytes())
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(stco), int64(len(stco)), "test.m4b")
+	stcoAtom, _ := readAtomHeader(sr, 0)
+
+	offsets, err := readChunkOffsets(sr, stcoAtom)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(offsets) != 1 || offsets[0] != 10 {
+		t.Errorf("expected [10], got %v", offsets)
+	}
+}
+
+func TestChapterTextBase(t *testing.T) {
+	sample := append([]byte{0x00, 0x05}, "Intro"...)
+
+	// free (24 bytes) + mdat; mdat payload starts at offset 32
+	data := createMockAtom("free", make([]byte, 16))
+	data = append(data, createMockAtom("mdat", sample)...)
+	const mdatData = 32
+
+	tests := []struct {
+		name   string
+		offset uint64
+		want   int64
+	}{
+		{"file-relative offset", mdatData, 0},
+		{"mdat-relative offset", 0, mdatData},
+		{"unrecoverable offset", 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := audiobinary.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.m4b")
+
+			base := chapterTextBase(sr, []uint64{tt.offset}, []uint32{uint32(len(sample))})
+			if base != tt.want {
+				t.Fatalf("expected base %d, got %d", tt.want, base)
+			}
+			if tt.want == 0 && tt.offset != mdatData {
+				return
+			}
+
+			if title := extractChapterTitle(sr, int64(tt.offset)+base, uint32(len(sample))); title != "Intro" {
+				t.Errorf("expected title %q after rebasing, got %q", "Intro", title)
+			}
+		})
+	}
+}
+
+// createStscAtom creates an stsc atom from first chunk, samples per chunk
+// pairs.
+func createStscAtom(runs ...[2]uint32) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(buf, binary.BigEndian, uint32(len(runs)))
+	for _, run := range runs {
+		binary.Write(buf, binary.BigEndian, run[0])
+		binary.Write(buf, binary.BigEndian, run[1])
+		binary.Write(buf, binary.BigEndian, uint32(1)) // sample description index
+	}
+	return createMockAtom("stsc", buf.Bytes())
+}
+
+func TestComputeSampleOffsets(t *testing.T) {
+	tests := []struct {
+		name         string
+		chunkOffsets []uint64
+		sampleSizes  []uint32
+		stsc         []stscEntry
+		want         []uint64
+	}{
+		{
+			name:         "no stsc, one chunk",
+			chunkOffsets: []uint64{100},
+			sampleSizes:  []uint32{10, 20, 30},
+			want:         []uint64{100, 110, 130},
+		},
+		{
+			name:         "no stsc, one sample per chunk",
+			chunkOffsets: []uint64{100, 200, 300},
+			sampleSizes:  []uint32{10, 20, 30},
+			want:         []uint64{100, 200, 300},
+		},
+		{
+			name:         "three then two samples per chunk",
+			chunkOffsets: []uint64{100, 200},
+			sampleSizes:  []uint32{10, 20, 30, 5, 5},
+			stsc:         []stscEntry{{1, 3}, {2, 2}},
+			want:         []uint64{100, 110, 130, 200, 205},
+		},
+		{
+			name:         "run covers several chunks",
+			chunkOffsets: []uint64{100, 200, 300},
+			sampleSizes:  []uint32{10, 10, 10, 10, 10},
+			stsc:         []stscEntry{{1, 2}, {3, 1}},
+			want:         []uint64{100, 110, 200, 210, 300},
+		},
+		{
+			name:         "more samples than chunks hold",
+			chunkOffsets: []uint64{100},
+			sampleSizes:  []uint32{10, 10, 10},
+			stsc:         []stscEntry{{1, 2}},
+			want:         []uint64{100, 110},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeSampleOffsets(tt.chunkOffsets, tt.sampleSizes, tt.stsc)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("computeSampleOffsets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildChaptersFromText_PackedChunks(t *testing.T) {
+	// m4b-tool layout: five titles in two chunks, three then two
+	titles := []string{"One", "Two", "Three", "Four", "Five"}
+	var data []byte
+	var sizes []uint32
+	var chunkOffsets []uint64
+	for i, title := range titles {
+		if i == 0 || i == 3 {
+			data = append(data, "pad!"...) // audio between the chunks
+			chunkOffsets = append(chunkOffsets, uint64(len(data)))
+		}
+		sample := append([]byte{0, byte(len(title))}, title...)
+		data = append(data, sample...)
+		sizes = append(sizes, uint32(len(sample)))
+	}
+
+	stbl := createMockAtom("stbl", createStscAtom([2]uint32{1, 3}, [2]uint32{2, 2}))
+	stblOffset := int64(len(data))
+	data = append(data, stbl...)
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.m4b")
+	stblAtom, _ := readAtomHeader(sr, stblOffset)
+	stsc, err := parseSampleToChunk(sr, stblAtom)
+	if err != nil {
+		t.Fatalf("parseSampleToChunk: %v", err)
+	}
+
+	times := []time.Duration{0, 10 * time.Second, 20 * time.Second, 30 * time.Second, 40 * time.Second}
+	chapters, err := buildChaptersFromText(context.Background(), sr, times, sizes, computeSampleOffsets(chunkOffsets, sizes, stsc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chapters) != len(titles) {
+		t.Fatalf("got %d chapters, want %d", len(chapters), len(titles))
+	}
+	for i, ch := range chapters {
+		if ch.Index != i+1 || ch.Title != titles[i] || ch.StartTime != times[i] {
+			t.Errorf("chapter %d = %d %q at %v, want %d %q at %v", i, ch.Index, ch.Title, ch.StartTime, i+1, titles[i], times[i])
+		}
+	}
+}
+
+func TestBuildChaptersFromText_Canceled(t *testing.T) {
+	data := []byte{0, 5, 'T', 'i', 't', 'l', 'e'}
+	sr := audiobinary.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.m4b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := buildChaptersFromText(ctx, sr, []time.Duration{0}, []uint32{7}, []uint64{0})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("buildChaptersFromText() error = %v, want context.Canceled", err)
+	}
+}
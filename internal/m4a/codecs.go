@@ -70,6 +70,66 @@ func parseCodecDetails(sr *binary.SafeReader, sampleEntryOffset int64, codec str
 	return nil
 }
 
+// alacSampleEntrySize is the size of the audio sample entry fields that
+// precede child boxes (header, reserved, data reference, sound description).
+const alacSampleEntrySize = 36
+
+// alacConfigSize is the size of ALACSpecificConfig inside the alac box.
+const alacConfigSize = 24
+
+// parseALACConfig reads the ALAC magic cookie (the alac box nested inside the
+// alac sample entry) for the encoder's real bit depth, channels, and rate.
+//
+// ALACSpecificConfig layout (after 4 bytes version + flags):
+// [4 bytes] frame length
+// [1 byte]  compatible version
+// [1 byte]  bit depth
+// [1 byte]  pb, [1 byte] mb, [1 byte] kb (Rice parameters)
+// [1 byte]  number of channels
+// [2 bytes] max run
+// [4 bytes] max frame bytes
+// [4 bytes] average bit rate
+// [4 bytes] sample rate
+func parseALACConfig(sr *binary.SafeReader, sampleEntryOffset, sampleEntrySize int64, file *types.File) error {
+	file.Audio.Lossless = true
+
+	start := sampleEntryOffset + alacSampleEntrySize
+	end := sampleEntryOffset + sampleEntrySize
+	if end <= start {
+		return nil
+	}
+
+	cookie, err := findAtom(sr, start, end, "alac")
+	if err != nil {
+		return err
+	}
+	if cookie.DataSize() < 4+alacConfigSize {
+		return nil
+	}
+
+	config := make([]byte, alacConfigSize)
+	if err := sr.ReadAt(config, cookie.DataOffset()+4, "alac config"); err != nil {
+		return err
+	}
+
+	if bitDepth := int(config[5]); bitDepth > 0 {
+		file.Audio.BitDepth = bitDepth
+	}
+	if channels := int(config[9]); channels > 0 {
+		file.Audio.Channels = channels
+	}
+
+	sampleRate, err := binary.Read[uint32](sr, cookie.DataOffset()+4+20, "alac sample rate")
+	if err != nil {
+		return err
+	}
+	if sampleRate > 0 {
+		file.Audio.SampleRate = int(sampleRate)
+	}
+
+	return nil
+}
+
 // parseAACProfile attempts to extract AAC profile from ESDS atom.
 func parseAACProfile(sr *binary.SafeReader, sampleEntryOffset int64) (string, error) {
 	// Search for "esds" within sample entry
@@ -1,7 +1,12 @@
 package m4a
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
+
+	audiobinary "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
 )
 
 func TestMapCodecName(t *testing.T) {
@@ -51,3 +56,151 @@ func TestAACProfiles(t *testing.T) {
 		}
 	}
 }
+
+// createALACStsd creates an stsd atom holding one alac sample entry whose
+// generic fields disagree with the nested magic cookie.
+func createALACStsd(bitDepth, channels uint8, sampleRate uint32) []byte {
+	cookie := &bytes.Buffer{}
+	binary.Write(cookie, binary.BigEndian, uint32(0))    // version + flags
+	binary.Write(cookie, binary.BigEndian, uint32(4096)) // frame length
+	cookie.WriteByte(0)                                  // compatible version
+	cookie.WriteByte(bitDepth)
+	cookie.Write([]byte{40, 10, 14}) // pb, mb, kb
+	cookie.WriteByte(channels)
+	binary.Write(cookie, binary.BigEndian, uint16(255))     // max run
+	binary.Write(cookie, binary.BigEndian, uint32(0))       // max frame bytes
+	binary.Write(cookie, binary.BigEndian, uint32(4608000)) // average bit rate
+	binary.Write(cookie, binary.BigEndian, sampleRate)
+
+	entry := &bytes.Buffer{}
+	entry.Write(make([]byte, 6))                      // reserved
+	binary.Write(entry, binary.BigEndian, uint16(1))  // data reference index
+	binary.Write(entry, binary.BigEndian, uint16(0))  // version
+	binary.Write(entry, binary.BigEndian, uint16(0))  // revision
+	binary.Write(entry, binary.BigEndian, uint32(0))  // vendor
+	binary.Write(entry, binary.BigEndian, uint16(2))  // channels
+	binary.Write(entry, binary.BigEndian, uint16(16)) // sample size
+	binary.Write(entry, binary.BigEndian, uint16(0))  // compression ID
+	binary.Write(entry, binary.BigEndian, uint16(0))  // packet size
+	binary.Write(entry, binary.BigEndian, uint32(0))  // sample rate (96kHz overflows 16.16)
+	entry.Write(createMockAtom("alac", cookie.Bytes()))
+
+	stsd := &bytes.Buffer{}
+	binary.Write(stsd, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(stsd, binary.BigEndian, uint32(1)) // entry count
+	stsd.Write(createMockAtom("alac", entry.Bytes()))
+
+	return createMockAtom("stsd", stsd.Bytes())
+}
+
+func TestParseStsd_ALACMagicCookie(t *testing.T) {
+	tests := []struct {
+		name        string
+		bitDepth    uint8
+		channels    uint8
+		sampleRate  uint32
+		wantHighRes bool
+	}{
+		{"24-bit 96kHz", 24, 2, 96000, true},
+		{"16-bit 44.1kHz", 16, 2, 44100, false},
+		{"24-bit 192kHz mono", 24, 1, 192000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := createALACStsd(tt.bitDepth, tt.channels, tt.sampleRate)
+			sr := audiobinary.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.m4a")
+			stsdAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := parseStsd(sr, stsdAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if file.Audio.BitDepth != int(tt.bitDepth) {
+				t.Errorf("BitDepth = %d, want %d", file.Audio.BitDepth, tt.bitDepth)
+			}
+			if file.Audio.SampleRate != int(tt.sampleRate) {
+				t.Errorf("SampleRate = %d, want %d", file.Audio.SampleRate, tt.sampleRate)
+			}
+			if file.Audio.Channels != int(tt.channels) {
+				t.Errorf("Channels = %d, want %d", file.Audio.Channels, tt.channels)
+			}
+			if !file.Audio.Lossless {
+				t.Error("expected Lossless to be true")
+			}
+			if got := file.Audio.IsHighRes(); got != tt.wantHighRes {
+				t.Errorf("IsHighRes() = %v, want %v", got, tt.wantHighRes)
+			}
+		})
+	}
+}
+
+// createChanStsd creates an stsd atom holding one alac sample entry that
+// claims stereo, followed by a chan atom with the given layout fields and
+// channel labels.
+func createChanStsd(layoutTag, bitmap uint32, labels ...uint32) []byte {
+	chanData := &bytes.Buffer{}
+	binary.Write(chanData, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(chanData, binary.BigEndian, layoutTag)
+	binary.Write(chanData, binary.BigEndian, bitmap)
+	binary.Write(chanData, binary.BigEndian, uint32(len(labels)))
+	for _, label := range labels {
+		binary.Write(chanData, binary.BigEndian, label)
+		chanData.Write(make([]byte, 16)) // flags + coordinates
+	}
+
+	entry := &bytes.Buffer{}
+	entry.Write(make([]byte, 6))                             // reserved
+	binary.Write(entry, binary.BigEndian, uint16(1))         // data reference index
+	binary.Write(entry, binary.BigEndian, uint16(0))         // version
+	binary.Write(entry, binary.BigEndian, uint16(0))         // revision
+	binary.Write(entry, binary.BigEndian, uint32(0))         // vendor
+	binary.Write(entry, binary.BigEndian, uint16(2))         // channels (wrong for surround)
+	binary.Write(entry, binary.BigEndian, uint16(16))        // sample size
+	binary.Write(entry, binary.BigEndian, uint16(0))         // compression ID
+	binary.Write(entry, binary.BigEndian, uint16(0))         // packet size
+	binary.Write(entry, binary.BigEndian, uint32(48000<<16)) // sample rate
+	entry.Write(createMockAtom("chan", chanData.Bytes()))
+
+	stsd := &bytes.Buffer{}
+	binary.Write(stsd, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(stsd, binary.BigEndian, uint32(1)) // entry count
+	stsd.Write(createMockAtom("alac", entry.Bytes()))
+
+	return createMockAtom("stsd", stsd.Bytes())
+}
+
+func TestParseStsd_ChannelLayout(t *testing.T) {
+	tests := []struct {
+		name         string
+		stsd         []byte
+		wantChannels int
+		wantLayout   string
+	}{
+		{"layout tag MPEG 5.1", createChanStsd(124<<16|6, 0), 6, "5.1"},
+		{"layout tag 7.1", createChanStsd(127<<16|8, 0), 8, "7.1"},
+		{"channel bitmap 5.1", createChanStsd(1<<16, 0x3F), 6, "5.1"},
+		{"channel descriptions 5.1", createChanStsd(0, 0, 1, 2, 3, channelLabelLFE, 5, 6), 6, "5.1"},
+		{"unknown layout tag", createChanStsd(200<<16|3, 0), 3, "3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := audiobinary.NewSafeReader(bytes.NewReader(tt.stsd), int64(len(tt.stsd)), "test.m4a")
+			stsdAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := parseStsd(sr, stsdAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if file.Audio.Channels != tt.wantChannels {
+				t.Errorf("Channels = %d, want %d", file.Audio.Channels, tt.wantChannels)
+			}
+			if file.Audio.ChannelLayout != tt.wantLayout {
+				t.Errorf("ChannelLayout = %q, want %q", file.Audio.ChannelLayout, tt.wantLayout)
+			}
+		})
+	}
+}
@@ -1,15 +1,26 @@
 package m4a
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/parsing"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
+// Well-known data atom type codes (the low 24 bits of the data atom's
+// version/flags word) that decodeDataValue distinguishes.
+const (
+	dataTypeImplicit = 0x00 // Binary; the tag defines the meaning
+	dataTypeSigned   = 0x15 // Big-endian signed integer, 1-8 bytes
+	dataTypeUnsigned = 0x16 // Big-endian unsigned integer, 1-8 bytes
+)
+
 // parseMetadataTag extracts the string value from an iTunes metadata tag atom.
+// Integer values are formatted in decimal; see decodeDataValue.
 func parseMetadataTag(sr *binary.SafeReader, tagAtom *Atom) (string, error) {
 	// Tag atoms contain a "data" atom with the actual value
 	// Format: tag atom → data atom → version/flags → value
@@ -25,6 +36,18 @@ func parseMetadataTag(sr *binary.SafeReader, tagAtom *Atom) (string, error) {
 		return "", nil
 	}
 
+	return readDataValue(sr, dataAtom)
+}
+
+// readDataValue reads the value of a data atom as a string.
+//
+// data atom structure:
+//
+//	[1 byte]  version
+//	[3 bytes] type code (1 = UTF-8, 0x15 = signed integer, ...)
+//	[4 bytes] locale
+//	[n bytes] value
+func readDataValue(sr *binary.SafeReader, dataAtom *Atom) (string, error) {
 	// Skip version (1 byte) + flags (3 bytes) + reserved (4 bytes) = 8 bytes
 	valueOffset := dataAtom.DataOffset() + 8
 	valueSize := int64(dataAtom.DataSize()) - 8
@@ -33,26 +56,142 @@ func parseMetadataTag(sr *binary.SafeReader, tagAtom *Atom) (string, error) {
 		return "", nil
 	}
 
-	// Read the string value
+	versionFlags, err := binary.Read[uint32](sr, dataAtom.DataOffset(), "data type")
+	if err != nil {
+		return "", err
+	}
+
 	buf := make([]byte, valueSize)
 	if err := sr.ReadAt(buf, valueOffset, "metadata value"); err != nil {
 		return "", err
 	}
 
-	// Trim null bytes and whitespace
-	value := string(buf)
-	value = strings.TrimRight(value, "\x00")
-	value = strings.TrimSpace(value)
+	return decodeDataValue(versionFlags&0xFFFFFF, buf), nil
+}
+
+// decodeDataValue formats a data atom value. Integer types are decoded as
+// big-endian and formatted in decimal, so a movement index of 3 reads "3"
+// rather than "\x03". Implicit (binary) values are read the same way when
+// they are integer-sized and not printable text, which is how some writers
+// store ©mvi and mvin. Anything else is text, trimmed of NUL padding and
+// whitespace.
+func decodeDataValue(typeCode uint32, buf []byte) string {
+	integerSized := len(buf) == 1 || len(buf) == 2 || len(buf) == 4 || len(buf) == 8
+	switch {
+	case integerSized && typeCode == dataTypeSigned:
+		return strconv.FormatInt(decodeSigned(buf), 10)
+	case integerSized && typeCode == dataTypeUnsigned:
+		return strconv.FormatUint(decodeUnsigned(buf), 10)
+	case integerSized && typeCode == dataTypeImplicit && !isPrintable(buf):
+		return strconv.FormatUint(decodeUnsigned(buf), 10)
+	}
+
+	value := strings.TrimRight(string(buf), "\x00")
+	return strings.TrimSpace(value)
+}
+
+// decodeUnsigned decodes a big-endian unsigned integer of up to 8 bytes.
+func decodeUnsigned(buf []byte) uint64 {
+	var v uint64
+	for _, b := range buf {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+// decodeSigned decodes a big-endian two's complement integer of 1, 2, 4
+// or 8 bytes.
+func decodeSigned(buf []byte) int64 {
+	shift := 64 - 8*len(buf)
+	return int64(decodeUnsigned(buf)<<shift) >> shift
+}
 
-	return value, nil
+// isPrintable reports whether buf is text: no control characters other
+// than trailing NUL padding.
+func isPrintable(buf []byte) bool {
+	text := strings.TrimRight(string(buf), "\x00")
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < 0x20 || r == 0x7F {
+			return false
+		}
+	}
+	return true
+}
+
+// parseIntegerTag extracts the value of an integer metadata tag atom (tmpo, cpil, stik, ...).
+//
+// iTunes stores integer tags as 1, 2, 4 or 8 byte big-endian values.
+// Returns an error if the data atom is missing or has an unsupported width.
+func parseIntegerTag(sr *binary.SafeReader, tagAtom *Atom) (int64, error) {
+	dataAtom, err := findAtom(sr, tagAtom.DataOffset(), tagAtom.DataOffset()+int64(tagAtom.DataSize()), "data")
+	if err != nil {
+		return 0, err
+	}
+
+	// Skip version (1 byte) + flags (3 bytes) + reserved (4 bytes) = 8 bytes
+	valueOffset := dataAtom.DataOffset() + 8
+	valueSize := int64(dataAtom.DataSize()) - 8
+
+	switch valueSize {
+	case 1:
+		v, err := binary.Read[uint8](sr, valueOffset, "integer tag value")
+		return int64(v), err
+	case 2:
+		v, err := binary.Read[uint16](sr, valueOffset, "integer tag value")
+		return int64(v), err
+	case 4:
+		v, err := binary.Read[uint32](sr, valueOffset, "integer tag value")
+		return int64(v), err
+	case 8:
+		v, err := binary.Read[uint64](sr, valueOffset, "integer tag value")
+		return int64(v), err
+	default:
+		return 0, fmt.Errorf("unsupported integer tag size %d for %s", valueSize, tagAtom.Type)
+	}
+}
+
+// mediaTypeFromStik maps a stik atom value to a MediaType. 0 is the
+// movie kind of older iTunes versions.
+func mediaTypeFromStik(kind int64) types.MediaType {
+	switch kind {
+	case 1:
+		return types.MediaTypeMusic
+	case 2:
+		return types.MediaTypeAudiobook
+	case 6:
+		return types.MediaTypeMusicVideo
+	case 0, 9:
+		return types.MediaTypeMovie
+	case 10:
+		return types.MediaTypeTVShow
+	case 11:
+		return types.MediaTypeBooklet
+	case 14:
+		return types.MediaTypeRingtone
+	case 21:
+		return types.MediaTypePodcast
+	case 23:
+		return types.MediaTypeITunesU
+	default:
+		return types.MediaTypeOther
+	}
 }
 
 // extractIlstMetadata parses all metadata items from the ilst atom.
-func extractIlstMetadata(sr *binary.SafeReader, ilstAtom *Atom, file *types.File) error {
+func extractIlstMetadata(ctx context.Context, sr *binary.SafeReader, ilstAtom *Atom, file *types.File) error {
 	offset := ilstAtom.DataOffset()
 	end := offset + int64(ilstAtom.DataSize())
 
-	for offset < end {
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
 		// Read tag atom
 		tagAtom, err := readAtomHeader(sr, offset)
 		if err != nil {
@@ -60,14 +199,48 @@ func extractIlstMetadata(sr *binary.SafeReader, ilstAtom *Atom, file *types.File
 		}
 
 		// Handle special binary tags
-		if tagAtom.Type == "trkn" {
+		switch tagAtom.Type {
+		case "trkn":
 			// Track number requires special binary parsing
 			trackData, err := parseTrackNumber(sr, tagAtom)
 			if err == nil {
 				file.Tags.TrackNumber = trackData.Number
 				file.Tags.TrackTotal = trackData.Total
 			}
-		} else {
+		case "disk":
+			// Disc number has the same layout as trkn
+			discData, err := parseTrackNumber(sr, tagAtom)
+			if err == nil {
+				file.Tags.DiscNumber = discData.Number
+				file.Tags.DiscTotal = discData.Total
+			}
+		case "covr":
+			// Cover art is loaded lazily via ExtractArtwork(); only record presence
+			file.HasEmbeddedArtwork = true
+		case "tmpo":
+			// BPM is stored as a big-endian integer
+			if bpm, err := parseIntegerTag(sr, tagAtom); err == nil && bpm > 0 {
+				file.Tags.BPM = int(bpm)
+			}
+		case "stik":
+			// Media kind, also kept raw for values with no MediaType
+			if kind, err := parseIntegerTag(sr, tagAtom); err == nil {
+				file.Audio.MediaType = mediaTypeFromStik(kind)
+				file.Tags.Set(tagAtom.Type, strconv.FormatInt(kind, 10))
+			}
+		case "gnre":
+			// Standard genre, the ID3v1 index plus one, kept raw as its name
+			if n, err := parseIntegerTag(sr, tagAtom); err == nil {
+				if genre, ok := parsing.ID3v1Genre(int(n) - 1); ok {
+					file.Tags.Set(tagAtom.Type, genre)
+				}
+			}
+		case "cpil", "pgap", "hdvd", "shwm":
+			// Boolean flags are a single integer byte, not text
+			if v, err := parseIntegerTag(sr, tagAtom); err == nil {
+				mapBooleanTag(tagAtom.Type, v != 0, file)
+			}
+		default:
 			// Parse as text tag
 			value, err := parseMetadataTag(sr, tagAtom)
 			if err != nil {
@@ -86,9 +259,52 @@ func extractIlstMetadata(sr *binary.SafeReader, ilstAtom *Atom, file *types.File
 		offset += int64(tagAtom.Size)
 	}
 
+	applyPodcastFallbacks(file)
+
 	return nil
 }
 
+// applyPodcastFallbacks fills Description and Genres from the podcast atoms
+// once the whole ilst has been read, so the music atoms (©des, ©gen) win
+// regardless of where they appear. A gnre genre, from older iTunes rips,
+// comes before the podcast category.
+func applyPodcastFallbacks(file *types.File) {
+	if file.Tags.Description == "" {
+		if ldes := file.Tags.GetFirst("ldes"); ldes != "" {
+			file.Tags.Description = ldes
+		} else {
+			file.Tags.Description = file.Tags.GetFirst("desc")
+		}
+	}
+
+	if len(file.Tags.Genres) == 0 {
+		if genre := file.Tags.GetBest("gnre", "catg"); genre != "" {
+			file.Tags.Genres = []string{genre}
+		}
+	}
+}
+
+// applyArtistList fills Tags.Artists by splitting ©ART on separators.
+// Artist keeps the unsplit display string. aART names the album artist,
+// not the track's, so without ©ART Artists stays empty.
+func applyArtistList(file *types.File, separators []string) {
+	if len(file.Tags.Artists) > 0 || file.Tags.Artist == "" {
+		return
+	}
+	file.Tags.Artists = parsing.SplitArtists(file.Tags.Artist, separators)
+}
+
+// splitKeywords splits a keyw value into its comma-separated keywords.
+func splitKeywords(value string) []string {
+	var keywords []string
+	for keyword := range strings.SplitSeq(value, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
 // Note: In MP4, © is represented as byte 0xA9, so "©nam" is "\xA9nam" in Go strings.
 func mapTagToField(tag string, value string, file *types.File) {
 	switch tag {
@@ -98,16 +314,23 @@ func mapTagToField(tag string, value string, file *types.File) {
 		file.Tags.Artist = value
 	case "\xA9alb": // Album (©alb)
 		file.Tags.Album = value
+	case "aART": // Album artist
+		file.Tags.AlbumArtist = value
 	case "\xA9gen": // Genre (©gen)
 		file.Tags.Genres = append(file.Tags.Genres, value)
 	case "\xA9cmt": // Comment (©cmt)
 		file.Tags.Comment = value
+		file.Tags.Comments = append(file.Tags.Comments, types.Comment{Text: value})
+	case "\xA9lyr": // Lyrics (©lyr), sometimes LRC with timestamps
+		setLyrics(value, file)
 	case "\xA9wrt": // Composer (©wrt)
 		file.Tags.Composers = append(file.Tags.Composers, value)
 	case "\xA9day": // Year (©day)
 		if year, err := strconv.Atoi(value); err == nil {
 			file.Tags.Year = year
 		}
+	case "\xA9nrt": // Narrator (©nrt), written by some audiobook taggers
+		file.Tags.Narrator = value
 	case "\xA9grp": // Grouping (©grp) - often contains series info for audiobooks
 		file.Tags.Grouping = value
 	case "\xA9des": // Description (©des) - long description separate from comment
@@ -120,7 +343,56 @@ func mapTagToField(tag string, value string, file *types.File) {
 		if file.Tags.SeriesPart == "" {
 			file.Tags.SeriesPart = value
 		}
+	case "\xA9st3": // Disc subtitle (©st3)
+		// MP4 has no standard disc subtitle atom; taggers store the ID3
+		// TSST value here, so it is the disc's subtitle, not the TIT3 one
+		file.Tags.DiscSubtitle = value
+	case "soal": // Sort album
+		file.Tags.SortAlbum = value
+	case "desc": // Podcast short description - resolved after the ilst walk
+		file.Tags.Set(tag, value)
+		file.Tags.Comments = append(file.Tags.Comments, types.Comment{Description: "description", Text: value})
+	case "ldes": // Podcast long description
+		file.Tags.Set(tag, value)
+		file.Tags.Comments = append(file.Tags.Comments, types.Comment{Description: "long description", Text: value})
+	case "catg": // Podcast category
+		file.Tags.Set(tag, value)
+	case "keyw": // Podcast keywords, comma-separated
+		file.Tags.Keywords = append(file.Tags.Keywords, splitKeywords(value)...)
+	case "purd": // Purchase date (iTunes Store)
+		file.Tags.PurchaseDate = value
+		file.Tags.Set(tag, value)
+	case "apID": // Purchasing account (iTunes Store) - raw only
+		file.Tags.Set(tag, value)
+	}
+}
+
+// setLyrics stores lyrics text and, when it is LRC, the synced lines too.
+// A plain ©lyr never clears synced lyrics read from a custom atom.
+func setLyrics(value string, file *types.File) {
+	file.Tags.Lyrics = value
+	if synced := types.ParseLRC(value); synced != nil {
+		file.Tags.SyncedLyrics = synced
+	}
+}
+
+// mapBooleanTag maps iTunes boolean atoms to metadata fields.
+// hdvd (HD video) has no standard field and is kept raw only.
+func mapBooleanTag(tag string, value bool, file *types.File) {
+	switch tag {
+	case "cpil": // Compilation
+		file.Tags.Compilation = value
+	case "pgap": // Gapless playback
+		file.Tags.Gapless = value
+	case "shwm": // Show work and movement
+		file.Tags.ShowMovement = value
+	}
+
+	raw := "0"
+	if value {
+		raw = "1"
 	}
+	file.Tags.Set(tag, raw)
 }
 
 // TrackData holds track number information.
@@ -129,7 +401,8 @@ type TrackData struct {
 	Total  int
 }
 
-// parseTrackNumber extracts track number and total from trkn atom.
+// parseTrackNumber extracts track number and total from trkn atom, or
+// disc number and total from disk atom.
 func parseTrackNumber(sr *binary.SafeReader, atom *Atom) (TrackData, error) {
 	result := TrackData{}
 
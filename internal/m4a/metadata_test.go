@@ -2,8 +2,12 @@ package m4a
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	audiobinary "github.com/simonhull/audiometa/internal/binary"
 	"github.com/simonhull/audiometa/internal/types"
@@ -113,7 +117,7 @@ func TestExtractIlstMetadata(t *testing.T) {
 	ilstAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := extractIlstMetadata(sr, ilstAtom, file)
+	err := extractIlstMetadata(context.Background(), sr, ilstAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -148,6 +152,19 @@ func TestMapTagToField(t *testing.T) {
 			return ""
 		}, "Genre"}, // ©gen
 		{"\xA9cmt", "Comment", func(f *types.File) string { return f.Tags.Comment }, "Comment"}, // ©cmt
+		{"purd", "2021-03-04 10:20:30", func(f *types.File) string { return f.Tags.PurchaseDate }, "2021-03-04 10:20:30"},
+		{"purd", "2021-03-04 10:20:30", func(f *types.File) string { return f.Tags.GetFirst("purd") }, "2021-03-04 10:20:30"},
+		{"apID", "buyer@example.com", func(f *types.File) string { return f.Tags.GetFirst("apID") }, "buyer@example.com"},
+		{"\xA9st3", "Disc Two", func(f *types.File) string { return f.Tags.DiscSubtitle }, "Disc Two"},         // ©st3
+		{"\xA9lyr", "[00:01.00]Hello", func(f *types.File) string { return f.Tags.Lyrics }, "[00:01.00]Hello"}, // ©lyr
+		{"\xA9lyr", "[00:01.00]Hello", func(f *types.File) string {
+			if len(f.Tags.SyncedLyrics) == 1 && f.Tags.SyncedLyrics[0].Time == time.Second {
+				return f.Tags.SyncedLyrics[0].Text
+			}
+			return ""
+		}, "Hello"},
+		{"soal", "White Album, The", func(f *types.File) string { return f.Tags.SortAlbum }, "White Album, The"},
+		{"\xA9st3", "Disc Two", func(f *types.File) string { return f.Tags.Subtitle }, ""}, // ©st3 is not the TIT3 subtitle
 	}
 
 	for _, tt := range tests {
@@ -257,3 +274,331 @@ func TestParseTrackNumber_NoDataAtom(t *testing.T) {
 		t.Error("expected error for missing data atom, got nil")
 	}
 }
+
+// createIntegerItem creates a metadata item whose data atom holds a big-endian integer.
+func createIntegerItem(itemType string, value []byte) []byte {
+	buf := &bytes.Buffer{}
+
+	dataSize := uint32(8 + 8 + len(value))
+	binary.Write(buf, binary.BigEndian, uint32(8)+dataSize)
+	buf.WriteString(itemType)
+
+	binary.Write(buf, binary.BigEndian, dataSize)
+	buf.WriteString("data")
+	binary.Write(buf, binary.BigEndian, uint32(21)) // version=0, flags=21 (signed integer)
+	binary.Write(buf, binary.BigEndian, uint32(0))  // reserved
+	buf.Write(value)
+
+	return buf.Bytes()
+}
+
+func TestExtractIlstMetadata_Tempo(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		{"16-bit", []byte{0x00, 0x80}},
+		{"8-bit", []byte{0x80}},
+		{"32-bit", []byte{0x00, 0x00, 0x00, 0x80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ilst := createMockAtom("ilst", createIntegerItem("tmpo", tt.value))
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+			ilstAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if file.Tags.BPM != 128 {
+				t.Errorf("expected BPM 128, got %d", file.Tags.BPM)
+			}
+		})
+	}
+}
+
+func TestExtractIlstMetadata_MediaType(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []byte
+		want  types.MediaType
+	}{
+		{"absent", nil, types.MediaTypeUnknown},
+		{"music", createIntegerItem("stik", []byte{0x01}), types.MediaTypeMusic},
+		{"audiobook", createIntegerItem("stik", []byte{0x02}), types.MediaTypeAudiobook},
+		{"podcast", createIntegerItem("stik", []byte{0x15}), types.MediaTypePodcast},
+		{"legacy movie", createIntegerItem("stik", []byte{0x00}), types.MediaTypeMovie},
+		{"32-bit", createIntegerItem("stik", []byte{0x00, 0x00, 0x00, 0x0A}), types.MediaTypeTVShow},
+		{"unlisted kind", createIntegerItem("stik", []byte{0x07}), types.MediaTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ilst := createMockAtom("ilst", tt.items)
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+			ilstAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if file.Audio.MediaType != tt.want {
+				t.Errorf("MediaType = %v, want %v", file.Audio.MediaType, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractIlstMetadata_StandardGenre(t *testing.T) {
+	tests := []struct {
+		name  string
+		items [][]byte
+		want  []string
+	}{
+		{"1-based index", [][]byte{createIntegerItem("gnre", []byte{0x00, 0x13})}, []string{"Techno"}},
+		{"first genre", [][]byte{createIntegerItem("gnre", []byte{0x00, 0x01})}, []string{"Blues"}},
+		{
+			"free text wins in any order",
+			[][]byte{createIntegerItem("gnre", []byte{0x00, 0x12}), createMetadataItem([]byte("\xA9gen"), "Shoegaze")},
+			[]string{"Shoegaze"},
+		},
+		{
+			"before podcast category",
+			[][]byte{createMetadataItem([]byte("catg"), "Technology"), createIntegerItem("gnre", []byte{0x00, 0x12})},
+			[]string{"Rock"},
+		},
+		{"out of range", [][]byte{createIntegerItem("gnre", []byte{0x00, 0x00})}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ilst := createMockAtom("ilst", bytes.Join(tt.items, nil))
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+			ilstAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(file.Tags.Genres, tt.want) {
+				t.Errorf("Genres = %v, want %v", file.Tags.Genres, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractIlstMetadata_BooleanAtoms(t *testing.T) {
+	ilst := createMockAtom("ilst", bytes.Join([][]byte{
+		createIntegerItem("cpil", []byte{0x01}),
+		createIntegerItem("pgap", []byte{0x01}),
+		createIntegerItem("shwm", []byte{0x00}),
+		createIntegerItem("hdvd", []byte{0x01}),
+	}, nil))
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+	ilstAtom, _ := readAtomHeader(sr, 0)
+
+	file := &types.File{}
+	if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !file.Tags.Compilation {
+		t.Error("expected Compilation from cpil")
+	}
+	if !file.Tags.Gapless {
+		t.Error("expected Gapless from pgap")
+	}
+	if file.Tags.ShowMovement {
+		t.Error("expected ShowMovement false from shwm=0")
+	}
+	if got := file.Tags.GetFirst("hdvd"); got != "1" {
+		t.Errorf("expected raw hdvd \"1\", got %q", got)
+	}
+
+	// No control bytes may leak into text fields
+	for name, value := range file.Tags.Fields() {
+		if strings.ContainsRune(value, 0x01) {
+			t.Errorf("%s contains raw flag byte: %q", name, value)
+		}
+	}
+}
+
+func TestExtractIlstMetadata_PodcastFallbacks(t *testing.T) {
+	catg := createMetadataItem([]byte("catg"), "Technology")
+	keyw := createMetadataItem([]byte("keyw"), "go, audio , metadata")
+	desc := createMetadataItem([]byte("desc"), "Short")
+	ldes := createMetadataItem([]byte("ldes"), "A much longer episode description")
+	gen := createMetadataItem([]byte{0xA9, 'g', 'e', 'n'}, "Rock")
+
+	tests := []struct {
+		name            string
+		items           [][]byte
+		wantGenres      []string
+		wantDescription string
+	}{
+		{"category only", [][]byte{catg, desc}, []string{"Technology"}, "Short"},
+		{"long description preferred", [][]byte{desc, ldes}, nil, "A much longer episode description"},
+		// ©gen after catg still wins, so music files are unaffected
+		{"music genre wins", [][]byte{catg, gen}, []string{"Rock"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ilstData []byte
+			for _, item := range append(tt.items, keyw) {
+				ilstData = append(ilstData, item...)
+			}
+			ilst := createMockAtom("ilst", ilstData)
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+			ilstAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(file.Tags.Genres, tt.wantGenres) {
+				t.Errorf("Genres = %v, want %v", file.Tags.Genres, tt.wantGenres)
+			}
+			if file.Tags.Description != tt.wantDescription {
+				t.Errorf("Description = %q, want %q", file.Tags.Description, tt.wantDescription)
+			}
+			if want := []string{"go", "audio", "metadata"}; !slices.Equal(file.Tags.Keywords, want) {
+				t.Errorf("Keywords = %v, want %v", file.Tags.Keywords, want)
+			}
+		})
+	}
+}
+
+func TestExtractIlstMetadata_Comments(t *testing.T) {
+	var ilstData []byte
+	for _, item := range [][]byte{
+		createMetadataItem([]byte("desc"), "Short"),
+		createMetadataItem([]byte{0xA9, 'c', 'm', 't'}, "Recorded live"),
+		createMetadataItem([]byte("ldes"), "A much longer episode description"),
+	} {
+		ilstData = append(ilstData, item...)
+	}
+	ilst := createMockAtom("ilst", ilstData)
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+	ilstAtom, _ := readAtomHeader(sr, 0)
+
+	file := &types.File{}
+	if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []types.Comment{
+		{Description: "description", Text: "Short"},
+		{Text: "Recorded live"},
+		{Description: "long description", Text: "A much longer episode description"},
+	}
+	if !slices.Equal(file.Tags.Comments, want) {
+		t.Errorf("Comments = %+v, want %+v", file.Tags.Comments, want)
+	}
+	if file.Tags.Comment != "Recorded live" {
+		t.Errorf("Comment = %q, want %q", file.Tags.Comment, "Recorded live")
+	}
+}
+
+func TestApplyArtistList(t *testing.T) {
+	art := []byte{0xA9, 'A', 'R', 'T'}
+
+	tests := []struct {
+		name        string
+		items       [][]byte
+		separators  []string
+		wantArtist  string
+		wantArtists []string
+	}{
+		{"split artist", [][]byte{createMetadataItem(art, "A; B")}, nil, "A; B", []string{"A", "B"}},
+		{"single artist", [][]byte{createMetadataItem(art, "AC/DC")}, nil, "AC/DC", []string{"AC/DC"}},
+		{"album artist only", [][]byte{createMetadataItem([]byte("aART"), "X / Y")}, nil, "", nil},
+		{"custom separators", [][]byte{createMetadataItem(art, "A, B & C")}, []string{", ", " & "}, "A, B & C", []string{"A", "B", "C"}},
+		{"splitting disabled", [][]byte{createMetadataItem(art, "A; B")}, []string{}, "A; B", []string{"A; B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ilstData []byte
+			for _, item := range tt.items {
+				ilstData = append(ilstData, item...)
+			}
+			ilst := createMockAtom("ilst", ilstData)
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+			ilstAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			applyArtistList(file, tt.separators)
+
+			if file.Tags.Artist != tt.wantArtist {
+				t.Errorf("Artist = %q, want %q", file.Tags.Artist, tt.wantArtist)
+			}
+			if !slices.Equal(file.Tags.Artists, tt.wantArtists) {
+				t.Errorf("Artists = %q, want %q", file.Tags.Artists, tt.wantArtists)
+			}
+		})
+	}
+}
+
+func TestDecodeDataValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeCode uint32
+		value    string
+		want     string
+	}{
+		{"UTF-8 text", 1, "Book One\x00", "Book One"},
+		{"signed 8-bit", dataTypeSigned, "\x03", "3"},
+		{"signed 16-bit", dataTypeSigned, "\x00\x03", "3"},
+		{"signed negative", dataTypeSigned, "\xFF\xFE", "-2"},
+		{"unsigned 64-bit", dataTypeUnsigned, "\x00\x00\x00\x01\x00\x00\x00\x00", "4294967296"},
+		{"implicit binary", dataTypeImplicit, "\x00\x03", "3"},
+		{"implicit text", dataTypeImplicit, "12", "12"},
+		{"odd-sized integer is text", dataTypeSigned, "abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeDataValue(tt.typeCode, []byte(tt.value)); got != tt.want {
+				t.Errorf("decodeDataValue(%#x, %q) = %q, want %q", tt.typeCode, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractIlstMetadata_IntegerAtoms(t *testing.T) {
+	disk := createIntegerItem("disk", []byte{0, 0, 0, 2, 0, 3, 0, 0})
+	ilst := createMockAtom("ilst", bytes.Join([][]byte{
+		createIntegerItem("\xA9mvi", []byte{0x00, 0x03}),
+		disk,
+	}, nil))
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4b")
+	ilstAtom, _ := readAtomHeader(sr, 0)
+
+	file := &types.File{}
+	if err := extractIlstMetadata(context.Background(), sr, ilstAtom, file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if file.Tags.SeriesPart != "3" {
+		t.Errorf("SeriesPart = %q, want %q", file.Tags.SeriesPart, "3")
+	}
+	if file.Tags.DiscNumber != 2 || file.Tags.DiscTotal != 3 {
+		t.Errorf("disc = %d/%d, want 2/3", file.Tags.DiscNumber, file.Tags.DiscTotal)
+	}
+}
@@ -3,6 +3,7 @@ package m4a
 import (
 	"context"
 	"io"
+	"log/slog"
 
 	"github.com/simonhull/audiometa/internal/binary"
 	"github.com/simonhull/audiometa/internal/registry"
@@ -12,6 +13,11 @@ import (
 // parser implements the audiometa.FormatParser interface.
 type parser struct{}
 
+// ctxCheckInterval is how many chapters or atoms are read between
+// cancellation checks. Audiobooks can have thousands of chapters, and a
+// crafted file any number of atoms, each a separate read.
+const ctxCheckInterval = 64
+
 // detectM4Format determines if this is M4A or M4B by checking the ftyp atom.
 func detectM4Format(sr *binary.SafeReader, size int64) types.Format {
 	// Try to find ftyp atom
@@ -74,25 +80,38 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		Audio:  types.AudioInfo{},
 	}
 
+	if registry.RawTagsEnabled(ctx) {
+		file.EnableRawTags()
+	}
+
+	log := registry.Logger(ctx)
+	logAtoms(ctx, log, sr, 0, size)
+
 	// Find moov atom (movie container)
-	moovAtom, err := findAtom(sr, 0, size, "moov")
+	moovAtom, err := findAtomContext(ctx, sr, 0, size, "moov")
 	if err != nil {
 		// No moov atom - return basic file info
-		return file, nil
+		return result(ctx, file)
 	}
 
+	logAtoms(ctx, log, sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()))
+
+	// Per-track tags for multi-track files; these don't depend on a
+	// movie-level ilst, so read them before the early returns below.
+	parseTrackTags(ctx, sr, moovAtom, file)
+
 	// Find udta atom (user data) inside moov
-	udtaAtom, err := findAtom(sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "udta")
+	udtaAtom, err := findAtomContext(ctx, sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "udta")
 	if err != nil {
 		// No udta - return basic file info
-		return file, nil
+		return result(ctx, file)
 	}
 
 	// Find meta atom inside udta
-	metaAtom, err := findAtom(sr, udtaAtom.DataOffset(), udtaAtom.DataOffset()+int64(udtaAtom.DataSize()), "meta")
+	metaAtom, err := findAtomContext(ctx, sr, udtaAtom.DataOffset(), udtaAtom.DataOffset()+int64(udtaAtom.DataSize()), "meta")
 	if err != nil {
 		// No meta - return basic file info
-		return file, nil
+		return result(ctx, file)
 	}
 
 	// meta atom has 4 bytes of version+flags before the data
@@ -100,23 +119,33 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 	metaDataEnd := metaAtom.DataOffset() + int64(metaAtom.DataSize())
 
 	// Find ilst atom (iTunes metadata list) inside meta
-	ilstAtom, err := findAtom(sr, metaDataOffset, metaDataEnd, "ilst")
+	ilstAtom, err := findAtomContext(ctx, sr, metaDataOffset, metaDataEnd, "ilst")
 	if err != nil {
 		// No ilst - return basic file info
-		return file, nil
+		return result(ctx, file)
 	}
 
+	logAtoms(ctx, log, sr, ilstAtom.DataOffset(), ilstAtom.DataOffset()+int64(ilstAtom.DataSize()))
+
 	// Extract metadata from ilst
-	if err := extractIlstMetadata(sr, ilstAtom, file); err != nil {
+	if err := extractIlstMetadata(ctx, sr, ilstAtom, file); err != nil {
 		file.Warnings = append(file.Warnings, types.Warning{
 			Stage:   "metadata",
 			Message: err.Error(),
 			Err:     err,
 		})
 	}
+	if file.RawTagsEnabled() {
+		addRawAtoms(ctx, sr, ilstAtom, file)
+	}
+	applyArtistList(file, registry.ArtistSeparators(ctx))
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Parse technical info (duration, bitrate, codec, sample rate, channels)
-	if err := parseTechnicalInfo(sr, moovAtom, file); err != nil {
+	if err := parseTechnicalInfo(ctx, sr, moovAtom, file); err != nil {
 		file.Warnings = append(file.Warnings, types.Warning{
 			Stage:   "technical",
 			Message: err.Error(),
@@ -125,7 +154,10 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 	}
 
 	// Parse chapters
-	chapters, err := parseChapters(sr, moovAtom, file.Audio.Duration)
+	chapters, source, err := parseChapters(ctx, sr, moovAtom, file.Audio.Duration)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		file.Warnings = append(file.Warnings, types.Warning{
 			Stage:   "chapters",
@@ -134,29 +166,113 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		})
 	} else if len(chapters) > 0 {
 		file.Chapters = chapters
+		file.ChapterSource = source
+		checkChapterTimescale(ctx, sr, moovAtom, file)
+		checkChunkOffsets(ctx, sr, moovAtom, file)
 	}
 
 	// Parse audiobook-specific tags (narrator, series, publisher, etc.)
+	narrated := false
 	if ilstAtom != nil {
-		if err := parseAudiobookTags(sr, ilstAtom, file); err != nil {
+		fromComposer := file.Tags.Narrator == "" && len(file.Tags.Composers) > 0
+		if err := parseAudiobookTags(ctx, sr, ilstAtom, file); err != nil {
 			file.Warnings = append(file.Warnings, types.Warning{
 				Stage:   "metadata",
 				Message: err.Error(),
 				Err:     err,
 			})
 		}
+		// The Composer fallback does not make a narrator
+		narrated = file.Tags.Narrator != "" && (!fromComposer || file.Tags.Narrator != file.Tags.Composers[0])
 	}
 
+	promoteAudiobook(file, narrated)
+
+	return result(ctx, file)
+}
+
+// result returns file, or ctx.Err() if ctx is done. The atom walks stop
+// early on cancellation without an error of their own, which would
+// otherwise pass for a file missing those atoms.
+func result(ctx context.Context, file *types.File) (*types.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return file, nil
 }
 
+// logAtoms logs the atoms between start and end at debug level. The walk
+// costs a header read per atom, so it is skipped when debug is disabled.
+func logAtoms(ctx context.Context, log *slog.Logger, sr *binary.SafeReader, start, end int64) {
+	if !log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	for i, offset := 0, start; offset < end; i++ {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return
+		}
+		atom, err := readAtomHeader(sr, offset)
+		if err != nil || atom.Size == 0 {
+			return
+		}
+		log.LogAttrs(ctx, slog.LevelDebug, "atom",
+			slog.String("format", "MP4"), slog.String("key", atom.Type),
+			slog.Int64("offset", atom.Offset), slog.Uint64("size", atom.Size))
+		offset += int64(atom.Size)
+	}
+}
+
+// ExtractChapters extracts chapters from M4A/M4B files.
+//
+// Only mvhd (for the final chapter's end time) and the chapter structures
+// are read; iTunes metadata and sample descriptions are skipped.
+func (p *parser) ExtractChapters(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Chapter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binary.NewSafeReader(r, size, path)
+
+	moovAtom, err := findAtom(sr, 0, size, "moov")
+	if err != nil {
+		return nil, nil
+	}
+
+	file := &types.File{Path: path, Size: size}
+	if mvhdAtom, err := findAtom(sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "mvhd"); err == nil {
+		_ = parseMvhd(sr, mvhdAtom, file)
+	}
+
+	chapters, _, err := parseChapters(ctx, sr, moovAtom, file.Audio.Duration)
+	return chapters, err
+}
+
 // ExtractArtwork extracts embedded artwork from M4A/M4B files.
 func (p *parser) ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 	sr := binary.NewSafeReader(r, size, path)
-	return extractArtwork(sr, size)
+	return extractArtwork(ctx, sr, size, true)
+}
+
+// ExtractArtworkMetadata describes covr images from their data atom flags
+// and image headers without loading the images.
+func (p *parser) ExtractArtworkMetadata(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sr := binary.NewSafeReader(r, size, path)
+	return extractArtwork(ctx, sr, size, false)
+}
+
+// CountArtwork counts covr data atoms from their headers.
+func (p *parser) CountArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	sr := binary.NewSafeReader(r, size, path)
+	return countArtwork(sr, size), nil
 }
 
 // init registers the M4A/M4B parser.
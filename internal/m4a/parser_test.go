@@ -4,6 +4,8 @@ import (
 	"context"
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"io"
 	"os"
 	"testing"
 
@@ -243,6 +245,11 @@ func TestParse_WithArtwork_Integration(t *testing.T) {
 		t.Errorf("expected title 'Test Book', got '%s'", parsedFile.Tags.Title)
 	}
 
+	// Presence is recorded during Parse without loading the image
+	if !parsedFile.HasEmbeddedArtwork {
+		t.Error("expected HasEmbeddedArtwork after parsing covr atom")
+	}
+
 	// Now extract artwork separately (lazy loading)
 	artwork, err := p.ExtractArtwork(context.Background(), file, stat.Size(), tmpFile.Name())
 	if err != nil {
@@ -309,3 +316,44 @@ func createM4BWithMetadataAndCover(title, artist string, coverData []byte) []byt
 	buf.Write(moovAtom)
 	return buf.Bytes()
 }
+
+// cancelAtReader cancels a context once a read reaches offset.
+type cancelAtReader struct {
+	r      io.ReaderAt
+	offset int64
+	cancel context.CancelFunc
+}
+
+func (c *cancelAtReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= c.offset {
+		c.cancel()
+	}
+	return c.r.ReadAt(p, off)
+}
+
+func TestParse_CanceledDuringMoovWalk(t *testing.T) {
+	// A moov of nothing but free atoms, cancelled partway through
+	moov := &bytes.Buffer{}
+	for range 10000 {
+		binary.Write(moov, binary.BigEndian, uint32(8))
+		moov.WriteString("free")
+	}
+	data := &bytes.Buffer{}
+	data.Write([]byte{0, 0, 0, 20, 'f', 't', 'y', 'p', 'M', '4', 'B', ' ', 0, 0, 0, 0, 'M', '4', 'B', ' '})
+	binary.Write(data, binary.BigEndian, uint32(8+moov.Len()))
+	data.WriteString("moov")
+	data.Write(moov.Bytes())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancelAtReader{r: bytes.NewReader(data.Bytes()), offset: int64(data.Len() / 2), cancel: cancel}
+
+	p := &parser{}
+	file, err := p.Parse(ctx, r, int64(data.Len()), "test.m4b")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Parse() error = %v, want context.Canceled", err)
+	}
+	if file != nil {
+		t.Error("expected no file on cancellation")
+	}
+}
@@ -0,0 +1,94 @@
+package m4a
+
+import (
+	"context"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// addRawAtoms records every data atom of the ilst items for
+// File.RawTags, keyed by item type, or by "----:<mean>:<name>" for
+// freeform items. Values are the bytes after the data atom's type code
+// and locale, so integer and image atoms keep their binary form.
+func addRawAtoms(ctx context.Context, sr *binary.SafeReader, ilstAtom *Atom, file *types.File) {
+	offset := ilstAtom.DataOffset()
+	end := offset + int64(ilstAtom.DataSize())
+
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return
+		}
+
+		item, err := readAtomHeader(sr, offset)
+		if err != nil || item.Size == 0 {
+			return
+		}
+		offset += int64(item.Size)
+
+		key := item.Type
+		var values []types.RawTag
+		childEnd := item.DataOffset() + int64(item.DataSize())
+		for child := item.DataOffset(); child < childEnd; {
+			atom, err := readAtomHeader(sr, child)
+			if err != nil || atom.Size == 0 {
+				break
+			}
+			child += int64(atom.Size)
+
+			switch atom.Type {
+			case "mean", "name":
+				// Freeform namespace and field name, after version and flags
+				if atom.DataSize() > 4 {
+					buf := make([]byte, atom.DataSize()-4)
+					if err := sr.ReadAt(buf, atom.DataOffset()+4, "freeform "+atom.Type); err == nil {
+						key += ":" + string(buf)
+					}
+				}
+			case "data":
+				if raw, err := readRawData(sr, atom); err == nil {
+					values = append(values, raw)
+				}
+			}
+		}
+
+		for _, raw := range values {
+			raw.Key = key
+			file.AddRawTag(raw)
+		}
+	}
+}
+
+// readRawData reads a data atom's value, typed by its type code.
+//
+// data atom structure:
+//
+//	[1 byte]  version
+//	[3 bytes] type code
+//	[4 bytes] locale
+//	[n bytes] value
+func readRawData(sr *binary.SafeReader, dataAtom *Atom) (types.RawTag, error) {
+	typeCode, err := binary.Read[uint32](sr, dataAtom.DataOffset(), "data atom type")
+	if err != nil {
+		return types.RawTag{}, err
+	}
+
+	var value []byte
+	if dataAtom.DataSize() > 8 {
+		value = make([]byte, dataAtom.DataSize()-8)
+		if err := sr.ReadAt(value, dataAtom.DataOffset()+8, "data atom value"); err != nil {
+			return types.RawTag{}, err
+		}
+	}
+
+	raw := types.RawTag{Value: value, Type: types.RawTagBinary}
+	switch typeCode & 0xFFFFFF {
+	case 0x01: // UTF-8
+		raw.Type, raw.Encoding = types.RawTagText, "UTF-8"
+	case 0x02: // UTF-16 (big-endian)
+		raw.Type, raw.Encoding = types.RawTagText, "UTF-16BE"
+	case 0x0D, 0x0E, 0x1B: // JPEG, PNG, BMP
+		raw.Type = types.RawTagImage
+	}
+	return raw, nil
+}
@@ -0,0 +1,52 @@
+package m4a
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	audiobinary "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestAddRawAtoms(t *testing.T) {
+	covr := createMockAtom("covr", createMockAtom("data", append([]byte{0, 0, 0, 0x0D, 0, 0, 0, 0}, "\xFF\xD8jpeg"...)))
+	ilst := createMockAtom("ilst", bytes.Join([][]byte{
+		createMetadataItem([]byte("\xA9nam"), "Title"),
+		createIntegerItem("trkn", []byte{0, 0, 0, 3, 0, 12, 0, 0}),
+		covr,
+		createCustomAtom("com.apple.iTunes", "MOOD", "Calm"),
+	}, nil))
+
+	sr := audiobinary.NewSafeReader(bytes.NewReader(ilst), int64(len(ilst)), "test.m4a")
+	ilstAtom, _ := readAtomHeader(sr, 0)
+
+	file := &types.File{}
+	addRawAtoms(context.Background(), sr, ilstAtom, file)
+	if file.RawTags() != nil {
+		t.Fatal("raw tags recorded without EnableRawTags")
+	}
+	file.EnableRawTags()
+	addRawAtoms(context.Background(), sr, ilstAtom, file)
+
+	tests := []struct {
+		key   string
+		value string
+		typ   types.RawTagType
+	}{
+		{"\xA9nam", "Title", types.RawTagText},
+		{"trkn", "\x00\x00\x00\x03\x00\x0C\x00\x00", types.RawTagBinary},
+		{"covr", "\xFF\xD8jpeg", types.RawTagImage},
+		{"----:com.apple.iTunes:MOOD", "Calm", types.RawTagText},
+	}
+	raw := file.RawTags()
+	if len(raw) != len(tests) {
+		t.Errorf("RawTags() has %d keys, want %d: %v", len(raw), len(tests), raw)
+	}
+	for _, tt := range tests {
+		got := raw[tt.key]
+		if len(got) != 1 || string(got[0].Value) != tt.value || got[0].Type != tt.typ {
+			t.Errorf("RawTags()[%q] = %+v, want value %q of type %d", tt.key, got, tt.value, tt.typ)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package m4a
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/simonhull/audiometa/internal/binary"
@@ -9,11 +11,12 @@ import (
 
 // parseTechnicalInfo extracts duration, bitrate, sample rate, channels, and codec.
 // Returns nil (no error) even if atoms are missing - technical info is best-effort.
-func parseTechnicalInfo(sr *binary.SafeReader, moovAtom *Atom, file *types.File) error {
+// The only error is ctx.Err(), if ctx is done during the moov walk.
+func parseTechnicalInfo(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, file *types.File) error {
 	// Find mvhd (movie header) atom for duration
-	mvhdAtom, err := findAtom(sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "mvhd")
+	mvhdAtom, err := findAtomContext(ctx, sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "mvhd")
 	if err != nil {
-		return nil
+		return ctx.Err()
 	}
 
 	// Parse mvhd for duration
@@ -23,9 +26,9 @@ func parseTechnicalInfo(sr *binary.SafeReader, moovAtom *Atom, file *types.File)
 
 	// Find trak atom for audio format info
 	// Path: moov -> trak
-	trakAtom, err := findAtom(sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "trak")
+	trakAtom, err := findAtomContext(ctx, sr, moovAtom.DataOffset(), moovAtom.DataOffset()+int64(moovAtom.DataSize()), "trak")
 	if err != nil {
-		return nil
+		return ctx.Err()
 	}
 
 	// Find mdia atom
@@ -99,12 +102,20 @@ func parseMvhd(sr *binary.SafeReader, mvhdAtom *Atom, file *types.File) error {
 		return err
 	}
 
-	// Calculate duration: duration / timescale
-	if timescale > 0 {
-		durationNs := (int64(duration) * 1_000_000_000) / int64(timescale)
-		file.Audio.Duration = time.Duration(durationNs)
+	// Reject implausible timescales rather than computing a nonsense duration
+	if !validTimescale(timescale) {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: fmt.Sprintf("implausible mvhd timescale %d, duration unknown", timescale),
+			Offset:  mvhdAtom.Offset,
+		})
+		return nil
 	}
 
+	// Calculate duration: duration / timescale
+	durationNs := (int64(duration) * 1_000_000_000) / int64(timescale)
+	file.Audio.Duration = time.Duration(durationNs)
+
 	return nil
 }
 
@@ -179,7 +190,8 @@ func parseStsd(sr *binary.SafeReader, stsdAtom *Atom, file *types.File) error {
 	// [4 bytes] format (codec type)
 	// ... more fields ...
 
-	_, err = binary.Read[uint32](sr, offset, "stsd entry size")
+	entryOffset := offset
+	entrySize, err := binary.Read[uint32](sr, offset, "stsd entry size")
 	if err != nil {
 		return err
 	}
@@ -229,5 +241,15 @@ func parseStsd(sr *binary.SafeReader, stsdAtom *Atom, file *types.File) error {
 	// High 16 bits = integer part, low 16 bits = fractional part
 	file.Audio.SampleRate = int(sampleRateFixed >> 16)
 
+	// The 16.16 field can't represent rates above 65535 Hz, so ALAC keeps
+	// the real rate and bit depth in its magic cookie (non-fatal if absent)
+	if codec == "alac" {
+		_ = parseALACConfig(sr, entryOffset, int64(entrySize), file)
+	}
+
+	// A chan atom describes the real speaker layout, which the 16-bit
+	// sample-entry count can get wrong for surround (non-fatal if absent)
+	_ = parseChannelLayout(sr, entryOffset, int64(entrySize), file)
+
 	return nil
 }
@@ -2,7 +2,9 @@ package m4a
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"slices"
 	"testing"
 	"time"
 
@@ -43,7 +45,7 @@ func TestParseTechnicalInfo_Duration(t *testing.T) {
 	moovAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseTechnicalInfo(sr, moovAtom, file)
+	err := parseTechnicalInfo(context.Background(), sr, moovAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -64,7 +66,7 @@ func TestParseTechnicalInfo_NonStandardTimescale(t *testing.T) {
 	moovAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseTechnicalInfo(sr, moovAtom, file)
+	err := parseTechnicalInfo(context.Background(), sr, moovAtom, file)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -88,7 +90,7 @@ func TestParseTechnicalInfo_NoMvhd(t *testing.T) {
 	moovAtom, _ := readAtomHeader(sr, 0)
 
 	file := &types.File{}
-	err := parseTechnicalInfo(sr, moovAtom, file)
+	err := parseTechnicalInfo(context.Background(), sr, moovAtom, file)
 
 	// Should not error, just leave duration as 0
 	if err != nil {
@@ -99,3 +101,161 @@ func TestParseTechnicalInfo_NoMvhd(t *testing.T) {
 		t.Errorf("expected duration 0 for missing mvhd, got %v", file.Audio.Duration)
 	}
 }
+
+func TestParseTechnicalInfo_ImplausibleTimescale(t *testing.T) {
+	tests := []struct {
+		name      string
+		timescale uint32
+	}{
+		{"zero", 0},
+		{"absurd", 0xFFFFFFFF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moov := createMockAtom("moov", createMvhdAtom(0, tt.timescale, 10000))
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4b")
+			moovAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			if err := parseTechnicalInfo(context.Background(), sr, moovAtom, file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if file.Audio.Duration != 0 {
+				t.Errorf("expected duration 0 for timescale %d, got %v", tt.timescale, file.Audio.Duration)
+			}
+			if len(file.Warnings) != 1 || file.Warnings[0].Stage != "technical" {
+				t.Errorf("expected one technical warning, got %v", file.Warnings)
+			}
+		})
+	}
+}
+
+// createTimescaleTrak creates a trak with tkhd (track ID), mdhd (timescale),
+// hdlr (handler type), and an optional tref/chap reference.
+func createTimescaleTrak(trackID, timescale uint32, handler string, chapterRef uint32) []byte {
+	tkhd := &bytes.Buffer{}
+	binary.Write(tkhd, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(tkhd, binary.BigEndian, uint32(0)) // creation time
+	binary.Write(tkhd, binary.BigEndian, uint32(0)) // modification time
+	binary.Write(tkhd, binary.BigEndian, trackID)
+
+	mdhd := &bytes.Buffer{}
+	binary.Write(mdhd, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(mdhd, binary.BigEndian, uint32(0)) // creation time
+	binary.Write(mdhd, binary.BigEndian, uint32(0)) // modification time
+	binary.Write(mdhd, binary.BigEndian, timescale)
+	binary.Write(mdhd, binary.BigEndian, uint32(0)) // duration
+
+	hdlr := &bytes.Buffer{}
+	binary.Write(hdlr, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(hdlr, binary.BigEndian, uint32(0)) // pre_defined
+	hdlr.WriteString(handler)
+
+	mdia := createMockAtom("mdia", append(createMockAtom("mdhd", mdhd.Bytes()), createMockAtom("hdlr", hdlr.Bytes())...))
+
+	trak := createMockAtom("tkhd", tkhd.Bytes())
+	if chapterRef != 0 {
+		chap := &bytes.Buffer{}
+		binary.Write(chap, binary.BigEndian, chapterRef)
+		trak = append(trak, createMockAtom("tref", createMockAtom("chap", chap.Bytes()))...)
+	}
+	trak = append(trak, mdia...)
+
+	return createMockAtom("trak", trak)
+}
+
+func TestCheckChapterTimescale(t *testing.T) {
+	tests := []struct {
+		name         string
+		audioScale   uint32
+		chapterScale uint32
+		wantWarning  bool
+	}{
+		{"typical audio vs millisecond chapters", 44100, 1000, false},
+		{"matching timescales", 48000, 48000, false},
+		{"implausible chapter timescale", 44100, 0xFFFFFFFF, true},
+		{"unexpected factor", 48000, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audio := createTimescaleTrak(1, tt.audioScale, "soun", 2)
+			chapter := createTimescaleTrak(2, tt.chapterScale, "text", 0)
+			moov := createMockAtom("moov", append(audio, chapter...))
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4b")
+			moovAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			checkChapterTimescale(context.Background(), sr, moovAtom, file)
+
+			if got := len(file.Warnings) > 0; got != tt.wantWarning {
+				t.Errorf("expected warning=%v, got warnings %v", tt.wantWarning, file.Warnings)
+			}
+		})
+	}
+}
+
+// createSttsStbl creates an stbl holding an stts with one sample per delta.
+func createSttsStbl(deltas ...uint32) []byte {
+	stts := &bytes.Buffer{}
+	binary.Write(stts, binary.BigEndian, uint32(0)) // version + flags
+	binary.Write(stts, binary.BigEndian, uint32(len(deltas)))
+	for _, d := range deltas {
+		binary.Write(stts, binary.BigEndian, uint32(1)) // sample count
+		binary.Write(stts, binary.BigEndian, d)
+	}
+	return createMockAtom("stbl", createMockAtom("stts", stts.Bytes()))
+}
+
+func TestRetimeChapters(t *testing.T) {
+	// Two chapters 30s apart, with the stts written in 44.1kHz units
+	stbl := createSttsStbl(30*44100, 70*44100)
+
+	tests := []struct {
+		name         string
+		chapterScale uint32
+		audioScale   uint32
+		fileDuration time.Duration
+		wantScale    uint32
+		wantSecond   time.Duration
+	}{
+		{"overshoot uses audio timescale", 1000, 44100, 100 * time.Second, 44100, 30 * time.Second},
+		{"times fit chapter timescale", 44100, 48000, 100 * time.Second, 0, 0},
+		{"unknown duration", 1000, 44100, 0, 0, 0},
+		{"audio timescale still overshoots", 1000, 4410, 100 * time.Second, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referrer := createTimescaleTrak(1, tt.audioScale, "soun", 2)
+			data := append(slices.Clone(stbl), referrer...)
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.m4b")
+			stblAtom, _ := readAtomHeader(sr, 0)
+			referrerAtom, _ := readAtomHeader(sr, int64(len(stbl)))
+
+			times, err := parseChapterTimings(sr, stblAtom, tt.chapterScale)
+			if err != nil {
+				t.Fatalf("parseChapterTimings: %v", err)
+			}
+
+			retimed, scale := retimeChapters(sr, stblAtom, referrerAtom, times, tt.chapterScale, tt.fileDuration)
+			if scale != tt.wantScale {
+				t.Fatalf("expected timescale %d, got %d", tt.wantScale, scale)
+			}
+			if tt.wantScale == 0 {
+				if retimed != nil {
+					t.Errorf("expected no retiming, got %v", retimed)
+				}
+				return
+			}
+			if len(retimed) != 2 || retimed[1] != tt.wantSecond {
+				t.Errorf("expected second chapter at %v, got %v", tt.wantSecond, retimed)
+			}
+		})
+	}
+}
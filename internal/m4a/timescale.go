@@ -0,0 +1,199 @@
+package m4a
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Timescale sanity bounds. Real files use values like 600, 1000, 44100 or
+// 48000; anything outside this range is treated as corrupt.
+const (
+	minTimescale      = 1
+	maxTimescale      = 1_000_000_000
+	defaultTimescale  = 1000
+	maxTimescaleRatio = 1000 // Chapter vs audio timescale factor before we warn
+)
+
+// validTimescale reports whether a timescale is plausible.
+func validTimescale(timescale uint32) bool {
+	return timescale >= minTimescale && timescale <= maxTimescale
+}
+
+// readMdhdTimescale reads the raw timescale from an mdia atom's mdhd.
+func readMdhdTimescale(sr *binary.SafeReader, mdiaAtom *Atom) (uint32, error) {
+	mdhdAtom, err := findAtom(sr, mdiaAtom.DataOffset(), mdiaAtom.DataOffset()+int64(mdiaAtom.DataSize()), "mdhd")
+	if err != nil {
+		return 0, err
+	}
+
+	mdhdOffset := mdhdAtom.DataOffset()
+	version, err := binary.Read[uint8](sr, mdhdOffset, "mdhd version")
+	if err != nil {
+		return 0, err
+	}
+
+	if version == 1 {
+		return binary.Read[uint32](sr, mdhdOffset+20, "timescale")
+	}
+	return binary.Read[uint32](sr, mdhdOffset+12, "timescale")
+}
+
+// trakTimescale reads the raw mdhd timescale of a trak atom.
+func trakTimescale(sr *binary.SafeReader, trakAtom *Atom) (uint32, error) {
+	mdiaAtom, err := findAtom(sr, trakAtom.DataOffset(), trakAtom.DataOffset()+int64(trakAtom.DataSize()), "mdia")
+	if err != nil {
+		return 0, err
+	}
+	return readMdhdTimescale(sr, mdiaAtom)
+}
+
+// trakHandlerType returns the mdia/hdlr handler type ("soun", "text", ...).
+func trakHandlerType(sr *binary.SafeReader, trakAtom *Atom) string {
+	mdiaAtom, err := findAtom(sr, trakAtom.DataOffset(), trakAtom.DataOffset()+int64(trakAtom.DataSize()), "mdia")
+	if err != nil {
+		return ""
+	}
+
+	hdlrAtom, err := findAtom(sr, mdiaAtom.DataOffset(), mdiaAtom.DataOffset()+int64(mdiaAtom.DataSize()), "hdlr")
+	if err != nil || hdlrAtom.DataSize() < 12 {
+		return ""
+	}
+
+	// hdlr: version/flags (4) + pre_defined (4) + handler type (4)
+	handler := make([]byte, 4)
+	if err := sr.ReadAt(handler, hdlrAtom.DataOffset()+8, "hdlr handler type"); err != nil {
+		return ""
+	}
+	return string(handler)
+}
+
+// findAudioTrack returns the first trak with a "soun" handler.
+func findAudioTrack(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom) *Atom {
+	offset := moovAtom.DataOffset()
+	end := offset + int64(moovAtom.DataSize())
+
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			break
+		}
+
+		trakAtom, err := readAtomHeader(sr, offset)
+		if err != nil {
+			break
+		}
+
+		if trakAtom.Type == "trak" && trakHandlerType(sr, trakAtom) == "soun" {
+			return trakAtom
+		}
+
+		offset += int64(trakAtom.Size)
+	}
+
+	return nil
+}
+
+// chapterTimesOvershoot reports whether the last chapter starts after the
+// end of the file. An unknown file duration never overshoots.
+func chapterTimesOvershoot(times []time.Duration, fileDuration time.Duration) bool {
+	return fileDuration > 0 && len(times) > 0 && times[len(times)-1] > fileDuration
+}
+
+// retimeChapters re-reads chapter start times against the referring track's
+// timescale when the chapter track's own timescale places chapters past the
+// end of the file. Some encoders write the chapter stts in audio track units
+// while leaving the chapter mdhd at its default.
+//
+// Returns the corrected times and the timescale used, or nil if the times
+// fit, the referrer has no usable timescale, or the retimed chapters still
+// overshoot.
+func retimeChapters(sr *binary.SafeReader, stblAtom, referrer *Atom, times []time.Duration, timescale uint32, fileDuration time.Duration) ([]time.Duration, uint32) {
+	if referrer == nil || !chapterTimesOvershoot(times, fileDuration) {
+		return nil, 0
+	}
+
+	audioScale, err := trakTimescale(sr, referrer)
+	if err != nil || !validTimescale(audioScale) || audioScale == timescale {
+		return nil, 0
+	}
+
+	retimed, err := parseChapterTimings(sr, stblAtom, audioScale)
+	if err != nil || chapterTimesOvershoot(retimed, fileDuration) {
+		return nil, 0
+	}
+	return retimed, audioScale
+}
+
+// checkChapterTimescale warns about implausible chapter track timescales.
+//
+// A corrupt chapter track timescale (e.g. 0xFFFFFFFF) collapses every
+// chapter to near-zero timestamps. The chapter parser already falls back to
+// 1000 for out-of-range values; this records why. When the audio track's
+// timescale is known, a chapter timescale more than maxTimescaleRatio away
+// from it is also flagged, since it usually means one of them is wrong.
+// Chapters that were retimed against the referring track are reported too.
+func checkChapterTimescale(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, file *types.File) {
+	referrer, chapterTrackID := findChapterReferrer(ctx, sr, moovAtom)
+	if chapterTrackID == 0 {
+		return
+	}
+
+	chapterTrak := findTrackByID(ctx, sr, moovAtom, chapterTrackID)
+	if chapterTrak == nil {
+		return
+	}
+
+	chapterScale, err := trakTimescale(sr, chapterTrak)
+	if err != nil {
+		return
+	}
+
+	effectiveScale := chapterScale
+	if !validTimescale(chapterScale) {
+		effectiveScale = defaultTimescale
+	}
+	if stblAtom := trakSampleTable(sr, chapterTrak); stblAtom != nil {
+		times, err := parseChapterTimings(sr, stblAtom, effectiveScale)
+		if err == nil {
+			if _, audioScale := retimeChapters(sr, stblAtom, referrer, times, effectiveScale, file.Audio.Duration); audioScale != 0 {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "chapters",
+					Message: fmt.Sprintf("chapter times at timescale %d overshoot the file duration, using referring track timescale %d", effectiveScale, audioScale),
+					Offset:  chapterTrak.Offset,
+				})
+				return
+			}
+		}
+	}
+
+	if !validTimescale(chapterScale) {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "chapters",
+			Message: fmt.Sprintf("implausible chapter track timescale %d, using %d", chapterScale, defaultTimescale),
+			Offset:  chapterTrak.Offset,
+		})
+		return
+	}
+
+	audioTrak := findAudioTrack(ctx, sr, moovAtom)
+	if audioTrak == nil {
+		return
+	}
+
+	audioScale, err := trakTimescale(sr, audioTrak)
+	if err != nil || !validTimescale(audioScale) {
+		return
+	}
+
+	hi, lo := max(chapterScale, audioScale), min(chapterScale, audioScale)
+	if hi/lo > maxTimescaleRatio {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "chapters",
+			Message: fmt.Sprintf("chapter track timescale %d differs from audio track timescale %d by more than %dx", chapterScale, audioScale, maxTimescaleRatio),
+			Offset:  chapterTrak.Offset,
+		})
+	}
+}
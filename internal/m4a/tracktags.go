@@ -0,0 +1,101 @@
+package m4a
+
+import (
+	"context"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// trackMetaPaths lists where a trak may carry its own meta atom, in the
+// order they are tried. udta/meta is what iTunes writes; a bare meta and
+// mdia/minf/meta show up in files from other muxers.
+var trackMetaPaths = [][]string{
+	{"udta", "meta"},
+	{"meta"},
+	{"mdia", "minf", "meta"},
+}
+
+// parseTrackTags fills file.TrackTags with one Tags per audio track when the
+// movie has more than one. Tracks without their own ilst get empty Tags so
+// indexes line up with the audio tracks.
+func parseTrackTags(ctx context.Context, sr *binary.SafeReader, moovAtom *Atom, file *types.File) {
+	var trackTags []types.Tags
+
+	offset := moovAtom.DataOffset()
+	end := offset + int64(moovAtom.DataSize())
+
+	for i := 0; offset < end; i++ {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			break
+		}
+
+		trakAtom, err := readAtomHeader(sr, offset)
+		if err != nil {
+			break
+		}
+
+		if trakAtom.Type == "trak" && trakHandlerType(sr, trakAtom) == "soun" {
+			trackTags = append(trackTags, readTrackTags(ctx, sr, trakAtom, file))
+		}
+
+		offset += int64(trakAtom.Size)
+	}
+
+	if len(trackTags) > 1 {
+		file.TrackTags = trackTags
+	}
+}
+
+// readTrackTags parses the ilst of a single trak. Warnings are recorded on
+// file, not on the returned Tags.
+func readTrackTags(ctx context.Context, sr *binary.SafeReader, trakAtom *Atom, file *types.File) types.Tags {
+	ilstAtom := findTrackIlst(sr, trakAtom)
+	if ilstAtom == nil {
+		return types.Tags{}
+	}
+
+	track := &types.File{}
+	if err := extractIlstMetadata(ctx, sr, ilstAtom, track); err != nil {
+		track.Warnings = append(track.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: err.Error(),
+			Err:     err,
+		})
+	}
+	if err := parseAudiobookTags(ctx, sr, ilstAtom, track); err != nil {
+		track.Warnings = append(track.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: err.Error(),
+			Err:     err,
+		})
+	}
+
+	file.Warnings = append(file.Warnings, track.Warnings...)
+	return track.Tags
+}
+
+// findTrackIlst returns the ilst atom of a trak-level meta, or nil.
+func findTrackIlst(sr *binary.SafeReader, trakAtom *Atom) *Atom {
+	for _, path := range trackMetaPaths {
+		atom := trakAtom
+		for _, child := range path {
+			next, err := findAtom(sr, atom.DataOffset(), atom.DataOffset()+int64(atom.DataSize()), child)
+			if err != nil {
+				atom = nil
+				break
+			}
+			atom = next
+		}
+		if atom == nil {
+			continue
+		}
+
+		// meta atom has 4 bytes of version+flags before its children
+		ilstAtom, err := findAtom(sr, atom.DataOffset()+4, atom.DataOffset()+int64(atom.DataSize()), "ilst")
+		if err == nil {
+			return ilstAtom
+		}
+	}
+	return nil
+}
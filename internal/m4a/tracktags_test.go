@@ -0,0 +1,103 @@
+package m4a
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	audiobinary "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// createTaggedTrak creates a trak carrying its own udta/meta/ilst with a
+// title, or no udta when title is empty.
+func createTaggedTrak(trackID uint32, handler, title string) []byte {
+	trak := createTimescaleTrak(trackID, 44100, handler, 0)
+	if title == "" {
+		return trak
+	}
+
+	ilst := createMockAtom("ilst", createMetadataItem([]byte{0xA9, 'n', 'a', 'm'}, title))
+	meta := createMockAtom("meta", append(make([]byte, 4), ilst...))
+	udta := createMockAtom("udta", meta)
+
+	// Re-wrap the trak children with the udta appended
+	return createMockAtom("trak", append(trak[8:], udta...))
+}
+
+func TestParseTrackTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		traks      [][]byte
+		wantTitles []string // nil means TrackTags stays nil
+	}{
+		{
+			name: "two tagged audio tracks",
+			traks: [][]byte{
+				createTaggedTrak(1, "soun", "Narration"),
+				createTaggedTrak(2, "soun", "Music"),
+			},
+			wantTitles: []string{"Narration", "Music"},
+		},
+		{
+			name: "untagged track keeps its slot",
+			traks: [][]byte{
+				createTaggedTrak(1, "soun", ""),
+				createTaggedTrak(2, "text", "Chapters"),
+				createTaggedTrak(3, "soun", "Music"),
+			},
+			wantTitles: []string{"", "Music"},
+		},
+		{
+			name:  "single audio track",
+			traks: [][]byte{createTaggedTrak(1, "soun", "Only")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moov := createMockAtom("moov", bytes.Join(tt.traks, nil))
+
+			sr := audiobinary.NewSafeReader(bytes.NewReader(moov), int64(len(moov)), "test.m4a")
+			moovAtom, _ := readAtomHeader(sr, 0)
+
+			file := &types.File{}
+			parseTrackTags(context.Background(), sr, moovAtom, file)
+
+			if tt.wantTitles == nil {
+				if file.TrackTags != nil {
+					t.Fatalf("expected nil TrackTags, got %v", file.TrackTags)
+				}
+				return
+			}
+			if len(file.TrackTags) != len(tt.wantTitles) {
+				t.Fatalf("expected %d track tags, got %d", len(tt.wantTitles), len(file.TrackTags))
+			}
+			for i, want := range tt.wantTitles {
+				if got := file.TrackTags[i].Title; got != want {
+					t.Errorf("track %d title = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_TrackTagsWithoutMovieUdta(t *testing.T) {
+	ftyp := createMockAtom("ftyp", []byte("mp42\x00\x00\x00\x00isommp42"))
+	moov := createMockAtom("moov", append(
+		createTaggedTrak(1, "soun", "Narration"),
+		createTaggedTrak(2, "soun", "Music")...))
+	data := append(ftyp, moov...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "multi.m4a")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(file.TrackTags) != 2 {
+		t.Fatalf("expected 2 track tags, got %d", len(file.TrackTags))
+	}
+	if file.TrackTags[0].Title != "Narration" || file.TrackTags[1].Title != "Music" {
+		t.Errorf("track titles = %q, %q", file.TrackTags[0].Title, file.TrackTags[1].Title)
+	}
+}
@@ -0,0 +1,179 @@
+package mkv
+
+import (
+	"errors"
+	"time"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Chapters element IDs.
+const (
+	idEditionEntry       = 0x45B9
+	idEditionFlagDefault = 0x45DB
+	idChapterAtom        = 0xB6
+	idChapterTimeStart   = 0x91
+	idChapterTimeEnd     = 0x92
+	idChapterFlagHidden  = 0x98
+	idChapterFlagEnabled = 0x4598
+	idChapterDisplay     = 0x80
+	idChapString         = 0x85
+)
+
+// maxChapterDepth bounds how deeply ChapterAtoms are followed into one
+// another.
+const maxChapterDepth = 8
+
+// parseChapters fills file.Chapters from the default EditionEntry, or the
+// first when none is flagged default.
+//
+// Hidden and disabled chapters are left out. A ChapterAtom nested in
+// another becomes one of its Children in file.ChapterTree, and
+// file.Chapters holds the innermost ones. A chapter without an end time
+// ends where the next one starts, or with its parent or the file.
+func parseChapters(sr *binutil.SafeReader, chapters element, file *types.File) error {
+	var edition element
+	err := eachChild(sr, chapters.offset, chapters.end(sr.Size()), func(el element) error {
+		if el.id != idEditionEntry {
+			return nil
+		}
+		isDefault := false
+		err := eachChild(sr, el.offset, el.end(sr.Size()), func(flag element) error {
+			if flag.id != idEditionFlagDefault {
+				return nil
+			}
+			v, err := readUint(sr, flag)
+			isDefault = v == 1
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if edition.id == 0 || isDefault {
+			edition = el
+		}
+		if isDefault {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return err
+	}
+	if edition.id == 0 {
+		return nil
+	}
+
+	list, err := parseChapterAtoms(sr, edition, 0)
+	fillChapterEnds(list, file.Audio.Duration)
+	file.Chapters = list
+	if types.IsChapterTree(list) {
+		file.ChapterTree = list
+		file.Chapters = types.ChapterLeaves(list)
+	}
+	return err
+}
+
+// parseChapterAtoms returns the visible chapters among the ChapterAtoms
+// directly under parent, indexed from 1.
+func parseChapterAtoms(sr *binutil.SafeReader, parent element, depth int) ([]types.Chapter, error) {
+	var list []types.Chapter
+	err := eachChild(sr, parent.offset, parent.end(sr.Size()), func(el element) error {
+		if el.id != idChapterAtom {
+			return nil
+		}
+		chapter, visible, err := parseChapterAtom(sr, el, depth)
+		if err != nil {
+			return err
+		}
+		if visible {
+			chapter.Index = len(list) + 1
+			list = append(list, chapter)
+		}
+		return nil
+	})
+	return list, err
+}
+
+// fillChapterEnds gives each chapter without an end time the start of the
+// one after it, or end for the last, then does the same for its children
+// within it.
+func fillChapterEnds(list []types.Chapter, end time.Duration) {
+	for i := range list {
+		if list[i].EndTime <= list[i].StartTime {
+			if i+1 < len(list) {
+				list[i].EndTime = list[i+1].StartTime
+			} else {
+				list[i].EndTime = max(end, list[i].StartTime)
+			}
+		}
+		fillChapterEnds(list[i].Children, list[i].EndTime)
+	}
+}
+
+// parseChapterAtom reads one ChapterAtom, reporting whether it is shown.
+// Its title is the first ChapterDisplay's ChapString.
+//
+// ChapterTimeStart and ChapterTimeEnd are in nanoseconds, unaffected by
+// TimecodeScale.
+func parseChapterAtom(sr *binutil.SafeReader, atom element, depth int) (chapter types.Chapter, visible bool, err error) {
+	visible = true
+	nested := false
+	err = eachChild(sr, atom.offset, atom.end(sr.Size()), func(el element) error {
+		var v uint64
+		var err error
+		switch el.id {
+		case idChapterTimeStart:
+			if v, err = readUint(sr, el); err == nil {
+				chapter.StartTime = nanoseconds(v)
+			}
+		case idChapterTimeEnd:
+			if v, err = readUint(sr, el); err == nil {
+				chapter.EndTime = nanoseconds(v)
+			}
+		case idChapterFlagHidden:
+			if v, err = readUint(sr, el); err == nil && v == 1 {
+				visible = false
+			}
+		case idChapterFlagEnabled:
+			if v, err = readUint(sr, el); err == nil && v == 0 {
+				visible = false
+			}
+		case idChapterDisplay:
+			if chapter.Title == "" {
+				chapter.Title, err = readChapString(sr, el)
+			}
+		case idChapterAtom:
+			nested = true
+		}
+		return err
+	})
+	if err != nil || !nested || depth >= maxChapterDepth {
+		return chapter, visible, err
+	}
+
+	chapter.Children, err = parseChapterAtoms(sr, atom, depth+1)
+	return chapter, visible, err
+}
+
+// readChapString returns the ChapString of a ChapterDisplay.
+func readChapString(sr *binutil.SafeReader, display element) (string, error) {
+	var title string
+	err := eachChild(sr, display.offset, display.end(sr.Size()), func(el element) error {
+		var err error
+		if el.id == idChapString && title == "" {
+			title, err = readString(sr, el)
+		}
+		return err
+	})
+	return title, err
+}
+
+// nanoseconds converts a chapter time, clamping it to time.Duration.
+func nanoseconds(v uint64) time.Duration {
+	if v > 1<<63-1 {
+		return 1<<63 - 1
+	}
+	return time.Duration(v)
+}
@@ -0,0 +1,153 @@
+package mkv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+)
+
+// maxStringSize bounds string and binary elements read into memory. Tag
+// values and chapter names are far smaller; anything larger is corrupt.
+const maxStringSize = 1 << 20
+
+// element is an EBML element header.
+//
+// Element layout:
+//
+//	[1-4 bytes] ID, a variable-length integer kept with its length marker
+//	[1-8 bytes] payload size, a variable-length integer (all ones: unknown)
+//	[n bytes]   payload
+type element struct {
+	id     uint32
+	offset int64 // Start of the payload
+	size   int64 // Payload size, -1 if unknown
+}
+
+// end returns where the payload ends, clamped to limit, the end of its
+// parent. An element of unknown size runs to limit.
+func (e element) end(limit int64) int64 {
+	if e.size < 0 {
+		return limit
+	}
+	return min(e.offset+e.size, limit)
+}
+
+// readElement reads the element header at offset.
+func readElement(sr *binutil.SafeReader, offset int64) (element, error) {
+	if sr.Size()-offset < 2 {
+		return element{}, fmt.Errorf("EBML element at offset %d: truncated header", offset)
+	}
+	header := make([]byte, min(12, sr.Size()-offset))
+	if err := sr.ReadAt(header, offset, "EBML element header"); err != nil {
+		return element{}, err
+	}
+
+	idLen := bits.LeadingZeros8(header[0]) + 1
+	if idLen > 4 || idLen >= len(header) {
+		return element{}, fmt.Errorf("EBML element at offset %d: invalid ID", offset)
+	}
+	var id uint32
+	for _, b := range header[:idLen] {
+		id = id<<8 | uint32(b)
+	}
+
+	sizeLen := bits.LeadingZeros8(header[idLen]) + 1
+	if sizeLen > 8 || idLen+sizeLen > len(header) {
+		return element{}, fmt.Errorf("EBML element 0x%X at offset %d: invalid size", id, offset)
+	}
+	size := uint64(header[idLen]) & (0xFF >> sizeLen)
+	unknown := size == 0xFF>>sizeLen
+	for _, b := range header[idLen+1 : idLen+sizeLen] {
+		size = size<<8 | uint64(b)
+		unknown = unknown && b == 0xFF
+	}
+
+	el := element{id: id, offset: offset + int64(idLen+sizeLen), size: -1}
+	if !unknown {
+		if size > math.MaxInt64/2 {
+			return element{}, fmt.Errorf("EBML element 0x%X at offset %d: size %d out of range", id, offset, size)
+		}
+		el.size = int64(size)
+	}
+	return el, nil
+}
+
+// eachChild calls fn for every child of the payload from offset to end,
+// stopping at the first error. A child of unknown size ends the walk after
+// fn, since where the next one starts can't be told.
+func eachChild(sr *binutil.SafeReader, offset, end int64, fn func(element) error) error {
+	for offset < end {
+		el, err := readElement(sr, offset)
+		if err != nil {
+			return err
+		}
+		if err := fn(el); err != nil {
+			return err
+		}
+		if el.size < 0 {
+			return nil
+		}
+		offset = el.offset + el.size
+	}
+	return nil
+}
+
+// readPayload reads the payload of a string, binary or number element.
+func readPayload(sr *binutil.SafeReader, el element) ([]byte, error) {
+	if el.size < 0 || el.size > maxStringSize {
+		return nil, fmt.Errorf("EBML element 0x%X: payload size %d out of range", el.id, el.size)
+	}
+	data := make([]byte, el.size)
+	if err := sr.ReadAt(data, el.offset, "EBML element payload"); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readUint reads an unsigned integer element of up to 8 bytes.
+func readUint(sr *binutil.SafeReader, el element) (uint64, error) {
+	if el.size > 8 {
+		return 0, fmt.Errorf("EBML element 0x%X: %d-byte integer", el.id, el.size)
+	}
+	data, err := readPayload(sr, el)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// readFloat reads a 4- or 8-byte float element. An empty one is 0.
+func readFloat(sr *binutil.SafeReader, el element) (float64, error) {
+	data, err := readPayload(sr, el)
+	if err != nil {
+		return 0, err
+	}
+	switch len(data) {
+	case 0:
+		return 0, nil
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	default:
+		return 0, fmt.Errorf("EBML element 0x%X: %d-byte float", el.id, len(data))
+	}
+}
+
+// readString reads a string or UTF-8 element, which may be padded with
+// trailing NULs.
+func readString(sr *binutil.SafeReader, el element) (string, error) {
+	data, err := readPayload(sr, el)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\x00"), nil
+}
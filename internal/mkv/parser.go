@@ -0,0 +1,486 @@
+// Package mkv provides Matroska (.mka) audio file parsing.
+package mkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Container names, by EBML DocType.
+const (
+	containerMatroska = "Matroska"
+	containerWebM     = "WebM"
+)
+
+// EBML header and top-level element IDs.
+const (
+	idEBML     = 0x1A45DFA3
+	idDocType  = 0x4282
+	idSegment  = 0x18538067
+	idSeekHead = 0x114D9B74
+	idInfo     = 0x1549A966
+	idTracks   = 0x1654AE6B
+	idTags     = 0x1254C367
+	idChapters = 0x1043A770
+	idCluster  = 0x1F43B675
+)
+
+// SeekHead element IDs.
+const (
+	idSeek         = 0x4DBB
+	idSeekID       = 0x53AB
+	idSeekPosition = 0x53AC
+)
+
+// Info element IDs.
+const (
+	idTimecodeScale = 0x2AD7B1
+	idDuration      = 0x4489
+	idTitle         = 0x7BA9
+)
+
+// Tracks element IDs.
+const (
+	idTrackEntry        = 0xAE
+	idTrackType         = 0x83
+	idCodecID           = 0x86
+	idAudio             = 0xE1
+	idSamplingFrequency = 0xB5
+	idChannels          = 0x9F
+	idBitDepth          = 0x6264
+)
+
+// trackTypeAudio is the TrackType of an audio track.
+const trackTypeAudio = 2
+
+// defaultTimecodeScale is the TimecodeScale, in nanoseconds per tick, of
+// a segment that does not state one.
+const defaultTimecodeScale = 1_000_000
+
+// errStopWalk ends a walk over the Segment's children early.
+var errStopWalk = errors.New("stop walk")
+
+// sections holds the top-level Segment children the parser reads, each
+// the first of its kind. A zero element means the section was not found.
+type sections struct {
+	info, tracks, tags, chapters element
+}
+
+// parser implements the audiometa.FormatParser interface for Matroska files.
+type parser struct{}
+
+// Parse parses a Matroska or WebM file and extracts its metadata, audio
+// properties and chapters.
+//
+// Only the EBML header and the Segment's Info, Tracks, Tags and Chapters
+// elements are read; Clusters of media data are skipped, using the
+// SeekHead index to find sections stored after them.
+func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path string) (*types.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+
+	header, err := readElement(sr, 0)
+	if err != nil || header.id != idEBML {
+		return nil, &types.CorruptedFileError{
+			Path:   path,
+			Reason: "missing EBML header",
+		}
+	}
+	docType, err := readDocType(sr, header)
+	if err != nil {
+		return nil, err
+	}
+	container := containerMatroska
+	switch docType {
+	case "matroska":
+	case "webm":
+		container = containerWebM
+	default:
+		return nil, &types.UnsupportedFormatError{
+			Path:   path,
+			Reason: fmt.Sprintf("EBML document type %q is not Matroska", docType),
+		}
+	}
+
+	segment, err := readElement(sr, header.end(size))
+	if err != nil || segment.id != idSegment {
+		return nil, &types.CorruptedFileError{
+			Path:   path,
+			Offset: header.end(size),
+			Reason: "missing Matroska Segment",
+		}
+	}
+
+	file := &types.File{
+		Path:   path,
+		Format: types.FormatMKA,
+		Size:   size,
+	}
+	file.Audio.Container = container
+
+	if registry.RawTagsEnabled(ctx) {
+		file.EnableRawTags()
+	}
+
+	found, err := findSections(ctx, sr, segment)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "metadata",
+			Message:  fmt.Sprintf("failed to read Matroska Segment: %v", err),
+			Severity: types.SeverityError,
+			Err:      err,
+		})
+	}
+
+	var infoTitle string
+	if found.info.id != 0 {
+		if infoTitle, err = parseInfo(sr, found.info, file); err != nil {
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "technical",
+				Message: fmt.Sprintf("failed to read Matroska Info: %v", err),
+				Err:     err,
+				Offset:  found.info.offset,
+			})
+		}
+	}
+
+	if found.tracks.id != 0 {
+		if err := parseTracks(sr, found.tracks, file); err != nil {
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "technical",
+				Message: fmt.Sprintf("failed to read Matroska Tracks: %v", err),
+				Err:     err,
+				Offset:  found.tracks.offset,
+			})
+		}
+	}
+	if seconds := file.Audio.Duration.Seconds(); seconds > 0 {
+		file.Audio.Bitrate = int(float64(size) * 8 / seconds)
+	}
+
+	if found.tags.id != 0 {
+		if err := parseTags(sr, found.tags, file); err != nil {
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "metadata",
+				Message: fmt.Sprintf("failed to read Matroska Tags: %v", err),
+				Err:     err,
+				Offset:  found.tags.offset,
+			})
+		}
+	}
+	// The Segment Info title stands in for a missing track title
+	if file.Tags.Title == "" {
+		file.Tags.Title = infoTitle
+	}
+
+	if found.chapters.id != 0 {
+		if err := parseChapters(sr, found.chapters, file); err != nil {
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "chapters",
+				Message: fmt.Sprintf("failed to read Matroska Chapters: %v", err),
+				Err:     err,
+				Offset:  found.chapters.offset,
+			})
+		}
+		if len(file.Chapters) > 0 {
+			file.ChapterSource = "mkv:chapters"
+		}
+	}
+
+	return file, nil
+}
+
+// readDocType returns the DocType of the EBML header, "matroska" when it
+// is absent as the specification's default.
+func readDocType(sr *binutil.SafeReader, header element) (string, error) {
+	docType := "matroska"
+	err := eachChild(sr, header.offset, header.end(sr.Size()), func(el element) error {
+		if el.id != idDocType {
+			return nil
+		}
+		value, err := readString(sr, el)
+		if err != nil {
+			return err
+		}
+		docType = value
+		return errStopWalk
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return "", &types.CorruptedFileError{
+			Path:   sr.Path(),
+			Reason: fmt.Sprintf("invalid EBML header: %v", err),
+		}
+	}
+	return docType, nil
+}
+
+// findSections locates the Segment children the parser reads.
+//
+// The children are walked in order until the first Cluster, when a
+// SeekHead has been seen, or an element of unknown size, whose end can't
+// be told. Sections not reached are then looked up in the SeekHead, whose
+// positions are relative to the start of the Segment's payload.
+func findSections(ctx context.Context, sr *binutil.SafeReader, segment element) (sections, error) {
+	var found sections
+	seeks := make(map[uint32]int64)
+	end := segment.end(sr.Size())
+	log := registry.Logger(ctx)
+
+	err := eachChild(sr, segment.offset, end, func(el element) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if log.Enabled(ctx, slog.LevelDebug) {
+			log.LogAttrs(ctx, slog.LevelDebug, "element",
+				slog.String("format", "Matroska"), slog.String("key", fmt.Sprintf("%X", el.id)),
+				slog.Int64("offset", el.offset), slog.Int64("size", el.size))
+		}
+		switch el.id {
+		case idInfo:
+			found.info = firstElement(found.info, el)
+		case idTracks:
+			found.tracks = firstElement(found.tracks, el)
+		case idTags:
+			found.tags = firstElement(found.tags, el)
+		case idChapters:
+			found.chapters = firstElement(found.chapters, el)
+		case idSeekHead:
+			if err := parseSeekHead(sr, el, seeks); err != nil {
+				return err
+			}
+		case idCluster:
+			if len(seeks) > 0 {
+				return errStopWalk
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errStopWalk) {
+		err = nil
+	}
+
+	for _, target := range []struct {
+		id uint32
+		el *element
+	}{
+		{idInfo, &found.info},
+		{idTracks, &found.tracks},
+		{idTags, &found.tags},
+		{idChapters, &found.chapters},
+	} {
+		position, ok := seeks[target.id]
+		if target.el.id != 0 || !ok {
+			continue
+		}
+		el, seekErr := readElement(sr, segment.offset+position)
+		if seekErr == nil && el.id == target.id {
+			*target.el = el
+		}
+	}
+
+	return found, err
+}
+
+// firstElement returns current if it was already found, else el, so that
+// the first of each section wins.
+func firstElement(current, el element) element {
+	if current.id != 0 {
+		return current
+	}
+	return el
+}
+
+// parseSeekHead records the position of each element a SeekHead indexes,
+// keyed by element ID.
+//
+// Seek layout: SeekID, the indexed element's ID as binary, and
+// SeekPosition, its offset from the start of the Segment's payload.
+func parseSeekHead(sr *binutil.SafeReader, seekHead element, seeks map[uint32]int64) error {
+	return eachChild(sr, seekHead.offset, seekHead.end(sr.Size()), func(seek element) error {
+		if seek.id != idSeek {
+			return nil
+		}
+		var id uint32
+		position := int64(-1)
+		err := eachChild(sr, seek.offset, seek.end(sr.Size()), func(el element) error {
+			switch el.id {
+			case idSeekID:
+				data, err := readPayload(sr, el)
+				if err != nil {
+					return err
+				}
+				for _, b := range data {
+					id = id<<8 | uint32(b)
+				}
+			case idSeekPosition:
+				v, err := readUint(sr, el)
+				if err != nil {
+					return err
+				}
+				if v < uint64(sr.Size()) {
+					position = int64(v)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if _, seen := seeks[id]; id != 0 && position >= 0 && !seen {
+			seeks[id] = position
+		}
+		return nil
+	})
+}
+
+// parseInfo fills file.Audio.Duration from the Info element and returns
+// the segment title.
+//
+// Duration is a float in ticks of TimecodeScale nanoseconds.
+func parseInfo(sr *binutil.SafeReader, info element, file *types.File) (string, error) {
+	scale := uint64(defaultTimecodeScale)
+	var duration float64
+	var title string
+
+	err := eachChild(sr, info.offset, info.end(sr.Size()), func(el element) error {
+		var err error
+		switch el.id {
+		case idTimecodeScale:
+			var v uint64
+			if v, err = readUint(sr, el); err == nil && v > 0 {
+				scale = v
+			}
+		case idDuration:
+			duration, err = readFloat(sr, el)
+		case idTitle:
+			title, err = readString(sr, el)
+		}
+		return err
+	})
+
+	if ns := duration * float64(scale); ns > 0 && ns < float64(1<<63) {
+		file.Audio.Duration = time.Duration(ns)
+	}
+	return title, err
+}
+
+// parseTracks fills file.Audio from the first audio TrackEntry.
+func parseTracks(sr *binutil.SafeReader, tracks element, file *types.File) error {
+	err := eachChild(sr, tracks.offset, tracks.end(sr.Size()), func(entry element) error {
+		if entry.id != idTrackEntry {
+			return nil
+		}
+		var trackType uint64
+		var codecID string
+		var audio element
+		err := eachChild(sr, entry.offset, entry.end(sr.Size()), func(el element) error {
+			var err error
+			switch el.id {
+			case idTrackType:
+				trackType, err = readUint(sr, el)
+			case idCodecID:
+				codecID, err = readString(sr, el)
+			case idAudio:
+				audio = el
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if trackType != trackTypeAudio {
+			return nil
+		}
+
+		file.Audio.Codec, file.Audio.Lossless = codecName(codecID)
+		file.Audio.CodecDescription = codecID
+		if audio.id != 0 {
+			if err := parseAudio(sr, audio, &file.Audio); err != nil {
+				return err
+			}
+		}
+		return errStopWalk
+	})
+	if errors.Is(err, errStopWalk) {
+		return nil
+	}
+	return err
+}
+
+// parseAudio reads the sample rate, channel count and bit depth of a
+// track's Audio element. Channels defaults to 1 when not stated.
+func parseAudio(sr *binutil.SafeReader, audio element, info *types.AudioInfo) error {
+	info.Channels = 1
+	return eachChild(sr, audio.offset, audio.end(sr.Size()), func(el element) error {
+		switch el.id {
+		case idSamplingFrequency:
+			rate, err := readFloat(sr, el)
+			if err != nil {
+				return err
+			}
+			if rate > 0 && rate < 1<<31 {
+				info.SampleRate = int(rate)
+			}
+		case idChannels:
+			channels, err := readUint(sr, el)
+			if err != nil {
+				return err
+			}
+			if channels > 0 && channels <= 255 {
+				info.Channels = int(channels)
+			}
+		case idBitDepth:
+			depth, err := readUint(sr, el)
+			if err != nil {
+				return err
+			}
+			if depth <= 64 {
+				info.BitDepth = int(depth)
+			}
+		}
+		return nil
+	})
+}
+
+// codecName maps a Matroska CodecID to the codec name used across
+// formats, and reports whether the codec is lossless. Unknown codecs keep
+// their CodecID.
+func codecName(codecID string) (name string, lossless bool) {
+	switch {
+	case codecID == "A_OPUS":
+		return "Opus", false
+	case codecID == "A_VORBIS":
+		return "Vorbis", false
+	case codecID == "A_FLAC":
+		return "FLAC", true
+	case codecID == "A_ALAC":
+		return "ALAC", true
+	case strings.HasPrefix(codecID, "A_AAC"):
+		return "AAC", false
+	case strings.HasPrefix(codecID, "A_MPEG/L3"):
+		return "MP3", false
+	case strings.HasPrefix(codecID, "A_PCM/"):
+		return "PCM", true
+	default:
+		return codecID, false
+	}
+}
+
+func init() {
+	registry.Register(types.FormatMKA, &parser{})
+}
@@ -0,0 +1,314 @@
+package mkv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// ebml builds an element from its ID and the concatenated payloads,
+// with a one-byte size when it fits and an eight-byte one otherwise.
+func ebml(id uint32, payloads ...[]byte) []byte {
+	payload := bytes.Join(payloads, nil)
+	buf := &bytes.Buffer{}
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || buf.Len() > 0 {
+			buf.WriteByte(b)
+		}
+	}
+	if len(payload) < 0x7F {
+		buf.WriteByte(0x80 | byte(len(payload)))
+	} else {
+		size := make([]byte, 8)
+		binary.BigEndian.PutUint64(size, uint64(len(payload)))
+		size[0] = 0x01
+		buf.Write(size)
+	}
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// unsized builds an element of unknown size.
+func unsized(id uint32, payloads ...[]byte) []byte {
+	el := ebml(id)
+	el[len(el)-1] = 0xFF
+	return append(el, bytes.Join(payloads, nil)...)
+}
+
+func ebmlUint(id uint32, v uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	return ebml(id, data)
+}
+
+func ebmlFloat(id uint32, v float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(v))
+	return ebml(id, data)
+}
+
+func ebmlString(id uint32, s string) []byte {
+	return ebml(id, []byte(s))
+}
+
+// createMKA builds a Matroska file holding the given Segment children.
+func createMKA(docType string, children ...[]byte) []byte {
+	header := ebml(idEBML, ebmlString(idDocType, docType))
+	return append(header, ebml(idSegment, children...)...)
+}
+
+func simpleTag(name, value string) []byte {
+	return ebml(idSimpleTag, ebmlString(idTagName, name), ebmlString(idTagString, value))
+}
+
+func chapterAtom(title string, start, end time.Duration, children ...[]byte) []byte {
+	payloads := [][]byte{
+		ebmlUint(idChapterTimeStart, uint64(start)),
+		ebml(idChapterDisplay, ebmlString(idChapString, title)),
+	}
+	if end > 0 {
+		payloads = append(payloads, ebmlUint(idChapterTimeEnd, uint64(end)))
+	}
+	return ebml(idChapterAtom, append(payloads, children...)...)
+}
+
+func parse(t *testing.T, data []byte) *types.File {
+	t.Helper()
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mka")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return file
+}
+
+func TestParse(t *testing.T) {
+	data := createMKA("matroska",
+		ebml(idInfo,
+			ebmlUint(idTimecodeScale, 1_000_000),
+			ebmlFloat(idDuration, 600_000),
+			ebmlString(idTitle, "Segment Title"),
+		),
+		ebml(idTracks,
+			ebml(idTrackEntry, ebmlUint(idTrackType, 1), ebmlString(idCodecID, "V_VP9")),
+			ebml(idTrackEntry,
+				ebmlUint(idTrackType, trackTypeAudio),
+				ebmlString(idCodecID, "A_OPUS"),
+				ebml(idAudio, ebmlFloat(idSamplingFrequency, 48000), ebmlUint(idChannels, 2)),
+			),
+		),
+		ebml(idTags,
+			ebml(idTag,
+				ebml(idTargets, ebmlUint(idTargetTypeValue, targetAlbum)),
+				simpleTag("TITLE", "The Album"),
+				simpleTag("ARTIST", "Album Artist"),
+				simpleTag("TOTAL_PARTS", "12"),
+			),
+			ebml(idTag,
+				ebml(idTargets, ebmlUint(idTargetTypeValue, targetTrack)),
+				simpleTag("TITLE", "The Track"),
+				simpleTag("ARTIST", "Track Artist"),
+				simpleTag("PART_NUMBER", "3"),
+				simpleTag("DATE_RELEASED", "2021-05-01"),
+				simpleTag("GENRE", "Jazz"),
+				simpleTag("Custom", "value"),
+			),
+		),
+		ebml(idChapters,
+			ebml(idEditionEntry,
+				chapterAtom("Discarded Edition", 0, 0),
+			),
+			ebml(idEditionEntry,
+				ebmlUint(idEditionFlagDefault, 1),
+				chapterAtom("Intro", 0, 0),
+				ebml(idChapterAtom,
+					ebmlUint(idChapterTimeStart, uint64(30*time.Second)),
+					ebmlUint(idChapterFlagHidden, 1),
+				),
+				chapterAtom("Part One", time.Minute, 0,
+					chapterAtom("Scene A", time.Minute, 0),
+					chapterAtom("Scene B", 5*time.Minute, 0),
+				),
+			),
+		),
+	)
+
+	file := parse(t, data)
+
+	if file.Format != types.FormatMKA {
+		t.Errorf("Format = %v, want FormatMKA", file.Format)
+	}
+	audio := file.Audio
+	if audio.Codec != "Opus" || audio.Container != containerMatroska {
+		t.Errorf("Codec, Container = %q, %q; want Opus, Matroska", audio.Codec, audio.Container)
+	}
+	if audio.SampleRate != 48000 || audio.Channels != 2 {
+		t.Errorf("SampleRate, Channels = %d, %d; want 48000, 2", audio.SampleRate, audio.Channels)
+	}
+	if audio.Duration != 10*time.Minute {
+		t.Errorf("Duration = %v, want 10m", audio.Duration)
+	}
+	if audio.Bitrate == 0 {
+		t.Error("Bitrate = 0, want it estimated from the duration")
+	}
+
+	tags := file.Tags
+	if tags.Title != "The Track" || tags.Artist != "Track Artist" {
+		t.Errorf("Title, Artist = %q, %q", tags.Title, tags.Artist)
+	}
+	if tags.Album != "The Album" || tags.AlbumArtist != "Album Artist" {
+		t.Errorf("Album, AlbumArtist = %q, %q", tags.Album, tags.AlbumArtist)
+	}
+	if tags.Date != "2021-05-01" || tags.Year != 2021 {
+		t.Errorf("Date, Year = %q, %d", tags.Date, tags.Year)
+	}
+	if tags.TrackNumber != 3 || tags.TrackTotal != 12 {
+		t.Errorf("TrackNumber, TrackTotal = %d, %d; want 3, 12", tags.TrackNumber, tags.TrackTotal)
+	}
+	if len(tags.Genres) != 1 || tags.Genres[0] != "Jazz" {
+		t.Errorf("Genres = %v, want [Jazz]", tags.Genres)
+	}
+	if got := tags.GetFirst("Custom"); got != "value" {
+		t.Errorf("raw Custom = %q, want value", got)
+	}
+
+	if file.ChapterSource != "mkv:chapters" {
+		t.Errorf("ChapterSource = %q, want mkv:chapters", file.ChapterSource)
+	}
+	if len(file.ChapterTree) != 2 {
+		t.Fatalf("len(ChapterTree) = %d, want 2 from the default edition", len(file.ChapterTree))
+	}
+	intro, part := file.ChapterTree[0], file.ChapterTree[1]
+	if intro.Title != "Intro" || intro.Index != 1 || intro.EndTime != time.Minute {
+		t.Errorf("ChapterTree[0] = %+v, want Intro ending where Part One starts", intro)
+	}
+	if part.Title != "Part One" || part.Index != 2 || part.EndTime != 10*time.Minute {
+		t.Errorf("ChapterTree[1] = %+v, want Part One ending with the file", part)
+	}
+	if len(part.Children) != 2 {
+		t.Fatalf("len(ChapterTree[1].Children) = %d, want 2", len(part.Children))
+	}
+	if scene := part.Children[1]; scene.Title != "Scene B" || scene.Index != 2 || scene.EndTime != 10*time.Minute {
+		t.Errorf("Children[1] = %+v, want Scene B ending with its parent", scene)
+	}
+
+	// Chapters holds the leaves, indexed across the whole file
+	var titles []string
+	for i, ch := range file.Chapters {
+		if ch.Index != i+1 || len(ch.Children) != 0 {
+			t.Errorf("Chapters[%d] = %+v, want Index %d and no Children", i, ch, i+1)
+		}
+		titles = append(titles, ch.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Intro, Scene A, Scene B" {
+		t.Errorf("Chapters = %s, want Intro, Scene A, Scene B", got)
+	}
+}
+
+func TestParse_SeekHead(t *testing.T) {
+	info := ebml(idInfo, ebmlFloat(idDuration, 2000))
+	cluster := ebml(idCluster, make([]byte, 64))
+	tags := ebml(idTags, ebml(idTag, simpleTag("TITLE", "After The Clusters")))
+
+	seekHead := func(tagsPosition uint64) []byte {
+		return ebml(idSeekHead, ebml(idSeek,
+			ebml(idSeekID, []byte{0x12, 0x54, 0xC3, 0x67}),
+			ebmlUint(idSeekPosition, tagsPosition),
+		))
+	}
+	// The SeekHead's own length doesn't depend on the position it holds
+	head := seekHead(0)
+	head = seekHead(uint64(len(head) + len(info) + len(cluster)))
+
+	// The cluster hides nothing here, but the walk stops at it once a
+	// SeekHead has been seen, so Tags can only be found by seeking
+	data := createMKA("webm", head, info, cluster, tags)
+	file := parse(t, data)
+
+	if file.Audio.Container != containerWebM {
+		t.Errorf("Container = %q, want WebM", file.Audio.Container)
+	}
+	if file.Audio.Duration != 2*time.Second {
+		t.Errorf("Duration = %v, want 2s", file.Audio.Duration)
+	}
+	if file.Tags.Album != "After The Clusters" {
+		t.Errorf("Album = %q, want the tag found through the SeekHead", file.Tags.Album)
+	}
+	if file.Tags.Title != "" {
+		t.Errorf("Title = %q, want none from album-level tags", file.Tags.Title)
+	}
+}
+
+func TestParse_UnknownSize(t *testing.T) {
+	// Live recordings leave the Segment and Clusters unsized
+	header := ebml(idEBML, ebmlString(idDocType, "matroska"))
+	data := append(header, unsized(idSegment,
+		ebml(idInfo, ebmlString(idTitle, "Live")),
+		unsized(idCluster, make([]byte, 32)),
+	)...)
+
+	file := parse(t, data)
+	if file.Tags.Title != "Live" {
+		t.Errorf("Title = %q, want the segment title", file.Tags.Title)
+	}
+	if len(file.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", file.Warnings)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            []byte
+		wantUnsupported bool
+	}{
+		{"not EBML", []byte("OggS\x00\x02\x00\x00"), false},
+		{"no segment", ebml(idEBML, ebmlString(idDocType, "matroska")), false},
+		{"other document type", createMKA("other"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &parser{}
+			_, err := p.Parse(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "test.mka")
+			if err == nil {
+				t.Fatal("Parse() error = nil, want an error")
+			}
+			var unsupported *types.UnsupportedFormatError
+			if errors.As(err, &unsupported) != tt.wantUnsupported {
+				t.Errorf("Parse() error = %v, unsupported format = %v", err, !tt.wantUnsupported)
+			}
+		})
+	}
+}
+
+func TestCodecName(t *testing.T) {
+	tests := []struct {
+		codecID      string
+		wantName     string
+		wantLossless bool
+	}{
+		{"A_OPUS", "Opus", false},
+		{"A_AAC", "AAC", false},
+		{"A_AAC/MPEG4/LC", "AAC", false},
+		{"A_FLAC", "FLAC", true},
+		{"A_VORBIS", "Vorbis", false},
+		{"A_MPEG/L3", "MP3", false},
+		{"A_PCM/INT/LIT", "PCM", true},
+		{"A_TRUEHD", "A_TRUEHD", false},
+	}
+
+	for _, tt := range tests {
+		name, lossless := codecName(tt.codecID)
+		if name != tt.wantName || lossless != tt.wantLossless {
+			t.Errorf("codecName(%q) = %q, %v; want %q, %v", tt.codecID, name, lossless, tt.wantName, tt.wantLossless)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package mkv
+
+import (
+	"strconv"
+	"strings"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/parsing"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Tags element IDs.
+const (
+	idTag             = 0x7373
+	idTargets         = 0x63C0
+	idTargetTypeValue = 0x68CA
+	idSimpleTag       = 0x67C8
+	idTagName         = 0x45A3
+	idTagString       = 0x4487
+)
+
+// Tag target levels. A Tag applies to the level of its Targets: a track
+// (30) or the album holding it (50, the default).
+const (
+	targetTrack = 30
+	targetAlbum = 50
+)
+
+// parseTags maps the SimpleTags of each Tag into file.Tags.
+//
+// TITLE and ARTIST name the track at track level and the album at album
+// level; other names mean the same at any level. Every tag is also kept
+// in the raw tags under its name.
+func parseTags(sr *binutil.SafeReader, tags element, file *types.File) error {
+	return eachChild(sr, tags.offset, tags.end(sr.Size()), func(tag element) error {
+		if tag.id != idTag {
+			return nil
+		}
+
+		level := uint64(targetAlbum)
+		var simpleTags []element
+		err := eachChild(sr, tag.offset, tag.end(sr.Size()), func(el element) error {
+			switch el.id {
+			case idTargets:
+				return eachChild(sr, el.offset, el.end(sr.Size()), func(target element) error {
+					if target.id != idTargetTypeValue {
+						return nil
+					}
+					v, err := readUint(sr, target)
+					if err == nil && v > 0 {
+						level = v
+					}
+					return err
+				})
+			case idSimpleTag:
+				simpleTags = append(simpleTags, el)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, simpleTag := range simpleTags {
+			name, value, err := readSimpleTag(sr, simpleTag)
+			if err != nil {
+				return err
+			}
+			if name != "" && value != "" {
+				applyTag(name, value, level, file)
+			}
+		}
+		return nil
+	})
+}
+
+// readSimpleTag returns the name and string value of a SimpleTag. Binary
+// values and the SimpleTags nested under it are not read.
+func readSimpleTag(sr *binutil.SafeReader, simpleTag element) (name, value string, err error) {
+	err = eachChild(sr, simpleTag.offset, simpleTag.end(sr.Size()), func(el element) error {
+		var err error
+		switch el.id {
+		case idTagName:
+			name, err = readString(sr, el)
+		case idTagString:
+			value, err = readString(sr, el)
+		}
+		return err
+	})
+	return name, value, err
+}
+
+// applyTag maps one SimpleTag at the given target level into file.Tags.
+// Names are matched case-insensitively, though Matroska writes them in
+// uppercase.
+func applyTag(name, value string, level uint64, file *types.File) { //nolint:gocyclo // Complexity from many simple field mappings - intentionally kept together
+	tags := &file.Tags
+	track := level <= targetTrack
+
+	switch key := strings.ToUpper(name); key {
+	case "TITLE":
+		if track {
+			tags.Title = value
+		} else if tags.Album == "" {
+			tags.Album = value
+		}
+	case "ARTIST":
+		if track {
+			tags.Artist = value
+			tags.Artists = append(tags.Artists, value)
+		} else if tags.AlbumArtist == "" {
+			tags.AlbumArtist = value
+		}
+	case "SUBTITLE":
+		tags.Subtitle = value
+	case "DATE_RELEASED", "DATE_RECORDED", "DATE":
+		if tags.Date == "" {
+			tags.Date = value
+			if year, err := strconv.Atoi(value[:min(4, len(value))]); err == nil && year > 0 {
+				tags.Year = year
+			}
+		}
+	case "PART_NUMBER":
+		if n, err := strconv.Atoi(value); err == nil {
+			if track {
+				tags.TrackNumber = n
+			} else {
+				tags.DiscNumber = n
+			}
+		}
+	case "TOTAL_PARTS":
+		// The count of parts one level down: tracks of an album, or
+		// albums of a set
+		if n, err := strconv.Atoi(value); err == nil {
+			if level == targetAlbum {
+				tags.TrackTotal = n
+			} else if level > targetAlbum {
+				tags.DiscTotal = n
+			}
+		}
+	case "GENRE":
+		tags.Genres = append(tags.Genres, value)
+	case "COMPOSER":
+		tags.Composers = append(tags.Composers, value)
+	case "LEAD_PERFORMER", "ACCOMPANIMENT":
+		tags.Performers = append(tags.Performers, value)
+	case "COMMENT":
+		tags.Comments = append(tags.Comments, types.Comment{Text: value})
+		if tags.Comment == "" {
+			tags.Comment = value
+		}
+	case "DESCRIPTION", "SYNOPSIS", "SUMMARY":
+		if tags.Description == "" {
+			tags.Description = value
+		}
+	case "LYRICS":
+		tags.Lyrics = value
+	case "READ_BY", "NARRATED_BY":
+		tags.Narrator = value
+	case "PUBLISHER":
+		tags.Publisher = value
+	case "LABEL":
+		tags.Label = value
+	case "COPYRIGHT":
+		tags.Copyright = value
+	case "ENCODER":
+		tags.Encoder = value
+	case "ENCODED_BY":
+		if tags.Encoder == "" {
+			tags.Encoder = value
+		}
+	case "ISRC":
+		tags.ISRC = value
+	case "BARCODE":
+		tags.Barcode = value
+	case "CATALOG_NUMBER":
+		tags.CatalogNumber = value
+	case "ISBN":
+		tags.ISBN = value
+	case "BPM":
+		if bpm := parsing.ParseBPM(value); bpm > 0 {
+			tags.BPM = bpm
+		}
+	case "REPLAYGAIN_GAIN", "REPLAYGAIN_PEAK":
+		// Matroska names ReplayGain by level rather than in the tag name
+		kind := "TRACK"
+		if !track {
+			kind = "ALBUM"
+		}
+		types.SetReplayGain(&file.Audio, "REPLAYGAIN_"+kind+key[len("REPLAYGAIN"):], value)
+	}
+
+	tags.Set(name, value)
+	file.AddRawTag(types.RawTag{Key: name, Encoding: "UTF-8", Value: []byte(value), Type: types.RawTagText})
+}
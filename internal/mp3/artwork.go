@@ -2,10 +2,15 @@ package mp3
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"io"
+	"math"
 
 	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/imagesize"
+	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -21,9 +26,15 @@ const (
 	mimePNG  = "image/png"
 )
 
+// apicHeaderProbe is how much of an APIC frame is read when image data is
+// not wanted: enough for the MIME type, description and the image header
+// that holds its dimensions (PNG IHDR, or a JPEG SOF after EXIF/ICC data).
+const apicHeaderProbe = 64 * 1024
+
 // extractArtwork extracts embedded artwork from MP3 files.
-// Parses ID3v2 APIC (Attached Picture) frames.
-func extractArtwork(r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
+// Parses ID3v2 APIC (Attached Picture) frames. Without withData, each frame
+// is read only up to apicHeaderProbe bytes and the returned Data is nil.
+func extractArtwork(ctx context.Context, r io.ReaderAt, size int64, path string, withData bool) ([]types.Artwork, error) {
 	sr := binutil.NewSafeReader(r, size, path)
 
 	// Parse ID3v2 header
@@ -32,6 +43,7 @@ func extractArtwork(r io.ReaderAt, size int64, path string) ([]types.Artwork, er
 		// No ID3v2 tag = no embedded artwork
 		return nil, nil
 	}
+	sr, header = resyncTag(sr, header)
 
 	frameDataOffset := skipExtendedHeader(sr, header)
 	tagEnd := int64(10 + header.Size)
@@ -39,16 +51,45 @@ func extractArtwork(r io.ReaderAt, size int64, path string) ([]types.Artwork, er
 
 	var artwork []types.Artwork
 
+	charset := registry.LegacyCharset(ctx)
+	wholeLimit := int64(math.MaxInt64)
+	if !withData {
+		wholeLimit = apicHeaderProbe
+	} else if limit := registry.MaxArtworkSize(ctx); limit > 0 {
+		// A frame holds more than its image, so one within a probe of the
+		// WithMaxArtworkSize limit may still fit; larger ones are probed
+		wholeLimit = int64(limit) + apicHeaderProbe
+	}
+
 	// Scan through frames looking for APIC
 	for offset < tagEnd {
-		frame, bytesRead, stop := readFrameForArtwork(sr, header, offset)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		frame, bytesRead, stop := readFrameForArtwork(ctx, sr, header, offset, tagEnd, wholeLimit)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if stop {
 			break
 		}
 
 		if frame != nil && frame.ID == "APIC" {
-			art, err := parseAPICFrame(frame.Data)
+			art, err := parseAPICFrame(frame.Data, charset)
 			if err == nil {
+				if !withData || int64(frame.Size) > wholeLimit {
+					// Size the image from the full frame, not the probe
+					art.Size = int(frame.Size) - (len(frame.Data) - art.Size)
+					art.Data = nil
+				}
+				if registry.ArtworkTooLarge(ctx, int64(art.Size)) {
+					art.Data = nil
+				}
+				if frameStoredVerbatim(header, frame.Flags) {
+					// The image ends the frame
+					art = types.WithArtworkLocation(art, offset+10+int64(frame.Size)-int64(art.Size), int64(art.Size))
+				}
 				artwork = append(artwork, art)
 			}
 		}
@@ -59,9 +100,46 @@ func extractArtwork(r io.ReaderAt, size int64, path string) ([]types.Artwork, er
 	return artwork, nil
 }
 
+// countArtwork counts APIC frames by walking ID3v2 frame headers only.
+func countArtwork(r io.ReaderAt, size int64, path string) int {
+	sr := binutil.NewSafeReader(r, size, path)
+
+	header, err := parseID3v2Header(sr)
+	if err != nil {
+		return 0
+	}
+	sr, header = resyncTag(sr, header)
+
+	tagEnd := int64(10 + header.Size)
+	offset := skipExtendedHeader(sr, header)
+	count := 0
+
+	frameHeader := make([]byte, 10)
+	for offset < tagEnd {
+		if err := sr.ReadAt(frameHeader, offset, "frame header"); err != nil || frameHeader[0] == 0 {
+			break
+		}
+
+		frameSize := decodeFrameSize(header.Version, frameHeader[4:8])
+		if frameSize == 0 || frameSize > 100*1024*1024 || frameExceedsTag(offset, frameSize, tagEnd) { // Same limits as readFrameForArtwork
+			break
+		}
+
+		if string(frameHeader[0:4]) == "APIC" {
+			count++
+		}
+
+		offset += 10 + int64(frameSize)
+	}
+
+	return count
+}
+
 // readFrameForArtwork reads a single frame header and data.
-// Similar to readSingleFrame but doesn't need the file parameter.
-func readFrameForArtwork(sr *binutil.SafeReader, header ID3v2Header, offset int64) (*ID3v2Frame, int64, bool) {
+// Similar to readSingleFrame but doesn't need the file parameter. APIC
+// frames over wholeLimit bytes are read only up to apicHeaderProbe; Size
+// still reports the full frame size.
+func readFrameForArtwork(ctx context.Context, sr *binutil.SafeReader, header ID3v2Header, offset, tagEnd, wholeLimit int64) (*ID3v2Frame, int64, bool) {
 	frameHeaderBuf := make([]byte, 10)
 	if err := sr.ReadAt(frameHeaderBuf, offset, "frame header"); err != nil {
 		return nil, 0, true
@@ -75,9 +153,10 @@ func readFrameForArtwork(sr *binutil.SafeReader, header ID3v2Header, offset int6
 	// Parse frame header
 	frameID := string(frameHeaderBuf[0:4])
 	frameSize := decodeFrameSize(header.Version, frameHeaderBuf[4:8])
+	frameFlags := binary.BigEndian.Uint16(frameHeaderBuf[8:10])
 
 	// Sanity check frame size
-	if frameSize == 0 || frameSize > 100*1024*1024 { // 100MB max
+	if frameSize == 0 || frameSize > 100*1024*1024 || frameExceedsTag(offset, frameSize, tagEnd) { // 100MB max, within the tag
 		return nil, 0, true
 	}
 
@@ -87,15 +166,20 @@ func readFrameForArtwork(sr *binutil.SafeReader, header ID3v2Header, offset int6
 	}
 
 	// Read frame data
-	frameData := make([]byte, frameSize)
-	if err := sr.ReadAt(frameData, offset+10, "APIC frame data"); err != nil {
+	dataSize := int64(frameSize)
+	if dataSize > wholeLimit {
+		dataSize = apicHeaderProbe
+	}
+	frameData := make([]byte, dataSize)
+	if err := sr.ReadAtContext(ctx, frameData, offset+10, "APIC frame data"); err != nil {
 		return nil, 10 + int64(frameSize), false
 	}
 
 	frame := &ID3v2Frame{
-		ID:   frameID,
-		Size: frameSize,
-		Data: frameData,
+		ID:    frameID,
+		Size:  frameSize,
+		Flags: frameFlags,
+		Data:  decodeFrameData(frameData, header, frameFlags),
 	}
 
 	return frame, 10 + int64(frameSize), false
@@ -109,7 +193,7 @@ func readFrameForArtwork(sr *binutil.SafeReader, header ID3v2Header, offset int6
 //	[1 byte]              Picture type
 //	[null-terminated]     Description
 //	[remaining]           Picture data
-func parseAPICFrame(data []byte) (types.Artwork, error) {
+func parseAPICFrame(data []byte, charset types.Charset) (types.Artwork, error) {
 	if len(data) < 4 {
 		return types.Artwork{}, errAPICTooShort
 	}
@@ -148,7 +232,7 @@ func parseAPICFrame(data []byte) (types.Artwork, error) {
 	descEnd := findNullTerminator(data[pos:], encoding)
 	description := ""
 	if descEnd >= 0 {
-		description = decodeText(data[pos:pos+descEnd], encoding)
+		description = decodeText(data[pos:pos+descEnd], encoding, charset)
 		pos += descEnd + terminatorSize(encoding)
 	}
 	// If no null terminator was found, treat remaining bytes as image data
@@ -167,7 +251,7 @@ func parseAPICFrame(data []byte) (types.Artwork, error) {
 	}
 
 	// Detect dimensions
-	width, height := detectImageDimensions(imageData, mimeType)
+	width, height := imagesize.Dimensions(imageData, mimeType)
 
 	return types.Artwork{
 		MIMEType:    mimeType,
@@ -176,6 +260,7 @@ func parseAPICFrame(data []byte) (types.Artwork, error) {
 		Type:        types.ArtworkType(pictureType),
 		Width:       width,
 		Height:      height,
+		Size:        len(imageData),
 	}, nil
 }
 
@@ -200,61 +285,3 @@ func detectMIMEType(data []byte) string {
 		return ""
 	}
 }
-
-// detectImageDimensions extracts width/height from image data.
-func detectImageDimensions(data []byte, mimeType string) (int, int) {
-	switch mimeType {
-	case mimeJPEG:
-		return detectJPEGDimensions(data)
-	case mimePNG:
-		return detectPNGDimensions(data)
-	default:
-		return 0, 0
-	}
-}
-
-// detectJPEGDimensions extracts dimensions from JPEG data.
-func detectJPEGDimensions(data []byte) (int, int) {
-	// JPEG structure: markers are 0xFF followed by marker type
-	// SOF markers contain dimensions: SOF0 (0xC0), SOF1 (0xC1), SOF2 (0xC2)
-	for i := range len(data) - 9 {
-		if data[i] != 0xFF {
-			continue
-		}
-
-		marker := data[i+1]
-		// Check for SOF markers (Start Of Frame)
-		if marker == 0xC0 || marker == 0xC1 || marker == 0xC2 {
-			// SOF format: FF Cn [2 bytes length] [1 byte precision] [2 bytes height] [2 bytes width]
-			if i+9 <= len(data) {
-				height := int(data[i+5])<<8 | int(data[i+6])
-				width := int(data[i+7])<<8 | int(data[i+8])
-				return width, height
-			}
-		}
-	}
-	return 0, 0
-}
-
-// detectPNGDimensions extracts dimensions from PNG data.
-func detectPNGDimensions(data []byte) (int, int) {
-	// PNG structure: 8-byte signature + IHDR chunk
-	// IHDR is at bytes 8-24: [4 len] [4 "IHDR"] [4 width] [4 height] [...]
-	if len(data) < 24 {
-		return 0, 0
-	}
-
-	// Verify PNG signature
-	pngSig := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
-	for i := range 8 {
-		if data[i] != pngSig[i] {
-			return 0, 0
-		}
-	}
-
-	// Read IHDR dimensions (big-endian)
-	width := int(data[16])<<24 | int(data[17])<<16 | int(data[18])<<8 | int(data[19])
-	height := int(data[20])<<24 | int(data[21])<<16 | int(data[22])<<8 | int(data[23])
-
-	return width, height
-}
@@ -0,0 +1,131 @@
+package mp3
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// CTOC flags.
+const (
+	tocFlagOrdered  = 0x01 // Children are in playback order
+	tocFlagTopLevel = 0x02 // The root of the table of contents
+)
+
+// tableOfContents is a decoded CTOC frame.
+type tableOfContents struct {
+	elementID string
+	title     string
+	ordered   bool
+	topLevel  bool
+	children  []string // Element IDs of CHAP or nested CTOC frames
+}
+
+// parseCTOCFrame decodes a CTOC frame. ok is false if it is malformed.
+//
+// Format: [element ID\0][flags][entry count][child element ID\0]...
+// then optional subframes, of which TIT2 names the table.
+func parseCTOCFrame(frame ID3v2Frame) (tableOfContents, bool) {
+	data := frame.Data
+	end := bytes.IndexByte(data, 0)
+	if end < 0 || len(data) < end+3 {
+		return tableOfContents{}, false
+	}
+
+	toc := tableOfContents{
+		elementID: string(data[:end]),
+		ordered:   data[end+1]&tocFlagOrdered != 0,
+		topLevel:  data[end+1]&tocFlagTopLevel != 0,
+	}
+	count := int(data[end+2])
+	data = data[end+3:]
+
+	for range count {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return tableOfContents{}, false
+		}
+		toc.children = append(toc.children, string(data[:end]))
+		data = data[end+1:]
+	}
+
+	toc.title, _ = parseChapterSubframes(data, frame.version, frame.charset)
+	return toc, true
+}
+
+// nestChapters arranges chapters as the top-level table of contents lays
+// them out. ids holds the CHAP element ID of each chapter, which arrive
+// sorted by start time. A nested table becomes a chapter spanning its own
+// children. Chapters no table lists follow those that are listed, and
+// ok is false if there is no top-level table.
+func nestChapters(chapters []types.Chapter, ids []string, tocs []tableOfContents) ([]types.Chapter, bool) {
+	root := slices.IndexFunc(tocs, func(toc tableOfContents) bool { return toc.topLevel })
+	if root < 0 {
+		return nil, false
+	}
+
+	byID := make(map[string]types.Chapter, len(chapters))
+	for i, id := range ids {
+		byID[id] = chapters[i]
+	}
+	tocByID := make(map[string]tableOfContents, len(tocs))
+	for _, toc := range tocs {
+		tocByID[toc.elementID] = toc
+	}
+
+	// used also breaks cycles between tables
+	used := map[string]bool{tocs[root].elementID: true}
+	var build func(toc tableOfContents) []types.Chapter
+	build = func(toc tableOfContents) []types.Chapter {
+		var out []types.Chapter
+		for _, id := range toc.children {
+			if used[id] {
+				continue
+			}
+			used[id] = true
+
+			if ch, ok := byID[id]; ok {
+				out = append(out, ch)
+				continue
+			}
+			sub, ok := tocByID[id]
+			if !ok {
+				continue
+			}
+			children := build(sub)
+			if len(children) == 0 {
+				continue
+			}
+			parent := types.Chapter{Title: cmp.Or(sub.title, sub.elementID), Children: children}
+			parent.StartTime = children[0].StartTime
+			for _, c := range children {
+				parent.StartTime = min(parent.StartTime, c.StartTime)
+				parent.EndTime = max(parent.EndTime, c.EndTime)
+			}
+			out = append(out, parent)
+		}
+		return indexChapters(out, toc.ordered)
+	}
+
+	nested := build(tocs[root])
+	for i, id := range ids {
+		if !used[id] {
+			nested = append(nested, chapters[i])
+		}
+	}
+	return indexChapters(nested, tocs[root].ordered), true
+}
+
+// indexChapters numbers chapters from 1, first sorting them by start time
+// unless their table is ordered.
+func indexChapters(chapters []types.Chapter, ordered bool) []types.Chapter {
+	if !ordered {
+		slices.SortStableFunc(chapters, func(a, b types.Chapter) int { return cmp.Compare(a.StartTime, b.StartTime) })
+	}
+	for i := range chapters {
+		chapters[i].Index = i + 1
+	}
+	return chapters
+}
@@ -0,0 +1,87 @@
+package mp3
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/parsing"
+)
+
+// parseGenres resolves the values of a TCON frame to genre names.
+//
+// ID3 genres may reference the ID3v1 genre list by number: "17", "(17)",
+// or in ID3v2.3 "(17)(6)" and "17/6". A reference may be followed by a
+// refinement, as in "(4)Eurodisco", which is kept as a genre of its own.
+// "(RX)" and "(CR)" stand for Remix and Cover, and "((" escapes a literal
+// parenthesis.
+func parseGenres(values []string) []string {
+	var genres []string
+	add := func(genre string) {
+		if genre != "" && !slices.Contains(genres, genre) {
+			genres = append(genres, genre)
+		}
+	}
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+
+		// "17/6" is several references, but "Pop/Funk" is one genre
+		if parts := strings.Split(value, "/"); len(parts) > 1 && allGenreRefs(parts) {
+			for _, part := range parts {
+				add(genreRef(strings.Trim(strings.TrimSpace(part), "()")))
+			}
+			continue
+		}
+
+		for strings.HasPrefix(value, "(") && !strings.HasPrefix(value, "((") {
+			end := strings.IndexByte(value, ')')
+			if end < 0 {
+				break
+			}
+			name := genreRef(value[1:end])
+			if name == "" {
+				break
+			}
+			add(name)
+			value = value[end+1:]
+		}
+		if strings.HasPrefix(value, "((") {
+			value = value[1:]
+		}
+
+		if name := genreRef(value); name != "" {
+			add(name)
+		} else {
+			add(strings.TrimSpace(value))
+		}
+	}
+	return genres
+}
+
+// genreRef returns the genre a reference names, or "" if ref is not one.
+func genreRef(ref string) string {
+	switch ref {
+	case "RX":
+		return "Remix"
+	case "CR":
+		return "Cover"
+	}
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return ""
+	}
+	name, _ := parsing.ID3v1Genre(n)
+	return name
+}
+
+// allGenreRefs reports whether every part is a numeric reference, with or
+// without parentheses.
+func allGenreRefs(parts []string) bool {
+	for _, part := range parts {
+		if genreRef(strings.Trim(strings.TrimSpace(part), "()")) == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package mp3
+
+import (
+	"bytes"
+	"strings"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/parsing"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// id3v1Size is the fixed size of an ID3v1 tag at the end of the file.
+const id3v1Size = 128
+
+// parseID3v1 reads a trailing ID3v1 tag, if present, into file.Tags.
+// As the last tag source it only fills what ID3v2 and APEv2 left empty.
+//
+// Layout: "TAG" + title(30) + artist(30) + album(30) + year(4) +
+// comment(30) + genre(1). Fields are 8-bit text in charset, padded with
+// NULs or spaces. ID3v1.1 shortens the comment to 28 bytes and stores the
+// track number in the last byte, after a NUL.
+func parseID3v1(sr *binutil.SafeReader, size int64, file *types.File, charset types.Charset) {
+	if size < id3v1Size {
+		return
+	}
+
+	buf := make([]byte, id3v1Size)
+	if err := sr.ReadAt(buf, size-id3v1Size, "ID3v1 tag"); err != nil {
+		return
+	}
+	if string(buf[0:3]) != "TAG" {
+		return
+	}
+
+	file.Tags.Title = id3v1Field(buf[3:33], charset)
+	file.Tags.Artist = id3v1Field(buf[33:63], charset)
+	file.Tags.Album = id3v1Field(buf[63:93], charset)
+	file.Tags.Year = parseYear(id3v1Field(buf[93:97], charset))
+	file.Tags.Comment = id3v1Field(buf[97:127], charset)
+
+	if comment := buf[97:127]; comment[28] == 0 && comment[29] != 0 {
+		file.Tags.TrackNumber = int(comment[29])
+	}
+	// Genre 0 is Blues, but it is also what a zeroed tag holds; with no
+	// title, artist or album it means no genre
+	blank := file.Tags.Title == "" && file.Tags.Artist == "" && file.Tags.Album == ""
+	if genre, ok := parsing.ID3v1Genre(int(buf[127])); ok && (buf[127] != 0 || !blank) {
+		file.Tags.Genres = []string{genre}
+	}
+
+	if file.Tags.Title == "" && file.Tags.Artist == "" && file.Tags.Album == "" &&
+		file.Tags.Year == 0 && file.Tags.Comment == "" && file.Tags.TrackNumber == 0 && len(file.Tags.Genres) == 0 {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "metadata",
+			Message:  "ID3v1 tag present but all fields are blank",
+			Severity: types.SeverityInfo,
+			Offset:   size - id3v1Size,
+		})
+	}
+}
+
+// id3v1Field trims NUL and space padding from a fixed-width ID3v1 field.
+// Anything after the first NUL is padding, even if it isn't zeroed.
+func id3v1Field(b []byte, charset types.Charset) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimRight(charset.Decode(b), " ")
+}
@@ -4,8 +4,10 @@ package mp3
 import (
 	"bytes"
 	"cmp"
+	"context"
 	"encoding/binary"
 	"fmt"
+	"log/slog"
 	"slices"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 
 	binutil "github.com/simonhull/audiometa/internal/binary"
 	"github.com/simonhull/audiometa/internal/parsing"
+	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -24,6 +27,8 @@ type ID3v2Header struct {
 	Flags        byte
 	Size         uint32 // Tag size (excluding header), synchsafe
 	ExtendedSize uint32 // Extended header size if present
+
+	charset types.Charset // Decoding for encoding-0 text, from WithLegacyCharset
 }
 
 // ID3v2Frame represents a single ID3v2 frame.
@@ -32,28 +37,42 @@ type ID3v2Frame struct {
 	Data  []byte
 	Size  uint32
 	Flags uint16
+
+	charset types.Charset // Inherited from the tag header
+	version byte          // Major version of the tag, 3 or 4
 }
 
-// parseID3v2 parses ID3v2 tags and extracts metadata.
-func parseID3v2(sr *binutil.SafeReader, file *types.File) (int64, error) {
+// parseID3v2 parses ID3v2 tags and extracts metadata. Encoding-0 text is
+// decoded with charset, and each frame read is logged to the logger of ctx.
+func parseID3v2(ctx context.Context, sr *binutil.SafeReader, file *types.File, charset types.Charset) (int64, error) {
 	header, err := parseID3v2Header(sr)
 	if err != nil {
 		return 0, err
 	}
+	header.charset = charset
+	tagSize := int64(10 + header.Size)
 
-	frameDataOffset := skipExtendedHeader(sr, header)
-	chapters := parseID3v2Frames(sr, file, header, frameDataOffset)
+	tagReader, header := resyncTag(sr, header)
+	frameDataOffset := skipExtendedHeader(tagReader, header)
+	chapters := parseID3v2Frames(ctx, tagReader, file, header, frameDataOffset)
 
 	// Process chapters
 	if len(chapters) > 0 {
-		file.Chapters = parseChapterFrames(chapters, file.Audio.Duration)
+		file.Chapters, file.ChapterTree = parseChapterFrames(chapters, file.Audio.Duration)
+		if len(file.Chapters) > 0 {
+			file.ChapterSource = "mp3:id3-chap"
+		}
 	}
 
 	// Post-parse fallbacks for audiobook series metadata.
 	resolveSeriesFallbacks(sr, file)
 
-	// Total tag size including header
-	return int64(10 + header.Size), nil
+	if file.Tags.Date == "" && file.Tags.Year > 0 {
+		file.Tags.Date = composeDate(file.Tags.Year, file.Tags.GetFirst("TDAT"), file.Tags.GetFirst("TIME"))
+	}
+
+	// Total tag size including header, as stored
+	return tagSize, nil
 }
 
 // resolveSeriesFallbacks applies fallback logic for series metadata after all frames are parsed.
@@ -141,19 +160,24 @@ func skipExtendedHeader(sr *binutil.SafeReader, header ID3v2Header) int64 {
 }
 
 // parseID3v2Frames parses all frames in the ID3v2 tag.
-func parseID3v2Frames(sr *binutil.SafeReader, file *types.File, header ID3v2Header, startOffset int64) []ID3v2Frame {
+func parseID3v2Frames(ctx context.Context, sr *binutil.SafeReader, file *types.File, header ID3v2Header, startOffset int64) []ID3v2Frame {
+	log := registry.Logger(ctx)
 	tagEnd := int64(10 + header.Size)
 	offset := startOffset
 	chapters := make([]ID3v2Frame, 0)
 
 	for offset < tagEnd {
-		frame, bytesRead, stop := readSingleFrame(sr, file, header, offset)
+		frame, bytesRead, stop := readSingleFrame(sr, file, header, offset, tagEnd)
 		if stop {
 			break
 		}
 
 		if frame != nil {
+			log.LogAttrs(ctx, slog.LevelDebug, "ID3v2 frame",
+				slog.String("format", "ID3v2"), slog.String("key", frame.ID),
+				slog.Int64("offset", offset), slog.Int64("size", int64(frame.Size)))
 			processFrame(*frame, file, &chapters)
+			file.AddRawTag(rawFrameTag(*frame))
 		}
 
 		offset += bytesRead
@@ -162,8 +186,15 @@ func parseID3v2Frames(sr *binutil.SafeReader, file *types.File, header ID3v2Head
 	return chapters
 }
 
-// readSingleFrame reads a single ID3v2 frame.
-func readSingleFrame(sr *binutil.SafeReader, file *types.File, header ID3v2Header, offset int64) (*ID3v2Frame, int64, bool) {
+// readSingleFrame reads a single ID3v2 frame. Frames that would run past
+// tagEnd stop the walk with a warning instead of being read from the audio
+// data that follows the tag.
+func readSingleFrame(sr *binutil.SafeReader, file *types.File, header ID3v2Header, offset, tagEnd int64) (*ID3v2Frame, int64, bool) {
+	if offset+10 > tagEnd {
+		// Too little tag left for a frame header: trailing garbage, not a frame
+		return nil, 0, true
+	}
+
 	frameHeaderBuf := make([]byte, 10)
 	if err := sr.ReadAt(frameHeaderBuf, offset, "frame header"); err != nil {
 		return nil, 0, true
@@ -179,13 +210,24 @@ func readSingleFrame(sr *binutil.SafeReader, file *types.File, header ID3v2Heade
 	frameSize := decodeFrameSize(header.Version, frameHeaderBuf[4:8])
 	frameFlags := binary.BigEndian.Uint16(frameHeaderBuf[8:10])
 
+	if frameExceedsTag(offset, frameSize, tagEnd) {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "metadata",
+			Message:  fmt.Sprintf("frame %s declares %d bytes but only %d remain in the tag, stopping", frameID, frameSize, tagEnd-offset-10),
+			Severity: types.SeverityError,
+			Offset:   offset,
+		})
+		return nil, 0, true
+	}
+
 	// Read frame data
 	frameData := make([]byte, frameSize)
 	if err := sr.ReadAt(frameData, offset+10, fmt.Sprintf("frame %s data", frameID)); err != nil {
 		file.Warnings = append(file.Warnings, types.Warning{
-			Stage:   "metadata",
-			Message: fmt.Sprintf("failed to read frame %s: %v", frameID, err),
-			Err:     err,
+			Stage:    "metadata",
+			Message:  fmt.Sprintf("failed to read frame %s: %v", frameID, err),
+			Severity: types.SeverityError,
+			Err:      err,
 		})
 		return nil, 10 + int64(frameSize), false
 	}
@@ -194,12 +236,21 @@ func readSingleFrame(sr *binutil.SafeReader, file *types.File, header ID3v2Heade
 		ID:    frameID,
 		Size:  frameSize,
 		Flags: frameFlags,
-		Data:  frameData,
+		Data:  decodeFrameData(frameData, header, frameFlags),
+
+		charset: header.charset,
+		version: header.Version,
 	}
 
 	return frame, 10 + int64(frameSize), false
 }
 
+// frameExceedsTag reports whether a frame of frameSize bytes at offset
+// would extend past tagEnd.
+func frameExceedsTag(offset int64, frameSize uint32, tagEnd int64) bool {
+	return offset+10+int64(frameSize) > tagEnd
+}
+
 // decodeFrameSize decodes frame size based on ID3v2 version.
 func decodeFrameSize(version byte, sizeBytes []byte) uint32 {
 	if version == 4 {
@@ -211,14 +262,29 @@ func decodeFrameSize(version byte, sizeBytes []byte) uint32 {
 // processFrame processes a single frame based on its ID.
 func processFrame(frame ID3v2Frame, file *types.File, chapters *[]ID3v2Frame) {
 	switch {
-	case strings.HasPrefix(frame.ID, "T") && frame.ID != "TXXX":
+	case strings.HasPrefix(frame.ID, "T") && frame.ID != "TXXX",
+		frame.ID == "GRP1", frame.ID == "MVNM", frame.ID == "MVIN":
+		// iTunes' GRP1/MVNM/MVIN are text frames despite not starting with T
 		parseTextFrame(frame, file)
 	case frame.ID == "TXXX":
 		parseTXXXFrame(frame, file)
 	case frame.ID == "COMM":
 		parseCommentFrame(frame, file)
-	case frame.ID == "CHAP":
+	case frame.ID == "USLT":
+		parseUSLTFrame(frame, file)
+	case frame.ID == "SYLT":
+		parseSYLTFrame(frame, file)
+	case frame.ID == "WXXX":
+		parseWXXXFrame(frame, file)
+	case strings.HasPrefix(frame.ID, "W"):
+		parseURLFrame(frame, file)
+	case frame.ID == "RVA2":
+		parseRVA2Frame(frame, file)
+	case frame.ID == "CHAP", frame.ID == "CTOC":
 		*chapters = append(*chapters, frame)
+	case frame.ID == "APIC":
+		// Pictures are decoded lazily via ExtractArtwork(); only record presence
+		file.HasEmbeddedArtwork = true
 	}
 }
 
@@ -239,33 +305,52 @@ func parseTextFrame(frame ID3v2Frame, file *types.File) { //nolint:gocyclo // Co
 		return
 	}
 
+	// Only ID3v2.4 allows null-separated values; v2.3 ignores anything after
+	// the terminator. Single-value fields take the first value.
 	encoding := frame.Data[0]
-	text := decodeText(frame.Data[1:], encoding)
+	values := decodeTextValues(frame.Data[1:], encoding, frame.charset)
+	if frame.version == 3 && len(values) > 1 {
+		values = values[:1]
+	}
+	var text string
+	if len(values) > 0 {
+		text = values[0]
+	}
 
 	switch frame.ID {
 	case "TIT2": // Title
 		file.Tags.Title = text
 	case "TIT3": // Subtitle/Description refinement
 		file.Tags.Subtitle = text
+	case "TSST": // Set subtitle (ID3v2.4), the disc's own title
+		file.Tags.DiscSubtitle = text
 	case "TPE1": // Artist
 		file.Tags.Artist = text
+		if len(values) > 1 {
+			file.Tags.Artists = append(file.Tags.Artists, values...)
+		}
 	case "TALB": // Album
 		file.Tags.Album = text
-	case "TCON": // Genre
-		if text != "" {
-			file.Tags.Genres = append(file.Tags.Genres, text)
-		}
+	case "TCON": // Genre, possibly as ID3v1 genre numbers
+		file.Tags.Genres = append(file.Tags.Genres, parseGenres(values)...)
 	case "TYER": // Year (ID3v2.3)
 		if year := parseYear(text); year > 0 {
 			file.Tags.Year = year
 		}
+	case "TDAT", "TIME", "TRDA": // Date parts (ID3v2.3), see composeDate
+		if text != "" {
+			file.Tags.Set(frame.ID, text)
+		}
 	case "TDRC": // Recording time (ID3v2.4)
 		if year := parseYear(text); year > 0 {
 			file.Tags.Year = year
 		}
+		file.Tags.Date = text
 	case "TCOM": // Composer (often used for Narrator)
-		if text != "" {
-			file.Tags.Composers = append(file.Tags.Composers, text)
+		file.Tags.Composers = append(file.Tags.Composers, values...)
+	case "TMCL": // Musician credits (ID3v2.4), instrument/name pairs
+		for i := 1; i < len(values); i += 2 {
+			file.Tags.Performers = append(file.Tags.Performers, values[i])
 		}
 	case "TRCK": // Track number/total
 		file.Tags.TrackNumber, file.Tags.TrackTotal = parseTrackNumber(text)
@@ -273,6 +358,10 @@ func parseTextFrame(frame ID3v2Frame, file *types.File) { //nolint:gocyclo // Co
 		file.Tags.DiscNumber, file.Tags.DiscTotal = parseTrackNumber(text)
 	case "TPE2": // Album artist
 		file.Tags.AlbumArtist = text
+	case "TBPM": // Beats per minute
+		if bpm := parsing.ParseBPM(text); bpm > 0 {
+			file.Tags.BPM = bpm
+		}
 	case "GRP1": // Grouping (often contains series info for audiobooks)
 		file.Tags.Grouping = text
 	case "TIT1": // Content group description (alternative grouping)
@@ -283,9 +372,10 @@ func parseTextFrame(frame ID3v2Frame, file *types.File) { //nolint:gocyclo // Co
 		if file.Tags.Series == "" {
 			file.Tags.Series = text
 		}
-	case "MVIN": // Movement number (series position in ID3v2.4)
+	case "MVIN": // Movement number (series position), "n" or "n/total"
 		if file.Tags.SeriesPart == "" {
-			file.Tags.SeriesPart = text
+			part, _, _ := strings.Cut(text, "/")
+			file.Tags.SeriesPart = strings.TrimSpace(part)
 		}
 	}
 }
@@ -293,29 +383,29 @@ func parseTextFrame(frame ID3v2Frame, file *types.File) { //nolint:gocyclo // Co
 // txxxFieldHandlers maps TXXX description (lowercased) → handler that writes
 // the value into the corresponding tag, optionally with a "first wins" rule.
 var txxxFieldHandlers = map[string]func(*types.File, string){
-	"narrator":          func(f *types.File, v string) { f.Tags.Narrator = v },
-	"series":            func(f *types.File, v string) { f.Tags.Series = v },
-	"series part":       func(f *types.File, v string) { f.Tags.SeriesPart = v },
-	"seriespart":        func(f *types.File, v string) { f.Tags.SeriesPart = v },
-	"part":              func(f *types.File, v string) { f.Tags.SeriesPart = v },
-	"series-part":       func(f *types.File, v string) { f.Tags.SeriesPart = v },
-	"series position":   func(f *types.File, v string) { f.Tags.SeriesPart = v },
-	"publisher":         func(f *types.File, v string) { f.Tags.Publisher = v },
-	"isbn":              func(f *types.File, v string) { f.Tags.ISBN = v },
-	"asin":              func(f *types.File, v string) { f.Tags.ASIN = v },
-	"audible_asin":      func(f *types.File, v string) { f.Tags.ASIN = v },
-	"language":          func(f *types.File, v string) { f.Tags.Language = v },
-	"lang":              func(f *types.File, v string) { f.Tags.Language = v },
-	"description":       setIfEmpty(func(t *types.Tags) *string { return &t.Description }),
-	"mvnm":              setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
-	"movement name":     setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
-	"movement":          setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
-	"show":              setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
-	"mvin":              setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
-	"movement number":   setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
-	"movement index":    setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
-	"episode_id":        setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
-	"grouping":          setIfEmpty(func(t *types.Tags) *string { return &t.Grouping }),
+	"narrator":        func(f *types.File, v string) { f.Tags.Narrator = v },
+	"series":          func(f *types.File, v string) { f.Tags.Series = v },
+	"series part":     func(f *types.File, v string) { f.Tags.SeriesPart = v },
+	"seriespart":      func(f *types.File, v string) { f.Tags.SeriesPart = v },
+	"part":            func(f *types.File, v string) { f.Tags.SeriesPart = v },
+	"series-part":     func(f *types.File, v string) { f.Tags.SeriesPart = v },
+	"series position": func(f *types.File, v string) { f.Tags.SeriesPart = v },
+	"publisher":       func(f *types.File, v string) { f.Tags.Publisher = v },
+	"isbn":            func(f *types.File, v string) { f.Tags.ISBN = v },
+	"asin":            func(f *types.File, v string) { f.Tags.ASIN = v },
+	"audible_asin":    func(f *types.File, v string) { f.Tags.ASIN = v },
+	"language":        func(f *types.File, v string) { f.Tags.Language = v },
+	"lang":            func(f *types.File, v string) { f.Tags.Language = v },
+	"description":     setIfEmpty(func(t *types.Tags) *string { return &t.Description }),
+	"mvnm":            setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
+	"movement name":   setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
+	"movement":        setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
+	"show":            setIfEmpty(func(t *types.Tags) *string { return &t.Series }),
+	"mvin":            setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
+	"movement number": setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
+	"movement index":  setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
+	"episode_id":      setIfEmpty(func(t *types.Tags) *string { return &t.SeriesPart }),
+	"grouping":        setIfEmpty(func(t *types.Tags) *string { return &t.Grouping }),
 }
 
 func setIfEmpty(field func(*types.Tags) *string) func(*types.File, string) {
@@ -340,53 +430,121 @@ func parseTXXXFrame(frame ID3v2Frame, file *types.File) {
 		return
 	}
 
-	description := decodeText(data[:nullIdx], encoding)
-	value := decodeText(data[nullIdx+terminatorSize(encoding):], encoding)
+	description := decodeText(data[:nullIdx], encoding, frame.charset)
+	value := decodeText(data[nullIdx+terminatorSize(encoding):], encoding, frame.charset)
 
+	if types.SetReplayGain(&file.Audio, description, value) {
+		return
+	}
 	if handler, ok := txxxFieldHandlers[strings.ToLower(description)]; ok {
 		handler(file, value)
 	}
 }
 
+// URL frames (WOAR, WCOM, WCOP, WOAF, WPUB, ...) hold a single ISO-8859-1
+// URL with no encoding byte.
+func parseURLFrame(frame ID3v2Frame, file *types.File) {
+	url := frame.Data
+	if idx := bytes.IndexByte(url, 0); idx >= 0 {
+		url = url[:idx]
+	}
+	addURL(file, frame.ID, frame.ID, strings.TrimSpace(decodeText(url, 0, frame.charset)))
+}
+
+// Format: [encoding][description\0][url]. The URL itself is always ISO-8859-1.
+func parseWXXXFrame(frame ID3v2Frame, file *types.File) {
+	if len(frame.Data) < 2 {
+		return
+	}
+
+	encoding := frame.Data[0]
+	data := frame.Data[1:]
+
+	nullIdx := findNullTerminator(data, encoding)
+	if nullIdx < 0 {
+		return
+	}
+
+	description := decodeText(data[:nullIdx], encoding, frame.charset)
+	url := data[nullIdx+terminatorSize(encoding):]
+	if idx := bytes.IndexByte(url, 0); idx >= 0 {
+		url = url[:idx]
+	}
+
+	key := description
+	if key == "" {
+		key = "WXXX"
+	}
+	addURL(file, key, "WXXX", strings.TrimSpace(decodeText(url, 0, frame.charset)))
+}
+
+// addURL records a URL under key in Tags.URLs (first wins) and appends it to
+// the raw tag for frameID, since URL frames may repeat.
+func addURL(file *types.File, key, frameID, url string) {
+	if url == "" {
+		return
+	}
+	if file.Tags.URLs == nil {
+		file.Tags.URLs = make(map[string]string)
+	}
+	if _, exists := file.Tags.URLs[key]; !exists {
+		file.Tags.URLs[key] = url
+	}
+	file.Tags.Set(frameID, append(file.Tags.Get(frameID), url)...)
+}
+
 // Format: [encoding][language(3)][short description\0][text].
+//
+// Every frame is kept in Tags.Comments; Tags.Comment shows the primary one.
 func parseCommentFrame(frame ID3v2Frame, file *types.File) {
 	if len(frame.Data) < 4 {
 		return
 	}
 
 	encoding := frame.Data[0]
-	// Skip language (3 bytes)
+	comment := types.Comment{Language: strings.TrimRight(string(frame.Data[1:4]), "\x00 ")}
 	data := frame.Data[4:]
 
 	// Find null terminator separating short description from text
 	nullIdx := findNullTerminator(data, encoding)
 	if nullIdx < 0 {
 		// No null terminator - treat all as comment
-		file.Tags.Comment = decodeText(data, encoding)
-		return
+		comment.Text = decodeText(data, encoding, frame.charset)
+	} else {
+		comment.Description = decodeText(data[:nullIdx], encoding, frame.charset)
+		comment.Text = decodeText(data[nullIdx+terminatorSize(encoding):], encoding, frame.charset)
 	}
 
-	// Extract comment text (after short description)
-	comment := decodeText(data[nullIdx+terminatorSize(encoding):], encoding)
-	file.Tags.Comment = comment
+	file.Tags.Comments = append(file.Tags.Comments, comment)
+	file.Tags.Comment = types.PrimaryComment(file.Tags.Comments)
 }
 
-// parseChapterFrames parses CHAP frames and builds chapter list.
+// parseChapterFrames parses CHAP frames and builds chapter list, sorted
+// by start time unless a top-level CTOC frame orders them. When the CTOC
+// frames nest chapters, tree holds that hierarchy and chapters its leaves.
 // CHAP frame format:
 //
 //	[encoding][element_id\0][start_time(4)][end_time(4)][start_offset(4)][end_offset(4)][subframes...]
-func parseChapterFrames(frames []ID3v2Frame, _ time.Duration) []types.Chapter {
+func parseChapterFrames(frames []ID3v2Frame, _ time.Duration) (chapters, tree []types.Chapter) {
 	type chapterData struct {
 		ElementID string
 		Title     string
+		Image     *types.Artwork
 		Index     int
 		StartTime uint32
 		EndTime   uint32
 	}
 
-	chapters := make([]chapterData, 0, len(frames))
+	chapterFrames := make([]chapterData, 0, len(frames))
+	var tocs []tableOfContents
 
 	for _, frame := range frames {
+		if frame.ID == "CTOC" {
+			if toc, ok := parseCTOCFrame(frame); ok {
+				tocs = append(tocs, toc)
+			}
+			continue
+		}
 		if len(frame.Data) < 20 {
 			continue
 		}
@@ -413,78 +571,98 @@ func parseChapterFrames(frames []ID3v2Frame, _ time.Duration) []types.Chapter {
 		endTime := binary.BigEndian.Uint32(data[4:8])
 		// Skip startOffset and endOffset (usually 0xFFFFFFFF) at data[8:16]
 
-		// Parse subframes for chapter title
-		title := extractChapterTitleFromSubframes(data[16:], elementID)
+		// Parse subframes for chapter title and image
+		title, image := parseChapterSubframes(data[16:], frame.version, frame.charset)
+		if title == "" {
+			title = elementID
+		}
 
-		chapters = append(chapters, chapterData{
-			Index:     len(chapters),
+		chapterFrames = append(chapterFrames, chapterData{
+			Index:     len(chapterFrames),
 			ElementID: elementID,
 			StartTime: startTime,
 			EndTime:   endTime,
 			Title:     title,
+			Image:     image,
 		})
 	}
 
 	// Sort by start time using modern Go 1.21+ slices package
-	slices.SortFunc(chapters, func(a, b chapterData) int {
+	slices.SortFunc(chapterFrames, func(a, b chapterData) int {
 		return cmp.Compare(a.StartTime, b.StartTime)
 	})
 
 	// Convert to types.Chapter
-	result := make([]types.Chapter, len(chapters))
-	for i, ch := range chapters {
+	result := make([]types.Chapter, len(chapterFrames))
+	ids := make([]string, len(chapterFrames))
+	for i, ch := range chapterFrames {
+		ids[i] = ch.ElementID
 		result[i] = types.Chapter{
 			Index:     i + 1,
 			Title:     ch.Title,
+			Image:     ch.Image,
 			StartTime: time.Duration(ch.StartTime) * time.Millisecond,
 			EndTime:   time.Duration(ch.EndTime) * time.Millisecond,
 		}
 	}
 
-	return result
-}
-
-// extractChapterTitleFromSubframes extracts chapter title from TIT2 subframe.
-func extractChapterTitleFromSubframes(subframeData []byte, fallbackTitle string) string {
-	if len(subframeData) < 10 {
-		return fallbackTitle
+	nested, ok := nestChapters(result, ids, tocs)
+	if !ok {
+		return result, nil
 	}
-
-	// Try to parse TIT2 subframe
-	subframeID := string(subframeData[0:4])
-	if subframeID != "TIT2" {
-		return fallbackTitle
-	}
-
-	// Try synchsafe size (CHAP subframes may use synchsafe)
-	subframeSize := decodeSynchsafe(subframeData[4:8])
-	if len(subframeData) < int(10+subframeSize) {
-		return fallbackTitle
-	}
-
-	titleData := subframeData[10 : 10+subframeSize]
-	if len(titleData) == 0 {
-		return fallbackTitle
+	if !types.IsChapterTree(nested) {
+		return nested, nil
 	}
+	return types.ChapterLeaves(nested), nested
+}
 
-	titleEncoding := titleData[0]
-	title := decodeText(titleData[1:], titleEncoding)
-	if title == "" {
-		return fallbackTitle
+// parseChapterSubframes walks the subframes embedded in a CHAP frame,
+// returning the title from TIT2 and the image from APIC. Either is empty
+// if missing; the first of each wins. Subframe headers are laid out like
+// those of the tag's frames, per its major version.
+func parseChapterSubframes(data []byte, version byte, charset types.Charset) (string, *types.Artwork) {
+	var title string
+	var image *types.Artwork
+	for len(data) >= 10 && data[0] != 0 {
+		id := string(data[0:4])
+		size := int64(decodeFrameSize(version, data[4:8]))
+		flags := binary.BigEndian.Uint16(data[8:10])
+		if size > int64(len(data)-10) {
+			break
+		}
+		payload := decodeFrameData(data[10:10+size], ID3v2Header{Version: version}, flags)
+		data = data[10+size:]
+
+		switch {
+		case id == "TIT2" && title == "" && len(payload) > 0:
+			title = decodeText(payload[1:], payload[0], charset)
+		case id == "APIC" && image == nil:
+			if art, err := parseAPICFrame(payload, charset); err == nil {
+				image = &art
+			}
+		}
 	}
-
-	return title
+	return title, image
 }
 
-// decodeText decodes text based on ID3v2 encoding byte.
-func decodeText(data []byte, encoding byte) string {
+// decodeText decodes text based on ID3v2 encoding byte. Encoding 0 is
+// nominally ISO-8859-1 and is transcoded through charset. Trailing null
+// terminators are dropped.
+func decodeText(data []byte, encoding byte, charset types.Charset) string {
+	data = trimTerminators(data, encoding)
 	if len(data) == 0 {
 		return ""
 	}
 
 	switch encoding {
 	case 0: // ISO-8859-1
-		return string(data)
+		// Taggers often write UTF-8 while labelling it ISO-8859-1. Real
+		// 8-bit text is almost never valid UTF-8 beyond ASCII, so take
+		// such bytes as UTF-8 rather than double-encode them.
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		return charset.Decode(data)
 
 	case 1: // UTF-16 with BOM
 		return decodeUTF16(data)
@@ -500,10 +678,55 @@ func decodeText(data []byte, encoding byte) string {
 
 	default:
 		// Unknown encoding - try as ISO-8859-1
-		return string(data)
+		return charset.Decode(data)
 	}
 }
 
+// decodeTextValues decodes the null-separated values of a text frame,
+// dropping empty ones. In UTF-16 with BOM, values after the first may
+// omit their BOM and inherit the first value's byte order.
+func decodeTextValues(data []byte, encoding byte, charset types.Charset) []string {
+	var values []string
+	var bom []byte
+	for len(data) > 0 {
+		value, rest := data, []byte(nil)
+		if end := findNullTerminator(data, encoding); end >= 0 {
+			value, rest = data[:end], data[end+terminatorSize(encoding):]
+		}
+
+		if encoding == 1 && len(value) >= 2 {
+			if hasUTF16BOM(value) {
+				bom = value[:2]
+			} else if bom != nil {
+				value = append(slices.Clone(bom), value...)
+			}
+		}
+		if text := decodeText(value, encoding, charset); text != "" {
+			values = append(values, text)
+		}
+		data = rest
+	}
+	return values
+}
+
+// trimTerminators drops trailing null terminators, one or two bytes wide
+// depending on the encoding.
+func trimTerminators(data []byte, encoding byte) []byte {
+	size := terminatorSize(encoding)
+	if size == 2 {
+		data = data[:len(data)-len(data)%2]
+	}
+	for len(data) >= size && data[len(data)-size] == 0 && data[len(data)-1] == 0 {
+		data = data[:len(data)-size]
+	}
+	return data
+}
+
+// hasUTF16BOM reports whether data starts with a UTF-16 byte order mark.
+func hasUTF16BOM(data []byte) bool {
+	return len(data) >= 2 && (data[0] == 0xFF && data[1] == 0xFE || data[0] == 0xFE && data[1] == 0xFF)
+}
+
 // decodeUTF16 decodes UTF-16 with BOM.
 func decodeUTF16(data []byte) string {
 	if len(data) < 2 {
@@ -596,6 +819,39 @@ func parseYear(text string) int {
 	return 0
 }
 
+// composeDate builds an ISO 8601 date from ID3v2.3's TYER year, TDAT
+// "DDMM" and TIME "HHMM" frames: "1997", "1997-08-29" or
+// "1997-08-29T13:37". Parts that are missing or malformed end the date
+// there. TRDA is free-form ("4th-7th June") and only kept raw.
+func composeDate(year int, tdat, tm string) string {
+	date := fmt.Sprintf("%04d", year)
+
+	day, month, ok := splitDigitPairs(tdat)
+	if !ok || month < 1 || month > 12 || day < 1 || day > 31 {
+		return date
+	}
+	date += fmt.Sprintf("-%02d-%02d", month, day)
+
+	hour, minute, ok := splitDigitPairs(tm)
+	if !ok || hour > 23 || minute > 59 {
+		return date
+	}
+	return date + fmt.Sprintf("T%02d:%02d", hour, minute)
+}
+
+// splitDigitPairs splits a four-digit string into two two-digit numbers.
+func splitDigitPairs(s string) (first, second int, ok bool) {
+	if len(s) != 4 {
+		return 0, 0, false
+	}
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, 0, false
+		}
+	}
+	return int(s[0]-'0')*10 + int(s[1]-'0'), int(s[2]-'0')*10 + int(s[3]-'0'), true
+}
+
 // parseTrackNumber parses "N" or "N/Total" format.
 func parseTrackNumber(text string) (number, total int) {
 	parts := strings.Split(text, "/")
@@ -0,0 +1,171 @@
+package mp3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// lameTagSize is the size of the LAME extension that follows the Xing fields.
+const lameTagSize = 36
+
+// Xing header flags marking which optional fields are present.
+const (
+	xingFlagFrames  = 0x1
+	xingFlagBytes   = 0x2
+	xingFlagTOC     = 0x4
+	xingFlagQuality = 0x8
+)
+
+// LAME replay gain name codes.
+const (
+	lameGainRadio      = 1 // Track gain
+	lameGainAudiophile = 2 // Album gain
+)
+
+// lameTag holds the LAME extension fields audiometa uses.
+type lameTag struct {
+	encoder    string
+	lowpassHz  int
+	replayGain *types.ReplayGainInfo
+}
+
+// parseLAMETag reads the LAME extension after the Xing/Info header of the
+// first frame. Returns nil without error when there is none.
+//
+// LAME extension layout (after the Xing fields):
+//
+//	[9 bytes]  encoder version ("LAME3.100", "Lavf58.76")
+//	[1 byte]   tag revision + VBR method
+//	[1 byte]   lowpass filter frequency / 100
+//	[4 bytes]  peak signal amplitude (fixed point, 1.0 = 1<<23)
+//	[2 bytes]  radio (track) replay gain
+//	[2 bytes]  audiophile (album) replay gain
+//	[1 byte]   encoding flags + ATH type
+//	[1 byte]   ABR/minimal bitrate
+//	[3 bytes]  encoder delay and padding
+//	[1 byte]   misc
+//	[1 byte]   MP3 gain
+//	[2 bytes]  preset and surround info
+//	[4 bytes]  music length
+//	[2 bytes]  music CRC
+//	[2 bytes]  CRC-16 of the frame up to this field
+func parseLAMETag(sr *binutil.SafeReader, frameOffset int64, header uint32) (*lameTag, error) {
+	xingOffset := frameOffset + 4 + sideInfoSize(header)
+	xing := make([]byte, 8)
+	if err := sr.ReadAt(xing, xingOffset, "Xing header"); err != nil {
+		return nil, nil //nolint:nilerr // A short file simply has no LAME tag
+	}
+	if marker := string(xing[0:4]); marker != "Xing" && marker != "Info" {
+		return nil, nil
+	}
+
+	// Skip whichever optional Xing fields are present
+	flags := binary.BigEndian.Uint32(xing[4:8])
+	offset := xingOffset + 8
+	for _, field := range []struct {
+		flag uint32
+		size int64
+	}{{xingFlagFrames, 4}, {xingFlagBytes, 4}, {xingFlagTOC, 100}, {xingFlagQuality, 4}} {
+		if flags&field.flag != 0 {
+			offset += field.size
+		}
+	}
+
+	data := make([]byte, lameTagSize)
+	if err := sr.ReadAt(data, offset, "LAME tag"); err != nil {
+		return nil, nil //nolint:nilerr // Xing without a LAME extension
+	}
+	encoder := strings.TrimRight(string(data[0:9]), "\x00 ")
+	if encoder == "" || strings.IndexFunc(encoder, func(r rune) bool { return r < 0x20 || r > 0x7E }) >= 0 {
+		return nil, nil
+	}
+
+	// The CRC covers the whole frame before the CRC field
+	covered := make([]byte, offset+lameTagSize-2-frameOffset)
+	if err := sr.ReadAt(covered, frameOffset, "LAME tag CRC range"); err != nil {
+		return nil, err
+	}
+	if stored, computed := binary.BigEndian.Uint16(data[34:36]), crc16(covered); stored != computed {
+		return nil, fmt.Errorf("LAME tag CRC mismatch: stored %04X, computed %04X", stored, computed)
+	}
+
+	tag := &lameTag{
+		encoder:   encoder,
+		lowpassHz: int(data[10]) * 100,
+	}
+
+	peak := float64(binary.BigEndian.Uint32(data[11:15])) / (1 << 23)
+	var rg types.ReplayGainInfo
+	var hasGain bool
+	for _, field := range []uint16{binary.BigEndian.Uint16(data[15:17]), binary.BigEndian.Uint16(data[17:19])} {
+		switch name, gain := lameGain(field); name {
+		case lameGainRadio:
+			rg.TrackGain, rg.TrackPeak, hasGain = gain, peak, true
+		case lameGainAudiophile:
+			rg.AlbumGain, hasGain = gain, true
+		}
+	}
+	if hasGain {
+		tag.replayGain = &rg
+	}
+
+	return tag, nil
+}
+
+// lameGain decodes a LAME replay gain field: 3 bits name code, 3 bits
+// originator, 1 sign bit and 9 bits of gain in tenths of a dB.
+func lameGain(field uint16) (name int, gain float64) {
+	gain = float64(field&0x1FF) / 10
+	if field&0x200 != 0 {
+		gain = -gain
+	}
+	return int(field >> 13), gain
+}
+
+// applyLAMETag fills fields the ID3 tags left empty.
+func applyLAMETag(tag *lameTag, file *types.File) {
+	if file.Tags.Encoder == "" {
+		file.Tags.Encoder = tag.encoder
+	}
+	file.Audio.LowpassHz = tag.lowpassHz
+	if file.Audio.ReplayGain == nil {
+		file.Audio.ReplayGain = tag.replayGain
+	}
+}
+
+// sideInfoSize returns the size of the Layer III side information that
+// sits between the frame header and the Xing header.
+func sideInfoSize(header uint32) int64 {
+	mpeg1 := (header>>19)&0x3 == 3
+	mono := (header>>6)&0x3 == 3
+	switch {
+	case mpeg1 && mono:
+		return 17
+	case mpeg1:
+		return 32
+	case mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// crc16 computes the CRC-16 (polynomial 0x8005, reflected) LAME uses.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b)
+		for range 8 {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,125 @@
+package mp3
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// SYLT timestamp formats.
+const (
+	syltMPEGFrames   = 1
+	syltMilliseconds = 2
+)
+
+// syltContentLyrics is the SYLT content type for lyrics; others are
+// transcriptions, chord names, trivia and so on.
+const syltContentLyrics = 1
+
+// parseUSLTFrame parses unsynchronized lyrics into Tags.Lyrics. Taggers
+// write one frame per language or description; the first frame with an
+// empty descriptor wins, else the first frame. The texts of frames with an
+// empty descriptor are also kept raw under "USLT".
+//
+// Format: [encoding][language(3)][descriptor\0][text].
+func parseUSLTFrame(frame ID3v2Frame, file *types.File) {
+	if len(frame.Data) < 4 {
+		return
+	}
+
+	encoding := frame.Data[0]
+	data := frame.Data[4:]
+	end := findNullTerminator(data, encoding)
+	if end < 0 {
+		return
+	}
+	descriptor := decodeText(data[:end], encoding, frame.charset)
+	text := decodeText(data[end+terminatorSize(encoding):], encoding, frame.charset)
+	if text == "" {
+		return
+	}
+
+	if descriptor == "" {
+		primary := file.Tags.Get("USLT")
+		if len(primary) == 0 {
+			file.Tags.Lyrics = text
+		}
+		file.Tags.Set("USLT", append(primary, text)...)
+		return
+	}
+	if file.Tags.Lyrics == "" {
+		file.Tags.Lyrics = text
+	}
+}
+
+// parseSYLTFrame parses synchronized lyrics into Tags.SyncedLyrics. The
+// first lyrics frame wins; later SYLT frames are usually translations.
+// Frames timed in MPEG frames are skipped with a warning, since converting
+// them needs the frame duration.
+//
+// Format: [encoding][language(3)][timestamp format][content type]
+// [descriptor\0] then repeated [text\0][timestamp(4)].
+func parseSYLTFrame(frame ID3v2Frame, file *types.File) {
+	if len(frame.Data) < 6 || file.Tags.SyncedLyrics != nil {
+		return
+	}
+
+	encoding := frame.Data[0]
+	format, contentType := frame.Data[4], frame.Data[5]
+	if contentType != syltContentLyrics && contentType != 0 {
+		return
+	}
+	if format != syltMilliseconds {
+		reason := fmt.Sprintf("unknown timestamp format %d", format)
+		if format == syltMPEGFrames {
+			reason = "timestamps in MPEG frames are not supported"
+		}
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: "skipping SYLT frame: " + reason,
+		})
+		return
+	}
+
+	data := frame.Data[6:]
+	end := findNullTerminator(data, encoding)
+	if end < 0 {
+		return
+	}
+	data = data[end+terminatorSize(encoding):]
+
+	var lines []types.LyricLine
+	var bom []byte
+	for len(data) > 0 {
+		end := findNullTerminator(data, encoding)
+		if end < 0 || len(data) < end+terminatorSize(encoding)+4 {
+			break
+		}
+		text := data[:end]
+		data = data[end+terminatorSize(encoding):]
+		ms := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+
+		if encoding == 1 && len(text) >= 2 {
+			if hasUTF16BOM(text) {
+				bom = text[:2]
+			} else if bom != nil {
+				text = append(slices.Clone(bom), text...)
+			}
+		}
+
+		// Lines conventionally start with the newline that ends the last one
+		lines = append(lines, types.LyricLine{
+			Text: strings.TrimLeft(decodeText(text, encoding, frame.charset), "\r\n"),
+			Time: time.Duration(ms) * time.Millisecond,
+		})
+	}
+
+	slices.SortStableFunc(lines, func(a, b types.LyricLine) int { return cmp.Compare(a.Time, b.Time) })
+	file.Tags.SyncedLyrics = lines
+}
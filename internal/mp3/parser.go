@@ -31,20 +31,21 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		Audio:  types.AudioInfo{},
 	}
 
-	// Parse ID3v2 tag (if present)
-	tagSize, err := parseID3v2(sr, file)
-	if err != nil {
-		// Not an ID3v2 file or parse error - try to find MP3 frames anyway
-		file.Warnings = append(file.Warnings, types.Warning{
-			Stage:   "metadata",
-			Message: fmt.Sprintf("ID3v2 parsing failed: %v", err),
-			Err:     err,
-		})
-		tagSize = 0
+	if registry.RawTagsEnabled(ctx) {
+		file.EnableRawTags()
 	}
 
+	// Read tag containers in precedence order (ID3v2, APEv2, then ID3v1)
+	v2 := &id3v2Source{ctx: ctx, charset: registry.LegacyCharset(ctx)}
+	registry.ReadTagSources(sr, file, tagSources(v2)...)
+
 	// Parse MP3 frame headers for technical info (bitrate, duration, etc.)
-	if err := parseTechnicalInfo(sr, tagSize, size, file); err != nil {
+	// The tag size from the header covers tags the ID3v2 reader rejected
+	audioStart := max(v2.tagSize, types.ID3v2TagSize(sr))
+	if err := parseTechnicalInfo(ctx, sr, audioStart, size, file); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		file.Warnings = append(file.Warnings, types.Warning{
 			Stage:   "technical",
 			Message: fmt.Sprintf("failed to parse MP3 technical info: %v", err),
@@ -66,7 +67,85 @@ func (p *parser) ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64,
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	return extractArtwork(r, size, path)
+	return extractArtwork(ctx, r, size, path, true)
+}
+
+// ExtractArtworkMetadata describes APIC frames from their headers and the
+// start of each image, without reading whole images.
+func (p *parser) ExtractArtworkMetadata(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return extractArtwork(ctx, r, size, path, false)
+}
+
+// CountArtwork counts APIC frames without reading their image data.
+func (p *parser) CountArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return countArtwork(r, size, path), nil
+}
+
+// ExtractChapters extracts ID3v2 CHAP chapters without scanning audio
+// frames. Only CHAP and CTOC frames are read; the others are skipped by
+// their headers.
+func (p *parser) ExtractChapters(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Chapter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+
+	// No ID3v2 tag means no CHAP frames
+	header, err := parseID3v2Header(sr)
+	if err != nil {
+		return nil, nil
+	}
+	header.charset = registry.LegacyCharset(ctx)
+	sr, header = resyncTag(sr, header)
+
+	frames, err := readChapterFrames(ctx, sr, header)
+	if err != nil {
+		return nil, err
+	}
+	chapters, _ := parseChapterFrames(frames, 0)
+	return chapters, nil
+}
+
+// readChapterFrames returns the CHAP and CTOC frames of the tag.
+func readChapterFrames(ctx context.Context, sr *binutil.SafeReader, header ID3v2Header) ([]ID3v2Frame, error) {
+	tagEnd := int64(10 + header.Size)
+	offset := skipExtendedHeader(sr, header)
+	scratch := &types.File{} // Collects warnings nobody reads
+
+	var frames []ID3v2Frame
+	frameHeader := make([]byte, 10)
+	for offset+10 <= tagEnd {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := sr.ReadAt(frameHeader, offset, "frame header"); err != nil || frameHeader[0] == 0 {
+			break
+		}
+
+		frameSize := decodeFrameSize(header.Version, frameHeader[4:8])
+		if frameExceedsTag(offset, frameSize, tagEnd) {
+			break
+		}
+
+		if id := string(frameHeader[0:4]); id == "CHAP" || id == "CTOC" {
+			if frame, _, stop := readSingleFrame(sr, scratch, header, offset, tagEnd); stop {
+				break
+			} else if frame != nil {
+				frames = append(frames, *frame)
+			}
+		}
+
+		offset += 10 + int64(frameSize)
+	}
+
+	return frames, nil
 }
 
 // init registers the MP3 parser.
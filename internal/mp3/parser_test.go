@@ -1,11 +1,20 @@
 package mp3
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -175,6 +184,92 @@ func TestParseYear(t *testing.T) {
 	}
 }
 
+func TestComposeDate(t *testing.T) {
+	tests := []struct {
+		tdat, time string
+		want       string
+	}{
+		{"", "", "1997"},
+		{"2908", "", "1997-08-29"},
+		{"2908", "1337", "1997-08-29T13:37"},
+		{"", "1337", "1997"},
+		{"3013", "1337", "1997"}, // month 13
+		{"2908", "2561", "1997-08-29"},
+		{"29-8", "", "1997"},
+	}
+
+	for _, tt := range tests {
+		if got := composeDate(1997, tt.tdat, tt.time); got != tt.want {
+			t.Errorf("composeDate(1997, %q, %q) = %q, want %q", tt.tdat, tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestParseID3v2_V23Date(t *testing.T) {
+	var frames []byte
+	for _, f := range [][2]string{{"TIME", "1337"}, {"TYER", "1997"}, {"TDAT", "2908"}} {
+		frames = append(frames, f[0]...)
+		frames = append(frames, 0, 0, 0, byte(len(f[1])+1), 0, 0, 0x00)
+		frames = append(frames, f[1]...)
+	}
+	data := append([]byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0, 0, 0, byte(len(frames))}, frames...)
+
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+	file := &types.File{}
+	if _, err := parseID3v2(context.Background(), sr, file, types.Latin1); err != nil {
+		t.Fatalf("parseID3v2 failed: %v", err)
+	}
+
+	if file.Tags.Date != "1997-08-29T13:37" || file.Tags.Year != 1997 {
+		t.Errorf("Date = %q, Year = %d; want 1997-08-29T13:37, 1997", file.Tags.Date, file.Tags.Year)
+	}
+}
+
+func TestParseID3v2_Unsynchronization(t *testing.T) {
+	// The title is "Ab\xFFCd"; unsynchronised, the 0xFF gains a 0x00
+	stuffed := []byte{0x00, 'A', 'b', 0xFF, 0x00, 'C', 'd'}
+
+	tests := []struct {
+		name    string
+		version byte
+		flags   byte   // Tag header flags
+		size    []byte // Frame size field
+		fflags  []byte // Frame flags field
+		data    []byte
+	}{
+		{"v2.3 whole tag", 3, 0x80, []byte{0, 0, 0, 6}, []byte{0, 0}, stuffed},
+		{"v2.4 frame", 4, 0x00, []byte{0, 0, 0, 11}, []byte{0, 0x03}, append([]byte{0, 0, 0, 6}, stuffed...)},
+		{"v2.4 whole tag", 4, 0x80, []byte{0, 0, 0, 7}, []byte{0, 0}, stuffed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := append([]byte("TIT2"), tt.size...)
+			frame = append(frame, tt.fflags...)
+			frame = append(frame, tt.data...)
+			frame = append(frame, []byte("TALB\x00\x00\x00\x03\x00\x00\x00Al")...)
+			data := append([]byte{'I', 'D', '3', tt.version, 0x00, tt.flags, 0, 0, 0, byte(len(frame))}, frame...)
+
+			sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+			file := &types.File{}
+			size, err := parseID3v2(context.Background(), sr, file, types.Latin1)
+			if err != nil {
+				t.Fatalf("parseID3v2 failed: %v", err)
+			}
+
+			if file.Tags.Title != "Ab\u00FFCd" {
+				t.Errorf("Title = %q, want %q", file.Tags.Title, "Ab\u00FFCd")
+			}
+			if file.Tags.Album != "Al" {
+				t.Errorf("Album = %q, want the frame after the title to be read intact", file.Tags.Album)
+			}
+			if size != int64(len(data)) {
+				t.Errorf("tag size = %d, want the stored size %d", size, len(data))
+			}
+		})
+	}
+}
+
 func TestParseTrackNumber(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -233,6 +328,396 @@ func TestParseTextFrame(t *testing.T) {
 	if file.Tags.TrackNumber != 5 || file.Tags.TrackTotal != 12 {
 		t.Errorf("expected track 5/12, got %d/%d", file.Tags.TrackNumber, file.Tags.TrackTotal)
 	}
+
+	// TSST (Set subtitle) frame
+	frame = ID3v2Frame{
+		ID:   "TSST",
+		Data: append([]byte{0x00}, "The Two Towers"...),
+	}
+	parseTextFrame(frame, file)
+
+	if file.Tags.DiscSubtitle != "The Two Towers" {
+		t.Errorf("expected disc subtitle 'The Two Towers', got '%s'", file.Tags.DiscSubtitle)
+	}
+
+	// TDRC (Recording time) frame
+	frame = ID3v2Frame{
+		ID:   "TDRC",
+		Data: append([]byte{0x00}, "2004-05-06"...),
+	}
+	parseTextFrame(frame, file)
+
+	if file.Tags.Date != "2004-05-06" || file.Tags.Year != 2004 {
+		t.Errorf("expected date 2004-05-06, got %q (year %d)", file.Tags.Date, file.Tags.Year)
+	}
+}
+
+func TestParseTextFrame_UTF16(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		data        []byte
+		wantArtist  string
+		wantArtists []string
+		wantGenres  []string
+	}{
+		{
+			name:       "trailing double null",
+			id:         "TPE1",
+			data:       []byte{0x01, 0xFF, 0xFE, 'A', 0, 'B', 0, 0, 0},
+			wantArtist: "AB",
+		},
+		{
+			name:        "null-separated values sharing the first BOM",
+			id:          "TPE1",
+			data:        []byte{0x01, 0xFF, 0xFE, 'A', 0, 0, 0, 'B', 0},
+			wantArtist:  "A",
+			wantArtists: []string{"A", "B"},
+		},
+		{
+			name:        "each value with its own BOM",
+			id:          "TPE1",
+			data:        []byte{0x01, 0xFE, 0xFF, 0, 'A', 0, 0, 0xFE, 0xFF, 0, 'B', 0, 0},
+			wantArtist:  "A",
+			wantArtists: []string{"A", "B"},
+		},
+		{
+			name:       "UTF-16BE genres",
+			id:         "TCON",
+			data:       []byte{0x02, 0, 'R', 0, 'o', 0, 0, 0, 'P', 0, 'o', 0, 0},
+			wantGenres: []string{"Ro", "Po"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &types.File{}
+			parseTextFrame(ID3v2Frame{ID: tt.id, Data: tt.data}, file)
+
+			if file.Tags.Artist != tt.wantArtist {
+				t.Errorf("Artist = %q, want %q", file.Tags.Artist, tt.wantArtist)
+			}
+			if !slices.Equal(file.Tags.Artists, tt.wantArtists) {
+				t.Errorf("Artists = %q, want %q", file.Tags.Artists, tt.wantArtists)
+			}
+			if !slices.Equal(file.Tags.Genres, tt.wantGenres) {
+				t.Errorf("Genres = %q, want %q", file.Tags.Genres, tt.wantGenres)
+			}
+		})
+	}
+}
+
+func TestParseTextFrame_MultiValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		version byte
+		id      string
+		text    string
+		get     func(*types.Tags) []string
+		want    []string
+	}{
+		{"v2.4 TPE1", 4, "TPE1", "A\x00B", func(t *types.Tags) []string { return t.Artists }, []string{"A", "B"}},
+		{"v2.4 TCOM", 4, "TCOM", "Bach\x00Handel\x00", func(t *types.Tags) []string { return t.Composers }, []string{"Bach", "Handel"}},
+		{"v2.4 TCON", 4, "TCON", "Rock\x00Metal", func(t *types.Tags) []string { return t.Genres }, []string{"Rock", "Metal"}},
+		{"v2.4 TCON numeric", 4, "TCON", "17\x00Shoegaze", func(t *types.Tags) []string { return t.Genres }, []string{"Rock", "Shoegaze"}},
+		{"v2.4 TMCL", 4, "TMCL", "guitar\x00Jimi\x00drums\x00Mitch", func(t *types.Tags) []string { return t.Performers }, []string{"Jimi", "Mitch"}},
+		{"v2.3 TPE1 keeps first", 3, "TPE1", "A\x00B", func(t *types.Tags) []string { return t.Artists }, nil},
+		{"v2.3 TCOM keeps first", 3, "TCOM", "Bach\x00Handel", func(t *types.Tags) []string { return t.Composers }, []string{"Bach"}},
+		{"v2.3 TCON references", 3, "TCON", "(17)(6)", func(t *types.Tags) []string { return t.Genres }, []string{"Rock", "Grunge"}},
+		{"v2.3 TCON slash references", 3, "TCON", "17/6", func(t *types.Tags) []string { return t.Genres }, []string{"Rock", "Grunge"}},
+		{"v2.3 TCON slash name", 3, "TCON", "Pop/Funk", func(t *types.Tags) []string { return t.Genres }, []string{"Pop/Funk"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &types.File{}
+			frame := ID3v2Frame{ID: tt.id, Data: append([]byte{0x03}, tt.text...), version: tt.version}
+			parseTextFrame(frame, file)
+
+			if got := tt.get(&file.Tags); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGenres(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  []string
+	}{
+		{[]string{"Rock"}, []string{"Rock"}},
+		{[]string{"(17)"}, []string{"Rock"}},
+		{[]string{"(4)Eurodisco"}, []string{"Disco", "Eurodisco"}},
+		{[]string{"(RX)(CR)"}, []string{"Remix", "Cover"}},
+		{[]string{"((Parenthetical)"}, []string{"(Parenthetical)"}},
+		{[]string{"(Unknown)"}, []string{"(Unknown)"}},
+		{[]string{"17", "(17)"}, []string{"Rock"}},
+		{[]string{"255"}, []string{"255"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseGenres(tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("parseGenres(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseTextFrame_LegacyCharset(t *testing.T) {
+	// "Don't Stop" with Windows-1252 smart quotes around it
+	data := []byte{0x00, 0x93, 'D', 'o', 'n', 0x92, 't', ' ', 'S', 't', 'o', 'p', 0x94}
+
+	tests := []struct {
+		charset types.Charset
+		want    string
+	}{
+		{types.Latin1, "\u0093Don\u0092t Stop\u0094"},
+		{types.Windows1252, "“Don’t Stop”"},
+	}
+
+	for _, tt := range tests {
+		file := &types.File{}
+		parseTextFrame(ID3v2Frame{ID: "TIT2", Data: data, charset: tt.charset}, file)
+		if file.Tags.Title != tt.want {
+			t.Errorf("charset %d: expected title %q, got %q", tt.charset, tt.want, file.Tags.Title)
+		}
+	}
+
+	// UTF-8 mislabelled as encoding 0 is kept, not double-encoded
+	for _, charset := range []types.Charset{types.Latin1, types.Windows1252} {
+		file := &types.File{}
+		parseTextFrame(ID3v2Frame{ID: "TIT2", Data: []byte("\x00Beyoncé – Halo"), charset: charset}, file)
+		if file.Tags.Title != "Beyoncé – Halo" {
+			t.Errorf("charset %d: expected UTF-8 title kept, got %q", charset, file.Tags.Title)
+		}
+	}
+
+	// ID3v1 fields go through the same table
+	tag := createID3v1Tag("\x93Quoted\x94", "", "", "", "")
+	sr := binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), "test.mp3")
+	file := &types.File{}
+	parseID3v1(sr, int64(len(tag)), file, types.Windows1252)
+	if file.Tags.Title != "“Quoted”" {
+		t.Errorf("expected ID3v1 title with smart quotes, got %q", file.Tags.Title)
+	}
+}
+
+func TestParseTextFrame_BPM(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"integer", "128", 128},
+		{"fractional rounds up", "128.5", 129},
+		{"fractional rounds down", "127.2", 127},
+		{"invalid", "fast", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &types.File{}
+			frame := ID3v2Frame{
+				ID:   "TBPM",
+				Data: append([]byte{0x00}, tt.text...),
+			}
+			parseTextFrame(frame, file)
+
+			if file.Tags.BPM != tt.expected {
+				t.Errorf("expected BPM %d, got %d", tt.expected, file.Tags.BPM)
+			}
+		})
+	}
+}
+
+func TestParseURLFrames(t *testing.T) {
+	file := &types.File{}
+	var chapters []ID3v2Frame
+
+	// WOAR: URL only, no encoding byte
+	processFrame(ID3v2Frame{ID: "WOAR", Data: []byte("https://artist.example.com")}, file, &chapters)
+
+	// WXXX: [encoding][description\0][url]
+	wxxx := append([]byte{0x00}, "Podcast Feed\x00https://feed.example.com/rss"...)
+	processFrame(ID3v2Frame{ID: "WXXX", Data: wxxx}, file, &chapters)
+
+	if got := file.Tags.URLs["WOAR"]; got != "https://artist.example.com" {
+		t.Errorf("expected WOAR URL, got %q", got)
+	}
+	if got := file.Tags.URLs["Podcast Feed"]; got != "https://feed.example.com/rss" {
+		t.Errorf("expected WXXX URL keyed by description, got %q", got)
+	}
+	if got := file.Tags.GetFirst("WOAR"); got != "https://artist.example.com" {
+		t.Errorf("expected raw WOAR, got %q", got)
+	}
+	if got := file.Tags.GetFirst("WXXX"); got != "https://feed.example.com/rss" {
+		t.Errorf("expected raw WXXX, got %q", got)
+	}
+}
+
+func TestProcessFrame_APICPresence(t *testing.T) {
+	file := &types.File{}
+	var chapters []ID3v2Frame
+
+	processFrame(ID3v2Frame{ID: "APIC", Data: []byte{0x00, 'i', 'm', 'a', 'g', 'e', '/', 'j', 'p', 'e', 'g', 0x00, 0x03, 0x00}}, file, &chapters)
+
+	if !file.HasEmbeddedArtwork {
+		t.Error("expected HasEmbeddedArtwork after APIC frame")
+	}
+}
+
+func TestProcessFrame_ITunesGroupingFrames(t *testing.T) {
+	file := &types.File{}
+	var chapters []ID3v2Frame
+
+	for _, frame := range []ID3v2Frame{
+		{ID: "GRP1", Data: append([]byte{0x00}, "The Expanse"...)},
+		{ID: "MVNM", Data: append([]byte{0x03}, "The Expanse"...)},
+		{ID: "MVIN", Data: append([]byte{0x00}, "3/9"...)},
+	} {
+		processFrame(frame, file, &chapters)
+	}
+
+	if file.Tags.Grouping != "The Expanse" {
+		t.Errorf("expected Grouping from GRP1, got %q", file.Tags.Grouping)
+	}
+	if file.Tags.Series != "The Expanse" {
+		t.Errorf("expected Series from MVNM, got %q", file.Tags.Series)
+	}
+	if file.Tags.SeriesPart != "3" {
+		t.Errorf("expected SeriesPart 3 from MVIN, got %q", file.Tags.SeriesPart)
+	}
+}
+
+func TestParseUSLTFrame(t *testing.T) {
+	uslt := func(descriptor, text string) ID3v2Frame {
+		data := append([]byte{0x03, 'e', 'n', 'g'}, descriptor...)
+		data = append(data, 0)
+		return ID3v2Frame{ID: "USLT", Data: append(data, text...)}
+	}
+	lyrics := "Første linje\nZweite Zeile\n"
+
+	tests := []struct {
+		name   string
+		frames []ID3v2Frame
+		want   string
+	}{
+		{"single frame", []ID3v2Frame{uslt("", lyrics)}, lyrics},
+		{"described frame only", []ID3v2Frame{uslt("Live", "live lyrics")}, "live lyrics"},
+		{"empty descriptor preferred", []ID3v2Frame{uslt("Live", "live lyrics"), uslt("", lyrics), uslt("", "other")}, lyrics},
+		{"null-terminated text", []ID3v2Frame{uslt("", lyrics+"\x00")}, lyrics},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &types.File{}
+			for _, frame := range tt.frames {
+				processFrame(frame, file, nil)
+			}
+			if file.Tags.Lyrics != tt.want {
+				t.Errorf("Lyrics = %q, want %q", file.Tags.Lyrics, tt.want)
+			}
+		})
+	}
+}
+
+// createSYLTFrame builds an ISO-8859-1 SYLT lyrics frame payload with the
+// given timestamp format and line/millisecond pairs.
+func createSYLTFrame(format byte, lines ...any) []byte {
+	data := []byte{0x00, 'e', 'n', 'g', format, 0x01}
+	data = append(data, "Descriptor\x00"...)
+	for i := 0; i+1 < len(lines); i += 2 {
+		data = append(data, lines[i].(string)...)
+		data = append(data, 0)
+		data = binary.BigEndian.AppendUint32(data, lines[i+1].(uint32))
+	}
+	return data
+}
+
+func TestParseSYLTFrame(t *testing.T) {
+	file := &types.File{}
+	processFrame(ID3v2Frame{ID: "SYLT", Data: createSYLTFrame(2, "\nSecond", uint32(2500), "First", uint32(1000))}, file, nil)
+
+	want := []types.LyricLine{
+		{Text: "First", Time: time.Second},
+		{Text: "Second", Time: 2500 * time.Millisecond},
+	}
+	if !slices.Equal(file.Tags.SyncedLyrics, want) {
+		t.Errorf("SyncedLyrics = %v, want %v", file.Tags.SyncedLyrics, want)
+	}
+
+	// A second frame, typically a translation, doesn't replace the first
+	processFrame(ID3v2Frame{ID: "SYLT", Data: createSYLTFrame(2, "Erste", uint32(1000))}, file, nil)
+	if len(file.Tags.SyncedLyrics) != 2 || file.Tags.SyncedLyrics[0].Text != "First" {
+		t.Errorf("SyncedLyrics replaced by a later frame: %v", file.Tags.SyncedLyrics)
+	}
+}
+
+func TestParseSYLTFrame_MPEGFrames(t *testing.T) {
+	file := &types.File{}
+	processFrame(ID3v2Frame{ID: "SYLT", Data: createSYLTFrame(1, "Line", uint32(38))}, file, nil)
+
+	if file.Tags.SyncedLyrics != nil {
+		t.Errorf("expected no lyrics from MPEG-frame timestamps, got %v", file.Tags.SyncedLyrics)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "metadata" {
+		t.Errorf("expected one metadata warning, got %v", file.Warnings)
+	}
+}
+
+func TestParseID3v2_Lyrics(t *testing.T) {
+	// A tag with both lyric frames fills both fields
+	frame := func(id string, data []byte) []byte {
+		f := append([]byte(id), encodeSynchsafe(uint32(len(data)))...)
+		return append(append(f, 0, 0), data...)
+	}
+	tag := createID3v24Tag(0,
+		frame("SYLT", createSYLTFrame(2, "First", uint32(1000))),
+		frame("USLT", []byte("\x00engDescriptor\x00First\nSecond")))
+
+	file := &types.File{}
+	sr := binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), "test.mp3")
+	if _, err := parseID3v2(context.Background(), sr, file, types.Latin1); err != nil {
+		t.Fatalf("parseID3v2() error = %v", err)
+	}
+
+	if want := []types.LyricLine{{Text: "First", Time: time.Second}}; !slices.Equal(file.Tags.SyncedLyrics, want) {
+		t.Errorf("SyncedLyrics = %v, want %v", file.Tags.SyncedLyrics, want)
+	}
+	if want := "First\nSecond"; file.Tags.Lyrics != want {
+		t.Errorf("Lyrics = %q, want %q", file.Tags.Lyrics, want)
+	}
+}
+
+func TestParseID3v2_ChapterSource(t *testing.T) {
+	frame := func(id string, data []byte) []byte {
+		f := append([]byte(id), encodeSynchsafe(uint32(len(data)))...)
+		return append(append(f, 0, 0), data...)
+	}
+	chap := append([]byte("ch1\x00"), make([]byte, 16)...)
+
+	tests := []struct {
+		name string
+		chap []byte
+		want string
+	}{
+		{"valid CHAP", chap, "mp3:id3-chap"},
+		{"truncated CHAP", chap[:12], ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := createID3v24Tag(0, frame("CHAP", tt.chap))
+			file := &types.File{}
+			sr := binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), "test.mp3")
+			if _, err := parseID3v2(context.Background(), sr, file, types.Latin1); err != nil {
+				t.Fatalf("parseID3v2() error = %v", err)
+			}
+			if file.ChapterSource != tt.want {
+				t.Errorf("ChapterSource = %q with chapters %v, want %q", file.ChapterSource, file.Chapters, tt.want)
+			}
+		})
+	}
 }
 
 func TestParseTXXXFrame(t *testing.T) {
@@ -272,6 +757,69 @@ func TestParseTXXXFrame(t *testing.T) {
 	}
 }
 
+func TestParseTXXXFrame_ReplayGain(t *testing.T) {
+	file := &types.File{}
+
+	for _, data := range []string{
+		"\x00REPLAYGAIN_TRACK_GAIN\x00-6.50 dB",
+		"\x00replaygain_track_peak\x000.988127",
+		"\x00REPLAYGAIN_ALBUM_GAIN\x00-7.10 dB",
+	} {
+		parseTXXXFrame(ID3v2Frame{ID: "TXXX", Data: []byte(data)}, file)
+	}
+
+	want := types.ReplayGainInfo{TrackGain: -6.5, TrackPeak: 0.988127, AlbumGain: -7.1}
+	if file.Audio.ReplayGain == nil || *file.Audio.ReplayGain != want {
+		t.Errorf("ReplayGain = %+v, want %+v", file.Audio.ReplayGain, want)
+	}
+}
+
+func TestParseRVA2Frame(t *testing.T) {
+	// Master volume -6.5 dB (-3328/512) with a 16-bit peak of 0.5, after a
+	// front-left channel entry that is ignored
+	rva2 := func(id string) []byte {
+		data := append([]byte(id), 0x00)
+		data = append(data, 0x03, 0x00, 0x00, 0x00)
+		return append(data, 0x01, 0xF3, 0x00, 0x10, 0x40, 0x00)
+	}
+
+	file := &types.File{}
+	processFrame(ID3v2Frame{ID: "RVA2", Data: rva2("track")}, file, nil)
+	processFrame(ID3v2Frame{ID: "RVA2", Data: rva2("ALBUM")}, file, nil)
+	processFrame(ID3v2Frame{ID: "RVA2", Data: rva2("normalize")}, file, nil)
+
+	want := types.ReplayGainInfo{TrackGain: -6.5, TrackPeak: 0.5, AlbumGain: -6.5, AlbumPeak: 0.5}
+	if file.Audio.ReplayGain == nil || *file.Audio.ReplayGain != want {
+		t.Errorf("ReplayGain = %+v, want %+v", file.Audio.ReplayGain, want)
+	}
+}
+
+func TestParseCommentFrame_Multiple(t *testing.T) {
+	file := &types.File{}
+
+	// Format: [encoding][language(3)][short description\0][text]
+	for _, data := range [][]byte{
+		append([]byte{0x00, 'e', 'n', 'g'}, "iTunNORM\x00 000001A2"...),
+		append([]byte{0x00, 'e', 'n', 'g'}, "\x00Recorded live"...),
+		append([]byte{0x00, 'd', 'e', 'u'}, "Review\x00Sehr gut"...),
+	} {
+		parseCommentFrame(ID3v2Frame{ID: "COMM", Data: data}, file)
+	}
+
+	want := []types.Comment{
+		{Language: "eng", Description: "iTunNORM", Text: " 000001A2"},
+		{Language: "eng", Text: "Recorded live"},
+		{Language: "deu", Description: "Review", Text: "Sehr gut"},
+	}
+	if !slices.Equal(file.Tags.Comments, want) {
+		t.Errorf("Comments = %+v, want %+v", file.Tags.Comments, want)
+	}
+	// The plain comment is primary, not the first or last frame
+	if file.Tags.Comment != "Recorded live" {
+		t.Errorf("Comment = %q, want %q", file.Tags.Comment, "Recorded live")
+	}
+}
+
 func TestParseChapterFrames(t *testing.T) {
 	// Create mock CHAP frames
 	frames := []ID3v2Frame{
@@ -300,7 +848,7 @@ func TestParseChapterFrames(t *testing.T) {
 		},
 	}
 
-	chapters := parseChapterFrames(frames, 20*time.Second)
+	chapters, _ := parseChapterFrames(frames, 20*time.Second)
 
 	if len(chapters) != 2 {
 		t.Fatalf("expected 2 chapters, got %d", len(chapters))
@@ -325,3 +873,864 @@ func TestParseChapterFrames(t *testing.T) {
 		t.Errorf("chapter 1: expected start time 10s, got %v", chapters[1].StartTime)
 	}
 }
+
+func TestParseChapterFrames_Subframes(t *testing.T) {
+	chap := func(id string, start, end uint32, subframes ...[]byte) ID3v2Frame {
+		data := append([]byte(id), 0)
+		data = binary.BigEndian.AppendUint32(data, start)
+		data = binary.BigEndian.AppendUint32(data, end)
+		data = append(data, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+		return ID3v2Frame{ID: "CHAP", Data: append(data, bytes.Join(subframes, nil)...), version: 4}
+	}
+	apic := func(data string) []byte {
+		frame, err := encodeAPICFrame(types.Artwork{MIMEType: "image/jpeg", Data: []byte(data)}, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return frame
+	}
+	title := append([]byte("TIT2\x00\x00\x00\x07\x00\x00\x03"), "Intro\x00"...)
+
+	chapters, _ := parseChapterFrames([]ID3v2Frame{
+		chap("ch1", 0, 1000, title, apic("\xFF\xD8one")), // Image after the title
+		chap("ch2", 1000, 2000, apic("\xFF\xD8two")),
+		chap("ch3", 2000, 3000),
+	}, 3*time.Second)
+
+	if len(chapters) != 3 {
+		t.Fatalf("expected 3 chapters, got %d", len(chapters))
+	}
+	if chapters[0].Title != "Intro" || chapters[1].Title != "ch2" {
+		t.Errorf("titles = %q, %q; want Intro and the element ID", chapters[0].Title, chapters[1].Title)
+	}
+	for i, want := range []string{"\xFF\xD8one", "\xFF\xD8two"} {
+		if img := chapters[i].Image; img == nil || string(img.Data) != want || img.MIMEType != "image/jpeg" {
+			t.Errorf("chapter %d image = %v, want its own JPEG", i+1, img)
+		}
+	}
+	if chapters[2].Image != nil {
+		t.Errorf("chapter 3 image = %v, want nil", chapters[2].Image)
+	}
+}
+
+func TestParseChapterFrames_TableOfContents(t *testing.T) {
+	chap := func(id string, start, end uint32) ID3v2Frame {
+		data := append([]byte(id), 0)
+		data = binary.BigEndian.AppendUint32(data, start)
+		data = binary.BigEndian.AppendUint32(data, end)
+		data = append(data, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+		return ID3v2Frame{ID: "CHAP", Data: data, version: 4}
+	}
+	ctoc := func(id string, flags byte, title string, children ...string) ID3v2Frame {
+		data := append([]byte(id), 0, flags, byte(len(children)))
+		for _, child := range children {
+			data = append(append(data, child...), 0)
+		}
+		if title != "" {
+			data = append(data, "TIT2"...)
+			data = binary.BigEndian.AppendUint32(data, uint32(len(title)+1))
+			data = append(append(data, 0, 0, 0), title...)
+		}
+		return ID3v2Frame{ID: "CTOC", Data: data, version: 3}
+	}
+	frames := []ID3v2Frame{
+		chap("ch1", 0, 1000),
+		chap("ch2", 1000, 2000),
+		chap("ch3", 2000, 3000),
+		chap("ch4", 3000, 4000),
+		chap("ch5", 4000, 5000),
+	}
+
+	// flatten lists titles depth first, parents in brackets
+	var flatten func(chapters []types.Chapter) string
+	flatten = func(chapters []types.Chapter) string {
+		var parts []string
+		for i, ch := range chapters {
+			if ch.Index != i+1 {
+				t.Errorf("%s: Index = %d, want %d", ch.Title, ch.Index, i+1)
+			}
+			if len(ch.Children) > 0 {
+				parts = append(parts, fmt.Sprintf("%s[%s]", ch.Title, flatten(ch.Children)))
+			} else {
+				parts = append(parts, ch.Title)
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+
+	tests := []struct {
+		name     string
+		tocs     []ID3v2Frame
+		want     string
+		wantTree string // Empty means no ChapterTree
+	}{
+		{"no table", nil, "ch1 ch2 ch3 ch4 ch5", ""},
+		{"ordered", []ID3v2Frame{ctoc("toc", 0x03, "", "ch3", "ch1", "ch2", "ch4", "ch5")}, "ch3 ch1 ch2 ch4 ch5", ""},
+		{"unordered", []ID3v2Frame{ctoc("toc", 0x02, "", "ch3", "ch1", "ch2", "ch4", "ch5")}, "ch1 ch2 ch3 ch4 ch5", ""},
+		{"not top-level", []ID3v2Frame{ctoc("toc", 0x01, "", "ch5", "ch4", "ch3", "ch2", "ch1")}, "ch1 ch2 ch3 ch4 ch5", ""},
+		{"unlisted last", []ID3v2Frame{ctoc("toc", 0x03, "", "ch4", "ch2")}, "ch4 ch2 ch1 ch3 ch5", ""},
+		{
+			"nested",
+			[]ID3v2Frame{
+				ctoc("part2", 0x01, "Part 2", "ch4", "ch3"),
+				ctoc("toc", 0x03, "", "ch1", "part1", "part2", "ch5"),
+				ctoc("part1", 0x00, "", "ch2"),
+			},
+			"ch1 ch2 ch4 ch3 ch5",
+			"ch1 part1[ch2] Part 2[ch4 ch3] ch5",
+		},
+		{
+			"cycle",
+			[]ID3v2Frame{
+				ctoc("toc", 0x03, "", "sub", "ch5"),
+				ctoc("sub", 0x01, "", "ch1", "ch2", "ch3", "ch4", "toc", "sub"),
+			},
+			"ch1 ch2 ch3 ch4 ch5",
+			"sub[ch1 ch2 ch3 ch4] ch5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chapters, tree := parseChapterFrames(append(slices.Clone(frames), tt.tocs...), 5*time.Second)
+			if got := flatten(chapters); got != tt.want {
+				t.Errorf("chapters = %s, want %s", got, tt.want)
+			}
+			if got := flatten(tree); got != tt.wantTree {
+				t.Errorf("tree = %s, want %s", got, tt.wantTree)
+			}
+		})
+	}
+
+	// A nested table spans its children
+	_, tree := parseChapterFrames(append(slices.Clone(frames),
+		ctoc("toc", 0x03, "", "part"), ctoc("part", 0x01, "", "ch4", "ch2")), 5*time.Second)
+	if part := tree[0]; part.StartTime != time.Second || part.EndTime != 4*time.Second {
+		t.Errorf("part spans %v-%v, want 1s-4s", part.StartTime, part.EndTime)
+	}
+}
+
+func TestRawFrameTag(t *testing.T) {
+	tests := []struct {
+		frame    ID3v2Frame
+		encoding string
+		typ      types.RawTagType
+	}{
+		{ID3v2Frame{ID: "TIT2", Data: []byte("\x03Title")}, "UTF-8", types.RawTagText},
+		{ID3v2Frame{ID: "TXXX", Data: []byte("\x01\xFF\xFE")}, "UTF-16", types.RawTagText},
+		{ID3v2Frame{ID: "APIC", Data: []byte("\x00image/png\x00\x03\x00")}, "ISO-8859-1", types.RawTagImage},
+		{ID3v2Frame{ID: "WOAR", Data: []byte("http://example.com")}, "", types.RawTagURL},
+		{ID3v2Frame{ID: "WXXX", Data: []byte("\x00\x00http://example.com")}, "ISO-8859-1", types.RawTagURL},
+		{ID3v2Frame{ID: "PCNT", Data: []byte{0, 0, 0, 7}}, "", types.RawTagCounter},
+		{ID3v2Frame{ID: "PRIV", Data: []byte("owner\x00\x03")}, "", types.RawTagBinary},
+		{ID3v2Frame{ID: "TIT2", Data: []byte("\x09bad encoding")}, "", types.RawTagText},
+	}
+
+	for _, tt := range tests {
+		got := rawFrameTag(tt.frame)
+		if got.Key != tt.frame.ID || !bytes.Equal(got.Value, tt.frame.Data) || got.Encoding != tt.encoding || got.Type != tt.typ {
+			t.Errorf("rawFrameTag(%s %q) = %+v, want encoding %q and type %d", tt.frame.ID, tt.frame.Data, got, tt.encoding, tt.typ)
+		}
+	}
+}
+
+func TestCountArtwork(t *testing.T) {
+	frame := func(id string, data []byte) []byte {
+		size := len(data)
+		out := []byte(id)
+		out = append(out, byte(size>>24), byte(size>>16), byte(size>>8), byte(size), 0x00, 0x00)
+		return append(out, data...)
+	}
+
+	apic := append([]byte{0x00}, []byte("image/jpeg\x00\x03\x00\xFF\xD8\xFF\xD9")...)
+	frames := append(frame("TIT2", []byte("\x00Title")), frame("APIC", apic)...)
+	frames = append(frames, frame("APIC", apic)...)
+
+	size := len(frames)
+	data := []byte{'I', 'D', '3', 0x03, 0x00, 0x00,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	data = append(data, frames...)
+
+	p := &parser{}
+	n, err := p.CountArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil {
+		t.Fatalf("CountArtwork failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 APIC frames, got %d", n)
+	}
+}
+
+func TestExtractChapters(t *testing.T) {
+	frame := func(id string, data []byte) []byte {
+		size := len(data)
+		out := []byte(id)
+		out = append(out, byte(size>>24), byte(size>>16), byte(size>>8), byte(size), 0x00, 0x00)
+		return append(out, data...)
+	}
+	chap := func(id string, start, end uint32) []byte {
+		data := append([]byte(id), 0)
+		data = binary.BigEndian.AppendUint32(data, start)
+		data = binary.BigEndian.AppendUint32(data, end)
+		return frame("CHAP", append(data, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF))
+	}
+
+	frames := frame("TIT2", []byte("\x00Title"))
+	frames = append(frames, chap("ch2", 1000, 2000)...)
+	frames = append(frames, frame("APIC", []byte("\x00image/jpeg\x00\x03\x00\xFF\xD8\xFF\xD9"))...)
+	frames = append(frames, chap("ch1", 0, 1000)...)
+
+	size := len(frames)
+	data := []byte{'I', 'D', '3', 0x03, 0x00, 0x00,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	data = append(data, frames...)
+
+	p := &parser{}
+	chapters, err := p.ExtractChapters(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil {
+		t.Fatalf("ExtractChapters failed: %v", err)
+	}
+	if len(chapters) != 2 || chapters[0].Title != "ch1" || chapters[1].Title != "ch2" {
+		t.Fatalf("chapters = %+v, want ch1 and ch2 by start time", chapters)
+	}
+	if chapters[1].EndTime != 2*time.Second {
+		t.Errorf("ch2 ends at %v, want 2s", chapters[1].EndTime)
+	}
+}
+
+func TestParseID3v2_FrameExceedsTag(t *testing.T) {
+	title := "Title"
+	frames := append([]byte{'T', 'I', 'T', '2', 0, 0, 0, byte(len(title) + 1), 0, 0, 0x00}, title...)
+	// TPE1 claims 1000 bytes, far more than the tag holds
+	frames = append(frames, 'T', 'P', 'E', '1', 0, 0, 0x03, 0xE8, 0, 0, 0x00, 'A')
+
+	size := len(frames)
+	data := []byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0, 0, 0, byte(size)}
+	data = append(data, frames...)
+	// Audio data after the tag must not be read as the TPE1 payload
+	data = append(data, bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x00}, 300)...)
+
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+	file := &types.File{}
+	if _, err := parseID3v2(context.Background(), sr, file, types.Latin1); err != nil {
+		t.Fatalf("parseID3v2 failed: %v", err)
+	}
+
+	if file.Tags.Title != "Title" {
+		t.Errorf("expected frames before the oversized one to parse, got title %q", file.Tags.Title)
+	}
+	if file.Tags.Artist != "" {
+		t.Errorf("expected oversized TPE1 to be skipped, got artist %q", file.Tags.Artist)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Offset != int64(10+len(title)+11) {
+		t.Errorf("expected one warning at the TPE1 frame, got %+v", file.Warnings)
+	}
+	if !file.HasErrors() {
+		t.Error("expected a truncated frame to be an error")
+	}
+}
+
+// createID3v1Tag creates a 128-byte ID3v1 tag.
+func createID3v1Tag(title, artist, album, year, comment string) []byte {
+	tag := make([]byte, 128)
+	copy(tag[0:3], "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	copy(tag[93:97], year)
+	copy(tag[97:127], comment)
+	tag[127] = 0xFF // no genre
+	return tag
+}
+
+func TestParseID3v1(t *testing.T) {
+	tag := createID3v1Tag("Old Title   ", "Old Artist", "Old Album", "1999", "Comment")
+	data := append(make([]byte, 64), tag...)
+
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+	file := &types.File{}
+	parseID3v1(sr, int64(len(data)), file, types.Latin1)
+
+	if file.Tags.Title != "Old Title" {
+		t.Errorf("expected padding-trimmed title, got %q", file.Tags.Title)
+	}
+	if file.Tags.Artist != "Old Artist" || file.Tags.Album != "Old Album" {
+		t.Errorf("unexpected artist/album %q/%q", file.Tags.Artist, file.Tags.Album)
+	}
+	if file.Tags.Year != 1999 {
+		t.Errorf("expected year 1999, got %d", file.Tags.Year)
+	}
+	if file.Tags.Comment != "Comment" {
+		t.Errorf("expected comment, got %q", file.Tags.Comment)
+	}
+}
+
+func TestParseID3v1_TrackAndGenre(t *testing.T) {
+	tag := createID3v1Tag("Title", "Artist", "", "", "Short comment")
+	tag[126] = 7  // ID3v1.1 track number
+	tag[127] = 17 // Rock
+
+	sr := binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), "test.mp3")
+	file := &types.File{}
+	parseID3v1(sr, int64(len(tag)), file, types.Latin1)
+
+	if file.Tags.TrackNumber != 7 {
+		t.Errorf("expected ID3v1.1 track 7, got %d", file.Tags.TrackNumber)
+	}
+	if !slices.Equal(file.Tags.Genres, []string{"Rock"}) {
+		t.Errorf("expected genre Rock, got %v", file.Tags.Genres)
+	}
+	if file.Tags.Comment != "Short comment" {
+		t.Errorf("unexpected comment %q", file.Tags.Comment)
+	}
+}
+
+func TestParseID3v1_Blank(t *testing.T) {
+	tests := []struct {
+		name  string
+		genre byte
+	}{
+		{"no genre", 0xFF},
+		{"zeroed genre", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := createID3v1Tag("", "", "", "", "")
+			copy(tag[3:33], "                              ")
+			tag[127] = tt.genre
+
+			sr := binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), "test.mp3")
+			file := &types.File{}
+			parseID3v1(sr, int64(len(tag)), file, types.Latin1)
+
+			if file.Tags.Genres != nil {
+				t.Errorf("Genres = %v, want none for a blank tag", file.Tags.Genres)
+			}
+			if len(file.Warnings) != 1 || file.Warnings[0].Stage != "metadata" {
+				t.Fatalf("expected one metadata warning for a blank tag, got %v", file.Warnings)
+			}
+			if file.Warnings[0].Severity != types.SeverityInfo {
+				t.Errorf("expected a blank tag to be informational, got %v", file.Warnings[0].Severity)
+			}
+		})
+	}
+}
+
+func TestParseID3v1_GenreZero(t *testing.T) {
+	tag := createID3v1Tag("Crossroad Blues", "", "", "", "")
+	tag[127] = 0
+
+	sr := binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), "test.mp3")
+	file := &types.File{}
+	parseID3v1(sr, int64(len(tag)), file, types.Latin1)
+
+	if !slices.Equal(file.Tags.Genres, []string{"Blues"}) {
+		t.Errorf("Genres = %v, want [Blues] when the tag has a title", file.Tags.Genres)
+	}
+}
+
+func TestParse_ID3v2TakesPrecedenceOverID3v1(t *testing.T) {
+	title := "New Title"
+	frame := append([]byte{'T', 'I', 'T', '2', 0, 0, 0, byte(len(title) + 1), 0, 0, 0x00}, title...)
+
+	data := []byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0, 0, 0, byte(len(frame))}
+	data = append(data, frame...)
+	data = append(data, 0xFF, 0xFB, 0x90, 0x00, 0x00, 0x00, 0x00, 0x00)
+	data = append(data, createID3v1Tag("Old Title", "Old Artist", "", "1999", "")...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Tags.Title != "New Title" {
+		t.Errorf("expected ID3v2 title to win, got %q", file.Tags.Title)
+	}
+	if file.Tags.Artist != "Old Artist" {
+		t.Errorf("expected ID3v1 to fill missing artist, got %q", file.Tags.Artist)
+	}
+	if file.Tags.Year != 1999 {
+		t.Errorf("expected ID3v1 to fill missing year, got %d", file.Tags.Year)
+	}
+}
+
+// createAPEv2Tag builds a footer-only APEv2 tag with one text item per
+// key/value pair.
+func createAPEv2Tag(pairs ...string) []byte {
+	items := &bytes.Buffer{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		binary.Write(items, binary.LittleEndian, uint32(len(pairs[i+1])))
+		binary.Write(items, binary.LittleEndian, uint32(0))
+		items.WriteString(pairs[i] + "\x00" + pairs[i+1])
+	}
+
+	tag := items.Bytes()
+	tag = append(tag, "APETAGEX"...)
+	tag = binary.LittleEndian.AppendUint32(tag, 2000)
+	tag = binary.LittleEndian.AppendUint32(tag, uint32(len(items.Bytes())+32))
+	tag = binary.LittleEndian.AppendUint32(tag, uint32(len(pairs)/2))
+	tag = binary.LittleEndian.AppendUint32(tag, 0)
+	return append(tag, make([]byte, 8)...)
+}
+
+func TestParse_APEv2Precedence(t *testing.T) {
+	title := "ID3v2 Title"
+	frame := append([]byte{'T', 'I', 'T', '2', 0, 0, 0, byte(len(title) + 1), 0, 0, 0x00}, title...)
+
+	data := []byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0, 0, 0, byte(len(frame))}
+	data = append(data, frame...)
+	data = append(data, 0xFF, 0xFB, 0x90, 0x00, 0x00, 0x00, 0x00, 0x00)
+	data = append(data, createAPEv2Tag("Title", "APE Title", "Artist", "APE Artist")...)
+	data = append(data, createID3v1Tag("Old Title", "Old Artist", "Old Album", "1999", "")...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Tags.Title != "ID3v2 Title" {
+		t.Errorf("expected ID3v2 title to win, got %q", file.Tags.Title)
+	}
+	if file.Tags.Artist != "APE Artist" {
+		t.Errorf("expected APEv2 artist over ID3v1, got %q", file.Tags.Artist)
+	}
+	if file.Tags.Album != "Old Album" {
+		t.Errorf("expected ID3v1 to fill missing album, got %q", file.Tags.Album)
+	}
+}
+
+func TestParse_APEv2ReplayGain(t *testing.T) {
+	tests := []struct {
+		name string
+		id3  []byte // Frames of a leading ID3v2.3 tag, if any
+		want types.ReplayGainInfo
+	}{
+		{
+			name: "APEv2 only",
+			want: types.ReplayGainInfo{TrackGain: -6.5, TrackPeak: 0.9, AlbumGain: -7.25},
+		},
+		{
+			name: "ID3v2 wins",
+			id3:  append([]byte{'T', 'X', 'X', 'X', 0, 0, 0, 31, 0, 0}, "\x00REPLAYGAIN_TRACK_GAIN\x00-3.00 dB"...),
+			want: types.ReplayGainInfo{TrackGain: -3, TrackPeak: 0.9, AlbumGain: -7.25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data []byte
+			if tt.id3 != nil {
+				data = []byte{'I', 'D', '3', 0x03, 0x00, 0x00, 0, 0, 0, byte(len(tt.id3))}
+				data = append(data, tt.id3...)
+			}
+			data = append(data, 0xFF, 0xFB, 0x90, 0x00, 0x00, 0x00, 0x00, 0x00)
+			data = append(data, createAPEv2Tag(
+				"REPLAYGAIN_TRACK_GAIN", "-6.50 dB",
+				"REPLAYGAIN_TRACK_PEAK", "0.900000",
+				"REPLAYGAIN_ALBUM_GAIN", "-7.25 dB",
+			)...)
+
+			p := &parser{}
+			file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if file.Audio.ReplayGain == nil || *file.Audio.ReplayGain != tt.want {
+				t.Errorf("ReplayGain = %+v, want %+v", file.Audio.ReplayGain, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractArtworkMetadata(t *testing.T) {
+	// PNG header followed by more data than the header probe reads
+	png := []byte{
+		0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A,
+		0x00, 0x00, 0x00, 0x0D, 'I', 'H', 'D', 'R',
+		0x00, 0x00, 0x05, 0x78, // width = 1400
+		0x00, 0x00, 0x05, 0x78, // height = 1400
+	}
+	png = append(png, make([]byte, 2*apicHeaderProbe)...)
+
+	apic := append([]byte("\x00image/png\x00\x03Cover\x00"), png...)
+	size := 10 + len(apic)
+	data := []byte{'I', 'D', '3', 0x04, 0x00, 0x00,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	data = append(data, 'A', 'P', 'I', 'C',
+		byte(len(apic)>>21&0x7F), byte(len(apic)>>14&0x7F), byte(len(apic)>>7&0x7F), byte(len(apic)&0x7F), 0x00, 0x00)
+	data = append(data, apic...)
+
+	p := &parser{}
+	artwork, err := p.ExtractArtworkMetadata(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil {
+		t.Fatalf("ExtractArtworkMetadata failed: %v", err)
+	}
+	if len(artwork) != 1 {
+		t.Fatalf("expected 1 picture, got %d", len(artwork))
+	}
+
+	art := artwork[0]
+	if art.Data != nil {
+		t.Errorf("expected nil Data, got %d bytes", len(art.Data))
+	}
+	if art.Size != len(png) {
+		t.Errorf("expected Size %d, got %d", len(png), art.Size)
+	}
+	if art.Width != 1400 || art.Height != 1400 || art.MIMEType != "image/png" || art.Description != "Cover" {
+		t.Errorf("unexpected picture metadata: %v", art)
+	}
+
+	full, err := p.ExtractArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil || len(full) != 1 || full[0].Size != len(full[0].Data) || full[0].Size != art.Size {
+		t.Errorf("full extraction disagrees with metadata: %v, %v", full, err)
+	}
+}
+
+// createLAMEFrame builds a 417-byte MPEG1 Layer III frame (128kbps, 44.1kHz,
+// joint stereo) carrying an Info header with every optional field and a
+// LAME extension.
+func createLAMEFrame(encoder string) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+
+	xing := 4 + 32 // Header + MPEG1 stereo side info
+	copy(frame[xing:], "Info")
+	frame[xing+7] = xingFlagFrames | xingFlagBytes | xingFlagTOC | xingFlagQuality
+	frame[xing+11] = 100 // Frame count
+
+	lame := xing + 8 + 4 + 4 + 100 + 4
+	copy(frame[lame:], encoder)
+	frame[lame+10] = 160                        // Lowpass 16kHz
+	frame[lame+12] = 0x40                       // Peak 0.5 (0x00400000)
+	frame[lame+15], frame[lame+16] = 0x2E, 0x41 // Radio: name 1, originator 3, -6.5 dB
+	frame[lame+17], frame[lame+18] = 0x4C, 0x14 // Audiophile: name 2, originator 3, +2.0 dB
+	crc := crc16(frame[:lame+34])
+	frame[lame+34], frame[lame+35] = byte(crc>>8), byte(crc)
+	return frame
+}
+
+func TestParseTechnicalInfo_LAMETag(t *testing.T) {
+	data := createLAMEFrame("LAME3.100")
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+
+	file := &types.File{}
+	if err := parseTechnicalInfo(context.Background(), sr, 0, int64(len(data)), file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(file.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", file.Warnings)
+	}
+	if file.Tags.Encoder != "LAME3.100" {
+		t.Errorf("Encoder = %q, want LAME3.100", file.Tags.Encoder)
+	}
+	if file.Audio.LowpassHz != 16000 {
+		t.Errorf("LowpassHz = %d, want 16000", file.Audio.LowpassHz)
+	}
+	rg := file.Audio.ReplayGain
+	if rg == nil {
+		t.Fatal("expected ReplayGain from the LAME tag")
+	}
+	if rg.TrackGain != -6.5 || rg.TrackPeak != 0.5 || rg.AlbumGain != 2.0 {
+		t.Errorf("ReplayGain = %+v, want track -6.5 dB peak 0.5, album +2.0 dB", *rg)
+	}
+}
+
+func TestParseTechnicalInfo_LAMETagBadCRC(t *testing.T) {
+	data := createLAMEFrame("Lavf58.76")
+	data[4+32+8+112+35] ^= 0xFF // Corrupt the stored CRC
+
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+	file := &types.File{}
+	if err := parseTechnicalInfo(context.Background(), sr, 0, int64(len(data)), file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(file.Warnings) != 1 || !strings.Contains(file.Warnings[0].Message, "CRC") {
+		t.Errorf("expected a CRC warning, got %v", file.Warnings)
+	}
+	if file.Tags.Encoder != "" || file.Audio.ReplayGain != nil {
+		t.Error("fields from a corrupt LAME tag should be ignored")
+	}
+}
+
+func TestReadID3v2_Embedded(t *testing.T) {
+	// An ID3v2 tag inside a container chunk, preceded by unrelated bytes
+	tag := []byte("ID3\x03\x00\x00\x00\x00\x00\x15" + // v2.3, 21 bytes of frames
+		"TIT2\x00\x00\x00\x0B\x00\x00\x00Test Title")
+	data := append([]byte("FORM\x00\x00\x00\x00AIFFID3 \x00\x00\x00\x1F"), tag...)
+	data = append(data, "trailing chunk"...)
+
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.aiff")
+	file := &types.File{}
+	var debug bytes.Buffer
+	ctx := registry.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if err := ReadID3v2(ctx, sr, 20, int64(len(tag)), file, types.Latin1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Tags.Title != "Test Title" {
+		t.Errorf("Title = %q, want %q", file.Tags.Title, "Test Title")
+	}
+	if got := debug.String(); !strings.Contains(got, "key=TIT2 offset=10 size=11") {
+		t.Errorf("debug log = %q, want the TIT2 frame at its offset in the tag", got)
+	}
+
+	if err := ReadID3v2(ctx, sr, 0, 20, &types.File{}, types.Latin1); err == nil {
+		t.Error("expected error when no tag is at the offset")
+	}
+}
+
+func TestParseTechnicalInfo_Canceled(t *testing.T) {
+	data := make([]byte, 64<<10) // no frame sync anywhere
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := parseTechnicalInfo(ctx, sr, 0, int64(len(data)), &types.File{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("parseTechnicalInfo() error = %v, want context.Canceled", err)
+	}
+}
+
+// createID3v24Tag builds an ID3v2.4 tag from complete frames, followed by
+// padding bytes of zeros.
+func createID3v24Tag(padding int, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	body = append(body, make([]byte, padding)...)
+	return append([]byte{'I', 'D', '3', 4, 0, 0}, append(encodeSynchsafe(uint32(len(body))), body...)...)
+}
+
+func TestWriteArtwork(t *testing.T) {
+	title := append([]byte("TIT2\x00\x00\x00\x06\x00\x00\x00"), "Title"...)
+	oldCover, err := encodeAPICFrame(types.Artwork{MIMEType: "image/png", Type: types.ArtworkFrontCover, Data: []byte("\x89PNGold")}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio := []byte{0xFF, 0xFB, 0x90, 0x00, 0, 0, 0, 0}
+
+	cover := types.Artwork{MIMEType: "image/jpeg", Type: types.ArtworkFrontCover, Description: "Vorderseite ü", Data: []byte("\xFF\xD8new")}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		inPlace bool
+	}{
+		{"replaces the cover in place", append(createID3v24Tag(512, title, oldCover), audio...), true},
+		{"grows the tag", append(createID3v24Tag(0, title, oldCover), audio...), false},
+		{"adds a tag", audio, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &parser{}
+			edit, err := p.WriteArtwork(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "test.mp3", nil, []types.Artwork{cover})
+			if err != nil {
+				t.Fatalf("WriteArtwork() error: %v", err)
+			}
+			if edit.InPlace() != tt.inPlace || (len(edit.Warnings) == 0) == !tt.inPlace {
+				t.Errorf("InPlace() = %v with warnings %v, want in place %v", edit.InPlace(), edit.Warnings, tt.inPlace)
+			}
+
+			out := append(append(slices.Clone(tt.data[:edit.Offset]), edit.Data...), tt.data[edit.Offset+edit.Length:]...)
+			if !bytes.HasSuffix(out, audio) {
+				t.Error("audio data changed")
+			}
+
+			art, err := extractArtwork(context.Background(), bytes.NewReader(out), int64(len(out)), "test.mp3", true)
+			if err != nil {
+				t.Fatalf("extractArtwork() error: %v", err)
+			}
+			if len(art) != 1 || art[0].Description != cover.Description || !bytes.Equal(art[0].Data, cover.Data) {
+				t.Errorf("artwork = %v, want only the new cover", art)
+			}
+
+			if len(tt.data) > len(audio) {
+				sr := binutil.NewSafeReader(bytes.NewReader(out), int64(len(out)), "test.mp3")
+				file := &types.File{}
+				if _, err := parseID3v2(context.Background(), sr, file, types.Latin1); err != nil || file.Tags.Title != "Title" {
+					t.Errorf("parseID3v2() = %v, Title %q; want the other frames kept", err, file.Tags.Title)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTechnicalInfo_VBRHeaders(t *testing.T) {
+	// frame builds a 1 KiB first frame with a VBR header at offset
+	frame := func(header []byte, offset int, fields ...any) []byte {
+		data := make([]byte, 1024)
+		copy(data, header)
+		pos := offset
+		for _, f := range fields {
+			switch v := f.(type) {
+			case string:
+				pos += copy(data[pos:], v)
+			case uint16:
+				binary.BigEndian.PutUint16(data[pos:], v)
+				pos += 2
+			case uint32:
+				binary.BigEndian.PutUint32(data[pos:], v)
+				pos += 4
+			}
+		}
+		return data
+	}
+	stereo := []byte{0xFF, 0xFB, 0x90, 0x00} // MPEG1, 128 kbps, 44.1 kHz
+	mono := []byte{0xFF, 0xFB, 0x90, 0xC0}
+	mpeg2 := []byte{0xFF, 0xF3, 0x80, 0x00} // MPEG2, 64 kbps, 22.05 kHz
+
+	tests := []struct {
+		name       string
+		data       []byte
+		duration   time.Duration
+		vbr        bool
+		bitrate    int
+		sampleRate int
+	}{
+		{
+			"Xing", frame(stereo, 36, "Xing", uint32(xingFlagFrames|xingFlagBytes), uint32(1225), uint32(640_000)),
+			32 * time.Second, true, 160_000, 44100,
+		},
+		{
+			"Xing mono", frame(mono, 21, "Xing", uint32(xingFlagFrames), uint32(441)),
+			11520 * time.Millisecond, true, 128000, 44100,
+		},
+		{
+			"Info", frame(stereo, 36, "Info", uint32(xingFlagFrames|xingFlagBytes), uint32(441), uint32(1234)),
+			11520 * time.Millisecond, false, 128000, 44100,
+		},
+		{
+			"VBRI", frame(stereo, 36, "VBRI", uint16(1), uint16(0), uint16(75), uint32(50000), uint32(882)),
+			23040 * time.Millisecond, true, 17361, 44100,
+		},
+		{
+			"MPEG2 Xing", frame(mpeg2, 21, "Xing", uint32(xingFlagFrames), uint32(441)),
+			11520 * time.Millisecond, true, 64000, 22050,
+		},
+		{
+			"no header", frame(stereo, 36),
+			64 * time.Millisecond, false, 128000, 44100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := binutil.NewSafeReader(bytes.NewReader(tt.data), int64(len(tt.data)), "test.mp3")
+			file := &types.File{}
+			if err := parseTechnicalInfo(context.Background(), sr, 0, int64(len(tt.data)), file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			audio := file.Audio
+			if audio.Duration != tt.duration || audio.VBR != tt.vbr {
+				t.Errorf("Duration = %v, VBR = %v; want %v, %v", audio.Duration, audio.VBR, tt.duration, tt.vbr)
+			}
+			if audio.Bitrate != tt.bitrate || audio.SampleRate != tt.sampleRate {
+				t.Errorf("Bitrate = %d, SampleRate = %d; want %d, %d", audio.Bitrate, audio.SampleRate, tt.bitrate, tt.sampleRate)
+			}
+		})
+	}
+}
+
+func TestParseTechnicalInfo_FrameScan(t *testing.T) {
+	// frames concatenates empty MPEG1 44.1 kHz stereo frames with the
+	// given bitrate indices
+	frames := func(indices ...byte) []byte {
+		var data []byte
+		for _, idx := range indices {
+			header := uint32(0xFFFB0000) | uint32(idx)<<12
+			frame := make([]byte, max(frameLength(header), 4))
+			binary.BigEndian.PutUint32(frame, header)
+			data = append(data, frame...)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		bitrate  int
+		vbr      bool
+		warnings int
+	}{
+		{"CBR", frames(9, 9, 9, 9), 128000, false, 0},
+		{"VBR", frames(9, 11, 13, 9), 176000, true, 0}, // 128, 192, 256, 128 kbps
+		{"reserved index", append([]byte{0xFF, 0xFB, 0xF0, 0x00}, frames(9, 9)...), 128000, false, 1},
+		{"free format", append([]byte{0xFF, 0xFB, 0x00, 0x00}, frames(9)...), 0, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := binutil.NewSafeReader(bytes.NewReader(tt.data), int64(len(tt.data)), "test.mp3")
+			file := &types.File{}
+			if err := parseTechnicalInfo(context.Background(), sr, 0, int64(len(tt.data)), file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if file.Audio.Bitrate != tt.bitrate || file.Audio.VBR != tt.vbr {
+				t.Errorf("Bitrate = %d, VBR = %v; want %d, %v", file.Audio.Bitrate, file.Audio.VBR, tt.bitrate, tt.vbr)
+			}
+			if file.Audio.SampleRate != 44100 || file.Audio.Channels != 2 {
+				t.Errorf("SampleRate = %d, Channels = %d; want 44100, 2", file.Audio.SampleRate, file.Audio.Channels)
+			}
+			if len(file.Warnings) != tt.warnings {
+				t.Errorf("Warnings = %v, want %d", file.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParse_SkipsUnreadableID3v2(t *testing.T) {
+	// An ID3v2.2 tag is not read, but its size still locates the audio
+	tag := []byte{'I', 'D', '3', 2, 0, 0, 0, 0, 0, 20}
+	tag = append(tag, bytes.Repeat([]byte{0xFF}, 20)...) // Would pass for frame sync
+	data := append(tag, 0xFF, 0xFB, 0x50, 0x00, 0, 0, 0, 0)
+
+	file, err := (&parser{}).Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Audio.Bitrate != 64000 {
+		t.Errorf("Bitrate = %d, want 64000 from the frame after the tag", file.Audio.Bitrate)
+	}
+}
+
+func TestExtractArtworkMetadata_Location(t *testing.T) {
+	image := []byte("\xFF\xD8cover\xFF\xD9")
+	apic, err := encodeAPICFrame(types.Artwork{MIMEType: "image/jpeg", Type: types.ArtworkFrontCover, Description: "Front", Data: image}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsynced := bytes.Clone(apic)
+	unsynced[9] |= frameFlagUnsync
+
+	tests := []struct {
+		name   string
+		frame  []byte
+		stored bool
+	}{
+		{"stored as is", apic, true},
+		{"unsynchronised", unsynced, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := createID3v24Tag(16, tt.frame)
+			p := &parser{}
+			artwork, err := p.ExtractArtworkMetadata(context.Background(), bytes.NewReader(data), int64(len(data)), "test.mp3")
+			if err != nil || len(artwork) != 1 {
+				t.Fatalf("ExtractArtworkMetadata() = %d images, %v; want 1", len(artwork), err)
+			}
+
+			offset, length, ok := types.ArtworkLocation(artwork[0])
+			if ok != tt.stored {
+				t.Fatalf("ArtworkLocation() ok = %v, want %v", ok, tt.stored)
+			}
+			if ok && !bytes.Equal(data[offset:offset+length], image) {
+				t.Errorf("ArtworkLocation() = %d, %d; covers %q, want %q", offset, length, data[offset:offset+length], image)
+			}
+		})
+	}
+}
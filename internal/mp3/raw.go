@@ -0,0 +1,37 @@
+package mp3
+
+import (
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// textEncodings names the ID3v2 text encoding bytes.
+var textEncodings = [...]string{"ISO-8859-1", "UTF-16", "UTF-16BE", "UTF-8"}
+
+// rawFrameTag describes a frame for File.RawTags. Encoding is read from
+// the first byte of the frames that start with one.
+func rawFrameTag(frame ID3v2Frame) types.RawTag {
+	tag := types.RawTag{Key: frame.ID, Value: frame.Data, Type: types.RawTagBinary}
+
+	hasEncoding := false
+	switch {
+	case frame.ID == "APIC":
+		tag.Type, hasEncoding = types.RawTagImage, true
+	case frame.ID == "PCNT", frame.ID == "POPM":
+		tag.Type = types.RawTagCounter
+	case frame.ID == "WXXX":
+		tag.Type, hasEncoding = types.RawTagURL, true
+	case strings.HasPrefix(frame.ID, "W"):
+		tag.Type = types.RawTagURL
+	case strings.HasPrefix(frame.ID, "T"):
+		tag.Type, hasEncoding = types.RawTagText, true
+	case frame.ID == "COMM", frame.ID == "USLT", frame.ID == "SYLT":
+		hasEncoding = true
+	}
+
+	if hasEncoding && len(frame.Data) > 0 && int(frame.Data[0]) < len(textEncodings) {
+		tag.Encoding = textEncodings[frame.Data[0]]
+	}
+	return tag
+}
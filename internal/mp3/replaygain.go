@@ -0,0 +1,70 @@
+package mp3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// rva2MasterVolume is the RVA2 channel type that adjusts all channels.
+const rva2MasterVolume = 0x01
+
+// parseRVA2Frame reads ReplayGain from an RVA2 (relative volume
+// adjustment) frame whose identification is "track" or "album", as
+// written by foobar2000 and mp3gain. Only the master volume adjustment is
+// used; per-channel ones have no ReplayGainInfo field.
+//
+// Format: [identification\0] then per channel: [1 byte] channel type,
+// [2 bytes] signed gain in 1/512 dB, [1 byte] bits of peak, [peak bytes].
+func parseRVA2Frame(frame ID3v2Frame, file *types.File) {
+	end := bytes.IndexByte(frame.Data, 0)
+	if end < 0 {
+		return
+	}
+	id := strings.ToLower(string(frame.Data[:end]))
+	if id != "track" && id != "album" {
+		return
+	}
+
+	data := frame.Data[end+1:]
+	for len(data) >= 4 {
+		channel := data[0]
+		gain := float64(int16(binary.BigEndian.Uint16(data[1:3]))) / 512
+		bits := int(data[3])
+		peakBytes := (bits + 7) / 8
+		if len(data) < 4+peakBytes {
+			return
+		}
+		peak := rva2Peak(data[4:4+peakBytes], bits)
+		data = data[4+peakBytes:]
+
+		if channel != rva2MasterVolume {
+			continue
+		}
+		if file.Audio.ReplayGain == nil {
+			file.Audio.ReplayGain = &types.ReplayGainInfo{}
+		}
+		if id == "track" {
+			file.Audio.ReplayGain.TrackGain, file.Audio.ReplayGain.TrackPeak = gain, peak
+		} else {
+			file.Audio.ReplayGain.AlbumGain, file.Audio.ReplayGain.AlbumPeak = gain, peak
+		}
+		return
+	}
+}
+
+// rva2Peak scales a peak of the given bit width to full scale = 1.0.
+// Peaks wider than 64 bits, or absent, read as 0.
+func rva2Peak(b []byte, bits int) float64 {
+	if bits == 0 || len(b) > 8 {
+		return 0
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return float64(v) / math.Exp2(float64(bits-1))
+}
@@ -0,0 +1,77 @@
+package mp3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/simonhull/audiometa/internal/apev2"
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// id3v2Source reads the leading ID3v2 tag and records its size so frame
+// scanning can start after it.
+type id3v2Source struct {
+	ctx     context.Context // Of the Parse call, for logging
+	charset types.Charset
+	tagSize int64
+}
+
+// ReadTags implements registry.TagSource.
+func (s *id3v2Source) ReadTags(sr *binutil.SafeReader, file *types.File) error {
+	tagSize, err := parseID3v2(s.ctx, sr, file, s.charset)
+	if err != nil {
+		// Not an ID3v2 file or parse error - frames are searched from 0
+		s.tagSize = 0
+		return fmt.Errorf("ID3v2 parsing failed: %w", err)
+	}
+	s.tagSize = tagSize
+	return nil
+}
+
+// id3v1Source reads the trailing 128-byte ID3v1 tag.
+type id3v1Source struct {
+	charset types.Charset
+}
+
+// ReadTags implements registry.TagSource.
+func (s id3v1Source) ReadTags(sr *binutil.SafeReader, file *types.File) error {
+	parseID3v1(sr, sr.Size(), file, s.charset)
+	return nil
+}
+
+// apev2Source reads a trailing APEv2 tag, as written by foobar2000 and
+// Mp3tag.
+type apev2Source struct{}
+
+// ReadTags implements registry.TagSource.
+func (apev2Source) ReadTags(sr *binutil.SafeReader, file *types.File) error {
+	if err := apev2.Parse(sr, sr.Size(), file); err != nil {
+		return fmt.Errorf("APEv2 parsing failed: %w", err)
+	}
+	return nil
+}
+
+// tagSources returns the MP3 tag containers in precedence order.
+//
+// ID3v2 is first: it has no field length limits, carries encodings other
+// than Latin-1, and is what taggers update. APEv2 fills what ID3v2 left
+// empty, the title included when there is no ID3v2 tag. ID3v1 comes last,
+// being the most limited. Both ID3 versions decode legacy 8-bit text with
+// v2's charset.
+func tagSources(v2 *id3v2Source) []registry.TagSource {
+	return []registry.TagSource{v2, apev2Source{}, id3v1Source{charset: v2.charset}}
+}
+
+// ReadID3v2 reads an ID3v2 tag embedded in another container, such as the
+// "id3 " chunk of a WAV or AIFF file, into file. The tag occupies the size
+// bytes at offset; it is parsed as an MP3's leading tag would be, so frame
+// offsets in warnings and on log are relative to the tag. Frames are
+// logged to the logger of ctx.
+func ReadID3v2(ctx context.Context, sr *binutil.SafeReader, offset, size int64, file *types.File, charset types.Charset) error {
+	if _, err := parseID3v2(ctx, sr.Section(offset, size), file, charset); err != nil {
+		return fmt.Errorf("ID3v2 parsing failed: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package mp3
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"time"
@@ -9,54 +10,172 @@ import (
 	"github.com/simonhull/audiometa/internal/types"
 )
 
-// MP3 bitrate table (MPEG1 Layer III) in kbps.
-var bitrateTable = []int{
-	0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0,
-}
+// Layer III bitrate tables in kbps, indexed by the header's bitrate index.
+var (
+	bitrateTable = []int{
+		0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0,
+	}
+	bitrateTableMPEG2 = []int{ // MPEG-2 and MPEG-2.5
+		0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0,
+	}
+)
 
-// MP3 sample rate table (MPEG1) in Hz.
+// MP3 sample rate table (MPEG1) in Hz. MPEG-2 halves each rate.
 var sampleRateTable = []int{
 	44100, 48000, 32000, 0,
 }
 
+// Samples per Layer III frame.
+const (
+	samplesPerFrameMPEG1 = 1152
+	samplesPerFrameMPEG2 = 576
+)
+
+// syncSearchCheckInterval is how many bytes the frame sync search steps
+// over between cancellation checks. Without a valid frame near the start,
+// the search runs to the end of the file.
+const syncSearchCheckInterval = 4096
+
+// frameScanLimit is how many frames scanFrames reads to tell a VBR stream
+// without a VBR header from a CBR one.
+const frameScanLimit = 64
+
+// Bitrate indices that name no bitrate.
+const (
+	bitrateFreeFormat = 0x0 // Encoder-chosen fixed bitrate, not in the header
+	bitrateReserved   = 0xF
+)
+
 // parseTechnicalInfo extracts bitrate, sample rate, codec, and duration from MP3 frames.
-func parseTechnicalInfo(sr *binutil.SafeReader, tagSize int64, fileSize int64, file *types.File) error {
+//
+// Returns ctx.Err() if ctx is done during the frame sync search.
+func parseTechnicalInfo(ctx context.Context, sr *binutil.SafeReader, tagSize int64, fileSize int64, file *types.File) error {
 	// Find first MP3 frame (after ID3 tag)
 	frameOffset := tagSize
+	warnedReserved := false
 
 	// Search for MP3 frame sync (11 bits set)
-	for frameOffset < fileSize-4 {
+	for ; frameOffset < fileSize-4; frameOffset++ {
+		if (frameOffset-tagSize)%syncSearchCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
 		header, err := findMP3FrameAt(sr, frameOffset)
-		if err == nil {
-			// Found valid frame
-			bitrate, sampleRate, channels := parseMP3FrameHeader(header)
-			if bitrate > 0 && sampleRate > 0 {
-				file.Audio.Bitrate = bitrate
-				file.Audio.SampleRate = sampleRate
-				file.Audio.Channels = channels
-				file.Audio.Codec = "MP3"
-
-				// Check for VBR header
-				duration, vbr := parseVBRHeader(sr, frameOffset, sampleRate, fileSize, tagSize)
-				if vbr {
-					file.Audio.Duration = duration
-					file.Audio.VBR = true
-				} else {
-					// CBR - estimate from bitrate and file size
-					file.Audio.Duration = estimateCBRDuration(bitrate, fileSize, tagSize)
-					file.Audio.VBR = false
-				}
-
-				return nil
+		if err != nil {
+			continue
+		}
+		bitrate, sampleRate, channels := parseMP3FrameHeader(header)
+		if sampleRate == 0 {
+			continue
+		}
+
+		switch (header >> 12) & 0xF {
+		case bitrateReserved:
+			// Likely a false sync in the audio data, so keep looking
+			if !warnedReserved {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "technical",
+					Message: "skipping MP3 frame header with a reserved bitrate index",
+					Offset:  frameOffset,
+				})
+				warnedReserved = true
+			}
+			continue
+		case bitrateFreeFormat:
+			file.Audio.SampleRate = sampleRate
+			file.Audio.Channels = channels
+			file.Audio.Codec = "MP3"
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "technical",
+				Message: "free-format MP3 stream: bitrate and duration are not known",
+				Offset:  frameOffset,
+			})
+			return nil
+		}
+
+		file.Audio.Bitrate = bitrate
+		file.Audio.SampleRate = sampleRate
+		file.Audio.Channels = channels
+		file.Audio.Codec = "MP3"
+
+		// An encoder's VBR header counts the frames; without one, sample
+		// the frame bitrates and estimate from the average and file size
+		if vh, ok := parseVBRHeader(sr, frameOffset, header); ok && vh.frames > 0 {
+			file.Audio.Duration = calculateDurationFromFrames(vh.frames, samplesPerFrame(header), sampleRate)
+			file.Audio.VBR = vh.vbr
+			if vh.vbr && vh.bytes > 0 && file.Audio.Duration > 0 {
+				// The first frame's bitrate says little about a VBR stream
+				file.Audio.Bitrate = int(float64(vh.bytes) * 8 / file.Audio.Duration.Seconds())
+			}
+		} else {
+			if average, vbr := scanFrames(sr, frameOffset, header); vbr {
+				file.Audio.Bitrate = average
+				file.Audio.VBR = true
 			}
+			file.Audio.Duration = estimateCBRDuration(file.Audio.Bitrate, fileSize, frameOffset)
 		}
 
-		frameOffset++
+		lame, err := parseLAMETag(sr, frameOffset, header)
+		if err != nil {
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:   "technical",
+				Message: err.Error(),
+				Err:     err,
+				Offset:  frameOffset,
+			})
+		} else if lame != nil {
+			applyLAMETag(lame, file)
+		}
+
+		return nil
 	}
 
 	return errors.New("no valid MP3 frame found")
 }
 
+// scanFrames walks up to frameScanLimit frames from the one with header
+// at offset, returning their average bitrate and whether it varied. The
+// walk stops early at anything that isn't a frame, such as a trailing tag.
+func scanFrames(sr *binutil.SafeReader, offset int64, header uint32) (average int, vbr bool) {
+	first := (header >> 12) & 0xF
+	total, n := 0, 0
+	for range frameScanLimit {
+		length := frameLength(header)
+		if length == 0 {
+			break
+		}
+		bitrate, _, _ := parseMP3FrameHeader(header)
+		total += bitrate
+		n++
+		vbr = vbr || (header>>12)&0xF != first
+
+		offset += length
+		next, err := findMP3FrameAt(sr, offset)
+		if err != nil {
+			break
+		}
+		header = next
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return total / n, vbr
+}
+
+// frameLength returns the size in bytes, header included, of the Layer
+// III frame with header, or 0 if the header names no bitrate or sample
+// rate.
+func frameLength(header uint32) int64 {
+	bitrate, sampleRate, _ := parseMP3FrameHeader(header)
+	if bitrate == 0 || sampleRate == 0 {
+		return 0
+	}
+	padding := int64((header >> 9) & 1)
+	return int64(samplesPerFrame(header)/8*bitrate/sampleRate) + padding
+}
+
 // findMP3FrameAt attempts to read an MP3 frame header at the given offset.
 func findMP3FrameAt(sr *binutil.SafeReader, offset int64) (uint32, error) {
 	buf := make([]byte, 4)
@@ -90,16 +209,19 @@ func findMP3FrameAt(sr *binutil.SafeReader, offset int64) (uint32, error) {
 
 // parseMP3FrameHeader extracts bitrate, sample rate, and channels from frame header.
 func parseMP3FrameHeader(header uint32) (bitrate, sampleRate, channels int) {
+	mpeg1 := (header>>19)&0x3 == 3
+
 	// Bitrate index (4 bits)
-	bitrateIdx := (header >> 12) & 0xF
-	if bitrateIdx < uint32(len(bitrateTable)) {
-		bitrate = bitrateTable[bitrateIdx] * 1000 // Convert to bps
+	bitrates := bitrateTable
+	if !mpeg1 {
+		bitrates = bitrateTableMPEG2
 	}
+	bitrate = bitrates[(header>>12)&0xF] * 1000 // Convert to bps
 
 	// Sample rate index (2 bits)
-	sampleRateIdx := (header >> 10) & 0x3
-	if sampleRateIdx < uint32(len(sampleRateTable)) {
-		sampleRate = sampleRateTable[sampleRateIdx]
+	sampleRate = sampleRateTable[(header>>10)&0x3]
+	if !mpeg1 {
+		sampleRate /= 2
 	}
 
 	// Channel mode (2 bits)
@@ -113,66 +235,79 @@ func parseMP3FrameHeader(header uint32) (bitrate, sampleRate, channels int) {
 	return
 }
 
-// parseVBRHeader checks for Xing/VBRI VBR headers and calculates accurate duration.
-func parseVBRHeader(sr *binutil.SafeReader, frameOffset int64, sampleRate int, _ int64, _ int64) (time.Duration, bool) {
-	// Xing/Info header is 36 bytes after frame header for MPEG1
-	// Check for "Xing" or "Info" marker
-	xingOffset := frameOffset + 36
-	buf := make([]byte, 120) // Read enough for Xing header
-	if err := sr.ReadAt(buf, xingOffset, "VBR header"); err != nil {
-		return 0, false
+// samplesPerFrame returns the number of samples per frame for header's
+// MPEG version.
+func samplesPerFrame(header uint32) int {
+	if (header>>19)&0x3 == 3 {
+		return samplesPerFrameMPEG1
 	}
+	return samplesPerFrameMPEG2
+}
+
+// vbrHeader is the stream summary an encoder writes in the first frame.
+type vbrHeader struct {
+	frames uint32 // Total frames, 0 if not recorded
+	bytes  uint32 // Total stream bytes, 0 if not recorded
+	vbr    bool   // False for LAME's "Info" header, written to CBR files
+}
 
-	// Check for Xing or Info marker
-	isXing := string(buf[0:4]) == "Xing" || string(buf[0:4]) == "Info"
-	if !isXing {
-		// Try VBRI header (different location)
-		vbriOffset := frameOffset + 36
-		vbriBuf := make([]byte, 32)
-		if err := sr.ReadAt(vbriBuf, vbriOffset, "VBRI header"); err == nil {
-			if string(vbriBuf[0:4]) == "VBRI" {
-				// VBRI header found
-				// Frames are at offset 14 (4 bytes)
-				if len(vbriBuf) >= 18 {
-					numFrames := binary.BigEndian.Uint32(vbriBuf[14:18])
-					return calculateDurationFromFrames(numFrames, sampleRate), true
-				}
+// parseVBRHeader reads a Xing, Info or VBRI header from the frame at
+// frameOffset. ok is false when the frame has none.
+//
+// Xing and Info headers follow the side information; VBRI (Fraunhofer) is
+// always 32 bytes past the frame header:
+//
+//	Xing: "Xing"/"Info" [4 flags] then, as flagged, [4 frames] [4 bytes] ...
+//	VBRI: "VBRI" [2 version] [2 delay] [2 quality] [4 bytes] [4 frames] ...
+func parseVBRHeader(sr *binutil.SafeReader, frameOffset int64, header uint32) (vbrHeader, bool) {
+	buf := make([]byte, 16)
+	if err := sr.ReadAt(buf, frameOffset+4+sideInfoSize(header), "Xing header"); err == nil {
+		if marker := string(buf[0:4]); marker == "Xing" || marker == "Info" {
+			vh := vbrHeader{vbr: marker == "Xing"}
+			flags := binary.BigEndian.Uint32(buf[4:8])
+			field := buf[8:]
+			if flags&xingFlagFrames != 0 {
+				vh.frames = binary.BigEndian.Uint32(field[0:4])
+				field = field[4:]
+			}
+			if flags&xingFlagBytes != 0 {
+				vh.bytes = binary.BigEndian.Uint32(field[0:4])
 			}
+			return vh, true
 		}
-		return 0, false
 	}
 
-	// Parse Xing header
-	flags := binary.BigEndian.Uint32(buf[4:8])
-
-	// Frames field is present if bit 0 is set
-	if flags&0x0001 != 0 {
-		numFrames := binary.BigEndian.Uint32(buf[8:12])
-		return calculateDurationFromFrames(numFrames, sampleRate), true
+	buf = make([]byte, 18)
+	if err := sr.ReadAt(buf, frameOffset+4+32, "VBRI header"); err == nil && string(buf[0:4]) == "VBRI" {
+		return vbrHeader{
+			bytes:  binary.BigEndian.Uint32(buf[10:14]),
+			frames: binary.BigEndian.Uint32(buf[14:18]),
+			vbr:    true,
+		}, true
 	}
 
-	return 0, false
+	return vbrHeader{}, false
 }
 
 // calculateDurationFromFrames calculates duration from number of frames.
-func calculateDurationFromFrames(numFrames uint32, sampleRate int) time.Duration {
-	// Each MPEG1 Layer III frame = 1152 samples
-	samplesPerFrame := 1152
-	totalSamples := uint64(numFrames) * uint64(samplesPerFrame)
-	durationSeconds := float64(totalSamples) / float64(sampleRate)
-	return time.Duration(durationSeconds * float64(time.Second))
+func calculateDurationFromFrames(numFrames uint32, samplesPerFrame, sampleRate int) time.Duration {
+	if sampleRate == 0 {
+		return 0
+	}
+	// Whole seconds first; samples times time.Second overflows after about 58 hours at 44.1 kHz
+	totalSamples := int64(numFrames) * int64(samplesPerFrame)
+	rate := int64(sampleRate)
+	return time.Duration(totalSamples/rate)*time.Second + time.Duration(totalSamples%rate)*time.Second/time.Duration(rate)
 }
 
-// estimateCBRDuration estimates duration for constant bitrate files.
-func estimateCBRDuration(bitrate int, fileSize int64, tagSize int64) time.Duration {
+// estimateCBRDuration estimates duration from a constant or average
+// bitrate and the bytes after audioStart, the first frame.
+func estimateCBRDuration(bitrate int, fileSize int64, audioStart int64) time.Duration {
 	if bitrate == 0 {
 		return 0
 	}
 
-	// Audio data size (excluding ID3 tag)
-	audioSize := fileSize - tagSize
-
 	// Duration = (audio size in bytes * 8 bits/byte) / bitrate
-	durationSeconds := float64(audioSize*8) / float64(bitrate)
+	durationSeconds := float64((fileSize-audioStart)*8) / float64(bitrate)
 	return time.Duration(durationSeconds * float64(time.Second))
 }
@@ -0,0 +1,77 @@
+package mp3
+
+import (
+	"bytes"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+)
+
+// Unsynchronisation flags. Writers insert a 0x00 after every 0xFF in the
+// tag so that no byte pair inside it looks like an MPEG frame sync.
+const (
+	headerFlagUnsync    = 0x80   // Tag header: the whole tag (v2.3) or all frames (v2.4)
+	frameFlagUnsync     = 0x0002 // v2.4 frame header: this frame's data
+	frameFlagDataLength = 0x0001 // v2.4 frame header: data starts with a 4-byte length
+)
+
+// resynchronize reverses unsynchronisation, replacing each 0xFF 0x00 pair
+// with 0xFF.
+func resynchronize(data []byte) []byte {
+	if bytes.Index(data, []byte{0xFF, 0x00}) < 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		out = append(out, data[i])
+		if data[i] == 0xFF && i+1 < len(data) && data[i+1] == 0x00 {
+			i++
+		}
+	}
+	return out
+}
+
+// resyncTag returns a reader over an ID3v2.3 tag with unsynchronisation
+// reversed, and header with Size adjusted to match. In v2.3 the frame
+// sizes count the resynchronized bytes, so the whole tag must be undone
+// before its frames can be walked. Other tags are returned as is.
+func resyncTag(sr *binutil.SafeReader, header ID3v2Header) (*binutil.SafeReader, ID3v2Header) {
+	if header.Version != 3 || header.Flags&headerFlagUnsync == 0 {
+		return sr, header
+	}
+
+	tag := make([]byte, min(10+int64(header.Size), sr.Size()))
+	if err := sr.ReadAt(tag, 0, "ID3v2 tag"); err != nil {
+		// Leave it to the frame walk to report the unreadable tag
+		return sr, header
+	}
+
+	tag = append(tag[:10], resynchronize(tag[10:])...)
+	header.Size = uint32(len(tag) - 10)
+	return binutil.NewSafeReader(bytes.NewReader(tag), int64(len(tag)), sr.Path()), header
+}
+
+// decodeFrameData strips the data length indicator from ID3v2.4 frame
+// data and reverses unsynchronisation, as the frame flags and tag header
+// call for.
+func decodeFrameData(data []byte, header ID3v2Header, flags uint16) []byte {
+	if header.Version != 4 {
+		return data
+	}
+	if flags&frameFlagDataLength != 0 && len(data) >= 4 {
+		data = data[4:]
+	}
+	if flags&frameFlagUnsync != 0 || header.Flags&headerFlagUnsync != 0 {
+		data = resynchronize(data)
+	}
+	return data
+}
+
+// frameStoredVerbatim reports whether frame data is stored in the file as
+// decodeFrameData returns it, with no unsynchronisation (of the tag, as
+// resyncTag undoes, or of the frame) or data length indicator to remove.
+func frameStoredVerbatim(header ID3v2Header, flags uint16) bool {
+	if header.Flags&headerFlagUnsync != 0 {
+		return false
+	}
+	return header.Version != 4 || flags&(frameFlagDataLength|frameFlagUnsync) == 0
+}
@@ -0,0 +1,197 @@
+package mp3
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// defaultPadding is the padding written after the frames when the tag has
+// to grow, so the next few edits can be made in place.
+const defaultPadding = 2048
+
+// maxSynchsafe is the largest size a 28-bit synchsafe integer holds.
+const maxSynchsafe = 1<<28 - 1
+
+// Tag header flags dropped on rewrite.
+const (
+	headerFlagExtended = 0x40 // Its CRC and padding size would be stale
+	headerFlagFooter   = 0x10 // v2.4 only
+)
+
+// WriteArtwork implements registry.ArtworkWriter. Tags are not written
+// for MP3, so tags is ignored.
+//
+// Every APIC frame is dropped from the ID3v2 tag and one frame per image
+// in art is added after the other frames, which are copied byte for byte.
+// A file without an ID3v2 tag gets a new ID3v2.4 tag at the start.
+//
+// The new tag is padded to the size of the old one when it fits, so the
+// edit applies in place; otherwise it gets fresh padding and the edit
+// carries a "write" warning that the audio data will move. The extended
+// header and footer are not kept, and a v2.3 tag is written without
+// unsynchronisation.
+func (p *parser) WriteArtwork(ctx context.Context, r io.ReaderAt, size int64, path string, _ *types.Tags, art []types.Artwork) (*registry.TagEdit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+	edit := &registry.TagEdit{Length: types.ID3v2TagSize(sr)}
+
+	header := ID3v2Header{Version: 4}
+	var frames []byte
+	if edit.Length > 0 {
+		var err error
+		if header, err = parseID3v2Header(sr); err != nil {
+			return nil, err
+		}
+		if frames, err = keptFrames(ctx, sr, header); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, a := range art {
+		frame, err := encodeAPICFrame(a, header.Version)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+		frames = append(frames, frame...)
+	}
+
+	padding := edit.Length - 10 - int64(len(frames))
+	if padding < 0 || edit.Length == 0 {
+		padding = defaultPadding
+		edit.Warnings = append(edit.Warnings, types.Warning{
+			Stage:    "write",
+			Message:  fmt.Sprintf("ID3v2 tag grew to %d bytes; audio frames will move and the whole file is rewritten", 10+int64(len(frames))+padding),
+			Severity: types.SeverityInfo,
+		})
+	}
+	tagSize := int64(len(frames)) + padding
+	if tagSize > maxSynchsafe {
+		return nil, fmt.Errorf("ID3v2 tag too large: %d bytes", tagSize)
+	}
+
+	edit.Data = make([]byte, 0, 10+tagSize)
+	edit.Data = append(edit.Data, 'I', 'D', '3', header.Version, 0)
+	edit.Data = append(edit.Data, header.Flags&^(headerFlagUnsync|headerFlagExtended|headerFlagFooter))
+	edit.Data = append(edit.Data, encodeSynchsafe(uint32(tagSize))...)
+	edit.Data = append(edit.Data, frames...)
+	edit.Data = append(edit.Data, make([]byte, padding)...)
+	return edit, nil
+}
+
+// keptFrames returns the frames of the tag in sr other than APIC, as
+// stored. v2.3 frames come back resynchronized; v2.4 frames of a tag
+// flagged as unsynchronised get the flag in their own header instead, so
+// the tag-wide flag can be cleared.
+func keptFrames(ctx context.Context, sr *binutil.SafeReader, header ID3v2Header) ([]byte, error) {
+	tagUnsync := header.Version == 4 && header.Flags&headerFlagUnsync != 0
+	sr, header = resyncTag(sr, header)
+	tagEnd := int64(10 + header.Size)
+
+	var frames []byte
+	for offset := skipExtendedHeader(sr, header); offset+10 <= tagEnd; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		frameHeader := make([]byte, 10)
+		if err := sr.ReadAt(frameHeader, offset, "frame header"); err != nil {
+			return nil, err
+		}
+		if frameHeader[0] == 0 {
+			break // Padding
+		}
+
+		frameID := string(frameHeader[0:4])
+		frameSize := decodeFrameSize(header.Version, frameHeader[4:8])
+		if frameExceedsTag(offset, frameSize, tagEnd) {
+			return nil, &types.CorruptedFileError{
+				Path:   sr.Path(),
+				Offset: offset,
+				Reason: fmt.Sprintf("frame %s declares %d bytes but only %d remain in the tag", frameID, frameSize, tagEnd-offset-10),
+			}
+		}
+
+		if frameID != "APIC" {
+			frame := make([]byte, 10+frameSize)
+			if err := sr.ReadAtContext(ctx, frame, offset, fmt.Sprintf("frame %s", frameID)); err != nil {
+				return nil, err
+			}
+			if tagUnsync {
+				frame[9] |= frameFlagUnsync
+			}
+			frames = append(frames, frame...)
+		}
+		offset += 10 + int64(frameSize)
+	}
+	return frames, nil
+}
+
+// encodeAPICFrame builds an APIC frame, header included, for a tag of the
+// given major version. The description is written as ISO-8859-1 when it
+// is plain ASCII, else as UTF-8 (v2.4) or BOM-prefixed UTF-16 (v2.3).
+//
+// Format: [encoding][MIME type\0][picture type][description\0][data].
+func encodeAPICFrame(art types.Artwork, version byte) ([]byte, error) {
+	encoding, description := byte(0), []byte(art.Description)
+	if !isASCII(art.Description) {
+		if version == 4 {
+			encoding = 3
+		} else {
+			encoding = 1
+			description = encodeUTF16(art.Description)
+		}
+	}
+
+	data := append([]byte{encoding}, art.MIMEType...)
+	data = append(data, 0, byte(art.Type))
+	data = append(data, description...)
+	data = append(data, make([]byte, terminatorSize(encoding))...)
+	data = append(data, art.Data...)
+	if len(data) > maxSynchsafe {
+		return nil, fmt.Errorf("APIC frame too large: %d bytes", len(data))
+	}
+
+	frame := make([]byte, 10, 10+len(data))
+	copy(frame, "APIC")
+	if version == 4 {
+		copy(frame[4:8], encodeSynchsafe(uint32(len(data))))
+	} else {
+		binary.BigEndian.PutUint32(frame[4:8], uint32(len(data)))
+	}
+	return append(frame, data...), nil
+}
+
+// encodeSynchsafe encodes n, which must fit in 28 bits, as a synchsafe
+// integer: four bytes of 7 bits each.
+func encodeSynchsafe(n uint32) []byte {
+	return []byte{byte(n>>21) & 0x7F, byte(n>>14) & 0x7F, byte(n>>7) & 0x7F, byte(n) & 0x7F}
+}
+
+// isASCII reports whether s is 7-bit ASCII, and so the same in ISO-8859-1.
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeUTF16 encodes s as little-endian UTF-16 with a byte order mark.
+func encodeUTF16(s string) []byte {
+	out := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, u)
+	}
+	return out
+}
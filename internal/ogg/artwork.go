@@ -1,22 +1,32 @@
 package ogg
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
 	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/picture"
+	"github.com/simonhull/audiometa/internal/registry"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
 // ExtractArtwork extracts embedded artwork from Ogg Vorbis/Opus files.
 //
 // Ogg files can embed artwork via the METADATA_BLOCK_PICTURE Vorbis comment,
-// which contains a base64-encoded FLAC picture block.
+// which contains a base64-encoded FLAC picture block. Older taggers wrote
+// the base64 image alone as COVERART, with its type in COVERARTMIME; those
+// follow the pictures, as front covers.
+//
+// The comments are read from the comment header directly rather than from
+// Tags, which keeps only the last value of a repeated name.
 func (p *parser) ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -24,139 +34,232 @@ func (p *parser) ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64,
 
 	sr := binutil.NewSafeReader(r, size, path)
 
-	// Parse file to get Vorbis comments
-	file, err := p.Parse(ctx, r, size, path)
+	data, listOffset, err := readCommentHeader(sr, size)
 	if err != nil {
 		return nil, fmt.Errorf("parse file: %w", err)
 	}
 
-	var artwork []types.Artwork
+	var pictures, covers, mimeTypes []string
+	eachComment(data, listOffset, func(comment []byte, complete bool) bool {
+		key, value, ok := strings.Cut(string(comment), "=")
+		if !complete || !ok {
+			return true
+		}
+		switch strings.ToUpper(key) {
+		case "METADATA_BLOCK_PICTURE":
+			pictures = append(pictures, value)
+		case "COVERART":
+			covers = append(covers, value)
+		case "COVERARTMIME":
+			mimeTypes = append(mimeTypes, value)
+		}
+		return true
+	})
 
-	// Look for METADATA_BLOCK_PICTURE tags
-	for key, values := range file.Tags.All() {
-		if !strings.EqualFold(key, "METADATA_BLOCK_PICTURE") {
+	var artwork []types.Artwork
+	for _, value := range pictures {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Over the WithMaxArtworkSize limit, decode no more than the header
+		if length, err := pictureDataLength(value); err == nil && registry.ArtworkTooLarge(ctx, length) {
+			artwork = append(artwork, types.Artwork{Size: int(length)})
 			continue
 		}
-
-		for _, value := range values {
-			pic, err := parseMetadataBlockPicture(value)
-			if err != nil {
-				// Skip invalid pictures but continue
-				continue
-			}
-			artwork = append(artwork, pic)
+		pic, err := parseMetadataBlockPicture(ctx, value)
+		if err != nil {
+			// Skip invalid pictures but continue
+			continue
 		}
+		artwork = append(artwork, pic)
 	}
 
-	// Also check raw comments in case they weren't parsed into Tags.raw
-	// (defensive - they should be there)
-	_ = sr // silence unused warning; sr could be used for direct parsing if needed
+	for i, value := range covers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if length := int64(base64.StdEncoding.DecodedLen(len(value))); registry.ArtworkTooLarge(ctx, length) {
+			artwork = append(artwork, types.Artwork{Size: int(length)})
+			continue
+		}
+		pic, err := parseLegacyCoverArt(value, mimeTypes, i)
+		if err != nil {
+			continue
+		}
+		artwork = append(artwork, pic)
+	}
 
 	return artwork, nil
 }
 
-// parseMetadataBlockPicture decodes a METADATA_BLOCK_PICTURE value.
-//
-// The value is base64-encoded data containing a FLAC picture block:
-//   - 4 bytes: picture type (uint32 BE)
-//   - 4 bytes: MIME type length
-//   - N bytes: MIME type string
-//   - 4 bytes: description length
-//   - N bytes: description string (UTF-8)
-//   - 4 bytes: width
-//   - 4 bytes: height
-//   - 4 bytes: color depth
-//   - 4 bytes: colors used (0 for non-indexed)
-//   - 4 bytes: image data length
-//   - N bytes: image data
-func parseMetadataBlockPicture(base64Value string) (types.Artwork, error) {
-	// Decode base64
+// parseLegacyCoverArt decodes the i-th COVERART value, a base64 image.
+// Its MIME type is the i-th of mimeTypes, the COVERARTMIME values, or is
+// sniffed from the image when there are fewer.
+func parseLegacyCoverArt(base64Value string, mimeTypes []string, i int) (types.Artwork, error) {
 	data, err := base64.StdEncoding.DecodeString(base64Value)
 	if err != nil {
 		return types.Artwork{}, fmt.Errorf("invalid base64: %w", err)
 	}
-
-	// Minimum size: 4 + 4 + 0 + 4 + 0 + 4 + 4 + 4 + 4 + 4 + 0 = 32 bytes
-	if len(data) < 32 {
-		return types.Artwork{}, fmt.Errorf("picture block too small: %d bytes", len(data))
+	if len(data) == 0 {
+		return types.Artwork{}, errors.New("empty COVERART image")
 	}
 
-	offset := 0
+	var mimeType string
+	if i < len(mimeTypes) {
+		mimeType = mimeTypes[i]
+	} else if sniffed := http.DetectContentType(data); strings.HasPrefix(sniffed, "image/") {
+		mimeType = sniffed
+	}
 
-	// Read picture type (32-bit big-endian)
-	pictureType := binary.BigEndian.Uint32(data[offset:])
-	offset += 4
+	return types.Artwork{
+		Data:     data,
+		MIMEType: mimeType,
+		Type:     types.ArtworkFrontCover,
+		Size:     len(data),
+	}, nil
+}
 
-	// Read MIME type length
-	mimeLength := binary.BigEndian.Uint32(data[offset:])
-	offset += 4
+// pictureCommentKeys are the Vorbis comment keys holding embedded artwork.
+var pictureCommentKeys = []string{"METADATA_BLOCK_PICTURE=", "COVERART="}
 
-	if offset+int(mimeLength) > len(data) {
-		return types.Artwork{}, errors.New("MIME type length exceeds data")
+// CountArtwork counts METADATA_BLOCK_PICTURE and COVERART comments in the
+// comment header.
+//
+// Only comment keys are inspected; the base64 picture data is never
+// decoded, and a picture comment cut short by the header page limit still
+// counts as long as its key was read.
+func (p *parser) CountArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
 	}
 
-	// Read MIME type
-	mimeType := string(data[offset : offset+int(mimeLength)])
-	offset += int(mimeLength)
+	sr := binutil.NewSafeReader(r, size, path)
 
-	// Read description length
-	if offset+4 > len(data) {
-		return types.Artwork{}, errors.New("unexpected end of data")
+	data, listOffset, err := readCommentHeader(sr, size)
+	if err != nil {
+		return 0, nil
 	}
-	descLength := binary.BigEndian.Uint32(data[offset:])
-	offset += 4
+	return countPictureComments(data, listOffset), nil
+}
 
-	if offset+int(descLength) > len(data) {
-		return types.Artwork{}, errors.New("description length exceeds data")
+// readCommentHeader returns the comment header packet and the offset of
+// its comment list, just past the packet magic. The headers live in the
+// first pages, as in Parse. A stream without a Vorbis or Opus comment
+// header yields no data and no error.
+func readCommentHeader(sr *binutil.SafeReader, size int64) (data []byte, listOffset int, err error) {
+	pages, _, _, err := readHeaderPages(sr, size)
+	if len(pages) == 0 {
+		return nil, 0, cmp.Or(err, errors.New("no Ogg pages found"))
 	}
 
-	// Read description
-	description := string(data[offset : offset+int(descLength)])
-	offset += int(descLength)
+	packets := extractPackets(pages)
+	if len(packets) < 2 {
+		return nil, 0, nil
+	}
 
-	// Read width, height (skip color depth and indexed colors)
-	if offset+16 > len(data) {
-		return types.Artwork{}, errors.New("unexpected end of data")
+	switch detectOggCodec(packets[0]) {
+	case codecVorbis:
+		return packets[1], 7, nil // 0x03 + "vorbis"
+	case "opus":
+		return packets[1], 8, nil // "OpusTags"
+	default:
+		return nil, 0, nil
 	}
-	width := binary.BigEndian.Uint32(data[offset:])
-	offset += 4
-	height := binary.BigEndian.Uint32(data[offset:])
-	offset += 4
+}
 
-	// Skip color depth and indexed colors
-	offset += 8
+// countPictureComments walks a Vorbis comment list starting at offset
+// (vendor length) and counts comments whose key is one of
+// pictureCommentKeys.
+func countPictureComments(data []byte, offset int) int {
+	count := 0
+	eachComment(data, offset, func(comment []byte, _ bool) bool {
+		for _, key := range pictureCommentKeys {
+			if len(comment) >= len(key) && strings.EqualFold(string(comment[:len(key)]), key) {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
 
-	// Read picture data length
+// eachComment calls fn with each comment of a Vorbis comment list starting
+// at offset (vendor length), until fn returns false. A comment cut short by
+// the end of data is passed as far as it goes, with complete false, and
+// ends the walk.
+func eachComment(data []byte, offset int, fn func(comment []byte, complete bool) bool) {
 	if offset+4 > len(data) {
-		return types.Artwork{}, errors.New("unexpected end of data")
+		return
 	}
-	dataLength := binary.BigEndian.Uint32(data[offset:])
+	vendorLen := int(binary.LittleEndian.Uint32(data[offset:]))
+	offset += 4 + vendorLen
+
+	if offset < 0 || offset+4 > len(data) {
+		return
+	}
+	commentCount := binary.LittleEndian.Uint32(data[offset:])
 	offset += 4
 
-	if offset+int(dataLength) > len(data) {
-		return types.Artwork{}, errors.New("picture data length exceeds data")
+	for range commentCount {
+		if offset+4 > len(data) {
+			return
+		}
+		commentLen := int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+		if commentLen < 0 {
+			return
+		}
+
+		end := offset + commentLen
+		complete := end >= offset && end <= len(data)
+		if !complete {
+			end = len(data)
+		}
+		if !fn(data[offset:end], complete) || !complete {
+			return
+		}
+		offset = end
+	}
+}
+
+// pictureDataLength returns the image data length declared by a
+// METADATA_BLOCK_PICTURE value, decoding only the fields before it.
+func pictureDataLength(base64Value string) (int64, error) {
+	dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Value))
+	field := make([]byte, 4)
+
+	// Picture type, then the MIME type and description with their lengths
+	if _, err := io.ReadFull(dec, field); err != nil {
+		return 0, err
+	}
+	for range 2 {
+		if _, err := io.ReadFull(dec, field); err != nil {
+			return 0, err
+		}
+		if _, err := io.CopyN(io.Discard, dec, int64(binary.BigEndian.Uint32(field))); err != nil {
+			return 0, err
+		}
 	}
 
-	// Read picture data
-	pictureData := data[offset : offset+int(dataLength)]
+	// Width, height, color depth and colors used, then the data length
+	if _, err := io.CopyN(io.Discard, dec, 16); err != nil {
+		return 0, err
+	}
+	if _, err := io.ReadFull(dec, field); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint32(field)), nil
+}
 
-	// Map FLAC picture type to types.ArtworkType
-	var artType types.ArtworkType
-	switch pictureType {
-	case 3:
-		artType = types.ArtworkFrontCover
-	case 4:
-		artType = types.ArtworkBackCover
-	default:
-		artType = types.ArtworkOther
+// parseMetadataBlockPicture decodes a METADATA_BLOCK_PICTURE value, a
+// base64-encoded FLAC picture block.
+func parseMetadataBlockPicture(ctx context.Context, base64Value string) (types.Artwork, error) {
+	data, err := base64.StdEncoding.DecodeString(base64Value)
+	if err != nil {
+		return types.Artwork{}, fmt.Errorf("invalid base64: %w", err)
 	}
 
-	return types.Artwork{
-		Data:        pictureData,
-		MIMEType:    mimeType,
-		Type:        artType,
-		Description: description,
-		Width:       int(width),
-		Height:      int(height),
-	}, nil
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "METADATA_BLOCK_PICTURE")
+	art, _, err := picture.Decode(ctx, sr, 0, int64(len(data)), true)
+	return art, err
 }
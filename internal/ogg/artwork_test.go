@@ -1,6 +1,8 @@
 package ogg
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/binary"
 	"testing"
@@ -13,7 +15,7 @@ func TestParseMetadataBlockPicture(t *testing.T) {
 	pic := createTestPictureBlock(3, "image/jpeg", "Front Cover", 100, 100, []byte{0xFF, 0xD8, 0xFF, 0xE0})
 	base64Pic := base64.StdEncoding.EncodeToString(pic)
 
-	artwork, err := parseMetadataBlockPicture(base64Pic)
+	artwork, err := parseMetadataBlockPicture(context.Background(), base64Pic)
 	if err != nil {
 		t.Fatalf("parseMetadataBlockPicture() error = %v", err)
 	}
@@ -42,7 +44,7 @@ func TestParseMetadataBlockPicture_BackCover(t *testing.T) {
 	pic := createTestPictureBlock(4, "image/png", "Back Cover", 200, 200, []byte{0x89, 'P', 'N', 'G'})
 	base64Pic := base64.StdEncoding.EncodeToString(pic)
 
-	artwork, err := parseMetadataBlockPicture(base64Pic)
+	artwork, err := parseMetadataBlockPicture(context.Background(), base64Pic)
 	if err != nil {
 		t.Fatalf("parseMetadataBlockPicture() error = %v", err)
 	}
@@ -60,7 +62,7 @@ func TestParseMetadataBlockPicture_OtherType(t *testing.T) {
 	pic := createTestPictureBlock(0, "image/gif", "", 50, 50, []byte{0x47, 0x49, 0x46})
 	base64Pic := base64.StdEncoding.EncodeToString(pic)
 
-	artwork, err := parseMetadataBlockPicture(base64Pic)
+	artwork, err := parseMetadataBlockPicture(context.Background(), base64Pic)
 	if err != nil {
 		t.Fatalf("parseMetadataBlockPicture() error = %v", err)
 	}
@@ -71,7 +73,7 @@ func TestParseMetadataBlockPicture_OtherType(t *testing.T) {
 }
 
 func TestParseMetadataBlockPicture_InvalidBase64(t *testing.T) {
-	_, err := parseMetadataBlockPicture("not valid base64!!!")
+	_, err := parseMetadataBlockPicture(context.Background(), "not valid base64!!!")
 	if err == nil {
 		t.Error("parseMetadataBlockPicture() should return error for invalid base64")
 	}
@@ -82,7 +84,7 @@ func TestParseMetadataBlockPicture_TooSmall(t *testing.T) {
 	data := make([]byte, 20)
 	base64Data := base64.StdEncoding.EncodeToString(data)
 
-	_, err := parseMetadataBlockPicture(base64Data)
+	_, err := parseMetadataBlockPicture(context.Background(), base64Data)
 	if err == nil {
 		t.Error("parseMetadataBlockPicture() should return error for data too small")
 	}
@@ -95,7 +97,7 @@ func TestParseMetadataBlockPicture_InvalidMimeLength(t *testing.T) {
 	binary.BigEndian.PutUint32(data[4:], 1000) // MIME length (too large)
 	base64Data := base64.StdEncoding.EncodeToString(data)
 
-	_, err := parseMetadataBlockPicture(base64Data)
+	_, err := parseMetadataBlockPicture(context.Background(), base64Data)
 	if err == nil {
 		t.Error("parseMetadataBlockPicture() should return error for invalid MIME length")
 	}
@@ -105,7 +107,7 @@ func TestParseMetadataBlockPicture_EmptyDescription(t *testing.T) {
 	pic := createTestPictureBlock(3, "image/jpeg", "", 100, 100, []byte{0xFF, 0xD8})
 	base64Pic := base64.StdEncoding.EncodeToString(pic)
 
-	artwork, err := parseMetadataBlockPicture(base64Pic)
+	artwork, err := parseMetadataBlockPicture(context.Background(), base64Pic)
 	if err != nil {
 		t.Fatalf("parseMetadataBlockPicture() error = %v", err)
 	}
@@ -116,6 +118,20 @@ func TestParseMetadataBlockPicture_EmptyDescription(t *testing.T) {
 }
 
 // createTestPictureBlock creates a valid FLAC picture block.
+func TestPictureDataLength(t *testing.T) {
+	pic := createTestPictureBlock(3, "image/jpeg", "Front Cover", 100, 100, make([]byte, 300))
+
+	length, err := pictureDataLength(base64.StdEncoding.EncodeToString(pic))
+	if err != nil || length != 300 {
+		t.Errorf("pictureDataLength() = %d, %v; want 300", length, err)
+	}
+
+	// Cut off before the data length field
+	if _, err := pictureDataLength(base64.StdEncoding.EncodeToString(pic[:30])); err == nil {
+		t.Error("expected error for truncated picture block")
+	}
+}
+
 func createTestPictureBlock(pictureType uint32, mimeType, description string, width, height uint32, imageData []byte) []byte {
 	// Calculate total size
 	size := 4 + 4 + len(mimeType) + 4 + len(description) + 4 + 4 + 4 + 4 + 4 + len(imageData)
@@ -162,3 +178,225 @@ func createTestPictureBlock(pictureType uint32, mimeType, description string, wi
 
 	return data
 }
+
+// createCommentList builds a Vorbis comment list (vendor + comments).
+func createCommentList(comments ...string) []byte {
+	var buf []byte
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len("audiometa")))
+	buf = append(buf, "audiometa"...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(comments)))
+	for _, c := range comments {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(c)))
+		buf = append(buf, c...)
+	}
+	return buf
+}
+
+func TestCountPictureComments(t *testing.T) {
+	picture := "METADATA_BLOCK_PICTURE=" + base64.StdEncoding.EncodeToString(make([]byte, 48))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"none", createCommentList("TITLE=Song"), 0},
+		{"two pictures", createCommentList("TITLE=Song", picture, "metadata_block_picture=AAAA"), 2},
+		{"truncated picture payload", createCommentList("TITLE=Song", picture)[:60], 1},
+		{"legacy cover art", createCommentList("COVERART=AAAA", "COVERARTMIME=image/jpeg", picture), 2},
+		{"empty", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countPictureComments(tt.data, 0); got != tt.want {
+				t.Errorf("countPictureComments() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// createOggStream lays out packets as an Ogg stream, starting each packet on
+// a new page and splitting it across continuation pages as needed. The last
+// packet is written as a single EOS page with the given granule position.
+func createOggStream(granule uint64, packets ...[]byte) []byte {
+	var out []byte
+	sequence := uint32(0)
+
+	writePage := func(headerType byte, granule uint64, segments []byte, data []byte) {
+		out = append(out, "OggS"...)
+		out = append(out, 0x00, headerType)
+		out = binary.LittleEndian.AppendUint64(out, granule)
+		out = binary.LittleEndian.AppendUint32(out, 1234) // serial
+		out = binary.LittleEndian.AppendUint32(out, sequence)
+		out = binary.LittleEndian.AppendUint32(out, 0) // checksum (unchecked)
+		out = append(out, byte(len(segments)))
+		out = append(out, segments...)
+		out = append(out, data...)
+		sequence++
+	}
+
+	for i, packet := range packets {
+		// Lacing: 255-byte segments terminated by one shorter segment
+		var segments []byte
+		for n := len(packet); ; n -= 255 {
+			if n < 255 {
+				segments = append(segments, byte(n))
+				break
+			}
+			segments = append(segments, 255)
+		}
+
+		var headerType byte
+		if i == 0 {
+			headerType = 0x02 // BOS
+		}
+		granulePos := uint64(0)
+		if i == len(packets)-1 {
+			headerType |= 0x04 // EOS
+			granulePos = granule
+		}
+
+		data := packet
+		for len(segments) > 0 {
+			n := min(len(segments), 255)
+			size := 0
+			for _, s := range segments[:n] {
+				size += int(s)
+			}
+			writePage(headerType, granulePos, segments[:n], data[:size])
+			segments, data = segments[n:], data[size:]
+			headerType = 0x01 // continuation
+		}
+	}
+
+	return out
+}
+
+func TestExtractArtwork_OpusMultiPagePicture(t *testing.T) {
+	// An image large enough that OpusTags spans several pages
+	image := bytes.Repeat([]byte{0xFF, 0xD8, 0xFF, 0xE0}, 40000)
+	pic := createTestPictureBlock(3, "image/jpeg", "Cover", 600, 600, image)
+	picture := "METADATA_BLOCK_PICTURE=" + base64.StdEncoding.EncodeToString(pic)
+
+	head := []byte("OpusHead")
+	head = append(head, 1, 2)
+	head = binary.LittleEndian.AppendUint16(head, 312)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	// OpusTags has no framing bit after the comment list, unlike Vorbis
+	tags := append([]byte("OpusTags"), createCommentList("TITLE=Song", picture)...)
+
+	data := createOggStream(48000, head, tags, make([]byte, 100))
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.opus")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if file.Format != types.FormatOpus || file.Tags.Title != "Song" {
+		t.Fatalf("unexpected parse result: format %v, title %q", file.Format, file.Tags.Title)
+	}
+	if len(file.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", file.Warnings)
+	}
+
+	artwork, err := p.ExtractArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.opus")
+	if err != nil {
+		t.Fatalf("ExtractArtwork failed: %v", err)
+	}
+	if len(artwork) != 1 {
+		t.Fatalf("expected 1 picture, got %d", len(artwork))
+	}
+	if !bytes.Equal(artwork[0].Data, image) {
+		t.Errorf("picture data mismatch: got %d bytes, want %d", len(artwork[0].Data), len(image))
+	}
+	if artwork[0].Type != types.ArtworkFrontCover || artwork[0].Width != 600 {
+		t.Errorf("unexpected picture metadata: %+v", artwork[0])
+	}
+
+	n, err := p.CountArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.opus")
+	if err != nil || n != 1 {
+		t.Errorf("CountArtwork() = %d, %v; want 1", n, err)
+	}
+}
+
+func TestExtractArtwork_LegacyCoverArt(t *testing.T) {
+	jpeg := append([]byte{0xFF, 0xD8, 0xFF, 0xE0}, make([]byte, 60)...)
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 60)...)
+	pic := createTestPictureBlock(4, "image/png", "Back", 10, 10, png)
+
+	head := []byte("OpusHead")
+	head = append(head, 1, 2)
+	head = binary.LittleEndian.AppendUint16(head, 312)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	tags := append([]byte("OpusTags"), createCommentList(
+		"TITLE=Song",
+		"COVERART="+base64.StdEncoding.EncodeToString(png),
+		"COVERARTMIME=image/x-custom",
+		"METADATA_BLOCK_PICTURE="+base64.StdEncoding.EncodeToString(pic),
+		"COVERART="+base64.StdEncoding.EncodeToString(jpeg),
+	)...)
+	data := createOggStream(48000, head, tags, make([]byte, 100))
+
+	p := &parser{}
+	artwork, err := p.ExtractArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.opus")
+	if err != nil {
+		t.Fatalf("ExtractArtwork failed: %v", err)
+	}
+	if len(artwork) != 3 {
+		t.Fatalf("expected 3 pictures, got %d", len(artwork))
+	}
+	if artwork[0].Type != types.ArtworkBackCover {
+		t.Errorf("artwork[0].Type = %v, want the METADATA_BLOCK_PICTURE first", artwork[0].Type)
+	}
+
+	// COVERARTMIME names only the first cover; the second is sniffed
+	for i, wantMIME := range []string{"image/x-custom", "image/jpeg"} {
+		art := artwork[i+1]
+		if art.Type != types.ArtworkFrontCover || art.MIMEType != wantMIME || art.Size != len(art.Data) {
+			t.Errorf("artwork[%d] = %+v, want a %s front cover", i+1, art, wantMIME)
+		}
+	}
+
+	n, err := p.CountArtwork(context.Background(), bytes.NewReader(data), int64(len(data)), "test.opus")
+	if err != nil || n != 3 {
+		t.Errorf("CountArtwork() = %d, %v; want 3", n, err)
+	}
+}
+
+func TestParseLegacyCoverArt(t *testing.T) {
+	jpeg := base64.StdEncoding.EncodeToString([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0})
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n"))
+
+	tests := []struct {
+		name      string
+		value     string
+		mimeTypes []string
+		wantMIME  string
+		wantErr   bool
+	}{
+		{"MIME from COVERARTMIME", jpeg, []string{"image/png"}, "image/png", false},
+		{"JPEG sniffed", jpeg, nil, "image/jpeg", false},
+		{"PNG sniffed", png, nil, "image/png", false},
+		{"GIF sniffed", base64.StdEncoding.EncodeToString([]byte("GIF89a")), nil, "image/gif", false},
+		{"unknown image", base64.StdEncoding.EncodeToString([]byte{0x00, 0x01, 0x02, 0x03}), nil, "", false},
+		{"invalid base64", "not base64!", nil, "", true},
+		{"empty", "", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			art, err := parseLegacyCoverArt(tt.value, tt.mimeTypes, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLegacyCoverArt() error = %v, want error %v", err, tt.wantErr)
+			}
+			if art.MIMEType != tt.wantMIME {
+				t.Errorf("MIMEType = %q, want %q", art.MIMEType, tt.wantMIME)
+			}
+		})
+	}
+}
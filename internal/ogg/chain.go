@@ -0,0 +1,127 @@
+package ogg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+)
+
+// pageCheckInterval is how many pages scanChain walks between cancellation
+// checks.
+const pageCheckInterval = 1024
+
+// chainLink is one link of a chained Ogg file: the logical streams that
+// begin together with a run of BOS pages, of which the first holding
+// Vorbis or Opus is the audio stream.
+type chainLink struct {
+	serial     uint32 // Serial number of the audio stream
+	sampleRate int    // Granule positions per second of the audio stream
+	granule    int64  // Last granule position on the audio stream's pages
+	hasAudio   bool
+}
+
+// chain is the stream structure of an Ogg file, learned by walking all of
+// its pages.
+type chain struct {
+	links   []chainLink
+	streams int // Logical streams, one per BOS page
+}
+
+// duration returns the total duration of the links' audio streams.
+func (c chain) duration() float64 {
+	var seconds float64
+	for _, link := range c.links {
+		if link.hasAudio && link.sampleRate > 0 && link.granule > 0 {
+			seconds += float64(link.granule) / float64(link.sampleRate)
+		}
+	}
+	return seconds
+}
+
+// scanChain walks the page headers of the whole file, reading no more of
+// the page data than a BOS page's identification header.
+//
+// Streams concatenated into one file each start with their own BOS pages
+// and restart their granule positions, so a link's duration is its own
+// last granule position. On a read failure the links found so far are
+// returned with the error.
+func scanChain(ctx context.Context, sr *binutil.SafeReader, size int64) (chain, error) {
+	var c chain
+	header := make([]byte, 27)
+	segments := make([]byte, 255)
+	ident := make([]byte, 16)
+	prevBOS := false
+
+	for offset, n := int64(0), 0; offset+int64(len(header)) <= size; n++ {
+		if n%pageCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return c, err
+			}
+		}
+
+		if err := sr.ReadAt(header, offset, "Ogg page header"); err != nil {
+			return c, err
+		}
+		if string(header[0:4]) != "OggS" {
+			return c, fmt.Errorf("invalid Ogg page at offset %d", offset)
+		}
+		headerType := header[5]
+		granule := int64(binary.LittleEndian.Uint64(header[6:14]))
+		serial := binary.LittleEndian.Uint32(header[14:18])
+
+		table := segments[:header[26]]
+		if err := sr.ReadAt(table, offset+27, "segment table"); err != nil {
+			return c, err
+		}
+		dataOffset := offset + 27 + int64(len(table))
+		dataSize := 0
+		for _, seg := range table {
+			dataSize += int(seg)
+		}
+
+		isBOS := headerType&0x02 != 0
+		if isBOS {
+			c.streams++
+			if !prevBOS {
+				c.links = append(c.links, chainLink{})
+			}
+			link := &c.links[len(c.links)-1]
+			if !link.hasAudio {
+				data := ident[:min(dataSize, len(ident))]
+				if err := sr.ReadAt(data, dataOffset, "identification header"); err != nil {
+					return c, err
+				}
+				link.serial, link.sampleRate, link.hasAudio = serial, identSampleRate(data), detectOggCodec(data) != "unknown"
+			}
+		}
+		prevBOS = isBOS
+
+		// A granule position of -1 means no packet ends on the page
+		if len(c.links) > 0 {
+			if link := &c.links[len(c.links)-1]; link.hasAudio && serial == link.serial && granule >= 0 {
+				link.granule = granule
+			}
+		}
+
+		offset = dataOffset + int64(dataSize)
+	}
+
+	return c, nil
+}
+
+// identSampleRate returns the granule rate of a stream from the start of
+// its identification header: the sample rate for Vorbis, and always 48 kHz
+// for Opus.
+func identSampleRate(data []byte) int {
+	switch detectOggCodec(data) {
+	case "opus":
+		return 48000
+	case codecVorbis:
+		if len(data) >= 16 {
+			return int(binary.LittleEndian.Uint32(data[12:16]))
+		}
+	}
+	return 0
+}
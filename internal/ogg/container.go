@@ -18,6 +18,7 @@ type Page struct {
 	SerialNumber    uint32
 	SequenceNumber  uint32
 	HeaderType      byte
+	Offset          int64 // Start of the page, at "OggS"
 }
 
 // readPage reads an Ogg page at the given offset.
@@ -93,6 +94,7 @@ func readPage(sr *binary.SafeReader, offset int64) (*Page, int64, error) {
 		SerialNumber:    serial,
 		SequenceNumber:  sequence,
 		Data:            data,
+		Offset:          offset,
 	}
 
 	// Calculate next page offset
@@ -101,6 +103,79 @@ func readPage(sr *binary.SafeReader, offset int64) (*Page, int64, error) {
 	return page, nextOffset, nil
 }
 
+// Header page bounds. Identification, comment and setup headers normally
+// fit in the first three pages, but a comment header carrying embedded
+// artwork (METADATA_BLOCK_PICTURE) spills across as many pages as the image
+// needs - always the case for Opus, whose OpusTags packet starts on its own
+// page. maxHeaderPages (~16MB of 64KB pages) stops a corrupt chain of
+// continuation flags from walking the whole file.
+const (
+	minHeaderPages = 3
+	maxHeaderPages = 256
+)
+
+// readHeaderPages reads the pages carrying the codec headers of the
+// primary stream: the first minHeaderPages of its pages, then any further
+// pages that continue the packet in progress. On a read failure the pages
+// read so far are returned together with the failing offset and error.
+//
+// A multiplexed file starts with the BOS pages of all its logical streams,
+// such as a Skeleton or Theora stream beside the audio; the primary stream
+// is the first whose BOS page holds a Vorbis or Opus identification
+// header, or else the first stream. Pages of the others are skipped, and
+// streams is the number of BOS pages seen.
+func readHeaderPages(sr *binary.SafeReader, size int64) (pages []*Page, streams int, failOffset int64, err error) {
+	offset := int64(0)
+	var primary *Page // BOS page of the primary stream, once chosen
+	var firstBOS *Page
+
+	for i := 0; i < maxHeaderPages && offset < size; i++ {
+		if len(pages) >= minHeaderPages {
+			// Stop at the first page that doesn't continue a header packet
+			headerType, err := binary.Read[uint8](sr, offset+5, "header type")
+			serial, serialErr := binary.ReadLE[uint32](sr, offset+14, "serial number")
+			if err != nil || serialErr != nil || (serial == primary.SerialNumber && headerType&0x01 == 0) {
+				break
+			}
+		}
+
+		page, nextOffset, err := readPage(sr, offset)
+		if err != nil {
+			return pages, streams, offset, err
+		}
+		offset = nextOffset
+
+		isBOS := page.HeaderType&0x02 != 0
+		if isBOS {
+			streams++
+		}
+		if primary == nil {
+			switch {
+			case isBOS && detectOggCodec(page.Data) == "unknown":
+				// Not audio; keep looking among the BOS pages
+				if firstBOS == nil {
+					firstBOS = page
+				}
+				continue
+			case !isBOS && firstBOS != nil:
+				// No BOS page held audio headers: read the first stream
+				primary = firstBOS
+				pages = append(pages, firstBOS)
+			default:
+				primary = page
+			}
+		}
+		if page.SerialNumber == primary.SerialNumber {
+			pages = append(pages, page)
+		}
+	}
+
+	if primary == nil && firstBOS != nil {
+		pages = append(pages, firstBOS)
+	}
+	return pages, streams, 0, nil
+}
+
 // extractPackets extracts complete packets from a series of pages.
 //
 // Ogg packets can span multiple pages. A packet ends when a segment
@@ -140,10 +215,11 @@ func extractPackets(pages []*Page) [][]byte {
 }
 
 // findLastGranulePosition searches backwards from the end of file
-// to find the last Ogg page's granule position.
+// to find the last Ogg page's granule position, and the serial number of
+// the stream the page belongs to.
 //
 // This is used to calculate the duration of the audio stream.
-func findLastGranulePosition(sr *binary.SafeReader, fileSize int64) (int64, error) {
+func findLastGranulePosition(sr *binary.SafeReader, fileSize int64) (granule int64, serial uint32, err error) {
 	// Search last 64KB for final page (typical max page size)
 	searchStart := fileSize - 65536
 	if searchStart < 0 {
@@ -153,7 +229,7 @@ func findLastGranulePosition(sr *binary.SafeReader, fileSize int64) (int64, erro
 	searchSize := fileSize - searchStart
 	buf := make([]byte, searchSize)
 	if err := sr.ReadAt(buf, searchStart, "search region"); err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	// Find last "OggS" marker
@@ -166,14 +242,19 @@ func findLastGranulePosition(sr *binary.SafeReader, fileSize int64) (int64, erro
 	}
 
 	if lastOggPos < 0 {
-		return 0, errors.New("could not find last Ogg page")
+		return 0, 0, errors.New("could not find last Ogg page")
 	}
 
-	// Read granule position from last page (at offset 6 from "OggS")
-	granule, err := binary.ReadLE[uint64](sr, lastOggPos+6, "granule position")
+	// Read granule position and serial number from last page (at offsets
+	// 6 and 14 from "OggS")
+	position, err := binary.ReadLE[uint64](sr, lastOggPos+6, "granule position")
+	if err != nil {
+		return 0, 0, err
+	}
+	serial, err = binary.ReadLE[uint32](sr, lastOggPos+14, "serial number")
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	return int64(granule), nil
+	return int64(position), serial, nil
 }
@@ -51,21 +51,23 @@ func parseOpusHead(data []byte, file *types.File) error {
 	file.Audio.SampleRate = 48000 // Opus always outputs at 48kHz
 	file.Audio.Channels = int(channels)
 	file.Audio.Lossless = false
-	file.Audio.VBR = true // Opus is VBR
+	file.Audio.VBR = true // Opus headers carry no bitrate mode; encoders default to VBR
 
 	// Add informational warnings for non-default values
 	if inputSampleRate != 48000 && inputSampleRate > 0 {
 		file.Warnings = append(file.Warnings, types.Warning{
-			Stage:   "technical",
-			Message: fmt.Sprintf("original sample rate was %d Hz (Opus outputs at 48 kHz)", inputSampleRate),
+			Stage:    "technical",
+			Message:  fmt.Sprintf("original sample rate was %d Hz (Opus outputs at 48 kHz)", inputSampleRate),
+			Severity: types.SeverityInfo,
 		})
 	}
 
 	if outputGain != 0 {
 		gainDB := float64(outputGain) / 256.0
 		file.Warnings = append(file.Warnings, types.Warning{
-			Stage:   "technical",
-			Message: fmt.Sprintf("output gain: %.2f dB", gainDB),
+			Stage:    "technical",
+			Message:  fmt.Sprintf("output gain: %.2f dB", gainDB),
+			Severity: types.SeverityInfo,
 		})
 	}
 
@@ -126,8 +128,9 @@ func parseOpusTags(data []byte, file *types.File) error {
 		if offset+4 > len(data) {
 			// Truncated, but don't fail - just stop reading
 			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "metadata",
-				Message: fmt.Sprintf("truncated comment %d (missing length field)", i),
+				Stage:    "metadata",
+				Message:  fmt.Sprintf("truncated comment %d (missing length field)", i),
+				Severity: types.SeverityError,
 			})
 			break
 		}
@@ -138,8 +141,9 @@ func parseOpusTags(data []byte, file *types.File) error {
 		if offset+int(commentLen) > len(data) {
 			// Truncated comment data
 			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "metadata",
-				Message: fmt.Sprintf("truncated comment %d data (expected %d bytes)", i, commentLen),
+				Stage:    "metadata",
+				Message:  fmt.Sprintf("truncated comment %d data (expected %d bytes)", i, commentLen),
+				Severity: types.SeverityError,
 			})
 			break
 		}
@@ -165,6 +169,9 @@ func parseOpusTags(data []byte, file *types.File) error {
 	// Parse chapters from CHAPTER comments
 	if len(allComments) > 0 {
 		file.Chapters = vorbis.ParseChapters(allComments, file.Audio.Duration)
+		if len(file.Chapters) > 0 {
+			file.ChapterSource = chapterSourceComments
+		}
 	}
 
 	return nil
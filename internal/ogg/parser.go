@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"time"
 
 	"github.com/simonhull/audiometa/internal/binary"
@@ -16,6 +17,10 @@ import (
 const (
 	codecVorbis  = "vorbis"
 	containerOgg = "Ogg"
+
+	// chapterSourceComments marks chapters read from CHAPTERxxx comments,
+	// for both Vorbis and Opus streams.
+	chapterSourceComments = "ogg:vorbis-comments"
 )
 
 // parser implements the audiometa.FormatParser interface for Ogg Vorbis files.
@@ -52,34 +57,36 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		Audio:  types.AudioInfo{},
 	}
 
-	// Read first few pages (Vorbis headers are always in the first pages)
-	var pages []*Page
-	offset := int64(0)
+	if registry.RawTagsEnabled(ctx) {
+		file.EnableRawTags()
+	}
 
-	// Read up to 3 pages (identification, comment, setup headers)
-	for i := 0; i < 3 && offset < size; i++ {
-		page, nextOffset, err := readPage(sr, offset)
-		if err != nil {
-			if i == 0 {
-				// First page failed - this is fatal
-				return nil, fmt.Errorf("failed to read first Ogg page: %w", err)
-			}
-			// Subsequent pages - add warning and stop
-			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "metadata",
-				Message: fmt.Sprintf("failed to read Ogg page %d: %v", i, err),
-				Err:     err,
-				Offset:  offset,
-			})
-			break
+	// Read the header pages (identification, comment, setup headers)
+	pages, streams, failOffset, err := readHeaderPages(sr, size)
+	if err != nil {
+		if len(pages) == 0 {
+			// First page failed - this is fatal
+			return nil, fmt.Errorf("failed to read first Ogg page: %w", err)
 		}
-		pages = append(pages, page)
-		offset = nextOffset
+		// Subsequent pages - add warning and continue with what was read
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "metadata",
+			Message:  fmt.Sprintf("failed to read Ogg page %d: %v", len(pages), err),
+			Severity: types.SeverityError,
+			Err:      err,
+			Offset:   failOffset,
+		})
 	}
 
 	if len(pages) == 0 {
 		return nil, errors.New("no Ogg pages found")
 	}
+	log := registry.Logger(ctx)
+	for _, page := range pages {
+		log.LogAttrs(ctx, slog.LevelDebug, "page",
+			slog.String("format", "Ogg"), slog.Uint64("sequence", uint64(page.SequenceNumber)),
+			slog.Int64("offset", page.Offset), slog.Int("size", len(page.Data)))
+	}
 
 	// Extract packets from pages
 	packets := extractPackets(pages)
@@ -91,6 +98,9 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 	// Detect codec (Vorbis or Opus) from first packet
 	codec := detectOggCodec(packets[0])
 
+	// Set when the duration needed a walk of the whole file
+	var layout *chain
+
 	switch codec {
 	case codecVorbis:
 		file.Format = types.FormatOgg
@@ -111,17 +121,13 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 
 		// Calculate duration from last page's granule position
 		if file.Audio.SampleRate > 0 {
-			duration, err := calculateDuration(sr, size, file.Audio.SampleRate)
+			duration, c, err := calculateDuration(ctx, sr, size, pages[0].SerialNumber, streams, file.Audio.SampleRate)
 			if err != nil {
 				// Non-fatal - add warning
-				file.Warnings = append(file.Warnings, types.Warning{
-					Stage:   "technical",
-					Message: fmt.Sprintf("failed to calculate duration: %v", err),
-					Err:     err,
-				})
-			} else {
-				file.Audio.Duration = duration
+				file.Warnings = append(file.Warnings, durationWarning(err))
 			}
+			file.Audio.Duration = duration
+			layout = c
 		}
 
 	case "opus":
@@ -142,17 +148,13 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		}
 
 		// Calculate duration (Opus always at 48kHz)
-		duration, err := calculateDuration(sr, size, 48000)
+		duration, c, err := calculateDuration(ctx, sr, size, pages[0].SerialNumber, streams, 48000)
 		if err != nil {
 			// Non-fatal - add warning
-			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "technical",
-				Message: fmt.Sprintf("failed to calculate duration: %v", err),
-				Err:     err,
-			})
-		} else {
-			file.Audio.Duration = duration
+			file.Warnings = append(file.Warnings, durationWarning(err))
 		}
+		file.Audio.Duration = duration
+		layout = c
 
 		// Estimate bitrate for Opus (no nominal bitrate in header)
 		if file.Audio.Duration > 0 {
@@ -163,6 +165,30 @@ func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path stri
 		return nil, fmt.Errorf("unknown or unsupported Ogg codec: %q", codec)
 	}
 
+	// Tags and audio properties are those of the primary stream alone
+	links := 1
+	if layout != nil {
+		streams = max(streams, layout.streams)
+		links = max(len(layout.links), 1)
+	}
+	if streams > 1 {
+		message := fmt.Sprintf("Ogg file multiplexes %d logical streams; read the first %s stream", streams, codec)
+		if links > 1 {
+			message = fmt.Sprintf("Ogg file chains %d logical streams in %d links; duration is the total, tags and audio properties are from the first %s stream", streams, links, codec)
+		}
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "technical",
+			Message:  message,
+			Severity: types.SeverityInfo,
+		})
+	}
+
+	// Chapters come from the comment header, read before the duration
+	// from the last page was known
+	if n := len(file.Chapters); n > 0 && file.Chapters[n-1].EndTime == 0 {
+		file.Chapters[n-1].EndTime = max(file.Audio.Duration, file.Chapters[n-1].StartTime)
+	}
+
 	// Post-parse fallbacks for audiobook series metadata.
 	if file.Tags.Series == "" && file.Tags.Grouping != "" {
 		series, part := parsing.ParseGrouping(file.Tags.Grouping)
@@ -230,26 +256,72 @@ func estimateOpusBitrate(fileSize int64, duration time.Duration) int {
 	return bitrate
 }
 
-// Duration = granule_position / sample_rate.
-func calculateDuration(sr *binary.SafeReader, fileSize int64, sampleRate int) (time.Duration, error) {
+// calculateDuration returns the duration of the primary stream, given
+// its serial number and the number of BOS pages among the header pages:
+// granule_position / sample_rate.
+//
+// A file with a single stream takes its duration from the last page. Only
+// when the header pages hold more than one BOS page, or the last page
+// belongs to another stream, are the page headers of the whole file walked
+// to count its links, runs of BOS pages that each start a set of streams.
+// Serial numbers are unique within a physical stream (RFC 3533), so a
+// chain always ends on another serial than it starts with. A chained
+// file's duration is the total over its links, each of which restarts its
+// granule positions. The chain walked is returned, or nil if there was
+// one stream.
+//
+// If the walk fails after reading some of a chain, the duration of the
+// links read is returned along with an errChainCutShort error.
+func calculateDuration(ctx context.Context, sr *binary.SafeReader, fileSize int64, serial uint32, streams, sampleRate int) (time.Duration, *chain, error) {
 	if sampleRate == 0 {
-		return 0, errors.New("sample rate is zero")
+		return 0, nil, errors.New("sample rate is zero")
 	}
 
 	// Find last page's granule position
-	granule, err := findLastGranulePosition(sr, fileSize)
+	granule, lastSerial, err := findLastGranulePosition(sr, fileSize)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
+	}
+
+	if streams > 1 || lastSerial != serial {
+		c, walkErr := scanChain(ctx, sr, fileSize)
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+		seconds := c.duration()
+		if walkErr != nil {
+			if seconds == 0 {
+				return 0, nil, walkErr
+			}
+			walkErr = fmt.Errorf("%w, duration covers only its first %d links: %w", errChainCutShort, len(c.links), walkErr)
+		}
+		return time.Duration(seconds * float64(time.Second)), &c, walkErr
 	}
 
 	// Granule position -1 means "not set"
 	if granule < 0 {
-		return 0, errors.New("granule position not set")
+		return 0, nil, errors.New("granule position not set")
 	}
 
 	// Calculate duration (granule is in samples)
 	seconds := float64(granule) / float64(sampleRate)
-	return time.Duration(seconds * float64(time.Second)), nil
+	return time.Duration(seconds * float64(time.Second)), nil, nil
+}
+
+// errChainCutShort marks a calculateDuration error returned with the
+// duration of only part of a chained file.
+var errChainCutShort = errors.New("page walk stopped early")
+
+// durationWarning describes a calculateDuration error.
+func durationWarning(err error) types.Warning {
+	if errors.Is(err, errChainCutShort) {
+		return types.Warning{Stage: "technical", Message: err.Error(), Err: err}
+	}
+	return types.Warning{
+		Stage:   "technical",
+		Message: fmt.Sprintf("failed to calculate duration: %v", err),
+		Err:     err,
+	}
 }
 
 // init registers the Ogg parser for both Vorbis and Opus formats.
@@ -5,8 +5,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/simonhull/audiometa/internal/types"
 )
@@ -643,6 +647,51 @@ func TestOggParser_BothCodecs(t *testing.T) {
 	}
 }
 
+// createVorbisIdentification creates a Vorbis identification header with the given bitrates.
+func createVorbisIdentification(maximum, nominal, minimum uint32) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteByte(0x01)
+	buf.WriteString("vorbis")
+	binary.Write(buf, binary.LittleEndian, uint32(0))     // Vorbis version
+	buf.WriteByte(2)                                      // Channels
+	binary.Write(buf, binary.LittleEndian, uint32(44100)) // Sample rate
+	binary.Write(buf, binary.LittleEndian, maximum)
+	binary.Write(buf, binary.LittleEndian, nominal)
+	binary.Write(buf, binary.LittleEndian, minimum)
+	buf.WriteByte(0xB8) // Blocksize info
+	buf.WriteByte(0x01) // Framing flag
+	return buf.Bytes()
+}
+
+func TestParseVorbisIdentification_BitrateMode(t *testing.T) {
+	tests := []struct {
+		name                      string
+		maximum, nominal, minimum uint32
+		wantVBR                   bool
+	}{
+		{"min == max == nominal is CBR", 128000, 128000, 128000, false},
+		{"nominal only is VBR", 0, 128000, 0, true},
+		{"differing bounds is VBR", 192000, 128000, 96000, true},
+		{"unset (-1) bounds is VBR", 0xFFFFFFFF, 128000, 0xFFFFFFFF, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &types.File{}
+			if err := parseVorbisIdentification(createVorbisIdentification(tt.maximum, tt.nominal, tt.minimum), file); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if file.Audio.VBR != tt.wantVBR {
+				t.Errorf("expected VBR=%v, got %v", tt.wantVBR, file.Audio.VBR)
+			}
+			if file.Audio.Bitrate != int(tt.nominal) {
+				t.Errorf("expected bitrate %d, got %d", tt.nominal, file.Audio.Bitrate)
+			}
+		})
+	}
+}
+
 // Benchmarks
 
 func BenchmarkParseOgg(b *testing.B) {
@@ -888,3 +937,172 @@ func BenchmarkParseOpusTags(b *testing.B) {
 		f.Close()
 	}
 }
+
+func TestParseOpus_Chapters(t *testing.T) {
+	head := []byte("OpusHead")
+	head = append(head, 1, 2)
+	head = binary.LittleEndian.AppendUint16(head, 0)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+
+	tags := append([]byte("OpusTags"), createCommentList(
+		"TITLE=Episode 12",
+		"CHAPTER002=00:00:30.000", "CHAPTER002NAME=Interview",
+		"CHAPTER001=00:00:00.000", "CHAPTER001NAME=Intro",
+	)...)
+
+	// Ten minutes at 48 kHz
+	data := createOggStream(48000*600, head, tags, make([]byte, 100))
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "podcast.opus")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.ChapterSource != chapterSourceComments {
+		t.Errorf("ChapterSource = %q, want %q", file.ChapterSource, chapterSourceComments)
+	}
+	if len(file.Chapters) != 2 {
+		t.Fatalf("expected 2 chapters, got %d", len(file.Chapters))
+	}
+	if intro := file.Chapters[0]; intro.Title != "Intro" || intro.EndTime != 30*time.Second {
+		t.Errorf("Chapters[0] = %+v, want Intro ending where Interview starts", intro)
+	}
+	if last := file.Chapters[1]; last.Title != "Interview" || last.EndTime != file.Audio.Duration || last.EndTime == 0 {
+		t.Errorf("Chapters[1] = %+v, want Interview ending with the file (%v)", last, file.Audio.Duration)
+	}
+}
+
+// withSerial rewrites the serial number of every page in stream.
+func withSerial(stream []byte, serial uint32) []byte {
+	out := bytes.Clone(stream)
+	for offset := 0; offset+27 <= len(out); {
+		binary.LittleEndian.PutUint32(out[offset+14:], serial)
+		segments := out[offset+27 : offset+27+int(out[offset+26])]
+		offset += 27 + len(segments)
+		for _, s := range segments {
+			offset += int(s)
+		}
+	}
+	return out
+}
+
+// createOpusHead builds an OpusHead packet for a stereo 48 kHz stream.
+func createOpusHead() []byte {
+	head := []byte("OpusHead")
+	head = append(head, 1, 2)
+	head = binary.LittleEndian.AppendUint16(head, 0)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	return append(head, 0, 0, 0)
+}
+
+func TestParseOpus_Chained(t *testing.T) {
+	// Two Opus files concatenated, as with `cat a.opus b.opus`: each link
+	// restarts its granule positions at zero
+	first := createOggStream(48000*90, createOpusHead(), append([]byte("OpusTags"), createCommentList("TITLE=Part One")...), make([]byte, 100))
+	second := createOggStream(48000*30, createOpusHead(), append([]byte("OpusTags"), createCommentList("TITLE=Part Two")...), make([]byte, 100))
+
+	data := append(bytes.Clone(first), withSerial(second, 5678)...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "chained.opus")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Audio.Duration != 2*time.Minute {
+		t.Errorf("Duration = %v, want the two links' total of 2m0s", file.Audio.Duration)
+	}
+	if file.Tags.Title != "Part One" {
+		t.Errorf("Title = %q, want the first link's %q", file.Tags.Title, "Part One")
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Severity != types.SeverityInfo ||
+		!strings.Contains(file.Warnings[0].Message, "2 logical streams in 2 links") {
+		t.Errorf("Warnings = %v, want one info warning counting the streams", file.Warnings)
+	}
+}
+
+// countingReaderAt counts the ReadAt calls made on it.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.reads++
+	return c.r.ReadAt(p, off)
+}
+
+func TestParseOpus_SingleStreamSkipsChainWalk(t *testing.T) {
+	// Hundreds of audio pages: walking them would take two reads each
+	packets := [][]byte{createOpusHead(), append([]byte("OpusTags"), createCommentList("TITLE=Single")...)}
+	for range 500 {
+		packets = append(packets, make([]byte, 100))
+	}
+	data := createOggStream(48000*60, packets...)
+
+	r := &countingReaderAt{r: bytes.NewReader(data)}
+	p := &parser{}
+	file, err := p.Parse(context.Background(), r, int64(len(data)), "single.opus")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Audio.Duration != time.Minute {
+		t.Errorf("Duration = %v, want 1m0s from the last page", file.Audio.Duration)
+	}
+	if r.reads > 50 {
+		t.Errorf("Parse made %d reads, want the header pages and one tail read, not a walk of all pages", r.reads)
+	}
+}
+
+func TestParseOpus_ChainCutShort(t *testing.T) {
+	first := createOggStream(48000*90, createOpusHead(), append([]byte("OpusTags"), createCommentList("TITLE=Part One")...), make([]byte, 100))
+	second := createOggStream(48000*30, createOpusHead(), append([]byte("OpusTags"), createCommentList("TITLE=Part Two")...), make([]byte, 100))
+
+	// Garbage between the links stops the walk after the first
+	data := append(append(bytes.Clone(first), "junk"...), withSerial(second, 5678)...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "chained.opus")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Audio.Duration != 90*time.Second {
+		t.Errorf("Duration = %v, want the first link's 1m30s", file.Audio.Duration)
+	}
+	cutShort := slices.ContainsFunc(file.Warnings, func(w types.Warning) bool {
+		return errors.Is(w.Err, errChainCutShort)
+	})
+	if !cutShort {
+		t.Errorf("Warnings = %v, want one saying the walk stopped early", file.Warnings)
+	}
+}
+
+func TestParseOpus_Multiplexed(t *testing.T) {
+	// A non-audio stream whose BOS page comes first and whose pages come
+	// last, interleaved page by page with the Opus stream
+	other := withSerial(createOggStream(0, []byte("fishead\x00"), make([]byte, 20)), 99)
+	otherBOS, otherEOS := other[:27+1+8], other[27+1+8:]
+	opus := createOggStream(48000*45, createOpusHead(), append([]byte("OpusTags"), createCommentList("TITLE=Muxed")...), make([]byte, 100))
+
+	data := append(append(bytes.Clone(otherBOS), opus...), otherEOS...)
+
+	p := &parser{}
+	file, err := p.Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "muxed.opus")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if file.Format != types.FormatOpus || file.Tags.Title != "Muxed" {
+		t.Errorf("Format, Title = %v, %q; want Opus, %q", file.Format, file.Tags.Title, "Muxed")
+	}
+	if file.Audio.Duration != 45*time.Second {
+		t.Errorf("Duration = %v, want 45s from the Opus stream's pages", file.Audio.Duration)
+	}
+	if len(file.Warnings) != 1 || !strings.Contains(file.Warnings[0].Message, "multiplexes 2 logical streams") {
+		t.Errorf("Warnings = %v, want one warning counting the streams", file.Warnings)
+	}
+}
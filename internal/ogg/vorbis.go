@@ -41,9 +41,10 @@ func parseVorbisIdentification(data []byte, file *types.File) error {
 	// Parse audio properties (all little-endian)
 	channels := data[11]
 	sampleRate := binary.LittleEndian.Uint32(data[12:16])
-	// bitrateMaximum is at data[16:20] but is optional and often 0, so we skip it
+	// Bitrate fields are hints: 0 (or -1) means unset
+	bitrateMaximum := binary.LittleEndian.Uint32(data[16:20])
 	bitrateNominal := binary.LittleEndian.Uint32(data[20:24])
-	// bitrateMinimum is at data[24:28] but is optional and often 0, so we skip it
+	bitrateMinimum := binary.LittleEndian.Uint32(data[24:28])
 
 	// Populate file.Audio
 	file.Audio.Codec = "Vorbis"
@@ -52,11 +53,23 @@ func parseVorbisIdentification(data []byte, file *types.File) error {
 	file.Audio.Channels = int(channels)
 	file.Audio.Bitrate = int(bitrateNominal)
 	file.Audio.Lossless = false
-	file.Audio.VBR = true // Vorbis is typically VBR
+	file.Audio.VBR = !isVorbisCBR(bitrateMinimum, bitrateNominal, bitrateMaximum)
 
 	return nil
 }
 
+// isVorbisCBR reports whether the identification header declares a constant
+// bitrate, i.e. minimum, nominal and maximum are all set and equal.
+// Anything else (unset bounds, or differing values) is treated as VBR,
+// which is how virtually all Vorbis files are encoded.
+func isVorbisCBR(minimum, nominal, maximum uint32) bool {
+	unset := func(v uint32) bool { return v == 0 || v == 0xFFFFFFFF }
+	if unset(minimum) || unset(nominal) || unset(maximum) {
+		return false
+	}
+	return minimum == nominal && nominal == maximum
+}
+
 // parseVorbisComment parses the Vorbis comment header (packet type 0x03).
 //
 // The comment header contains Vorbis comments (tags) in the same format
@@ -113,8 +126,9 @@ func parseVorbisComment(data []byte, file *types.File) error {
 		if offset+4 > len(data) {
 			// Truncated, but don't fail - just stop reading
 			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "metadata",
-				Message: fmt.Sprintf("truncated comment %d", i),
+				Stage:    "metadata",
+				Message:  fmt.Sprintf("truncated comment %d", i),
+				Severity: types.SeverityError,
 			})
 			break
 		}
@@ -125,8 +139,9 @@ func parseVorbisComment(data []byte, file *types.File) error {
 		if offset+int(commentLen) > len(data) {
 			// Truncated comment
 			file.Warnings = append(file.Warnings, types.Warning{
-				Stage:   "metadata",
-				Message: fmt.Sprintf("truncated comment %d data", i),
+				Stage:    "metadata",
+				Message:  fmt.Sprintf("truncated comment %d data", i),
+				Severity: types.SeverityError,
 			})
 			break
 		}
@@ -151,6 +166,9 @@ func parseVorbisComment(data []byte, file *types.File) error {
 	// Parse chapters from CHAPTER comments
 	if len(allComments) > 0 {
 		file.Chapters = vorbis.ParseChapters(allComments, file.Audio.Duration)
+		if len(file.Chapters) > 0 {
+			file.ChapterSource = chapterSourceComments
+		}
 	}
 
 	return nil
@@ -0,0 +1,38 @@
+package parsing
+
+import "strings"
+
+// DefaultArtistSeparators are the separators SplitArtists uses when none
+// are configured. A bare "/" is left out so names like "AC/DC" survive.
+var DefaultArtistSeparators = []string{";", " / "}
+
+// SplitArtists splits a multi-artist string ("A; B", "A / B") into its
+// names, trimming whitespace and dropping empty entries.
+//
+// A nil separators slice means DefaultArtistSeparators; an empty non-nil
+// slice disables splitting. Returns nil for empty input.
+func SplitArtists(value string, separators []string) []string {
+	if separators == nil {
+		separators = DefaultArtistSeparators
+	}
+
+	parts := []string{value}
+	for _, sep := range separators {
+		if sep == "" {
+			continue
+		}
+		var split []string
+		for _, part := range parts {
+			split = append(split, strings.Split(part, sep)...)
+		}
+		parts = split
+	}
+
+	var artists []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			artists = append(artists, part)
+		}
+	}
+	return artists
+}
@@ -0,0 +1,32 @@
+package parsing
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitArtists(t *testing.T) {
+	tests := []struct {
+		input      string
+		separators []string
+		expected   []string
+	}{
+		{"A; B", nil, []string{"A", "B"}},
+		{"A / B;C", nil, []string{"A", "B", "C"}},
+		{"AC/DC", nil, []string{"AC/DC"}},
+		{"Solo", nil, []string{"Solo"}},
+		{"A; ; B;", nil, []string{"A", "B"}},
+		{"", nil, nil},
+		{"A, B & C", []string{", ", " & "}, []string{"A", "B", "C"}},
+		{"A; B", []string{}, []string{"A; B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := SplitArtists(tt.input, tt.separators)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("SplitArtists(%q, %q) = %q, want %q", tt.input, tt.separators, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package parsing
+
+import "testing"
+
+func TestID3v1Genre(t *testing.T) {
+	tests := []struct {
+		index  int
+		want   string
+		wantOK bool
+	}{
+		{0, "Blues", true},
+		{17, "Rock", true},
+		{79, "Hard Rock", true},
+		{80, "Folk", true},
+		{147, "Synthpop", true},
+		{148, "", false},
+		{255, "", false},
+		{-1, "", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := ID3v1Genre(tt.index); got != tt.want || ok != tt.wantOK {
+			t.Errorf("ID3v1Genre(%d) = %q, %v; want %q, %v", tt.index, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package parsing
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseBPM parses a beats-per-minute text value.
+//
+// Fractional values are rounded to the nearest integer ("128.5" → 129).
+// Returns 0 for empty, negative, or unparseable input.
+func ParseBPM(text string) int {
+	text = strings.TrimSpace(strings.TrimRight(text, "\x00"))
+	if text == "" {
+		return 0
+	}
+
+	if bpm, err := strconv.Atoi(text); err == nil {
+		return max(bpm, 0)
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0
+	}
+	return int(math.Round(f))
+}
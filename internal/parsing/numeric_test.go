@@ -0,0 +1,25 @@
+package parsing
+
+import "testing"
+
+func TestParseBPM(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"128", 128},
+		{"128.5", 129},
+		{"127.4", 127},
+		{" 90 ", 90},
+		{"120\x00", 120},
+		{"", 0},
+		{"-5", 0},
+		{"fast", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseBPM(tt.input); got != tt.expected {
+			t.Errorf("ParseBPM(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+}
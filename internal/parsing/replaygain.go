@@ -0,0 +1,51 @@
+package parsing
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseReplayGainValue parses a ReplayGain gain value like "-6.50 dB".
+// Returns 0 for unparseable input.
+func ParseReplayGainValue(s string) float64 {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, " dB")
+	s = strings.TrimSuffix(s, "dB")
+	s = strings.TrimSpace(s)
+	val, _ := strconv.ParseFloat(s, 64)
+	return val
+}
+
+// ParseReplayGainPeak parses a ReplayGain peak value like "0.988127".
+// Returns 0 for unparseable input.
+func ParseReplayGainPeak(s string) float64 {
+	val, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return val
+}
+
+// ParseSoundCheck approximates ReplayGain track gain and peak from an
+// iTunes Sound Check (iTunNORM) value: ten space-separated hex words, of
+// which the first two are the left and right channel loudness in
+// thousandths of the 1 mW reference and the seventh and eighth their peak
+// sample values. ok is false if s is not a Sound Check value.
+func ParseSoundCheck(s string) (gain, peak float64, ok bool) {
+	fields := strings.Fields(s)
+	if len(fields) < 10 {
+		return 0, 0, false
+	}
+	words := make([]uint64, 10)
+	for i := range words {
+		v, err := strconv.ParseUint(fields[i], 16, 32)
+		if err != nil {
+			return 0, 0, false
+		}
+		words[i] = v
+	}
+
+	loudness := max(words[0], words[1])
+	if loudness == 0 {
+		return 0, 0, false
+	}
+	return -10 * math.Log10(float64(loudness)/1000), float64(max(words[6], words[7])) / 32768, true
+}
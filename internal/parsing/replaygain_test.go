@@ -0,0 +1,73 @@
+package parsing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseReplayGainValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"-6.50 dB", -6.50},
+		{"-6.50dB", -6.50},
+		{"-6.50", -6.50},
+		{"  -6.50 dB  ", -6.50},
+		{"+3.20 dB", 3.20},
+		{"0", 0.0},
+		{"invalid", 0.0},
+	}
+
+	for _, tc := range tests {
+		got := ParseReplayGainValue(tc.input)
+		if math.Abs(got-tc.want) > 0.001 {
+			t.Errorf("ParseReplayGainValue(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestParseReplayGainPeak(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"0.988127", 0.988127},
+		{"1.0", 1.0},
+		{"  0.5  ", 0.5},
+		{"invalid", 0.0},
+	}
+
+	for _, tc := range tests {
+		got := ParseReplayGainPeak(tc.input)
+		if math.Abs(got-tc.want) > 0.000001 {
+			t.Errorf("ParseReplayGainPeak(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestParseSoundCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantGain float64
+		wantPeak float64
+		invalid  bool
+	}{
+		{" 000003E8 000003E8 00000000 00000000 00000000 00000000 00008000 00007FFF 00000000 00000000", 0, 1, false},
+		{" 00000064 000000C8 00000000 00000000 00000000 00000000 00002000 00004000 00000000 00000000", 6.9897, 0.5, false},
+		{"000003E8 000003E8", 0, 0, true},
+		{" 000003E8 nothex 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000", 0, 0, true},
+		{" 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000", 0, 0, true},
+	}
+
+	for _, tc := range tests {
+		gain, peak, ok := ParseSoundCheck(tc.input)
+		if ok == tc.invalid {
+			t.Errorf("ParseSoundCheck(%q) ok = %v, want %v", tc.input, ok, !tc.invalid)
+			continue
+		}
+		if ok && (math.Abs(gain-tc.wantGain) > 0.001 || math.Abs(peak-tc.wantPeak) > 0.001) {
+			t.Errorf("ParseSoundCheck(%q) = %v, %v; want %v, %v", tc.input, gain, peak, tc.wantGain, tc.wantPeak)
+		}
+	}
+}
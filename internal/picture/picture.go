@@ -0,0 +1,140 @@
+// Package picture decodes FLAC PICTURE blocks, which FLAC stores as a
+// metadata block and Ogg Vorbis and Opus store base64-encoded in the
+// METADATA_BLOCK_PICTURE comment.
+package picture
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Decode reads the picture block of blockLength bytes at offset, and
+// returns it along with the offset of its image data.
+//
+// Image data is only read when withData is set, and is not over the
+// WithMaxArtworkSize limit; Size is always filled in. The MIME type,
+// description and data lengths must each fit in what is left of the
+// block, and of the reader, or nothing is allocated for them.
+//
+// Block layout (big-endian):
+//
+//	[4 bytes] picture type
+//	[4 bytes] MIME type length, then the MIME type
+//	[4 bytes] description length, then the UTF-8 description
+//	[4 bytes] width, height, color depth, indexed colors
+//	[4 bytes] data length, then the image data
+func Decode(ctx context.Context, sr *binary.SafeReader, offset, blockLength int64, withData bool) (art types.Artwork, dataOffset int64, err error) {
+	currentOffset := offset
+	end := min(offset+blockLength, sr.Size())
+	checkLength := func(n uint32, what string) error {
+		if left := end - currentOffset; int64(n) > left {
+			return fmt.Errorf("picture %s length %d exceeds the %d bytes left in the block", what, n, max(left, 0))
+		}
+		return nil
+	}
+
+	// Read picture type (32-bit big-endian)
+	pictureType, err := binary.Read[uint32](sr, currentOffset, "picture type")
+	if err != nil {
+		return types.Artwork{}, 0, err
+	}
+	currentOffset += 4
+
+	// Read MIME type length (32-bit big-endian)
+	mimeLength, err := binary.Read[uint32](sr, currentOffset, "MIME type length")
+	if err != nil {
+		return types.Artwork{}, 0, err
+	}
+	currentOffset += 4
+	if err := checkLength(mimeLength, "MIME type"); err != nil {
+		return types.Artwork{}, 0, err
+	}
+
+	// Read MIME type string
+	mimeData := make([]byte, mimeLength)
+	if err := sr.ReadAt(mimeData, currentOffset, "MIME type"); err != nil {
+		return types.Artwork{}, 0, err
+	}
+	mimeType := string(mimeData)
+	currentOffset += int64(mimeLength)
+
+	// Read description length (32-bit big-endian)
+	descLength, err := binary.Read[uint32](sr, currentOffset, "description length")
+	if err != nil {
+		return types.Artwork{}, 0, err
+	}
+	currentOffset += 4
+	if err := checkLength(descLength, "description"); err != nil {
+		return types.Artwork{}, 0, err
+	}
+
+	// Read description string (UTF-8)
+	descData := make([]byte, descLength)
+	if descLength > 0 {
+		if err := sr.ReadAt(descData, currentOffset, "description"); err != nil {
+			return types.Artwork{}, 0, err
+		}
+	}
+	description := string(descData)
+	currentOffset += int64(descLength)
+
+	// Read width, height, color depth, indexed colors (4 × 32-bit big-endian)
+	width, err := binary.Read[uint32](sr, currentOffset, "width")
+	if err != nil {
+		return types.Artwork{}, 0, err
+	}
+	currentOffset += 4
+
+	height, err := binary.Read[uint32](sr, currentOffset, "height")
+	if err != nil {
+		return types.Artwork{}, 0, err
+	}
+	currentOffset += 4
+
+	// Skip color depth and indexed colors (not used)
+	currentOffset += 8
+
+	// Read picture data length (32-bit big-endian)
+	dataLength, err := binary.Read[uint32](sr, currentOffset, "picture data length")
+	if err != nil {
+		return types.Artwork{}, 0, err
+	}
+	currentOffset += 4
+	if err := checkLength(dataLength, "data"); err != nil {
+		return types.Artwork{}, 0, err
+	}
+
+	// Read picture data, unless it is over the WithMaxArtworkSize limit
+	var pictureData []byte
+	if withData && !registry.ArtworkTooLarge(ctx, int64(dataLength)) {
+		pictureData = make([]byte, dataLength)
+		if err := sr.ReadAtContext(ctx, pictureData, currentOffset, "picture data"); err != nil {
+			return types.Artwork{}, 0, err
+		}
+	}
+
+	// Map FLAC picture type to types.ArtworkType
+	var artType types.ArtworkType
+	switch pictureType {
+	case 3:
+		artType = types.ArtworkFrontCover
+	case 4:
+		artType = types.ArtworkBackCover
+	default:
+		artType = types.ArtworkOther
+	}
+
+	return types.Artwork{
+		Data:        pictureData,
+		MIMEType:    mimeType,
+		Type:        artType,
+		Description: description,
+		Width:       int(width),
+		Height:      int(height),
+		Size:        int(dataLength),
+	}, currentOffset, nil
+}
@@ -0,0 +1,107 @@
+package picture
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// createBlock builds a picture block, with prefix bytes before it.
+func createBlock(prefix int, pictureType uint32, mimeType, description string, width, height uint32, data []byte) []byte {
+	block := make([]byte, prefix)
+	block = binary.BigEndian.AppendUint32(block, pictureType)
+	block = binary.BigEndian.AppendUint32(block, uint32(len(mimeType)))
+	block = append(block, mimeType...)
+	block = binary.BigEndian.AppendUint32(block, uint32(len(description)))
+	block = append(block, description...)
+	block = binary.BigEndian.AppendUint32(block, width)
+	block = binary.BigEndian.AppendUint32(block, height)
+	block = append(block, make([]byte, 8)...) // Color depth, indexed colors
+	block = binary.BigEndian.AppendUint32(block, uint32(len(data)))
+	return append(block, data...)
+}
+
+func TestDecode(t *testing.T) {
+	image := []byte{0xFF, 0xD8, 0xFF, 0xE0}
+	data := createBlock(8, 4, "image/jpeg", "Back", 600, 400, image)
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		withData bool
+		wantData []byte
+	}{
+		{"with data", context.Background(), true, image},
+		{"without data", context.Background(), false, nil},
+		{"over the size limit", registry.WithMaxArtworkSize(context.Background(), 2), true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			art, dataOffset, err := Decode(tt.ctx, sr, 8, int64(len(data)-8), tt.withData)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			want := types.Artwork{
+				Data:        tt.wantData,
+				MIMEType:    "image/jpeg",
+				Type:        types.ArtworkBackCover,
+				Description: "Back",
+				Width:       600,
+				Height:      400,
+				Size:        len(image),
+			}
+			if !reflect.DeepEqual(art, want) {
+				t.Errorf("Decode() = %+v, want %+v", art, want)
+			}
+			if want := int64(len(data) - len(image)); dataOffset != want {
+				t.Errorf("data offset = %d, want %d", dataOffset, want)
+			}
+		})
+	}
+}
+
+func TestDecode_OversizedLengths(t *testing.T) {
+	// Blocks whose MIME type, description and data lengths run past the
+	// block: nothing is allocated for them
+	block := func(mimeLength, descLength, dataLength uint32) []byte {
+		b := binary.BigEndian.AppendUint32(nil, 3)
+		b = binary.BigEndian.AppendUint32(b, mimeLength)
+		if mimeLength <= 9 {
+			b = append(b, "image/png"[:mimeLength]...)
+			b = binary.BigEndian.AppendUint32(b, descLength)
+			b = append(b, make([]byte, 16)...) // Width, height, depth, colors
+			b = binary.BigEndian.AppendUint32(b, dataLength)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		block []byte
+		want  string
+	}{
+		{"MIME type", block(0x9a9a9a9a, 0, 0), "MIME type length"},
+		{"description", block(9, 0xffffffff, 0), "description length"},
+		{"data", block(9, 0, 1<<30), "data length"},
+		{"truncated", block(9, 0, 0)[:24], "width"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := binutil.NewSafeReader(bytes.NewReader(tt.block), int64(len(tt.block)), "test")
+			_, _, err := Decode(context.Background(), sr, 0, int64(len(tt.block)), true)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Decode() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// charsetKey is the context key for the legacy text charset.
+type charsetKey struct{}
+
+// WithLegacyCharset returns a context telling parsers how to decode legacy
+// 8-bit tag text. Parse has no options parameter, so per-open settings that
+// change decoding travel on the context.
+func WithLegacyCharset(ctx context.Context, charset types.Charset) context.Context {
+	return context.WithValue(ctx, charsetKey{}, charset)
+}
+
+// LegacyCharset returns the charset set by WithLegacyCharset, or Latin1.
+func LegacyCharset(ctx context.Context) types.Charset {
+	if charset, ok := ctx.Value(charsetKey{}).(types.Charset); ok {
+		return charset
+	}
+	return types.Latin1
+}
+
+// artistSeparatorsKey is the context key for multi-artist separators.
+type artistSeparatorsKey struct{}
+
+// WithArtistSeparators returns a context carrying the separators parsers
+// split single-string artist fields on. See parsing.SplitArtists for how
+// nil and empty slices differ.
+func WithArtistSeparators(ctx context.Context, separators []string) context.Context {
+	return context.WithValue(ctx, artistSeparatorsKey{}, separators)
+}
+
+// ArtistSeparators returns the separators set by WithArtistSeparators, or
+// nil for the defaults.
+func ArtistSeparators(ctx context.Context) []string {
+	separators, _ := ctx.Value(artistSeparatorsKey{}).([]string)
+	return separators
+}
+
+// rawTagsKey is the context key for raw tag collection.
+type rawTagsKey struct{}
+
+// WithRawTags returns a context telling parsers whether to record raw tag
+// items with types.File.AddRawTag.
+func WithRawTags(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, rawTagsKey{}, enabled)
+}
+
+// RawTagsEnabled reports whether WithRawTags asked for raw tags.
+func RawTagsEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(rawTagsKey{}).(bool)
+	return enabled
+}
+
+// maxArtworkSizeKey is the context key for the artwork size limit.
+type maxArtworkSizeKey struct{}
+
+// WithMaxArtworkSize returns a context carrying the largest image, in
+// bytes, artwork extractors should load. Zero means no limit.
+func WithMaxArtworkSize(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, maxArtworkSizeKey{}, n)
+}
+
+// ArtworkTooLarge reports whether an image whose declared data length is n
+// exceeds the limit set by WithMaxArtworkSize. Extractors check it before
+// allocating image data, and return an image over the limit with nil Data
+// and Size n, for the caller to drop.
+func ArtworkTooLarge(ctx context.Context, n int64) bool {
+	limit := MaxArtworkSize(ctx)
+	return limit > 0 && n > int64(limit)
+}
+
+// MaxArtworkSize returns the limit set by WithMaxArtworkSize, or 0.
+func MaxArtworkSize(ctx context.Context) int {
+	limit, _ := ctx.Value(maxArtworkSizeKey{}).(int)
+	return limit
+}
+
+// loggerKey is the context key for the debug logger.
+type loggerKey struct{}
+
+// discardLogger is what Logger returns when no logger was set.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// WithLogger returns a context carrying the logger parsers write debug
+// output to: the structures they walk, what they skip, and why.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger set by WithLogger, or one that discards
+// everything. Parsers log through it unconditionally; with the discard
+// handler a LogAttrs call costs an Enabled check and no allocation.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, _ := ctx.Value(loggerKey{}).(*slog.Logger); logger != nil {
+		return logger
+	}
+	return discardLogger
+}
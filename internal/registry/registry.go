@@ -4,6 +4,7 @@ package registry
 import (
 	"context"
 	"io"
+	"slices"
 	"sync"
 
 	"github.com/simonhull/audiometa/internal/types"
@@ -25,6 +26,67 @@ type ArtworkExtractor interface {
 	ExtractArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error)
 }
 
+// ArtworkMetadataExtractor is an optional interface for parsers that can
+// describe embedded images without loading their data.
+type ArtworkMetadataExtractor interface {
+	// ExtractArtworkMetadata returns one Artwork per image with Data nil and
+	// Size set. MIME type and dimensions come from the container or from a
+	// bounded read of the image header, never the full payload.
+	ExtractArtworkMetadata(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Artwork, error)
+}
+
+// ArtworkCounter is an optional interface for parsers that can count
+// embedded images from headers alone, without reading image data.
+type ArtworkCounter interface {
+	// CountArtwork returns the number of embedded images.
+	CountArtwork(ctx context.Context, r io.ReaderAt, size int64, path string) (int, error)
+}
+
+// ChapterExtractor is an optional interface for parsers that can extract
+// chapters without a full metadata parse.
+type ChapterExtractor interface {
+	// ExtractChapters reads only the structures needed to build chapter markers.
+	ExtractChapters(ctx context.Context, r io.ReaderAt, size int64, path string) ([]types.Chapter, error)
+}
+
+// TagWriter is an optional interface for parsers that can write tags back
+// to a file.
+type TagWriter interface {
+	// WriteTags plans a rewrite of the file read from r that stores tags in
+	// place of its current metadata. It only reads; the caller applies the
+	// returned edit.
+	WriteTags(ctx context.Context, r io.ReaderAt, size int64, path string, tags *types.Tags) (*TagEdit, error)
+}
+
+// ArtworkWriter is an optional interface for parsers that can write
+// embedded artwork back to a file.
+type ArtworkWriter interface {
+	// WriteArtwork plans a rewrite of the file read from r that embeds art
+	// in place of its current pictures. Parsers that are also TagWriters
+	// store tags too, unless tags is nil; others ignore it. Like WriteTags,
+	// it only reads.
+	WriteArtwork(ctx context.Context, r io.ReaderAt, size int64, path string, tags *types.Tags, art []types.Artwork) (*TagEdit, error)
+}
+
+// TagEdit describes a tag rewrite as one byte-range replacement: the Length
+// bytes at Offset become Data, and everything else is kept as is.
+//
+// When len(Data) equals Length the file can be patched in place; otherwise
+// the bytes after the range move and the file must be rewritten.
+type TagEdit struct {
+	Offset int64
+	Length int64
+	Data   []byte
+
+	Warnings []types.Warning // Issues found while planning, such as moved audio
+}
+
+// InPlace reports whether applying the edit leaves the file size, and
+// every byte outside the range, unchanged.
+func (e *TagEdit) InPlace() bool {
+	return int64(len(e.Data)) == e.Length
+}
+
 var (
 	mu      sync.RWMutex
 	parsers = make(map[types.Format]FormatParser)
@@ -45,3 +107,17 @@ func Get(format types.Format) FormatParser {
 	defer mu.RUnlock()
 	return parsers[format]
 }
+
+// Formats returns the formats that have a registered parser, in ascending
+// Format order.
+func Formats() []types.Format {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	formats := make([]types.Format, 0, len(parsers))
+	for format := range parsers {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
@@ -123,3 +123,26 @@ func TestRealParsersRegistered(t *testing.T) {
 		_ = parser
 	}
 }
+
+func TestFormats(t *testing.T) {
+	Register(types.Format(995), &mockParser{name: "b"})
+	Register(types.Format(994), &mockParser{name: "a"})
+
+	formats := Formats()
+
+	idx994, idx995 := -1, -1
+	for i, f := range formats {
+		switch f {
+		case types.Format(994):
+			idx994 = i
+		case types.Format(995):
+			idx995 = i
+		}
+	}
+	if idx994 == -1 || idx995 == -1 {
+		t.Fatalf("Formats() = %v, missing registered formats", formats)
+	}
+	if idx994 > idx995 {
+		t.Errorf("Formats() not sorted: %v", formats)
+	}
+}
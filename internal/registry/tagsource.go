@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// TagSource reads one tag container (ID3v2, ID3v1, APEv2, ...) into a file.
+//
+// Formats that can carry several containers list their sources in
+// precedence order and combine them with ReadTagSources.
+type TagSource interface {
+	// ReadTags parses the container and populates file.Tags. A missing
+	// container is not an error; sources return nil and leave file as is.
+	ReadTags(sr *binary.SafeReader, file *types.File) error
+}
+
+// ReadTagSources runs sources in precedence order, highest first.
+//
+// The first source reads directly into file, so anything beyond tags it
+// sets (chapters, HasEmbeddedArtwork) is kept. Each later source reads into
+// a scratch file whose tags only fill what earlier sources left empty;
+// multi-value fields are combined and, for raw tags present in both, the
+// earlier source's values are kept. ReplayGain values are filled the same
+// way, HasEmbeddedArtwork is set if any source found artwork, and warnings
+// from every source are kept.
+//
+// A source error does not stop the remaining sources. It is recorded as a
+// "metadata" warning and returned in errs, indexed like sources (nil for
+// sources that succeeded).
+func ReadTagSources(sr *binary.SafeReader, file *types.File, sources ...TagSource) (errs []error) {
+	errs = make([]error, len(sources))
+
+	for i, source := range sources {
+		target := file
+		if i > 0 {
+			target = &types.File{Path: file.Path, Format: file.Format, Size: file.Size, Audio: file.Audio}
+			if file.Audio.ReplayGain != nil {
+				// A copy, so the source can't overwrite earlier values
+				rg := *file.Audio.ReplayGain
+				target.Audio.ReplayGain = &rg
+			}
+		}
+
+		if err := source.ReadTags(sr, target); err != nil {
+			errs[i] = err
+			target.Warnings = append(target.Warnings, types.Warning{
+				Stage:   "metadata",
+				Message: err.Error(),
+				Err:     err,
+			})
+		}
+
+		if i > 0 {
+			// Lower-precedence tags underneath: file wins on every conflict
+			merged := target.Tags
+			merged.MergeWith(&file.Tags, types.PreferOther)
+			file.Tags = merged
+			mergeReplayGain(&file.Audio, target.Audio.ReplayGain)
+			file.HasEmbeddedArtwork = file.HasEmbeddedArtwork || target.HasEmbeddedArtwork
+			file.Warnings = append(file.Warnings, target.Warnings...)
+		}
+	}
+
+	return errs
+}
+
+// mergeReplayGain fills the ReplayGain values audio lacks from rg.
+func mergeReplayGain(audio *types.AudioInfo, rg *types.ReplayGainInfo) {
+	if rg == nil {
+		return
+	}
+	if audio.ReplayGain == nil {
+		audio.ReplayGain = &types.ReplayGainInfo{}
+	}
+
+	dst := audio.ReplayGain
+	if dst.TrackGain == 0 {
+		dst.TrackGain = rg.TrackGain
+	}
+	if dst.TrackPeak == 0 {
+		dst.TrackPeak = rg.TrackPeak
+	}
+	if dst.AlbumGain == 0 {
+		dst.AlbumGain = rg.AlbumGain
+	}
+	if dst.AlbumPeak == 0 {
+		dst.AlbumPeak = rg.AlbumPeak
+	}
+}
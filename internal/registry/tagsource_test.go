@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// mockSource implements TagSource by copying fixed tags into the file.
+type mockSource struct {
+	tags types.Tags
+	err  error
+}
+
+func (m mockSource) ReadTags(_ *binary.SafeReader, file *types.File) error {
+	file.Tags = *m.tags.Clone()
+	return m.err
+}
+
+func TestReadTagSources(t *testing.T) {
+	primary := mockSource{tags: types.Tags{Title: "Primary", Genres: []string{"Rock"}}}
+	primary.tags.Set("COMMENT", "primary")
+
+	secondary := mockSource{tags: types.Tags{Title: "Secondary", Artist: "Fallback", Genres: []string{"Pop"}}}
+	secondary.tags.Set("COMMENT", "secondary")
+	secondary.tags.Set("EXTRA", "kept")
+
+	failing := mockSource{err: errors.New("bad footer")}
+
+	file := &types.File{}
+	errs := ReadTagSources(nil, file, primary, secondary, failing)
+
+	if file.Tags.Title != "Primary" {
+		t.Errorf("Title = %q, want the first source to win", file.Tags.Title)
+	}
+	if file.Tags.Artist != "Fallback" {
+		t.Errorf("Artist = %q, want it filled from the second source", file.Tags.Artist)
+	}
+	if len(file.Tags.Genres) != 2 || file.Tags.Genres[0] != "Rock" {
+		t.Errorf("Genres = %v, want [Rock Pop]", file.Tags.Genres)
+	}
+	if got := file.Tags.GetFirst("COMMENT"); got != "primary" {
+		t.Errorf("raw COMMENT = %q, want the first source's value", got)
+	}
+	if got := file.Tags.GetFirst("EXTRA"); got != "kept" {
+		t.Errorf("raw EXTRA = %q, want it copied from the second source", got)
+	}
+
+	if errs[0] != nil || errs[1] != nil || errs[2] == nil {
+		t.Errorf("errs = %v, want only the third source to fail", errs)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "metadata" {
+		t.Errorf("Warnings = %v, want one metadata warning", file.Warnings)
+	}
+}
@@ -0,0 +1,133 @@
+// Package riff walks the chunk lists of RIFF (WAV) and IFF (AIFF) files.
+//
+// Both containers are a sequence of chunks, each a 4-character ID and a
+// 32-bit payload size followed by the payload and, when the size is odd,
+// one pad byte. They differ only in byte order: RIFF sizes are
+// little-endian, IFF sizes big-endian.
+package riff
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/simonhull/audiometa/internal/binary"
+)
+
+// HeaderSize is the size of a chunk header: ID (4) + payload size (4).
+const HeaderSize = 8
+
+// Chunk describes one chunk found by a Scanner.
+type Chunk struct {
+	ID     string
+	Offset int64 // Start of the payload, just past the header
+	Size   int64 // Payload size, excluding any pad byte
+}
+
+// End returns the offset just past the payload and its pad byte, where the
+// next chunk starts.
+func (c Chunk) End() int64 {
+	return c.Offset + c.Size + c.Size&1
+}
+
+// Form is the outer RIFF or FORM chunk that wraps a whole file.
+type Form struct {
+	ID    string // "RIFF" or "FORM"
+	Type  string // Form type: "WAVE", "AIFF", "AIFC"
+	Order binary.Endianness
+	Size  int64 // Declared size of the form payload, including Type
+}
+
+// ReadForm reads the 12-byte file header and reports the byte order its
+// chunks use. Returns an error if the file is neither RIFF nor FORM.
+//
+// Form layout:
+//
+//	[4 bytes] "RIFF" or "FORM"
+//	[4 bytes] size of the rest of the file
+//	[4 bytes] form type
+func ReadForm(sr *binary.SafeReader) (Form, error) {
+	header := make([]byte, 12)
+	if err := sr.ReadAt(header, 0, "form header"); err != nil {
+		return Form{}, err
+	}
+
+	form := Form{ID: string(header[0:4]), Type: string(header[8:12])}
+	switch form.ID {
+	case "RIFF":
+		form.Order = binary.LittleEndian
+	case "FORM":
+		form.Order = binary.BigEndian
+	default:
+		return Form{}, fmt.Errorf("not a RIFF or IFF file: header %q", form.ID)
+	}
+
+	size, err := binary.ReadEndian[uint32](sr, 4, "form size", form.Order)
+	if err != nil {
+		return Form{}, err
+	}
+	form.Size = int64(size)
+	return form, nil
+}
+
+// Chunks returns a scanner over the top-level chunks of form, bounded by
+// the declared form size and the file size.
+func (f Form) Chunks(sr *binary.SafeReader) *Scanner {
+	return NewScanner(sr, 12, min(HeaderSize+f.Size, sr.Size()), f.Order)
+}
+
+// Scanner walks the chunks between two offsets. Iterate with All, then
+// check Err, as with bufio.Scanner.
+type Scanner struct {
+	sr         *binary.SafeReader
+	start, end int64
+	order      binary.Endianness
+	err        error
+}
+
+// NewScanner returns a scanner over the chunks in [start, end).
+func NewScanner(sr *binary.SafeReader, start, end int64, order binary.Endianness) *Scanner {
+	return &Scanner{sr: sr, start: start, end: end, order: order}
+}
+
+// All returns an iterator over the chunks in file order.
+//
+// A chunk whose declared size runs past the end is yielded with its Size
+// cut to the bytes that remain, then the walk stops and Err reports the
+// overrun; truncated files usually still have a usable unfinished data
+// chunk. Trailing bytes too short for a chunk header are ignored.
+func (s *Scanner) All() iter.Seq[Chunk] {
+	return func(yield func(Chunk) bool) {
+		offset := s.start
+		for offset+HeaderSize <= s.end {
+			header := make([]byte, 4)
+			if err := s.sr.ReadAt(header, offset, "chunk ID"); err != nil {
+				s.err = err
+				return
+			}
+			size, err := binary.ReadEndian[uint32](s.sr, offset+4, "chunk size", s.order)
+			if err != nil {
+				s.err = err
+				return
+			}
+
+			chunk := Chunk{ID: string(header), Offset: offset + HeaderSize, Size: int64(size)}
+			if remaining := s.end - chunk.Offset; chunk.Size > remaining {
+				s.err = fmt.Errorf("chunk %q at offset %d declares %d bytes but only %d remain",
+					chunk.ID, offset, chunk.Size, remaining)
+				chunk.Size = remaining
+				yield(chunk)
+				return
+			}
+
+			if !yield(chunk) {
+				return
+			}
+			offset = chunk.End()
+		}
+	}
+}
+
+// Err returns the error that stopped the last walk, if any.
+func (s *Scanner) Err() error {
+	return s.err
+}
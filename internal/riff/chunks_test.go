@@ -0,0 +1,130 @@
+package riff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+)
+
+// createForm builds a RIFF or FORM file from (id, payload) chunk pairs,
+// padding odd-length payloads.
+func createForm(id, formType string, order binary.ByteOrder, chunks ...string) []byte {
+	body := &bytes.Buffer{}
+	body.WriteString(formType)
+	for i := 0; i+1 < len(chunks); i += 2 {
+		body.WriteString(chunks[i])
+		binary.Write(body, order, uint32(len(chunks[i+1])))
+		body.WriteString(chunks[i+1])
+		if len(chunks[i+1])%2 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(id)
+	binary.Write(buf, order, uint32(body.Len()))
+	buf.Write(body.Bytes())
+	return buf.Bytes()
+}
+
+func TestScanner_All(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		formType  string
+		order     binary.ByteOrder
+		wantOrder binutil.Endianness
+	}{
+		{"wav", "RIFF", "WAVE", binary.LittleEndian, binutil.LittleEndian},
+		{"aiff", "FORM", "AIFF", binary.BigEndian, binutil.BigEndian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// "odd" has a 3-byte payload, so a pad byte precedes "last"
+			data := createForm(tt.id, tt.formType, tt.order, "fmt ", "1234", "odd ", "abc", "last", "xy")
+			sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test")
+
+			form, err := ReadForm(sr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if form.Type != tt.formType || form.Order != tt.wantOrder {
+				t.Errorf("unexpected form %+v", form)
+			}
+
+			scanner := form.Chunks(sr)
+			var got []Chunk
+			for chunk := range scanner.All() {
+				got = append(got, chunk)
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := []Chunk{
+				{ID: "fmt ", Offset: 20, Size: 4},
+				{ID: "odd ", Offset: 32, Size: 3},
+				{ID: "last", Offset: 44, Size: 2},
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d chunks, want %d: %+v", len(got), len(want), got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("chunk %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScanner_Truncated(t *testing.T) {
+	data := createForm("RIFF", "WAVE", binary.LittleEndian, "fmt ", "1234", "data", "0123456789")
+	data = data[:len(data)-4] // Cut the data chunk short
+
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.wav")
+	form, err := ReadForm(sr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	scanner := form.Chunks(sr)
+	var last Chunk
+	for chunk := range scanner.All() {
+		last = chunk
+	}
+
+	if last.ID != "data" || last.Size != 6 {
+		t.Errorf("expected truncated data chunk of 6 bytes, got %+v", last)
+	}
+	if scanner.Err() == nil {
+		t.Error("expected an error for the overrunning chunk")
+	}
+}
+
+func TestScanner_EarlyStop(t *testing.T) {
+	data := createForm("FORM", "AIFF", binary.BigEndian, "COMM", "12", "SSND", "34")
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.aiff")
+	form, _ := ReadForm(sr)
+
+	count := 0
+	for range form.Chunks(sr).All() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("expected iteration to stop after 1 chunk, got %d", count)
+	}
+}
+
+func TestReadForm_NotRIFF(t *testing.T) {
+	data := []byte("ID3\x04\x00\x00\x00\x00\x00\x00\x00\x00")
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "test.mp3")
+
+	if _, err := ReadForm(sr); err == nil {
+		t.Error("expected error for non-RIFF header")
+	}
+}
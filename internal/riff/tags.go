@@ -0,0 +1,63 @@
+package riff
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// MergeTags sets file.Tags from a file's ID3 chunk and its native metadata
+// chunks (LIST INFO in WAV; NAME, AUTH and friends in AIFF).
+//
+// Either container may come first in the file or be missing; the result
+// depends only on their contents. ID3 takes precedence: it has far more
+// fields, carries Unicode text, and is what taggers keep up to date.
+// Native values fill fields ID3 leaves empty, and multi-value fields are
+// combined, as with Tags.Merge.
+//
+// Each standard field both containers set to different values, where the
+// native value is dropped, adds a "metadata" warning naming the field and
+// both values. Combined multi-value fields lose nothing and are not
+// reported. Either argument may be nil.
+func MergeTags(file *types.File, id3, native *types.Tags) {
+	if id3 == nil {
+		id3 = &types.Tags{}
+	}
+
+	merged := id3.Clone()
+	merged.Merge(native)
+
+	if native != nil {
+		for name, value := range native.Fields() {
+			kept, _ := id3.Field(name)
+			if result, _ := merged.Field(name); kept == "" || kept == value || result != kept {
+				continue
+			}
+			file.Warnings = append(file.Warnings, types.Warning{
+				Stage:    "metadata",
+				Message:  fmt.Sprintf("ID3 and native %s differ (%q vs %q); ID3 takes precedence", name, kept, value),
+				Severity: types.SeverityInfo,
+			})
+		}
+	}
+
+	file.Tags = *merged
+}
+
+// DecodeText decodes the text of a native metadata chunk: LIST INFO items
+// in WAV, NAME, AUTH and the like in AIFF. Both are specified as 8-bit
+// text, NUL-terminated or padded; newer writers put UTF-8 there, so text
+// that is valid UTF-8 is kept as is and anything else is decoded in
+// charset.
+func DecodeText(data []byte, charset types.Charset) string {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	data = bytes.TrimSpace(data)
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return charset.Decode(data)
+}
@@ -0,0 +1,87 @@
+package riff
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestMergeTags(t *testing.T) {
+	id3 := &types.Tags{Title: "ID3 Title", Artist: "Same Artist", Genres: []string{"Rock"}}
+	native := &types.Tags{Title: "INFO Title", Artist: "Same Artist", Album: "INFO Album", Genres: []string{"Pop"}}
+
+	file := &types.File{}
+	MergeTags(file, id3, native)
+
+	if file.Tags.Title != "ID3 Title" {
+		t.Errorf("Title = %q, want the ID3 value", file.Tags.Title)
+	}
+	if file.Tags.Album != "INFO Album" {
+		t.Errorf("Album = %q, want it filled from native tags", file.Tags.Album)
+	}
+	if !slices.Equal(file.Tags.Genres, []string{"Rock", "Pop"}) {
+		t.Errorf("Genres = %v, want [Rock Pop]", file.Tags.Genres)
+	}
+
+	// Title conflicts; Artist agrees and Genres are combined
+	if len(file.Warnings) != 1 {
+		t.Fatalf("expected 1 conflict warning, got %v", file.Warnings)
+	}
+	if w := file.Warnings[0]; w.Stage != "metadata" || !strings.Contains(w.Message, "Title") {
+		t.Errorf("unexpected warning %+v", w)
+	}
+
+	// The inputs are left untouched
+	if id3.Album != "" || len(id3.Genres) != 1 {
+		t.Errorf("MergeTags modified the ID3 tags: %+v", id3)
+	}
+}
+
+func TestMergeTags_Missing(t *testing.T) {
+	tests := []struct {
+		name        string
+		id3, native *types.Tags
+		want        string
+	}{
+		{"no ID3", nil, &types.Tags{Title: "INFO"}, "INFO"},
+		{"no native", &types.Tags{Title: "ID3"}, nil, "ID3"},
+		{"neither", nil, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &types.File{}
+			MergeTags(file, tt.id3, tt.native)
+			if file.Tags.Title != tt.want {
+				t.Errorf("Title = %q, want %q", file.Tags.Title, tt.want)
+			}
+			if len(file.Warnings) != 0 {
+				t.Errorf("unexpected warnings: %v", file.Warnings)
+			}
+		})
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"NUL terminated", "Title\x00\x00", "Title"},
+		{"padded", "Title  ", "Title"},
+		{"UTF-8", "Caf\xC3\xA9\x00", "Café"},
+		{"Latin-1", "Caf\xE9\x00", "Café"},
+		{"empty", "\x00", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeText([]byte(tt.data), types.Latin1); got != tt.want {
+				t.Errorf("DecodeText(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,32 @@ type Artwork struct {
 	Type        ArtworkType
 	Width       int
 	Height      int
+
+	// Size is the image size in bytes. It is set even when Data is nil,
+	// as with metadata-only extraction (WithArtworkMetadataOnly).
+	Size int
+
+	// offset and length locate the image bytes in the file; length is
+	// zero if they aren't stored there as is.
+	offset, length int64
+}
+
+// WithArtworkLocation returns a copy of a recording that its image bytes
+// are stored unencoded at offset in the file, length bytes long.
+// Extractors set it so that audiometa.File.WriteArtworkTo can copy an
+// image straight from the file. It is a function rather than a method so
+// that callers of the public Artwork alias cannot fabricate a location.
+func WithArtworkLocation(a Artwork, offset, length int64) Artwork {
+	a.offset, a.length = offset, length
+	return a
+}
+
+// ArtworkLocation returns where the image bytes of a are stored in the
+// file, as set by WithArtworkLocation. ok is false for images whose
+// stored bytes need decoding first (base64 in Ogg comments, ID3v2
+// unsynchronisation) and for images not read from a file.
+func ArtworkLocation(a Artwork) (offset, length int64, ok bool) {
+	return a.offset, a.length, a.length > 0
 }
 
 // ArtworkType categorizes the purpose/content of artwork.
@@ -72,6 +98,9 @@ const (
 // Example output: "Front cover (1200x1200 JPEG, 245KB)".
 func (a Artwork) String() string {
 	size := len(a.Data)
+	if a.Data == nil {
+		size = a.Size
+	}
 	sizeStr := formatSize(size)
 
 	// Format dimensions
@@ -126,12 +155,28 @@ func mimeToFormat(mime string) string {
 // RawTag represents an unparsed tag value.
 //
 // RawTag preserves the original binary representation and encoding
-// information for tags that are not mapped to standard Tag fields.
+// information of one tag item, whether or not a standard Tags field maps
+// it. See File.RawTags.
 type RawTag struct {
-	Key      string
+	// Key is the item's name as stored: an ID3v2 frame ID ("TIT2"), an
+	// MP4 atom type ("\xA9nam", "covr"), "----:<mean>:<name>" for MP4
+	// freeform atoms, a Vorbis comment field name or Matroska SimpleTag
+	// name with its case kept, or "PICTURE" for a FLAC PICTURE block.
+	Key string
+
+	// Encoding names the text encoding of Value ("ISO-8859-1", "UTF-16",
+	// "UTF-16BE", "UTF-8"), or is empty when Value is not text or does
+	// not say.
 	Encoding string
-	Value    []byte
-	Type     RawTagType
+
+	// Value is the item's payload: an ID3v2 frame body after
+	// unsynchronisation is reversed (encoding byte included), an MP4 data
+	// atom's value, a Vorbis comment's text after the "=", or a FLAC
+	// PICTURE block. A METADATA_BLOCK_PICTURE comment holds the decoded
+	// picture block rather than its base64 text.
+	Value []byte
+
+	Type RawTagType
 }
 
 // RawTagType indicates the semantic type of a raw tag value.
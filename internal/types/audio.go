@@ -3,7 +3,10 @@ package types
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
+
+	"github.com/simonhull/audiometa/internal/parsing"
 )
 
 // AudioInfo represents technical audio properties.
@@ -12,6 +15,7 @@ import (
 // such as duration, sample rate, bit depth, and codec information.
 type AudioInfo struct {
 	ReplayGain       *ReplayGainInfo
+	LoopInfo         *LoopInfo // ACID loop metadata (WAV acid chunk), nil if absent
 	Codec            string
 	CodecDescription string
 	CodecProfile     string
@@ -21,10 +25,102 @@ type AudioInfo struct {
 	BitDepth         int
 	Channels         int
 	Bitrate          int
-	Lossless         bool
-	VBR              bool
+
+	// ChannelLayout names the speaker arrangement ("stereo", "5.1", "7.1")
+	// when the container records one (M4A chan atom). Empty when unknown.
+	ChannelLayout string
+
+	// SampleFormat and Endianness describe how PCM samples are stored
+	// (WAV format tag, AIFF-C compression type). FLAC always decodes to
+	// signed integers and leaves Endianness unknown.
+	SampleFormat SampleFormat
+	Endianness   Endianness
+
+	// MediaType is the kind of media the file holds, as iTunes records it
+	// (M4A stik atom). MediaTypeUnknown when not recorded.
+	MediaType MediaType
+
+	// LowpassHz is the encoder's lowpass filter cutoff (MP3 LAME tag),
+	// 0 when unknown.
+	LowpassHz int
+
+	// FLAC STREAMINFO block and frame size bounds, 0 when unknown.
+	// MinBlockSize == MaxBlockSize indicates a fixed-block-size stream.
+	MinBlockSize int
+	MaxBlockSize int
+	MinFrameSize int
+	MaxFrameSize int
+
+	// TotalSamples is the number of samples per channel in the stream
+	// (FLAC STREAMINFO), for sample-accurate work where Duration rounds.
+	// 0 when unknown.
+	TotalSamples uint64
+
+	// AudioMD5 is the MD5 signature of the unencoded audio (FLAC
+	// STREAMINFO), against which a decoder's output can be verified. All
+	// zeros when the encoder did not compute it.
+	AudioMD5 [16]byte
+
+	Lossless bool
+	VBR      bool
 }
 
+// SampleFormat is the numeric representation of PCM samples.
+type SampleFormat int
+
+const (
+	// SampleFormatUnknown means the format is not PCM or was not recorded.
+	SampleFormatUnknown SampleFormat = iota
+	// SampleSignedInt is two's-complement integer PCM.
+	SampleSignedInt
+	// SampleUnsignedInt is offset-binary integer PCM (8-bit WAV, AIFF "raw ").
+	SampleUnsignedInt
+	// SampleFloat is IEEE 754 floating-point PCM.
+	SampleFloat
+)
+
+// Endianness is the byte order of multi-byte PCM samples.
+type Endianness int
+
+const (
+	// EndianUnknown means the byte order is not recorded or not applicable.
+	EndianUnknown Endianness = iota
+	// EndianLittle stores the least significant byte first (WAV, AIFF-C sowt).
+	EndianLittle
+	// EndianBig stores the most significant byte first (AIFF).
+	EndianBig
+)
+
+// MediaType is the iTunes media kind of a file.
+//
+//go:generate stringer -type=MediaType -linecomment
+type MediaType int
+
+const (
+	// MediaTypeUnknown means the media kind is not recorded.
+	MediaTypeUnknown MediaType = iota // Unknown
+	// MediaTypeMusic is a song, which iTunes calls "Normal".
+	MediaTypeMusic // Music
+	// MediaTypeAudiobook is an audiobook.
+	MediaTypeAudiobook // Audiobook
+	// MediaTypePodcast is a podcast episode.
+	MediaTypePodcast // Podcast
+	// MediaTypeMusicVideo is a music video.
+	MediaTypeMusicVideo // Music Video
+	// MediaTypeMovie is a movie.
+	MediaTypeMovie // Movie
+	// MediaTypeTVShow is a TV show episode.
+	MediaTypeTVShow // TV Show
+	// MediaTypeBooklet is a digital booklet.
+	MediaTypeBooklet // Booklet
+	// MediaTypeRingtone is a ringtone.
+	MediaTypeRingtone // Ringtone
+	// MediaTypeITunesU is iTunes U course material.
+	MediaTypeITunesU // iTunes U
+	// MediaTypeOther is a recorded media kind with no constant here.
+	MediaTypeOther // Other
+)
+
 // ReplayGainInfo represents loudness normalization data.
 //
 // ReplayGain provides information for normalizing playback volume across
@@ -36,6 +132,55 @@ type ReplayGainInfo struct {
 	AlbumPeak float64 // Album peak amplitude (0.0 to 1.0+)
 }
 
+// SetReplayGain stores a ReplayGain tag in audio.ReplayGain, allocating it
+// if needed. name is REPLAYGAIN_TRACK_GAIN, REPLAYGAIN_TRACK_PEAK,
+// REPLAYGAIN_ALBUM_GAIN or REPLAYGAIN_ALBUM_PEAK, in any case, as Vorbis
+// comments, ID3v2 TXXX descriptions and iTunes freeform atoms all name
+// them. Reports whether name was one of these.
+func SetReplayGain(audio *AudioInfo, name, value string) bool {
+	rg := audio.ReplayGain
+	if rg == nil {
+		rg = &ReplayGainInfo{}
+	}
+
+	switch strings.ToUpper(name) {
+	case "REPLAYGAIN_TRACK_GAIN":
+		rg.TrackGain = parsing.ParseReplayGainValue(value)
+	case "REPLAYGAIN_TRACK_PEAK":
+		rg.TrackPeak = parsing.ParseReplayGainPeak(value)
+	case "REPLAYGAIN_ALBUM_GAIN":
+		rg.AlbumGain = parsing.ParseReplayGainValue(value)
+	case "REPLAYGAIN_ALBUM_PEAK":
+		rg.AlbumPeak = parsing.ParseReplayGainPeak(value)
+	default:
+		return false
+	}
+
+	audio.ReplayGain = rg
+	return true
+}
+
+// LoopInfo describes an ACID-format loop, as used by DAWs and sample
+// libraries to time-stretch loops to the project tempo.
+type LoopInfo struct {
+	Tempo       float64 // Loop tempo in beats per minute
+	Beats       int     // Number of beats in the loop
+	RootNote    int     // MIDI note number of the root key; valid when HasRootNote is set
+	MeterNumer  int     // Time signature numerator (e.g. 4 in 4/4)
+	MeterDenom  int     // Time signature denominator
+	OneShot     bool    // Plays once instead of looping
+	HasRootNote bool
+	Stretch     bool // Loop may be time-stretched
+}
+
+// SeekPoint is one entry of a FLAC SEEKTABLE: the first sample of a frame
+// and where that frame starts.
+type SeekPoint struct {
+	SampleNumber uint64 // First sample of the target frame
+	Offset       uint64 // Bytes from the first frame header to the target frame header
+	FrameSamples uint16 // Number of samples in the target frame
+}
+
 // String returns a human-readable representation of the audio info.
 // Example output: "FLAC 44.1kHz 16-bit stereo".
 func (a AudioInfo) String() string {
@@ -48,8 +193,11 @@ func (a AudioInfo) String() string {
 		bitDepth = fmt.Sprintf("%d-bit", a.BitDepth)
 	}
 
-	// Format channels
-	channels := channelDescription(a.Channels)
+	// Format channels, preferring the declared layout
+	channels := a.ChannelLayout
+	if channels == "" {
+		channels = channelDescription(a.Channels)
+	}
 
 	// Format quality indicator
 	quality := ""
@@ -80,6 +228,57 @@ func (a AudioInfo) String() string {
 	return join(parts, " ")
 }
 
+// Plausible technical ranges checked by Validate.
+const (
+	minSampleRate = 8_000
+	maxSampleRate = 768_000
+	maxChannels   = 64
+
+	// DSD rates run from DSD64 (64 × 44.1 kHz) to DSD1024 (1024 × 48 kHz)
+	minDSDRate = 2_822_400
+	maxDSDRate = 49_152_000
+)
+
+// Validate reports technical values no real stream has, which usually mean
+// a corrupt header or a parser reading from the wrong offset.
+//
+// Checked: sample rate outside 8kHz..768kHz (2.8224MHz..49.152MHz for the
+// 1-bit DSD codec, whose rate is the bit rate), more than 64 channels, a
+// lossless codec without a bit depth, and a duration with no sample rate.
+// Unset (zero) fields are not flagged on their own. Warnings use the
+// "validation" stage. Open runs Validate under WithStrictParsing.
+//
+// Example:
+//
+//	for _, w := range file.Audio.Validate() {
+//		log.Printf("%s: suspicious audio info: %s", file.Path, w.Message)
+//	}
+func (a AudioInfo) Validate() []Warning {
+	var warnings []Warning
+	add := func(format string, args ...any) {
+		warnings = append(warnings, Warning{Stage: "validation", Message: fmt.Sprintf(format, args...)})
+	}
+
+	minRate, maxRate := minSampleRate, maxSampleRate
+	if a.Codec == "DSD" {
+		minRate, maxRate = minDSDRate, maxDSDRate
+	}
+	if a.SampleRate != 0 && (a.SampleRate < minRate || a.SampleRate > maxRate) {
+		add("sample rate %d Hz outside %d..%d Hz", a.SampleRate, minRate, maxRate)
+	}
+	if a.Channels > maxChannels {
+		add("%d channels exceeds the maximum of %d", a.Channels, maxChannels)
+	}
+	if a.Lossless && a.BitDepth == 0 {
+		add("lossless codec %q has no bit depth", a.Codec)
+	}
+	if a.Duration > 0 && a.SampleRate == 0 {
+		add("duration %v with zero sample rate", a.Duration)
+	}
+
+	return warnings
+}
+
 // channelDescription returns a human-readable channel description.
 func channelDescription(channels int) string {
 	switch channels {
@@ -121,6 +320,8 @@ func join(parts []string, sep string) string {
 //   - Sample rate > 48kHz, OR
 //   - Bit depth > 16
 //
+// DSD audio, though 1-bit, qualifies through its MHz sample rate.
+//
 // Example:
 //
 //	if file.Audio.IsHighRes() {
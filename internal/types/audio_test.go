@@ -345,3 +345,65 @@ func TestAudioInfo_Duration(t *testing.T) {
 		t.Errorf("Duration = %v, want %v", audio.Duration, 225*time.Second)
 	}
 }
+
+func TestAudioInfo_Comparable(t *testing.T) {
+	// AudioInfo is a value type callers compare with ==; this fails to
+	// compile if a slice or map field is added
+	a := AudioInfo{Codec: "FLAC", SampleRate: 44100, AudioMD5: [16]byte{1}}
+	b := a
+	if a != b {
+		t.Errorf("copies of %v compare unequal", a)
+	}
+}
+
+func TestAudioInfo_Validate(t *testing.T) {
+	valid := AudioInfo{Codec: "FLAC", SampleRate: 44100, BitDepth: 16, Channels: 2, Lossless: true, Duration: time.Minute}
+
+	tests := []struct {
+		name   string
+		modify func(*AudioInfo)
+		want   int
+	}{
+		{"valid", func(*AudioInfo) {}, 0},
+		{"unset fields", func(a *AudioInfo) { *a = AudioInfo{} }, 0},
+		{"sample rate too low", func(a *AudioInfo) { a.SampleRate = 300 }, 1},
+		{"sample rate too high", func(a *AudioInfo) { a.SampleRate = 1_000_000 }, 1},
+		{"too many channels", func(a *AudioInfo) { a.Channels = 200 }, 1},
+		{"lossless without bit depth", func(a *AudioInfo) { a.BitDepth = 0 }, 1},
+		{"duration without sample rate", func(a *AudioInfo) { a.SampleRate = 0 }, 1},
+		{"lossy without bit depth", func(a *AudioInfo) { a.Lossless, a.BitDepth = false, 0 }, 0},
+		{"DSD64", func(a *AudioInfo) { a.Codec, a.SampleRate, a.BitDepth = "DSD", 2_822_400, 1 }, 0},
+		{"DSD at a PCM rate", func(a *AudioInfo) { a.Codec, a.BitDepth = "DSD", 1 }, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audio := valid
+			tt.modify(&audio)
+
+			warnings := audio.Validate()
+			if len(warnings) != tt.want {
+				t.Fatalf("Validate() = %v, want %d warnings", warnings, tt.want)
+			}
+			for _, w := range warnings {
+				if w.Stage != "validation" {
+					t.Errorf("unexpected stage %q", w.Stage)
+				}
+			}
+		})
+	}
+}
+
+func TestSetReplayGain(t *testing.T) {
+	var audio AudioInfo
+	if SetReplayGain(&audio, "TITLE", "x") || audio.ReplayGain != nil {
+		t.Fatal("expected non-ReplayGain name to be ignored")
+	}
+
+	SetReplayGain(&audio, "replaygain_track_gain", "-6.50 dB")
+	SetReplayGain(&audio, "REPLAYGAIN_ALBUM_PEAK", "0.5")
+	want := ReplayGainInfo{TrackGain: -6.5, AlbumPeak: 0.5}
+	if audio.ReplayGain == nil || *audio.ReplayGain != want {
+		t.Errorf("ReplayGain = %+v, want %+v", audio.ReplayGain, want)
+	}
+}
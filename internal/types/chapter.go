@@ -9,6 +9,7 @@ import "time"
 //   - MP3 files (ID3v2 CHAP frames)
 //   - FLAC files (CUESHEET metadata block)
 //   - Ogg Vorbis/Opus files (CHAPTER Vorbis comments)
+//   - Matroska/WebM files (Chapters element)
 //
 // Access chapters via file.Chapters:
 //
@@ -25,4 +26,43 @@ type Chapter struct {
 	Index     int           `json:"index"`
 	StartTime time.Duration `json:"start_time"`
 	EndTime   time.Duration `json:"end_time"`
+
+	// Image is the chapter's own picture, such as a podcast chapter
+	// thumbnail, or nil. Only MP3 CHAP frames carry one. It is left out
+	// of JSON encodings.
+	Image *Artwork `json:"-"`
+
+	// Children holds the chapters grouped under this one by a nested
+	// table of contents, indexed from 1 within the group. It is only set
+	// on chapters in File.ChapterTree; File.Chapters is always flat.
+	Children []Chapter `json:"children,omitempty"`
+}
+
+// ChapterLeaves flattens a chapter tree into the chapters without
+// Children, depth first, indexed from 1.
+func ChapterLeaves(tree []Chapter) []Chapter {
+	var leaves []Chapter
+	var walk func(chapters []Chapter)
+	walk = func(chapters []Chapter) {
+		for _, ch := range chapters {
+			if len(ch.Children) > 0 {
+				walk(ch.Children)
+				continue
+			}
+			ch.Index = len(leaves) + 1
+			leaves = append(leaves, ch)
+		}
+	}
+	walk(tree)
+	return leaves
+}
+
+// IsChapterTree reports whether any of chapters has Children.
+func IsChapterTree(chapters []Chapter) bool {
+	for _, ch := range chapters {
+		if len(ch.Children) > 0 {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,36 @@
+package types
+
+import "unicode/utf8"
+
+// Charset selects how legacy 8-bit tag text (ID3v2 encoding 0, ID3v1) is
+// transcoded to UTF-8.
+type Charset int
+
+const (
+	// Latin1 decodes bytes as ISO-8859-1, as the ID3 specification requires.
+	Latin1 Charset = iota
+	// Windows1252 decodes bytes as Windows-1252, which differs from Latin-1
+	// only in 0x80-0x9F (smart quotes, dashes, the euro sign). Many
+	// Windows taggers wrote it while labelling the text ISO-8859-1.
+	Windows1252
+)
+
+// windows1252High maps bytes 0x80-0x9F to their Windows-1252 code points.
+// The five bytes Windows-1252 leaves undefined keep their Latin-1 value.
+var windows1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// Decode transcodes 8-bit text in this charset to UTF-8.
+func (c Charset) Decode(data []byte) string {
+	buf := make([]byte, 0, len(data))
+	for _, b := range data {
+		r := rune(b)
+		if c == Windows1252 && b >= 0x80 && b <= 0x9F {
+			r = windows1252High[b-0x80]
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return string(buf)
+}
@@ -0,0 +1,27 @@
+package types
+
+import "testing"
+
+func TestCharset_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		charset Charset
+		input   []byte
+		want    string
+	}{
+		{"ascii", Latin1, []byte("Plain"), "Plain"},
+		{"latin1 accents", Latin1, []byte{'C', 'a', 'f', 0xE9}, "Café"},
+		{"latin1 keeps C1 controls", Latin1, []byte{0x93, 'Q', 0x94}, "\u0093Q\u0094"},
+		{"windows-1252 smart quotes", Windows1252, []byte{0x93, 'Q', 0x94}, "“Q”"},
+		{"windows-1252 dash and euro", Windows1252, []byte{'5', 0x80, ' ', 0x96, ' ', 0xE9}, "5€ – é"},
+		{"windows-1252 undefined byte", Windows1252, []byte{0x81}, "\u0081"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.charset.Decode(tt.input); got != tt.want {
+				t.Errorf("Decode(% X) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
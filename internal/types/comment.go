@@ -0,0 +1,24 @@
+package types
+
+// Comment is one comment entry: an ID3v2 COMM frame, an M4A ©cmt, desc or
+// ldes atom, or a Vorbis COMMENT field.
+type Comment struct {
+	Language    string // ISO 639-2 code from COMM frames ("eng"), empty elsewhere
+	Description string // Short content description distinguishing comments of one file
+	Text        string
+}
+
+// PrimaryComment picks the comment Tags.Comment should show: the first one
+// without a description, since described comments are usually tool data
+// (iTunNORM, iTunSMPB), or else the first one. Returns "" for no comments.
+func PrimaryComment(comments []Comment) string {
+	for _, c := range comments {
+		if c.Description == "" {
+			return c.Text
+		}
+	}
+	if len(comments) > 0 {
+		return comments[0].Text
+	}
+	return ""
+}
@@ -1,6 +1,9 @@
 package types
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // OutOfBoundsError is returned when attempting to read beyond file bounds.
 type OutOfBoundsError struct {
@@ -30,6 +33,30 @@ func (e *UnsupportedFormatError) Error() string {
 	return fmt.Sprintf("%s: unsupported format: %s", e.Path, e.Reason)
 }
 
+// ErrParserNotLinked is the sentinel wrapped by ParserNotLinkedError.
+// Match it with errors.Is.
+var ErrParserNotLinked = errors.New("parser not linked")
+
+// ParserNotLinkedError is returned when a file's format was detected but
+// the package providing its parser was not linked into the binary, so its
+// init() never registered it. The root package imports every built-in
+// parser, so this only happens in builds that trim those imports.
+type ParserNotLinkedError struct {
+	Path    string
+	Package string // Import path that registers the parser
+	Format  Format
+}
+
+func (e *ParserNotLinkedError) Error() string {
+	return fmt.Sprintf("%s: %s detected but %v; import _ %q to register its parser",
+		e.Path, e.Format, ErrParserNotLinked, e.Package)
+}
+
+// Unwrap returns ErrParserNotLinked.
+func (e *ParserNotLinkedError) Unwrap() error {
+	return ErrParserNotLinked
+}
+
 // CorruptedFileError is returned when file structure is invalid.
 type CorruptedFileError struct {
 	Path   string
@@ -41,6 +68,18 @@ func (e *CorruptedFileError) Error() string {
 	return fmt.Sprintf("%s: corrupted file at offset %d: %s", e.Path, e.Offset, e.Reason)
 }
 
+// FileTooLargeError is returned when a file exceeds the size cap set with
+// WithMaxFileSize.
+type FileTooLargeError struct {
+	Path  string
+	Size  int64
+	Limit int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("%s: file size %d exceeds maximum of %d bytes", e.Path, e.Size, e.Limit)
+}
+
 // Warning represents a non-fatal issue encountered during parsing.
 //
 // Warnings indicate problems that don't prevent metadata extraction but
@@ -58,22 +97,103 @@ type Warning struct {
 	// Warning message
 	Message string
 
+	// Severity classifies the issue, for tooling that filters warnings.
+	// The zero value is SeverityWarning.
+	Severity Severity
+
 	// Err is the underlying error, if any. Callers can use errors.Is/As
 	// against this via Warning.Unwrap(). May be nil for warnings that
 	// don't originate from a Go error value.
 	Err error
 
-	// File offset where the issue occurred (0 if not applicable)
+	// File offset where the issue occurred (0 if not applicable).
+	// For collapsed warnings this is the offset of the first occurrence.
 	Offset int64
+
+	// Count is the number of identical warnings collapsed into this one
+	// by CompactWarnings (0 or 1 for a warning that occurred once).
+	Count int
+}
+
+// Severity is how serious a Warning is.
+//
+// Severities are ordered, so w.Severity >= SeverityWarning selects
+// everything but notes.
+type Severity int
+
+const (
+	// SeverityInfo notes something unusual but expected to be harmless,
+	// such as a missing optional tag or a value that was normalized.
+	SeverityInfo Severity = iota - 1
+	// SeverityWarning is an issue that may affect some of the result, such
+	// as an unreadable tag item. It is the zero value.
+	SeverityWarning
+	// SeverityError means part of the file is corrupt or truncated and
+	// what depends on it is missing or unreliable.
+	SeverityError
+)
+
+// String returns "info", "warning" or "error".
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
 }
 
 // String returns a human-readable warning message.
 func (w Warning) String() string {
+	msg := w.Message
+	if w.Count > 1 {
+		msg = fmt.Sprintf("%s (repeated %d times)", msg, w.Count)
+	}
 	if w.Offset > 0 {
-		return fmt.Sprintf("%s (at offset %d): %s", w.Stage, w.Offset, w.Message)
+		return fmt.Sprintf("%s (at offset %d): %s", w.Stage, w.Offset, msg)
 	}
-	return fmt.Sprintf("%s: %s", w.Stage, w.Message)
+	return fmt.Sprintf("%s: %s", w.Stage, msg)
 }
 
 // Unwrap returns the underlying error so callers can use errors.Is/As.
 func (w Warning) Unwrap() error { return w.Err }
+
+// CompactWarnings collapses identical warnings and optionally caps the result.
+//
+// Warnings with the same Stage and Message are merged into the first
+// occurrence, whose Count records how many times it was seen and whose
+// Severity is the highest among them. Order of first
+// occurrence is preserved. If limit > 0, at most limit distinct warnings are
+// kept; later distinct warnings are dropped.
+//
+// A corrupt file can otherwise produce one warning per malformed frame.
+func CompactWarnings(warnings []Warning, limit int) []Warning {
+	if len(warnings) == 0 {
+		return warnings
+	}
+
+	type key struct{ stage, message string }
+	index := make(map[key]int, len(warnings))
+	result := make([]Warning, 0, len(warnings))
+
+	for _, w := range warnings {
+		k := key{w.Stage, w.Message}
+		if i, ok := index[k]; ok {
+			result[i].Count += max(w.Count, 1)
+			result[i].Severity = max(result[i].Severity, w.Severity)
+			continue
+		}
+		if limit > 0 && len(result) >= limit {
+			continue
+		}
+		w.Count = max(w.Count, 1)
+		index[k] = len(result)
+		result = append(result, w)
+	}
+
+	return result
+}
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompactWarnings_CollapsesDuplicates(t *testing.T) {
+	var warnings []Warning
+	for i := range 1000 {
+		warnings = append(warnings, Warning{Stage: "metadata", Message: "malformed frame", Offset: int64(100 + i)})
+	}
+	warnings = append(warnings, Warning{Stage: "technical", Message: "no audio frames"})
+	warnings = append(warnings, Warning{Stage: "chapters", Message: "malformed frame"})
+
+	got := CompactWarnings(warnings, 0)
+
+	if len(got) != 3 {
+		t.Fatalf("CompactWarnings() returned %d warnings, want 3", len(got))
+	}
+	if got[0].Count != 1000 {
+		t.Errorf("got[0].Count = %d, want 1000", got[0].Count)
+	}
+	if got[0].Offset != 100 {
+		t.Errorf("got[0].Offset = %d, want offset of first occurrence (100)", got[0].Offset)
+	}
+	if got[1].Stage != "technical" || got[1].Count != 1 {
+		t.Errorf("got[1] = %+v, want technical warning with Count 1", got[1])
+	}
+	if got[2].Stage != "chapters" {
+		t.Errorf("got[2].Stage = %q, want chapters (different stage is distinct)", got[2].Stage)
+	}
+}
+
+func TestCompactWarnings_Limit(t *testing.T) {
+	warnings := []Warning{
+		{Stage: "metadata", Message: "a"},
+		{Stage: "metadata", Message: "b"},
+		{Stage: "metadata", Message: "c"},
+		{Stage: "metadata", Message: "a"},
+	}
+
+	got := CompactWarnings(warnings, 2)
+
+	if len(got) != 2 {
+		t.Fatalf("CompactWarnings() returned %d warnings, want 2", len(got))
+	}
+	if got[0].Message != "a" || got[0].Count != 2 {
+		t.Errorf("got[0] = %+v, want message a with Count 2", got[0])
+	}
+	if got[1].Message != "b" {
+		t.Errorf("got[1].Message = %q, want b", got[1].Message)
+	}
+}
+
+func TestCompactWarnings_Empty(t *testing.T) {
+	if got := CompactWarnings(nil, 10); got != nil {
+		t.Errorf("CompactWarnings(nil) = %v, want nil", got)
+	}
+}
+
+func TestWarning_String_Count(t *testing.T) {
+	w := Warning{Stage: "metadata", Message: "bad frame", Count: 3}
+	if got := w.String(); !strings.Contains(got, "repeated 3 times") {
+		t.Errorf("String() = %q, want repeat count", got)
+	}
+
+	w.Count = 1
+	if got := w.String(); got != "metadata: bad frame" {
+		t.Errorf("String() = %q, want %q", got, "metadata: bad frame")
+	}
+}
+
+func TestCompactWarnings_KeepsHighestSeverity(t *testing.T) {
+	warnings := []Warning{
+		{Stage: "metadata", Message: "bad frame", Severity: SeverityInfo},
+		{Stage: "metadata", Message: "bad frame", Severity: SeverityError},
+		{Stage: "metadata", Message: "bad frame"},
+	}
+
+	got := CompactWarnings(warnings, 0)
+	if len(got) != 1 || got[0].Severity != SeverityError {
+		t.Errorf("CompactWarnings() = %+v, want one warning with SeverityError", got)
+	}
+}
+
+func TestSeverity_String(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		want     string
+	}{
+		{SeverityInfo, "info"},
+		{SeverityWarning, "warning"},
+		{SeverityError, "error"},
+		{Severity(5), "Severity(5)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.severity.String(); got != tt.want {
+			t.Errorf("Severity(%d).String() = %q, want %q", int(tt.severity), got, tt.want)
+		}
+	}
+	if got := (Warning{}).Severity; got != SeverityWarning {
+		t.Errorf("zero Warning has Severity %v, want warning", got)
+	}
+}
+
+func TestFile_HasErrors(t *testing.T) {
+	file := &File{Warnings: []Warning{
+		{Stage: "metadata", Message: "blank tag", Severity: SeverityInfo},
+		{Stage: "technical", Message: "odd value"},
+	}}
+	if file.HasErrors() {
+		t.Error("HasErrors() = true, want false without an error")
+	}
+
+	file.Warnings = append(file.Warnings, Warning{Stage: "metadata", Message: "truncated frame", Severity: SeverityError})
+	if !file.HasErrors() {
+		t.Error("HasErrors() = false, want true")
+	}
+}
@@ -21,4 +21,85 @@ type File struct {
 	Audio    AudioInfo
 	Format   Format
 	Size     int64
+
+	// ChapterSource names the structure Chapters came from, as
+	// "<format>:<mechanism>" ("mp3:id3-chap", "m4a:quicktime",
+	// "m4a:nero-chpl", "flac:cuesheet", "ogg:vorbis-comments",
+	// "mkv:chapters"). Empty when there are no chapters.
+	ChapterSource string
+
+	// ChapterTree holds the chapters as the file's table of contents
+	// nests them (MP3 CTOC frames, nested Matroska ChapterAtoms), with
+	// grouped chapters under their parent's Children. Chapters holds the
+	// leaves of the same tree in playback order. Nil when the file does
+	// not nest its chapters.
+	ChapterTree []Chapter
+
+	// TrackTags holds per-track metadata, one entry per audio track in
+	// track order, for containers with more than one audio track (M4A
+	// trak-level ilst). Tags remains the file-level view. Nil otherwise.
+	TrackTags []Tags
+
+	// SeekPoints are the seek points of a FLAC SEEKTABLE block, in sample
+	// order, letting a player jump close to a sample without scanning the
+	// frames. Placeholder points are left out. Nil when there is none.
+	// They live here rather than on AudioInfo to keep that a comparable
+	// value type.
+	SeekPoints []SeekPoint
+
+	// HasEmbeddedArtwork records whether picture data (PICTURE block, APIC
+	// frame, covr atom, METADATA_BLOCK_PICTURE comment) was seen while
+	// parsing metadata. The image bytes themselves are not loaded.
+	HasEmbeddedArtwork bool
+
+	// rawTags holds the items added by AddRawTag, keyed by RawTag.Key.
+	// It is nil until EnableRawTags is called.
+	rawTags map[string][]RawTag
+}
+
+// EnableRawTags makes AddRawTag record items. Parsers call it when raw
+// tags were asked for (audiometa.WithRawTags), since keeping every item's
+// bytes, pictures included, costs memory most callers don't need.
+func (f *File) EnableRawTags() {
+	if f.rawTags == nil {
+		f.rawTags = make(map[string][]RawTag)
+	}
+}
+
+// RawTagsEnabled reports whether EnableRawTags was called. Parsers check
+// it before reading items they would otherwise skip, such as pictures.
+func (f *File) RawTagsEnabled() bool {
+	return f.rawTags != nil
+}
+
+// AddRawTag records tag under its key, after any others with the same
+// key. It does nothing unless EnableRawTags was called.
+func (f *File) AddRawTag(tag RawTag) {
+	if f.rawTags != nil {
+		f.rawTags[tag.Key] = append(f.rawTags[tag.Key], tag)
+	}
+}
+
+// HasErrors reports whether any warning has SeverityError, meaning part of
+// the file could not be read and the result is incomplete.
+func (f *File) HasErrors() bool {
+	for _, w := range f.Warnings {
+		if w.Severity >= SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// RawTags returns every tag item as stored in the file, keyed by
+// RawTag.Key, with repeated keys in file order. This includes items no
+// Tags field maps and binary items such as covr and trkn, whose bytes are
+// kept as they are.
+//
+// It returns nil unless the file was opened with audiometa.WithRawTags.
+// FLAC, MP3 (ID3v2 frames), M4A, Ogg and Matroska files record raw tags.
+// The map and its values are shared with the File and must not be
+// modified.
+func (f *File) RawTags() map[string][]RawTag {
+	return f.rawTags
 }
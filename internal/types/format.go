@@ -1,7 +1,11 @@
 package types
 
 import (
+	"fmt"
 	"io"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/simonhull/audiometa/internal/binary"
 )
@@ -30,6 +34,12 @@ const (
 	FormatWAV // WAV
 	// FormatAIFF represents AIFF audio files.
 	FormatAIFF // AIFF
+	// FormatWavPack represents WavPack audio files.
+	FormatWavPack // WavPack
+	// FormatMKA represents Matroska and WebM audio files.
+	FormatMKA // Matroska
+	// FormatDSF represents DSD Stream Files.
+	FormatDSF // DSF
 )
 
 // Extensions returns common file extensions for this format.
@@ -51,6 +61,12 @@ func (f Format) Extensions() []string {
 		return []string{".wav"}
 	case FormatAIFF:
 		return []string{".aiff", ".aif"}
+	case FormatWavPack:
+		return []string{".wv"}
+	case FormatMKA:
+		return []string{".mka", ".mkv", ".webm"}
+	case FormatDSF:
+		return []string{".dsf"}
 	case FormatUnknown:
 		return nil
 	default:
@@ -58,16 +74,93 @@ func (f Format) Extensions() []string {
 	}
 }
 
-// DetectFormat determines the audio file format by examining magic bytes.
+// FormatFromExtension returns the format a path's extension names, or
+// FormatUnknown. Case is ignored.
+func FormatFromExtension(path string) Format {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return FormatUnknown
+	}
+	for f := FormatFLAC; f <= FormatDSF; f++ {
+		if slices.Contains(f.Extensions(), ext) {
+			return f
+		}
+	}
+	return FormatUnknown
+}
+
+// DetectFormat determines the audio file format by examining magic bytes,
+// with the extension of path as a tiebreaker.
 //
-// Supported formats: FLAC, MP3, M4A, M4B, Ogg Vorbis, Opus, WAV, AIFF
+// Supported formats: FLAC, MP3, M4A, M4B, Ogg Vorbis, Opus, WAV, AIFF, WavPack,
+// Matroska, DSF
 //
-// Detection is based on file signatures (magic bytes) at the beginning of the file.
-// Format detection does not validate the entire file structure.
-func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //nolint:gocyclo // Format detection requires checking multiple magic byte patterns
+// Detection does not validate the entire file structure. See
+// DetectFormatWarnings for the precedence of the two sources.
+func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) {
+	format, _, err := DetectFormatWarnings(r, size, path)
+	return format, err
+}
+
+// DetectFormatWarnings is DetectFormat, also returning warnings about how
+// the extension of path was used. In order of precedence:
+//
+//  1. A file signature (magic bytes) at the start of the file decides
+//     the format. An extension naming a different container is only
+//     warned about.
+//  2. A generic MP4 brand ("mp42", "isom") is read as M4A, unless the
+//     extension is .m4b.
+//  3. Without a recognized signature, the extension names a candidate,
+//     accepted only if the content looks like it: top-level MP4 atoms
+//     other than ftyp for M4A and M4B, an MPEG audio frame header
+//     within the first 4 KiB for MP3. The fallback is warned about.
+//
+// If none applies, the error from the signature check is returned.
+func DetectFormatWarnings(r io.ReaderAt, size int64, path string) (Format, []Warning, error) {
+	byExt := FormatFromExtension(path)
+	format, generic, err := detectMagic(r, size, path)
+	if err != nil {
+		if sniffFormat(r, size, path, byExt) {
+			return byExt, []Warning{{
+				Stage:   "detect",
+				Message: fmt.Sprintf("no file signature found; detected %s from the %s extension", byExt, filepath.Ext(path)),
+			}}, nil
+		}
+		return FormatUnknown, nil, err
+	}
+
+	switch {
+	case generic && byExt == FormatM4B:
+		return FormatM4B, nil, nil
+	case byExt != FormatUnknown && byExt.container() != format.container():
+		return format, []Warning{{
+			Stage:   "detect",
+			Message: fmt.Sprintf("file signature is %s but the %s extension names %s; reading as %s", format, filepath.Ext(path), byExt, format),
+		}}, nil
+	}
+	return format, nil, nil
+}
+
+// container maps a format to the one whose container it shares, so that
+// an Opus stream in a .ogg file or an audiobook in a .m4a file is not
+// a mismatch.
+func (f Format) container() Format {
+	switch f {
+	case FormatM4B:
+		return FormatM4A
+	case FormatOpus:
+		return FormatOgg
+	default:
+		return f
+	}
+}
+
+// detectMagic identifies the format from its file signature. generic
+// reports an MP4 file whose brand does not tell M4A from M4B.
+func detectMagic(r io.ReaderAt, size int64, path string) (_ Format, generic bool, _ error) { //nolint:gocyclo // Format detection requires checking multiple magic byte patterns
 	// File must be at least 4 bytes for any meaningful detection
 	if size < 4 {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "file too small",
 		}
@@ -78,7 +171,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	// Read first 4 bytes for magic number detection
 	magic := make([]byte, 4)
 	if err := sr.ReadAt(magic, 0, "file magic bytes"); err != nil {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "failed to read file header",
 		}
@@ -86,18 +179,38 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 
 	// Check for FLAC (fLaC = 0x664C6143)
 	if string(magic) == "fLaC" {
-		return FormatFLAC, nil
+		return FormatFLAC, false, nil
+	}
+
+	// Check for WavPack block header ("wvpk")
+	if string(magic) == "wvpk" {
+		return FormatWavPack, false, nil
 	}
 
-	// Check for ID3v2 tag (MP3)
+	// Check for an EBML header (Matroska, WebM)
+	if string(magic) == "\x1A\x45\xDF\xA3" {
+		return FormatMKA, false, nil
+	}
+
+	// Check for a DSF DSD chunk ("DSD ")
+	if string(magic) == "DSD " {
+		return FormatDSF, false, nil
+	}
+
+	// Check for ID3v2 tag (MP3), unless a tagger prepended it to a FLAC stream
 	if string(magic[:3]) == "ID3" {
-		return FormatMP3, nil
+		if tagSize := ID3v2TagSize(sr); tagSize > 0 && tagSize+4 <= size {
+			if err := sr.ReadAt(magic, tagSize, "FLAC magic after ID3v2"); err == nil && string(magic) == "fLaC" {
+				return FormatFLAC, false, nil
+			}
+		}
+		return FormatMP3, false, nil
 	}
 
 	// Check for MP3 frame sync (0xFFE or 0xFFF)
 	// This catches MP3 files without ID3 tags
 	if magic[0] == 0xFF && (magic[1]&0xE0) == 0xE0 {
-		return FormatMP3, nil
+		return FormatMP3, false, nil
 	}
 
 	// Check for Ogg (OggS) - could be Vorbis or Opus
@@ -115,13 +228,13 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 					codecMagic := make([]byte, 8)
 					if err := sr.ReadAt(codecMagic, packetOffset, "codec magic"); err == nil {
 						if string(codecMagic) == "OpusHead" {
-							return FormatOpus, nil
+							return FormatOpus, false, nil
 						}
 					}
 				}
 			}
 		}
-		return FormatOgg, nil
+		return FormatOgg, false, nil
 	}
 
 	// Check for RIFF/WAV (RIFF....WAVE)
@@ -129,7 +242,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 		waveTag := make([]byte, 4)
 		if err := sr.ReadAt(waveTag, 8, "WAVE tag"); err == nil {
 			if string(waveTag) == "WAVE" {
-				return FormatWAV, nil
+				return FormatWAV, false, nil
 			}
 		}
 	}
@@ -139,7 +252,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 		aiffTag := make([]byte, 4)
 		if err := sr.ReadAt(aiffTag, 8, "AIFF tag"); err == nil {
 			if string(aiffTag) == "AIFF" || string(aiffTag) == "AIFC" {
-				return FormatAIFF, nil
+				return FormatAIFF, false, nil
 			}
 		}
 	}
@@ -148,7 +261,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	// Read ftyp atom size (first 4 bytes)
 	atomSize, err := binary.Read[uint32](sr, 0, "ftyp atom size")
 	if err != nil {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "failed to read file header",
 		}
@@ -157,7 +270,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	// Read ftyp atom type (next 4 bytes)
 	atomType, err := binary.Read[uint32](sr, 4, "ftyp atom type")
 	if err != nil {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "failed to read file header",
 		}
@@ -166,7 +279,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	// Check if it's an ftyp atom (0x66747970 = "ftyp")
 	ftypMagic := uint32(0x66747970)
 	if atomType != ftypMagic {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "unsupported file format",
 		}
@@ -174,7 +287,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 
 	// ftyp atom must be at least 16 bytes (size + type + brand + version)
 	if atomSize < 16 {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "ftyp atom too small",
 		}
@@ -183,7 +296,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	// Read major brand (next 4 bytes)
 	majorBrand, err := binary.Read[uint32](sr, 8, "major brand")
 	if err != nil {
-		return FormatUnknown, &UnsupportedFormatError{
+		return FormatUnknown, false, &UnsupportedFormatError{
 			Path:   path,
 			Reason: "failed to read major brand",
 		}
@@ -192,7 +305,7 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	// Check for M4B brand (0x4D344220 = "M4B ")
 	m4bMagic := uint32(0x4D344220)
 	if majorBrand == m4bMagic {
-		return FormatM4B, nil
+		return FormatM4B, false, nil
 	}
 
 	// Check for M4A brands
@@ -203,13 +316,39 @@ func DetectFormat(r io.ReaderAt, size int64, path string) (Format, error) { //no
 	mp42Magic := uint32(0x6D703432)
 	isomMagic := uint32(0x69736F6D)
 
-	if majorBrand == m4aMagic || majorBrand == mp42Magic || majorBrand == isomMagic {
-		return FormatM4A, nil
+	if majorBrand == m4aMagic {
+		return FormatM4A, false, nil
+	}
+	if majorBrand == mp42Magic || majorBrand == isomMagic {
+		return FormatM4A, true, nil
 	}
 
 	// Unsupported brand
-	return FormatUnknown, &UnsupportedFormatError{
+	return FormatUnknown, false, &UnsupportedFormatError{
 		Path:   path,
 		Reason: "unsupported file brand",
 	}
 }
+
+// ID3v2TagSize returns the total size of an ID3v2 tag at the start of the
+// file, header and footer included, or 0 if there is none.
+//
+// ID3v2 header layout:
+//
+//	[3 bytes] "ID3"
+//	[2 bytes] version
+//	[1 byte]  flags (bit 4: footer present)
+//	[4 bytes] synchsafe tag size, excluding header and footer
+func ID3v2TagSize(sr *binary.SafeReader) int64 {
+	header := make([]byte, 10)
+	if err := sr.ReadAt(header, 0, "ID3v2 header"); err != nil || string(header[:3]) != "ID3" {
+		return 0
+	}
+
+	size := int64(header[6]&0x7F)<<21 | int64(header[7]&0x7F)<<14 | int64(header[8]&0x7F)<<7 | int64(header[9]&0x7F)
+	size += 10
+	if header[5]&0x10 != 0 {
+		size += 10 // Footer
+	}
+	return size
+}
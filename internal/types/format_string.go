@@ -17,11 +17,14 @@ func _() {
 	_ = x[FormatOpus-6]
 	_ = x[FormatWAV-7]
 	_ = x[FormatAIFF-8]
+	_ = x[FormatWavPack-9]
+	_ = x[FormatMKA-10]
+	_ = x[FormatDSF-11]
 }
 
-const _Format_name = "UnknownFLACMP3M4AM4BOgg VorbisOpusWAVAIFF"
+const _Format_name = "UnknownFLACMP3M4AM4BOgg VorbisOpusWAVAIFFWavPackMatroskaDSF"
 
-var _Format_index = [...]uint8{0, 7, 11, 14, 17, 20, 30, 34, 37, 41}
+var _Format_index = [...]uint8{0, 7, 11, 14, 17, 20, 30, 34, 37, 41, 48, 56, 59}
 
 func (i Format) String() string {
 	idx := int(i) - 0
@@ -61,6 +61,20 @@ func TestDetectFormat_MP3_ID3(t *testing.T) {
 	}
 }
 
+func TestDetectFormat_FLACBehindID3(t *testing.T) {
+	// ID3v2 header with a 4-byte tag body, followed by the FLAC magic
+	data := []byte("ID3\x03\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00fLaC")
+
+	r := bytes.NewReader(data)
+	format, err := DetectFormat(r, int64(len(data)), "test.flac")
+	if err != nil {
+		t.Fatalf("DetectFormat() error = %v", err)
+	}
+	if format != FormatFLAC {
+		t.Errorf("DetectFormat() = %v, want FormatFLAC", format)
+	}
+}
+
 func TestDetectFormat_MP3_FrameSync(t *testing.T) {
 	// MP3 frame sync: 0xFF 0xFB (MPEG1 Layer3)
 	data := []byte{0xFF, 0xFB, 0x90, 0x00, 0x00, 0x00, 0x00, 0x00}
@@ -101,6 +115,45 @@ func TestDetectFormat_AIFF(t *testing.T) {
 	}
 }
 
+func TestDetectFormat_WavPack(t *testing.T) {
+	data := []byte("wvpk\x18\x00\x00\x00\x10\x04")
+
+	r := bytes.NewReader(data)
+	format, err := DetectFormat(r, int64(len(data)), "test.wv")
+	if err != nil {
+		t.Fatalf("DetectFormat() error = %v", err)
+	}
+	if format != FormatWavPack {
+		t.Errorf("DetectFormat() = %v, want FormatWavPack", format)
+	}
+}
+
+func TestDetectFormat_MKA(t *testing.T) {
+	data := []byte("\x1A\x45\xDF\xA3\x84\x42\x82\x81\x00")
+
+	r := bytes.NewReader(data)
+	format, err := DetectFormat(r, int64(len(data)), "test.mka")
+	if err != nil {
+		t.Fatalf("DetectFormat() error = %v", err)
+	}
+	if format != FormatMKA {
+		t.Errorf("DetectFormat() = %v, want FormatMKA", format)
+	}
+}
+
+func TestDetectFormat_DSF(t *testing.T) {
+	data := []byte("DSD \x1C\x00\x00\x00\x00\x00\x00\x00")
+
+	r := bytes.NewReader(data)
+	format, err := DetectFormat(r, int64(len(data)), "test.dsf")
+	if err != nil {
+		t.Fatalf("DetectFormat() error = %v", err)
+	}
+	if format != FormatDSF {
+		t.Errorf("DetectFormat() = %v, want FormatDSF", format)
+	}
+}
+
 func TestDetectFormat_TooSmall(t *testing.T) {
 	data := []byte("abc")
 
@@ -124,6 +177,9 @@ func TestFormat_Extensions(t *testing.T) {
 		{FormatOpus, []string{".opus"}},
 		{FormatWAV, []string{".wav"}},
 		{FormatAIFF, []string{".aiff", ".aif"}},
+		{FormatWavPack, []string{".wv"}},
+		{FormatMKA, []string{".mka", ".mkv", ".webm"}},
+		{FormatDSF, []string{".dsf"}},
 		{FormatUnknown, nil},
 	}
 
@@ -141,6 +197,79 @@ func TestFormat_Extensions(t *testing.T) {
 	}
 }
 
+func TestFormatFromExtension(t *testing.T) {
+	tests := []struct {
+		path string
+		want Format
+	}{
+		{"song.flac", FormatFLAC},
+		{"/music/Song.MP3", FormatMP3},
+		{"book.m4b", FormatM4B},
+		{"clip.mp4", FormatM4A},
+		{"voice.opus", FormatOpus},
+		{"take.aif", FormatAIFF},
+		{"notes.txt", FormatUnknown},
+		{"README", FormatUnknown},
+		{"", FormatUnknown},
+	}
+
+	for _, tc := range tests {
+		if got := FormatFromExtension(tc.path); got != tc.want {
+			t.Errorf("FormatFromExtension(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestDetectFormatWarnings(t *testing.T) {
+	ftyp := func(brand string) []byte {
+		return append([]byte("\x00\x00\x00\x10ftyp"), brand+"\x00\x00\x00\x00"...)
+	}
+	moovFirst := append([]byte("\x00\x00\x00\x08moov"), ftyp("M4A ")...)
+	// A stream cut mid-frame: leftover bytes, then an MPEG-1 Layer III header
+	midFrame := append(bytes.Repeat([]byte{0x55}, 100), 0xFF, 0xFB, 0x90, 0x64)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		path     string
+		want     Format
+		warnings int
+		wantErr  bool
+	}{
+		{"generic brand read as M4A", ftyp("isom"), "song.m4a", FormatM4A, 0, false},
+		{"generic brand with .m4b", ftyp("mp42"), "book.m4b", FormatM4B, 0, false},
+		{"M4A brand wins over .m4b", ftyp("M4A "), "book.m4b", FormatM4A, 0, false},
+		{"M4B brand in .mp4", ftyp("M4B "), "book.mp4", FormatM4B, 0, false},
+		{"Opus in .ogg", createMinimalOggPage("OpusHead"), "song.ogg", FormatOpus, 0, false},
+		{"signature wins over extension", []byte("fLaC\x00\x00\x00\x00"), "song.mp3", FormatFLAC, 1, false},
+		{"moov before ftyp", moovFirst, "song.m4a", FormatM4A, 1, false},
+		{"moov before ftyp without extension", moovFirst, "song", FormatUnknown, 0, true},
+		{"MP3 cut mid-frame", midFrame, "stream.mp3", FormatMP3, 1, false},
+		{"MP3 extension on other content", bytes.Repeat([]byte{0x55}, 100), "noise.mp3", FormatUnknown, 0, true},
+		{"FLAC extension is not sniffed", midFrame, "song.flac", FormatUnknown, 0, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, warnings, err := DetectFormatWarnings(bytes.NewReader(tc.data), int64(len(tc.data)), tc.path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("DetectFormatWarnings() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("DetectFormatWarnings() = %v, want %v", got, tc.want)
+			}
+			if len(warnings) != tc.warnings {
+				t.Errorf("warnings = %v, want %d", warnings, tc.warnings)
+			}
+			for _, w := range warnings {
+				if w.Stage != "detect" {
+					t.Errorf("warning stage = %q, want detect", w.Stage)
+				}
+			}
+		})
+	}
+}
+
 // createMinimalOggPage creates a minimal Ogg page with the given first packet content.
 func createMinimalOggPage(packetContent string) []byte {
 	// Ogg page header structure:
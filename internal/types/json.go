@@ -0,0 +1,227 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+)
+
+// The JSON encodings below share their field names with audiometa's
+// MetadataReport and change only with its ReportVersion. Durations are
+// encoded twice: in nanoseconds, and as time.Duration text under a
+// "_text" key for people reading the output.
+
+// tagsJSON is the JSON shape of Tags.
+type tagsJSON struct {
+	Fields map[string]any      `json:"fields"`
+	URLs   map[string]string   `json:"urls,omitempty"`
+	Raw    map[string][]string `json:"raw,omitempty"`
+}
+
+// commentJSON is the JSON shape of a Comment.
+type commentJSON struct {
+	Text        string `json:"text"`
+	Language    string `json:"language,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// lyricLineJSON is the JSON shape of a LyricLine.
+type lyricLineJSON struct {
+	Time     time.Duration `json:"time"`
+	TimeText string        `json:"time_text"`
+	Text     string        `json:"text"`
+}
+
+// TagFields returns the non-empty standard fields of t, keyed by the names
+// accepted by Field, as they are encoded in JSON. Artists, Composers,
+// Performers, Genres and Keywords are string slices; Comments and
+// SyncedLyrics are slices of objects with the text, language and
+// description of each comment and the time and text of each line. Other
+// fields are the text Field returns. Nothing in the map shares memory
+// with t.
+func TagFields(t *Tags) map[string]any {
+	fields := make(map[string]any)
+	for name, value := range t.Fields() {
+		fields[name] = value
+	}
+
+	lists := map[string][]string{
+		"Artists":    t.Artists,
+		"Composers":  t.Composers,
+		"Performers": t.Performers,
+		"Genres":     t.Genres,
+		"Keywords":   t.Keywords,
+	}
+	for name, values := range lists {
+		if _, ok := fields[name]; ok {
+			fields[name] = slices.Clone(values)
+		}
+	}
+	if _, ok := fields["Comments"]; ok {
+		comments := make([]commentJSON, len(t.Comments))
+		for i, c := range t.Comments {
+			comments[i] = commentJSON{Text: c.Text, Language: c.Language, Description: c.Description}
+		}
+		fields["Comments"] = comments
+	}
+	if _, ok := fields["SyncedLyrics"]; ok {
+		lines := make([]lyricLineJSON, len(t.SyncedLyrics))
+		for i, line := range t.SyncedLyrics {
+			lines[i] = lyricLineJSON{Time: line.Time, TimeText: line.Time.String(), Text: line.Text}
+		}
+		fields["SyncedLyrics"] = lines
+	}
+	return fields
+}
+
+// MarshalJSON encodes the tags as their non-empty standard fields under
+// "fields", in the shape TagFields describes, plus URLs and the raw tags
+// under "raw".
+//
+// It has a value receiver so that Tags fields of values marshal the same.
+func (t Tags) MarshalJSON() ([]byte, error) {
+	out := tagsJSON{
+		Fields: TagFields(&t),
+		URLs:   t.URLs,
+	}
+	if len(t.raw) > 0 {
+		out.Raw = maps.Collect(t.All())
+	}
+	return json.Marshal(out)
+}
+
+// replayGainJSON is the JSON shape of ReplayGainInfo.
+type replayGainJSON struct {
+	TrackGain float64 `json:"track_gain"`
+	TrackPeak float64 `json:"track_peak"`
+	AlbumGain float64 `json:"album_gain"`
+	AlbumPeak float64 `json:"album_peak"`
+}
+
+// loopJSON is the JSON shape of LoopInfo. RootNote is omitted unless
+// HasRootNote is set, since 0 is a valid MIDI note.
+type loopJSON struct {
+	Tempo      float64 `json:"tempo"`
+	Beats      int     `json:"beats"`
+	RootNote   *int    `json:"root_note,omitempty"`
+	MeterNumer int     `json:"meter_numerator"`
+	MeterDenom int     `json:"meter_denominator"`
+	OneShot    bool    `json:"one_shot"`
+	Stretch    bool    `json:"stretch"`
+}
+
+// audioJSON is the JSON shape of AudioInfo.
+type audioJSON struct {
+	Codec            string          `json:"codec,omitempty"`
+	CodecDescription string          `json:"codec_description,omitempty"`
+	CodecProfile     string          `json:"codec_profile,omitempty"`
+	Container        string          `json:"container,omitempty"`
+	Duration         time.Duration   `json:"duration"`
+	DurationText     string          `json:"duration_text"`
+	SampleRate       int             `json:"sample_rate,omitempty"`
+	BitDepth         int             `json:"bit_depth,omitempty"`
+	Channels         int             `json:"channels,omitempty"`
+	ChannelLayout    string          `json:"channel_layout,omitempty"`
+	SampleFormat     string          `json:"sample_format,omitempty"`
+	Endianness       string          `json:"endianness,omitempty"`
+	Bitrate          int             `json:"bitrate,omitempty"`
+	LowpassHz        int             `json:"lowpass_hz,omitempty"`
+	MediaType        string          `json:"media_type,omitempty"`
+	MinBlockSize     int             `json:"min_block_size,omitempty"`
+	MaxBlockSize     int             `json:"max_block_size,omitempty"`
+	MinFrameSize     int             `json:"min_frame_size,omitempty"`
+	MaxFrameSize     int             `json:"max_frame_size,omitempty"`
+	TotalSamples     uint64          `json:"total_samples,omitempty"`
+	AudioMD5         string          `json:"audio_md5,omitempty"`
+	Lossless         bool            `json:"lossless"`
+	VBR              bool            `json:"vbr"`
+	ReplayGain       *replayGainJSON `json:"replay_gain,omitempty"`
+	Loop             *loopJSON       `json:"loop,omitempty"`
+}
+
+// sampleFormatNames and endiannessNames are the JSON names of the known
+// SampleFormat and Endianness values.
+var (
+	sampleFormatNames = map[SampleFormat]string{
+		SampleSignedInt:   "signed_int",
+		SampleUnsignedInt: "unsigned_int",
+		SampleFloat:       "float",
+	}
+	endiannessNames = map[Endianness]string{
+		EndianLittle: "little",
+		EndianBig:    "big",
+	}
+)
+
+// MarshalJSON encodes the audio properties with snake_case field names.
+// AudioMD5 is hex-encoded, and omitted when all zeros.
+func (a AudioInfo) MarshalJSON() ([]byte, error) {
+	out := audioJSON{
+		Codec:            a.Codec,
+		CodecDescription: a.CodecDescription,
+		CodecProfile:     a.CodecProfile,
+		Container:        a.Container,
+		Duration:         a.Duration,
+		DurationText:     a.Duration.String(),
+		SampleRate:       a.SampleRate,
+		BitDepth:         a.BitDepth,
+		Channels:         a.Channels,
+		ChannelLayout:    a.ChannelLayout,
+		SampleFormat:     sampleFormatNames[a.SampleFormat],
+		Endianness:       endiannessNames[a.Endianness],
+		Bitrate:          a.Bitrate,
+		LowpassHz:        a.LowpassHz,
+		MinBlockSize:     a.MinBlockSize,
+		MaxBlockSize:     a.MaxBlockSize,
+		MinFrameSize:     a.MinFrameSize,
+		MaxFrameSize:     a.MaxFrameSize,
+		TotalSamples:     a.TotalSamples,
+		Lossless:         a.Lossless,
+		VBR:              a.VBR,
+	}
+	if a.MediaType != MediaTypeUnknown {
+		out.MediaType = a.MediaType.String()
+	}
+	if a.AudioMD5 != [16]byte{} {
+		out.AudioMD5 = hex.EncodeToString(a.AudioMD5[:])
+	}
+	if rg := a.ReplayGain; rg != nil {
+		out.ReplayGain = &replayGainJSON{
+			TrackGain: rg.TrackGain,
+			TrackPeak: rg.TrackPeak,
+			AlbumGain: rg.AlbumGain,
+			AlbumPeak: rg.AlbumPeak,
+		}
+	}
+	if loop := a.LoopInfo; loop != nil {
+		out.Loop = &loopJSON{
+			Tempo:      loop.Tempo,
+			Beats:      loop.Beats,
+			MeterNumer: loop.MeterNumer,
+			MeterDenom: loop.MeterDenom,
+			OneShot:    loop.OneShot,
+			Stretch:    loop.Stretch,
+		}
+		if loop.HasRootNote {
+			rootNote := loop.RootNote
+			out.Loop.RootNote = &rootNote
+		}
+	}
+	return json.Marshal(out)
+}
+
+// chapterFields is Chapter without its MarshalJSON method, so its struct
+// tags can be embedded below.
+type chapterFields Chapter
+
+// MarshalJSON encodes the chapter using its struct tags, adding the start
+// and end times as text.
+func (c Chapter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		chapterFields
+		StartTimeText string `json:"start_time_text"`
+		EndTimeText   string `json:"end_time_text"`
+	}{chapterFields(c), c.StartTime.String(), c.EndTime.String()})
+}
@@ -0,0 +1,135 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTags_MarshalJSON(t *testing.T) {
+	tags := Tags{
+		Title:        "Song",
+		Year:         2024,
+		Artists:      []string{"A", "B"},
+		Genres:       []string{"Rock; Pop"},
+		Comments:     []Comment{{Language: "eng", Description: "Note", Text: "Hi"}},
+		SyncedLyrics: []LyricLine{{Text: "La", Time: 1500 * time.Millisecond}},
+	}
+	tags.Set("MOOD", "Mellow")
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+		Raw    map[string][]string        `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	for name, want := range map[string]string{
+		"Title":        `"Song"`,
+		"Year":         `"2024"`,
+		"Artists":      `["A","B"]`,
+		"Genres":       `["Rock; Pop"]`,
+		"Comments":     `[{"text":"Hi","language":"eng","description":"Note"}]`,
+		"SyncedLyrics": `[{"time":1500000000,"time_text":"1.5s","text":"La"}]`,
+	} {
+		if got := string(decoded.Fields[name]); got != want {
+			t.Errorf("fields[%q] = %s, want %s", name, got, want)
+		}
+	}
+	if len(decoded.Raw["MOOD"]) != 1 {
+		t.Errorf("raw = %v, want MOOD", decoded.Raw)
+	}
+	if strings.Contains(string(data), `"Album"`) {
+		t.Errorf("empty fields should be omitted: %s", data)
+	}
+}
+
+func TestAudioInfo_MarshalJSON(t *testing.T) {
+	audio := AudioInfo{
+		Codec:      "FLAC",
+		Duration:   90 * time.Second,
+		SampleRate: 44100,
+		MediaType:  MediaTypeAudiobook,
+		ReplayGain: &ReplayGainInfo{TrackGain: -6.5},
+	}
+
+	data, err := json.Marshal(audio)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded["duration"] != float64(90*time.Second) || decoded["duration_text"] != "1m30s" {
+		t.Errorf("duration = %v, duration_text = %v", decoded["duration"], decoded["duration_text"])
+	}
+	if decoded["sample_rate"] != float64(44100) || decoded["codec"] != "FLAC" || decoded["media_type"] != "Audiobook" {
+		t.Errorf("unexpected encoding %s", data)
+	}
+	if rg, ok := decoded["replay_gain"].(map[string]any); !ok || rg["track_gain"] != -6.5 {
+		t.Errorf("replay_gain = %v", decoded["replay_gain"])
+	}
+}
+
+func TestAudioInfo_MarshalJSON_StreamInfo(t *testing.T) {
+	audio := AudioInfo{
+		SampleFormat: SampleSignedInt,
+		Endianness:   EndianLittle,
+		MinBlockSize: 4096,
+		MaxBlockSize: 4096,
+		MinFrameSize: 14,
+		MaxFrameSize: 16384,
+		TotalSamples: 441000,
+		AudioMD5:     [16]byte{0xde, 0xad, 15: 0x01},
+		LoopInfo:     &LoopInfo{Tempo: 120, Beats: 8, MeterNumer: 4, MeterDenom: 4},
+	}
+
+	data, err := json.Marshal(audio)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`"sample_format":"signed_int"`,
+		`"endianness":"little"`,
+		`"min_block_size":4096`,
+		`"max_frame_size":16384`,
+		`"total_samples":441000`,
+		`"audio_md5":"dead0000000000000000000000000001"`,
+		`"loop":{"tempo":120,"beats":8,"meter_numerator":4,"meter_denominator":4,"one_shot":false,"stretch":false}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("missing %s in %s", want, data)
+		}
+	}
+
+	data, err = json.Marshal(AudioInfo{})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, key := range []string{"sample_format", "audio_md5", "loop"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("unset %s should be omitted: %s", key, data)
+		}
+	}
+}
+
+func TestChapter_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Chapter{Title: "Intro", Index: 1, EndTime: 2500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	want := `{"title":"Intro","index":1,"start_time":0,"end_time":2500000000,"start_time_text":"0s","end_time_text":"2.5s"}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
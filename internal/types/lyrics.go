@@ -0,0 +1,88 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LyricLine is one line of time-synced lyrics.
+type LyricLine struct {
+	Text string
+	Time time.Duration // Offset from the start of the track
+}
+
+// String formats the line as LRC ("[01:23.45]Text").
+func (l LyricLine) String() string {
+	cs := max(l.Time, 0).Milliseconds() / 10
+	return fmt.Sprintf("[%02d:%02d.%02d]%s", cs/6000, cs/100%60, cs%100, l.Text)
+}
+
+// ParseLRC parses LRC-formatted lyrics into time-ordered lines.
+//
+// Lines carry one or more "[mm:ss.xx]" timestamps followed by text; a line
+// with several timestamps (a repeated chorus) yields one LyricLine each.
+// ID tags such as "[ar:Artist]" are skipped, except "[offset:+/-ms]", which
+// shifts every timestamp. Returns nil when no line is timestamped, so plain
+// lyrics can be passed in unconditionally.
+func ParseLRC(text string) []LyricLine {
+	var lines []LyricLine
+	var offset time.Duration
+
+	for raw := range strings.Lines(text) {
+		line := strings.TrimSpace(raw)
+
+		var times []time.Duration
+		for strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				break
+			}
+			tag := line[1:end]
+			if t, ok := parseLRCTimestamp(tag); ok {
+				times = append(times, t)
+			} else if value, ok := strings.CutPrefix(tag, "offset:"); ok {
+				if ms, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+					offset = time.Duration(ms) * time.Millisecond
+				}
+			}
+			line = line[end+1:]
+		}
+
+		for _, t := range times {
+			lines = append(lines, LyricLine{Time: t, Text: strings.TrimSpace(line)})
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// A positive offset makes lyrics appear sooner
+	for i := range lines {
+		lines[i].Time = max(lines[i].Time-offset, 0)
+	}
+	slices.SortStableFunc(lines, func(a, b LyricLine) int { return cmp.Compare(a.Time, b.Time) })
+
+	return lines
+}
+
+// parseLRCTimestamp parses "mm:ss", "mm:ss.xx" or "mm:ss.xxx".
+func parseLRCTimestamp(tag string) (time.Duration, bool) {
+	minutes, rest, ok := strings.Cut(tag, ":")
+	if !ok || rest == "" || rest[0] < '0' || rest[0] > '9' {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 {
+		return 0, false
+	}
+	s, err := strconv.ParseFloat(rest, 64)
+	if err != nil || s < 0 || s >= 60 {
+		return 0, false
+	}
+	return time.Duration(m)*time.Minute + time.Duration(s*float64(time.Second)).Round(time.Millisecond), true
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseLRC(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []LyricLine
+	}{
+		{
+			name:  "plain lyrics",
+			input: "First line\nSecond line",
+			want:  nil,
+		},
+		{
+			name:  "basic",
+			input: "[ar:Artist]\n[00:01.50]Hello\r\n[01:02.345] World \n",
+			want: []LyricLine{
+				{Time: 1500 * time.Millisecond, Text: "Hello"},
+				{Time: time.Minute + 2345*time.Millisecond, Text: "World"},
+			},
+		},
+		{
+			name:  "repeated line and ordering",
+			input: "[00:30.00][00:10.00]Chorus\n[00:20.00]Verse",
+			want: []LyricLine{
+				{Time: 10 * time.Second, Text: "Chorus"},
+				{Time: 20 * time.Second, Text: "Verse"},
+				{Time: 30 * time.Second, Text: "Chorus"},
+			},
+		},
+		{
+			name:  "offset shifts earlier",
+			input: "[offset:+500]\n[00:02.00]Late\n[00:00.20]Clamped",
+			want: []LyricLine{
+				{Time: 0, Text: "Clamped"},
+				{Time: 1500 * time.Millisecond, Text: "Late"},
+			},
+		},
+		{
+			name:  "instrumental gap",
+			input: "[00:05]\n[00:07.00]Back",
+			want: []LyricLine{
+				{Time: 5 * time.Second, Text: ""},
+				{Time: 7 * time.Second, Text: "Back"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLRC(tt.input); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseLRC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLyricLine_String(t *testing.T) {
+	line := LyricLine{Time: time.Minute + 2345*time.Millisecond, Text: "World"}
+	if got := line.String(); got != "[01:02.34]World" {
+		t.Errorf("String() = %q, want %q", got, "[01:02.34]World")
+	}
+}
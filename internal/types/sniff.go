@@ -0,0 +1,82 @@
+package types
+
+import (
+	"io"
+
+	"github.com/simonhull/audiometa/internal/binary"
+)
+
+// mp3SniffWindow is how far into a file without a signature sniffMP3
+// looks for a frame header.
+const mp3SniffWindow = 4096
+
+// sniffFormat reports whether the content of a file without a recognized
+// signature is consistent with format. Formats with nothing weaker than
+// their signature to go on are never accepted.
+func sniffFormat(r io.ReaderAt, size int64, path string, format Format) bool {
+	sr := binary.NewSafeReader(r, size, path)
+	switch format {
+	case FormatM4A, FormatM4B:
+		return sniffMP4(sr)
+	case FormatMP3:
+		return sniffMP3(sr)
+	default:
+		return false
+	}
+}
+
+// sniffMP4 reports whether the file starts with a top-level MP4 atom that
+// may come before ftyp, or stand in for it in old QuickTime-era files.
+//
+// Atom header: [4 bytes] size (1 for a 64-bit size that follows), [4 bytes] type.
+func sniffMP4(sr *binary.SafeReader) bool {
+	header := make([]byte, 8)
+	if err := sr.ReadAt(header, 0, "atom header"); err != nil {
+		return false
+	}
+
+	atomSize := int64(header[0])<<24 | int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+	if atomSize != 1 && (atomSize < 8 || atomSize > sr.Size()) {
+		return false
+	}
+	switch string(header[4:]) {
+	case "moov", "mdat", "free", "skip", "wide", "uuid", "pnot":
+		return true
+	default:
+		return false
+	}
+}
+
+// sniffMP3 reports whether an MPEG audio frame header appears within the
+// first mp3SniffWindow bytes, as in a stream cut mid-frame.
+func sniffMP3(sr *binary.SafeReader) bool {
+	data := make([]byte, min(mp3SniffWindow, sr.Size()))
+	if err := sr.ReadAt(data, 0, "MPEG frame search"); err != nil {
+		return false
+	}
+	for i := 0; i+4 <= len(data); i++ {
+		if isMPEGFrameHeader(data[i : i+4]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMPEGFrameHeader reports whether h starts with a frame sync and has no
+// reserved or free-format field values.
+//
+// Header bits: [11] sync, [2] version, [2] layer, [1] protection,
+// [4] bitrate index, [2] sample rate index, [1] padding, [1] private,
+// [2] channel mode, [2] mode extension, [1] copyright, [1] original,
+// [2] emphasis.
+func isMPEGFrameHeader(h []byte) bool {
+	if h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := h[1] >> 3 & 0x03
+	layer := h[1] >> 1 & 0x03
+	bitrate := h[2] >> 4
+	sampleRate := h[2] >> 2 & 0x03
+	emphasis := h[3] & 0x03
+	return version != 1 && layer != 0 && bitrate != 0 && bitrate != 0x0F && sampleRate != 3 && emphasis != 2
+}
@@ -4,7 +4,10 @@ import (
 	"iter"
 	"maps"
 	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/simonhull/audiometa/internal/parsing"
 )
 
 // Tags represents format-agnostic audio metadata.
@@ -16,6 +19,7 @@ import (
 // Get() method to retrieve raw tag values by key.
 type Tags struct {
 	raw                 map[string][]string
+	URLs                map[string]string // Links keyed by frame ID or description (WOAR, WCOM, WXXX, etc.)
 	MusicBrainzAlbumID  string
 	Narrator            string
 	AlbumArtist         string
@@ -30,9 +34,10 @@ type Tags struct {
 	MusicBrainzArtistID string
 	Title               string
 	Subtitle            string // Book/album subtitle (TIT3 in ID3v2)
+	DiscSubtitle        string // Subtitle of this disc in a set (TSST in ID3v2, DISCSUBTITLE in Vorbis)
 	MusicBrainzTrackID  string
 	Album               string
-	Comment             string
+	Comment             string // Primary comment; Comments holds every entry
 	Description         string // Longer description text (separate from comment)
 	Series              string
 	Grouping            string // Content grouping (©grp in M4A, TIT1 in ID3v2) - often contains series info
@@ -42,15 +47,46 @@ type Tags struct {
 	ISBN                string
 	ASIN                string
 	Language            string // Language code or name (e.g., "en", "English")
+	PurchaseDate        string // Store purchase date (purd in M4A)
+	Encoder             string // Encoding software or person (ENCODER/ENCODED-BY)
+	SortTitle           string // Sort keys (TITLESORT, ARTISTSORT, etc.) - used for ordering, not display
+	SortArtist          string
+	SortAlbum           string
+	SortAlbumArtist     string
+	SortComposer        string
 	Performers          []string
 	Composers           []string
 	Genres              []string
+	Keywords            []string // Search keywords (keyw in M4A podcasts)
 	Artists             []string
+	SyncedLyrics        []LyricLine // Time-synced lyrics (LRC text in a lyrics tag), sorted by time
+	Comments            []Comment   // All comment entries in file order
+	BPM                 int         // Beats per minute (tmpo in M4A, TBPM in ID3v2), rounded to the nearest integer
 	DiscTotal           int
 	DiscNumber          int
 	TrackTotal          int
 	TrackNumber         int
 	Year                int
+	Compilation         bool // Part of a various-artists compilation (cpil in M4A)
+	Gapless             bool // Album should play without gaps between tracks (pgap in M4A)
+	ShowMovement        bool // Display work/movement instead of title (shwm in M4A)
+}
+
+// NewTags returns empty Tags ready to be filled in, with the raw tag map
+// already allocated.
+//
+// Use it to build metadata from scratch rather than from a parsed file.
+// The zero Tags value works too; NewTags just makes the entry point
+// explicit.
+//
+// Example:
+//
+//	tags := NewTags()
+//	tags.Title = "Chapter One"
+//	tags.Artists = []string{"Jane Doe"}
+//	tags.Set("ENCODER", "my-encoder 1.0")
+func NewTags() *Tags {
+	return &Tags{raw: make(map[string][]string)}
 }
 
 // All returns an iterator over all raw tags.
@@ -138,13 +174,14 @@ func (t *Tags) GetBest(candidates ...string) string {
 	return ""
 }
 
-// Set sets a tag value (for future write support).
+// Set sets a raw tag value.
 //
 // If values is empty, the tag is removed.
 // Multiple values can be provided for multi-value tags.
 //
-// Note: This only modifies the in-memory representation.
-// Write support is planned for a future release.
+// Set only modifies the in-memory representation; File.Save writes raw
+// tags for keys that no standard field maps to. To change a mapped tag
+// such as TITLE, set the field (Tags.Title) instead.
 //
 // Example:
 //
@@ -163,130 +200,210 @@ func (t *Tags) Set(key string, values ...string) {
 	t.raw[key] = slices.Clone(values)
 }
 
+// MergeStrategy controls how Tags.MergeWith resolves a field that is set
+// on both sides.
+type MergeStrategy int
+
+const (
+	// PreferExisting keeps values already set in t and only fills empty
+	// fields from other. This is the behavior of Merge.
+	PreferExisting MergeStrategy = iota
+
+	// PreferOther overwrites t with every non-empty value from other.
+	PreferOther
+
+	// PreferLongest keeps whichever string value is longer, on the basis
+	// that it is the more complete one ("Abbey Road (Remastered)" over
+	// "Abbey Road"). Numeric fields have no length and keep existing values.
+	PreferLongest
+)
+
 // Merge merges tags from another Tags object.
 //
 // For standard fields, non-empty values in other override empty values in t.
 // For raw tags, all tags from other are copied to t.
 //
+// Merge is equivalent to MergeWith(other, PreferExisting).
+//
 // Example:
 //
 //	// Apply fallback tags
 //	fileTags.Merge(defaultTags)
-func (t *Tags) Merge(other *Tags) { //nolint:gocyclo // Merging requires checking all tag fields individually
+func (t *Tags) Merge(other *Tags) {
+	t.MergeWith(other, PreferExisting)
+}
+
+// MergeWith merges tags from another Tags object, resolving conflicts with
+// the given strategy.
+//
+// Multi-value fields (Artists, Genres, ...) are always combined, with
+// duplicates removed case-insensitively; the strategy only decides whose
+// values come first. Boolean flags are set if either side sets them.
+//
+// Example:
+//
+//	// Sidecar tags are authoritative
+//	fileTags.MergeWith(sidecarTags, audiometa.PreferOther)
+func (t *Tags) MergeWith(other *Tags, strategy MergeStrategy) {
 	if other == nil {
 		return
 	}
 
-	// Merge standard fields (non-empty wins)
-	if t.Title == "" {
-		t.Title = other.Title
-	}
-	if t.Subtitle == "" {
-		t.Subtitle = other.Subtitle
-	}
-	if t.Artist == "" {
-		t.Artist = other.Artist
-	}
-	if t.Album == "" {
-		t.Album = other.Album
-	}
-	if t.AlbumArtist == "" {
-		t.AlbumArtist = other.AlbumArtist
-	}
-	if t.Year == 0 {
-		t.Year = other.Year
-	}
-	if t.Date == "" {
-		t.Date = other.Date
-	}
-	if t.OriginalDate == "" {
-		t.OriginalDate = other.OriginalDate
-	}
-	if t.Comment == "" {
-		t.Comment = other.Comment
-	}
-	if t.Description == "" {
-		t.Description = other.Description
-	}
-	if t.Lyrics == "" {
-		t.Lyrics = other.Lyrics
-	}
-	if t.Narrator == "" {
-		t.Narrator = other.Narrator
-	}
-	if t.Publisher == "" {
-		t.Publisher = other.Publisher
-	}
-	if t.Series == "" {
-		t.Series = other.Series
-	}
-	if t.Grouping == "" {
-		t.Grouping = other.Grouping
-	}
-	if t.SeriesPart == "" {
-		t.SeriesPart = other.SeriesPart
-	}
-	if t.ISBN == "" {
-		t.ISBN = other.ISBN
-	}
-	if t.ASIN == "" {
-		t.ASIN = other.ASIN
-	}
-	if t.Language == "" {
-		t.Language = other.Language
-	}
-	if t.TrackNumber == 0 {
-		t.TrackNumber = other.TrackNumber
-	}
-	if t.TrackTotal == 0 {
-		t.TrackTotal = other.TrackTotal
-	}
-	if t.DiscNumber == 0 {
-		t.DiscNumber = other.DiscNumber
-	}
-	if t.DiscTotal == 0 {
-		t.DiscTotal = other.DiscTotal
-	}
+	// Merge standard fields
+	mergeString(&t.Title, other.Title, strategy)
+	mergeString(&t.Subtitle, other.Subtitle, strategy)
+	mergeString(&t.Artist, other.Artist, strategy)
+	mergeString(&t.Album, other.Album, strategy)
+	mergeString(&t.AlbumArtist, other.AlbumArtist, strategy)
+	mergeInt(&t.Year, other.Year, strategy)
+	mergeString(&t.Date, other.Date, strategy)
+	mergeString(&t.OriginalDate, other.OriginalDate, strategy)
+	mergeString(&t.Comment, other.Comment, strategy)
+	mergeString(&t.Description, other.Description, strategy)
+	mergeString(&t.Lyrics, other.Lyrics, strategy)
+	mergeString(&t.Narrator, other.Narrator, strategy)
+	mergeString(&t.Publisher, other.Publisher, strategy)
+	mergeString(&t.Series, other.Series, strategy)
+	mergeString(&t.Grouping, other.Grouping, strategy)
+	mergeString(&t.SeriesPart, other.SeriesPart, strategy)
+	mergeString(&t.ISBN, other.ISBN, strategy)
+	mergeString(&t.ASIN, other.ASIN, strategy)
+	mergeString(&t.Language, other.Language, strategy)
+	mergeString(&t.Encoder, other.Encoder, strategy)
+	mergeString(&t.PurchaseDate, other.PurchaseDate, strategy)
+	mergeString(&t.SortTitle, other.SortTitle, strategy)
+	mergeString(&t.SortArtist, other.SortArtist, strategy)
+	mergeString(&t.SortAlbum, other.SortAlbum, strategy)
+	mergeString(&t.SortAlbumArtist, other.SortAlbumArtist, strategy)
+	mergeString(&t.SortComposer, other.SortComposer, strategy)
+	mergeInt(&t.TrackNumber, other.TrackNumber, strategy)
+	mergeInt(&t.TrackTotal, other.TrackTotal, strategy)
+	mergeInt(&t.DiscNumber, other.DiscNumber, strategy)
+	mergeInt(&t.DiscTotal, other.DiscTotal, strategy)
+	mergeString(&t.DiscSubtitle, other.DiscSubtitle, strategy)
+	mergeInt(&t.BPM, other.BPM, strategy)
+	t.Compilation = t.Compilation || other.Compilation
+	t.Gapless = t.Gapless || other.Gapless
+	t.ShowMovement = t.ShowMovement || other.ShowMovement
 
 	// Merge multi-value fields (append unique)
-	t.Artists = mergeUnique(t.Artists, other.Artists)
-	t.Genres = mergeUnique(t.Genres, other.Genres)
-	t.Composers = mergeUnique(t.Composers, other.Composers)
-	t.Performers = mergeUnique(t.Performers, other.Performers)
+	t.Artists = mergeSlice(t.Artists, other.Artists, strategy)
+	t.Genres = mergeSlice(t.Genres, other.Genres, strategy)
+	t.Composers = mergeSlice(t.Composers, other.Composers, strategy)
+	t.Performers = mergeSlice(t.Performers, other.Performers, strategy)
+	t.Keywords = mergeSlice(t.Keywords, other.Keywords, strategy)
+	t.SyncedLyrics = mergeLyrics(t.SyncedLyrics, other.SyncedLyrics, strategy)
+	t.Comments = mergeComments(t.Comments, other.Comments, strategy)
 
 	// Merge cataloging fields
-	if t.MusicBrainzTrackID == "" {
-		t.MusicBrainzTrackID = other.MusicBrainzTrackID
+	mergeString(&t.MusicBrainzTrackID, other.MusicBrainzTrackID, strategy)
+	mergeString(&t.MusicBrainzAlbumID, other.MusicBrainzAlbumID, strategy)
+	mergeString(&t.MusicBrainzArtistID, other.MusicBrainzArtistID, strategy)
+	mergeString(&t.ISRC, other.ISRC, strategy)
+	mergeString(&t.Barcode, other.Barcode, strategy)
+	mergeString(&t.CatalogNumber, other.CatalogNumber, strategy)
+	mergeString(&t.Label, other.Label, strategy)
+	mergeString(&t.Copyright, other.Copyright, strategy)
+
+	// Merge URLs per key
+	for key, url := range other.URLs {
+		existing := t.URLs[key]
+		mergeString(&existing, url, strategy)
+		if t.URLs == nil {
+			t.URLs = make(map[string]string, len(other.URLs))
+		}
+		t.URLs[key] = existing
 	}
-	if t.MusicBrainzAlbumID == "" {
-		t.MusicBrainzAlbumID = other.MusicBrainzAlbumID
+
+	// Merge raw tags. Other's values replace t's except under
+	// PreferLongest, where the longer combined value wins.
+	if t.raw == nil {
+		t.raw = make(map[string][]string)
 	}
-	if t.MusicBrainzArtistID == "" {
-		t.MusicBrainzArtistID = other.MusicBrainzArtistID
+	for key, values := range other.raw {
+		if strategy == PreferLongest && len(strings.Join(t.raw[key], "")) >= len(strings.Join(values, "")) {
+			continue
+		}
+		t.raw[key] = slices.Clone(values)
 	}
-	if t.ISRC == "" {
-		t.ISRC = other.ISRC
+}
+
+// mergeString resolves a single string field according to strategy.
+func mergeString(dst *string, src string, strategy MergeStrategy) {
+	if src == "" {
+		return
 	}
-	if t.Barcode == "" {
-		t.Barcode = other.Barcode
+	switch strategy {
+	case PreferOther:
+		*dst = src
+	case PreferLongest:
+		if len(src) > len(*dst) {
+			*dst = src
+		}
+	default:
+		if *dst == "" {
+			*dst = src
+		}
 	}
-	if t.CatalogNumber == "" {
-		t.CatalogNumber = other.CatalogNumber
+}
+
+// mergeInt resolves a single numeric field according to strategy.
+// PreferLongest has no meaning for numbers and behaves like PreferExisting.
+func mergeInt(dst *int, src int, strategy MergeStrategy) {
+	if src == 0 {
+		return
 	}
-	if t.Label == "" {
-		t.Label = other.Label
+	if strategy == PreferOther || *dst == 0 {
+		*dst = src
 	}
-	if t.Copyright == "" {
-		t.Copyright = other.Copyright
+}
+
+// mergeSlice combines two multi-value fields, listing the preferred side first.
+func mergeSlice(existing, other []string, strategy MergeStrategy) []string {
+	if strategy == PreferOther {
+		return mergeUnique(other, existing)
 	}
+	return mergeUnique(existing, other)
+}
 
-	// Merge raw tags
-	if t.raw == nil {
-		t.raw = make(map[string][]string)
+// mergeLyrics picks one side's synced lyrics whole; interleaving two
+// timelines would garble both. PreferLongest keeps the one with more lines.
+func mergeLyrics(existing, other []LyricLine, strategy MergeStrategy) []LyricLine {
+	if len(other) == 0 {
+		return existing
+	}
+	switch strategy {
+	case PreferOther:
+		return slices.Clone(other)
+	case PreferLongest:
+		if len(other) > len(existing) {
+			return slices.Clone(other)
+		}
+	default:
+		if len(existing) == 0 {
+			return slices.Clone(other)
+		}
 	}
-	for key, values := range other.raw {
-		t.raw[key] = slices.Clone(values)
+	return existing
+}
+
+// mergeComments combines two comment lists, listing the preferred side
+// first and dropping exact duplicates.
+func mergeComments(existing, other []Comment, strategy MergeStrategy) []Comment {
+	if len(other) == 0 {
+		return existing
+	}
+	first, second := existing, other
+	if strategy == PreferOther {
+		first, second = other, existing
+	}
+	result := slices.Clone(first)
+	for _, c := range second {
+		if !slices.Contains(result, c) {
+			result = append(result, c)
+		}
 	}
+	return result
 }
 
 // Clone creates a deep copy of the Tags.
@@ -313,6 +430,11 @@ func (t *Tags) Clone() *Tags {
 		TrackTotal:          t.TrackTotal,
 		DiscNumber:          t.DiscNumber,
 		DiscTotal:           t.DiscTotal,
+		DiscSubtitle:        t.DiscSubtitle,
+		BPM:                 t.BPM,
+		Compilation:         t.Compilation,
+		Gapless:             t.Gapless,
+		ShowMovement:        t.ShowMovement,
 		Comment:             t.Comment,
 		Description:         t.Description,
 		Lyrics:              t.Lyrics,
@@ -324,6 +446,13 @@ func (t *Tags) Clone() *Tags {
 		ISBN:                t.ISBN,
 		ASIN:                t.ASIN,
 		Language:            t.Language,
+		Encoder:             t.Encoder,
+		PurchaseDate:        t.PurchaseDate,
+		SortTitle:           t.SortTitle,
+		SortArtist:          t.SortArtist,
+		SortAlbum:           t.SortAlbum,
+		SortAlbumArtist:     t.SortAlbumArtist,
+		SortComposer:        t.SortComposer,
 		MusicBrainzTrackID:  t.MusicBrainzTrackID,
 		MusicBrainzAlbumID:  t.MusicBrainzAlbumID,
 		MusicBrainzArtistID: t.MusicBrainzArtistID,
@@ -338,6 +467,13 @@ func (t *Tags) Clone() *Tags {
 		Genres:     slices.Clone(t.Genres),
 		Composers:  slices.Clone(t.Composers),
 		Performers: slices.Clone(t.Performers),
+		Keywords:   slices.Clone(t.Keywords),
+
+		SyncedLyrics: slices.Clone(t.SyncedLyrics),
+		Comments:     slices.Clone(t.Comments),
+
+		// Clone maps
+		URLs: maps.Clone(t.URLs),
 	}
 
 	// Clone raw tags
@@ -351,6 +487,25 @@ func (t *Tags) Clone() *Tags {
 	return clone
 }
 
+// CopyFrom replaces every field of t, raw tags included, with a deep copy
+// of other's, leaving t.Equal(other) true. Fields other leaves empty are
+// cleared in t; a nil other resets t to the zero Tags.
+//
+// Unlike Merge, nothing already in t survives. Use it to apply tags from
+// an authoritative source exactly.
+//
+// Example:
+//
+//	// Replace the file's tags with the MusicBrainz release
+//	file.Tags.CopyFrom(releaseTags)
+func (t *Tags) CopyFrom(other *Tags) {
+	if other == nil {
+		*t = Tags{}
+		return
+	}
+	*t = *other.Clone()
+}
+
 // Equal checks if two Tags are equal.
 //
 // Compares all standard fields and raw tags for equality.
@@ -381,6 +536,11 @@ func (t *Tags) Equal(other *Tags) bool { //nolint:gocyclo // Equality check requ
 		t.TrackTotal != other.TrackTotal ||
 		t.DiscNumber != other.DiscNumber ||
 		t.DiscTotal != other.DiscTotal ||
+		t.DiscSubtitle != other.DiscSubtitle ||
+		t.BPM != other.BPM ||
+		t.Compilation != other.Compilation ||
+		t.Gapless != other.Gapless ||
+		t.ShowMovement != other.ShowMovement ||
 		t.Comment != other.Comment ||
 		t.Description != other.Description ||
 		t.Lyrics != other.Lyrics ||
@@ -392,6 +552,13 @@ func (t *Tags) Equal(other *Tags) bool { //nolint:gocyclo // Equality check requ
 		t.ISBN != other.ISBN ||
 		t.ASIN != other.ASIN ||
 		t.Language != other.Language ||
+		t.Encoder != other.Encoder ||
+		t.PurchaseDate != other.PurchaseDate ||
+		t.SortTitle != other.SortTitle ||
+		t.SortArtist != other.SortArtist ||
+		t.SortAlbum != other.SortAlbum ||
+		t.SortAlbumArtist != other.SortAlbumArtist ||
+		t.SortComposer != other.SortComposer ||
 		t.MusicBrainzTrackID != other.MusicBrainzTrackID ||
 		t.MusicBrainzAlbumID != other.MusicBrainzAlbumID ||
 		t.MusicBrainzArtistID != other.MusicBrainzArtistID ||
@@ -407,7 +574,14 @@ func (t *Tags) Equal(other *Tags) bool { //nolint:gocyclo // Equality check requ
 	if !slices.Equal(t.Artists, other.Artists) ||
 		!slices.Equal(t.Genres, other.Genres) ||
 		!slices.Equal(t.Composers, other.Composers) ||
-		!slices.Equal(t.Performers, other.Performers) {
+		!slices.Equal(t.Performers, other.Performers) ||
+		!slices.Equal(t.Keywords, other.Keywords) ||
+		!slices.Equal(t.SyncedLyrics, other.SyncedLyrics) ||
+		!slices.Equal(t.Comments, other.Comments) {
+		return false
+	}
+
+	if !maps.Equal(t.URLs, other.URLs) {
 		return false
 	}
 
@@ -440,6 +614,120 @@ func (t *Tags) Filter(predicate func(string) bool) iter.Seq2[string, []string] {
 	}
 }
 
+// DisplayArtist returns the artist to show for a track in a library view:
+// AlbumArtist if set, so compilation tracks group under "Various Artists",
+// else Artist, else the first of Artists. Returns "" if none is set.
+func (t *Tags) DisplayArtist() string {
+	switch {
+	case t.AlbumArtist != "":
+		return t.AlbumArtist
+	case t.Artist != "":
+		return t.Artist
+	case len(t.Artists) > 0:
+		return t.Artists[0]
+	default:
+		return ""
+	}
+}
+
+// AllArtists returns Artist, AlbumArtist and then each of Artists, leaving
+// out empty values and any that repeat an earlier one, compared case
+// insensitively. Formats that split a joined artist string into Artists
+// repeat Artist there, and featured artists often appear only in Artists.
+//
+// Example:
+//
+//	// Artist "Daft Punk", Artists ["Daft Punk", "Pharrell Williams"]
+//	tags.AllArtists() // ["Daft Punk", "Pharrell Williams"]
+func (t *Tags) AllArtists() []string {
+	candidates := append([]string{t.Artist, t.AlbumArtist}, t.Artists...)
+	return mergeUnique(nil, slices.DeleteFunc(candidates, func(s string) bool { return s == "" }))
+}
+
+// SeriesPartFloat returns SeriesPart as a number, so that a library can
+// sort parts "1", "2", "2.5" and "10" numerically. Besides bare numbers it
+// reads the forms series positions are taken from titles in, such as
+// "Book 3", "#4" and "Vol. 2". ok is false if SeriesPart holds no number.
+func (t *Tags) SeriesPartFloat() (part float64, ok bool) {
+	text := parsing.ExtractSeriesPartFromText(strings.TrimSpace(t.SeriesPart))
+	if text == "" {
+		return 0, false
+	}
+	part, err := strconv.ParseFloat(text, 64)
+	return part, err == nil
+}
+
+// Sanitize removes NUL and other control characters from all tag values.
+//
+// Characters 0x00-0x1F are stripped from every string field, multi-value
+// field, URL, and raw tag value, except tab and newline. Buggy encoders sometimes
+// embed these, and they break display, logging, and database storage.
+//
+// Example:
+//
+//	file.Tags.Sanitize()
+func (t *Tags) Sanitize() {
+	for _, field := range t.stringFields() {
+		*field = stripControl(*field)
+	}
+
+	for _, values := range [][]string{t.Artists, t.Genres, t.Composers, t.Performers, t.Keywords} {
+		for i := range values {
+			values[i] = stripControl(values[i])
+		}
+	}
+
+	for i := range t.SyncedLyrics {
+		t.SyncedLyrics[i].Text = stripControl(t.SyncedLyrics[i].Text)
+	}
+
+	for i := range t.Comments {
+		c := &t.Comments[i]
+		c.Language, c.Description, c.Text = stripControl(c.Language), stripControl(c.Description), stripControl(c.Text)
+	}
+
+	for key, url := range t.URLs {
+		t.URLs[key] = stripControl(url)
+	}
+
+	for _, values := range t.raw {
+		for i := range values {
+			values[i] = stripControl(values[i])
+		}
+	}
+}
+
+// stringFields returns pointers to all single-value string fields.
+func (t *Tags) stringFields() []*string {
+	return []*string{
+		&t.Title, &t.Subtitle, &t.DiscSubtitle, &t.Artist, &t.Album, &t.AlbumArtist,
+		&t.Date, &t.OriginalDate, &t.Comment, &t.Description, &t.Lyrics,
+		&t.Narrator, &t.Publisher, &t.Series, &t.Grouping, &t.SeriesPart,
+		&t.ISBN, &t.ASIN, &t.Language, &t.PurchaseDate, &t.Encoder,
+		&t.SortTitle, &t.SortArtist, &t.SortAlbum, &t.SortAlbumArtist, &t.SortComposer,
+		&t.MusicBrainzTrackID, &t.MusicBrainzAlbumID, &t.MusicBrainzArtistID,
+		&t.ISRC, &t.Barcode, &t.CatalogNumber, &t.Label, &t.Copyright,
+	}
+}
+
+// stripControl removes C0 control characters except tab and newline.
+func stripControl(s string) string {
+	clean := strings.IndexFunc(s, isStrippedControl) < 0
+	if clean {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if isStrippedControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isStrippedControl(r rune) bool {
+	return r < 0x20 && r != '\t' && r != '\n'
+}
+
 // mergeUnique appends elements from b to a, skipping duplicates.
 // Uses case-insensitive comparison for strings.
 func mergeUnique(a, b []string) []string {
@@ -0,0 +1,162 @@
+package types
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// tagField describes one standard Tags field for name-based access.
+type tagField struct {
+	name string
+	get  func(*Tags) string
+}
+
+// multiValueSeparator joins slice fields (Artists, Genres, ...) into a
+// single string for Field and Fields.
+const multiValueSeparator = "; "
+
+// standardFields lists the standard Tags fields in display order. Field and
+// Fields are driven by this table, so new fields only need adding here.
+var standardFields = []tagField{
+	{"Title", func(t *Tags) string { return t.Title }},
+	{"Subtitle", func(t *Tags) string { return t.Subtitle }},
+	{"Artist", func(t *Tags) string { return t.Artist }},
+	{"Artists", func(t *Tags) string { return strings.Join(t.Artists, multiValueSeparator) }},
+	{"Album", func(t *Tags) string { return t.Album }},
+	{"AlbumArtist", func(t *Tags) string { return t.AlbumArtist }},
+	{"Composers", func(t *Tags) string { return strings.Join(t.Composers, multiValueSeparator) }},
+	{"Performers", func(t *Tags) string { return strings.Join(t.Performers, multiValueSeparator) }},
+	{"Genres", func(t *Tags) string { return strings.Join(t.Genres, multiValueSeparator) }},
+	{"Keywords", func(t *Tags) string { return strings.Join(t.Keywords, multiValueSeparator) }},
+	{"Year", func(t *Tags) string { return formatIntField(t.Year) }},
+	{"Date", func(t *Tags) string { return t.Date }},
+	{"OriginalDate", func(t *Tags) string { return t.OriginalDate }},
+	{"TrackNumber", func(t *Tags) string { return formatIntField(t.TrackNumber) }},
+	{"TrackTotal", func(t *Tags) string { return formatIntField(t.TrackTotal) }},
+	{"DiscNumber", func(t *Tags) string { return formatIntField(t.DiscNumber) }},
+	{"DiscTotal", func(t *Tags) string { return formatIntField(t.DiscTotal) }},
+	{"DiscSubtitle", func(t *Tags) string { return t.DiscSubtitle }},
+	{"BPM", func(t *Tags) string { return formatIntField(t.BPM) }},
+	{"Compilation", func(t *Tags) string { return formatBoolField(t.Compilation) }},
+	{"Gapless", func(t *Tags) string { return formatBoolField(t.Gapless) }},
+	{"ShowMovement", func(t *Tags) string { return formatBoolField(t.ShowMovement) }},
+	{"Comment", func(t *Tags) string { return t.Comment }},
+	{"Comments", func(t *Tags) string { return formatCommentsField(t.Comments) }},
+	{"Description", func(t *Tags) string { return t.Description }},
+	{"Lyrics", func(t *Tags) string { return t.Lyrics }},
+	{"SyncedLyrics", func(t *Tags) string { return formatLyricsField(t.SyncedLyrics) }},
+	{"Narrator", func(t *Tags) string { return t.Narrator }},
+	{"Publisher", func(t *Tags) string { return t.Publisher }},
+	{"Series", func(t *Tags) string { return t.Series }},
+	{"SeriesPart", func(t *Tags) string { return t.SeriesPart }},
+	{"Grouping", func(t *Tags) string { return t.Grouping }},
+	{"ISBN", func(t *Tags) string { return t.ISBN }},
+	{"ASIN", func(t *Tags) string { return t.ASIN }},
+	{"Language", func(t *Tags) string { return t.Language }},
+	{"PurchaseDate", func(t *Tags) string { return t.PurchaseDate }},
+	{"Encoder", func(t *Tags) string { return t.Encoder }},
+	{"SortTitle", func(t *Tags) string { return t.SortTitle }},
+	{"SortArtist", func(t *Tags) string { return t.SortArtist }},
+	{"SortAlbum", func(t *Tags) string { return t.SortAlbum }},
+	{"SortAlbumArtist", func(t *Tags) string { return t.SortAlbumArtist }},
+	{"SortComposer", func(t *Tags) string { return t.SortComposer }},
+	{"MusicBrainzTrackID", func(t *Tags) string { return t.MusicBrainzTrackID }},
+	{"MusicBrainzAlbumID", func(t *Tags) string { return t.MusicBrainzAlbumID }},
+	{"MusicBrainzArtistID", func(t *Tags) string { return t.MusicBrainzArtistID }},
+	{"ISRC", func(t *Tags) string { return t.ISRC }},
+	{"Barcode", func(t *Tags) string { return t.Barcode }},
+	{"CatalogNumber", func(t *Tags) string { return t.CatalogNumber }},
+	{"Label", func(t *Tags) string { return t.Label }},
+	{"Copyright", func(t *Tags) string { return t.Copyright }},
+}
+
+// standardFieldIndex maps lowercased field names to standardFields entries.
+var standardFieldIndex = func() map[string]int {
+	index := make(map[string]int, len(standardFields))
+	for i, f := range standardFields {
+		index[strings.ToLower(f.name)] = i
+	}
+	return index
+}()
+
+// formatLyricsField renders synced lyrics back as LRC, one line per entry.
+func formatLyricsField(lines []LyricLine) string {
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		parts[i] = line.String()
+	}
+	return strings.Join(parts, "\n")
+}
+
+// formatCommentsField joins the comment texts like other multi-value fields.
+func formatCommentsField(comments []Comment) string {
+	parts := make([]string, len(comments))
+	for i, c := range comments {
+		parts[i] = c.Text
+	}
+	return strings.Join(parts, multiValueSeparator)
+}
+
+// formatIntField renders a numeric field, treating zero as unset.
+func formatIntField(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// formatBoolField renders a flag field, treating false as unset.
+func formatBoolField(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
+
+// Field returns the string representation of a standard field by name.
+//
+// Names match the Tags field names ("Artist", "Year", "MusicBrainzAlbumID")
+// case-insensitively. Numeric fields are formatted in base 10 and are empty
+// when zero; flags are "true" or empty; multi-value fields are joined with "; ".
+//
+// The boolean reports whether name is a standard field, not whether it has
+// a value. Raw format-specific keys are available through Get.
+//
+// Example:
+//
+//	if narrator, ok := file.Tags.Field("Narrator"); ok && narrator != "" {
+//		fmt.Println("Read by", narrator)
+//	}
+func (t *Tags) Field(name string) (string, bool) {
+	i, ok := standardFieldIndex[strings.ToLower(name)]
+	if !ok {
+		return "", false
+	}
+	return standardFields[i].get(t), true
+}
+
+// Fields returns an iterator over all non-empty standard fields.
+//
+// Fields are yielded in a stable order (title, artists, album, numbering,
+// audiobook fields, sort keys, identifiers), using the same names and
+// formatting as Field.
+//
+// Example:
+//
+//	for name, value := range file.Tags.Fields() {
+//		fmt.Printf("%s: %s\n", name, value)
+//	}
+func (t *Tags) Fields() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, f := range standardFields {
+			value := f.get(t)
+			if value == "" {
+				continue
+			}
+			if !yield(f.name, value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTags_Field(t *testing.T) {
+	tags := &Tags{
+		Artist:     "Test Artist",
+		Year:       2024,
+		Genres:     []string{"Rock", "Alternative"},
+		TrackTotal: 0,
+	}
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"Artist", "Test Artist", true},
+		{"artist", "Test Artist", true},
+		{"Year", "2024", true},
+		{"Genres", "Rock; Alternative", true},
+		{"TrackTotal", "", true},
+		{"Narrator", "", true},
+		{"NotAField", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tags.Field(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Field(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTags_Fields(t *testing.T) {
+	tags := &Tags{
+		Title:       "Test Song",
+		Artist:      "Test Artist",
+		TrackNumber: 3,
+	}
+
+	got := map[string]string{}
+	var order []string
+	for name, value := range tags.Fields() {
+		got[name] = value
+		order = append(order, name)
+	}
+
+	want := map[string]string{"Title": "Test Song", "Artist": "Test Artist", "TrackNumber": "3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+	if want := []string{"Title", "Artist", "TrackNumber"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Fields() order = %v, want %v", order, want)
+	}
+
+	// Early break must stop iteration
+	count := 0
+	for range tags.Fields() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("expected iteration to stop after 1, got %d", count)
+	}
+}
+
+// TestStandardFields_CoverTags fails when a field is added to Tags without
+// a matching standardFields entry.
+func TestStandardFields_CoverTags(t *testing.T) {
+	typ := reflect.TypeOf(Tags{})
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() || field.Type.Kind() == reflect.Map {
+			continue
+		}
+		if _, ok := standardFieldIndex[strings.ToLower(field.Name)]; !ok {
+			t.Errorf("Tags.%s has no standardFields entry", field.Name)
+		}
+	}
+}
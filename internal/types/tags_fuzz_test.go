@@ -0,0 +1,180 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// tagsRoundTrips lists the serialize→parse paths checked by FuzzTags_RoundTrip.
+// Each must return Tags equal to its input.
+var tagsRoundTrips = map[string]func(*Tags) (*Tags, error){
+	"Clone": func(t *Tags) (*Tags, error) { return t.Clone(), nil },
+}
+
+// fuzzTags fills every standard field of Tags from the fuzz inputs, so a
+// field missing from Clone or Equal shows up as a mismatch.
+func fuzzTags(text string, number int, flag bool, rawKey, rawValue string) *Tags {
+	tags := &Tags{}
+	v := reflect.ValueOf(tags).Elem()
+
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		name := v.Type().Field(i).Name
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(name + ":" + text)
+		case reflect.Int:
+			field.SetInt(int64(number + i))
+		case reflect.Bool:
+			field.SetBool(flag)
+		case reflect.Slice:
+			if field.Type() == reflect.TypeFor[[]LyricLine]() {
+				field.Set(reflect.ValueOf([]LyricLine{{Text: text, Time: time.Duration(number)}, {Text: name}}))
+				continue
+			}
+			if field.Type() == reflect.TypeFor[[]Comment]() {
+				field.Set(reflect.ValueOf([]Comment{{Language: "eng", Description: name, Text: text}, {Text: name}}))
+				continue
+			}
+			field.Set(reflect.ValueOf([]string{text, name}))
+		case reflect.Map:
+			field.Set(reflect.ValueOf(map[string]string{name: text}))
+		}
+	}
+
+	tags.Set(rawKey, rawValue, text)
+	return tags
+}
+
+func FuzzTags_RoundTrip(f *testing.F) {
+	f.Add("Title", 1, true, "TITLE", "value")
+	f.Add("a=b", 0, false, "KEY=WITH=EQUALS", "line\nbreak")
+	f.Add("日本語 é́", -5, true, "©nam", "\x00\x1f")
+	f.Add("", 1<<30, false, "", "")
+
+	f.Fuzz(func(t *testing.T, text string, number int, flag bool, rawKey, rawValue string) {
+		original := fuzzTags(text, number, flag, rawKey, rawValue)
+		if !original.Equal(original) {
+			t.Fatal("Tags not equal to itself")
+		}
+
+		for name, roundTrip := range tagsRoundTrips {
+			got, err := roundTrip(original)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !got.Equal(original) {
+				t.Fatalf("%s: round trip changed tags\n got: %+v\nwant: %+v", name, got, original)
+			}
+
+			// The copy must be independent of the original
+			title := original.Title
+			got.Title += "!"
+			got.Set(rawKey, "changed")
+			if got.Equal(original) {
+				t.Fatalf("%s: Equal missed a modified copy", name)
+			}
+			if original.Title != title || original.GetFirst(rawKey) != rawValue {
+				t.Fatalf("%s: modifying the copy changed the original", name)
+			}
+		}
+	})
+}
+
+// unmarshalTags decodes tags encoded by Tags.MarshalJSON, setting each
+// standard field on the Tags field of the same name.
+func unmarshalTags(data []byte) (*Tags, error) {
+	var in struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+		URLs   map[string]string          `json:"urls"`
+		Raw    map[string][]string        `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	tags := &Tags{URLs: in.URLs}
+	v := reflect.ValueOf(tags).Elem()
+	for name, raw := range in.Fields {
+		field := v.FieldByName(name)
+		if !field.IsValid() {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		var err error
+		switch field.Interface().(type) {
+		case []Comment:
+			var comments []commentJSON
+			err = json.Unmarshal(raw, &comments)
+			for _, c := range comments {
+				tags.Comments = append(tags.Comments, Comment{Text: c.Text, Language: c.Language, Description: c.Description})
+			}
+		case []LyricLine:
+			var lines []lyricLineJSON
+			err = json.Unmarshal(raw, &lines)
+			for _, line := range lines {
+				tags.SyncedLyrics = append(tags.SyncedLyrics, LyricLine{Text: line.Text, Time: line.Time})
+			}
+		case []string:
+			err = json.Unmarshal(raw, field.Addr().Interface())
+		default:
+			var text string
+			if err = json.Unmarshal(raw, &text); err != nil {
+				break
+			}
+			switch field.Kind() {
+			case reflect.String:
+				field.SetString(text)
+			case reflect.Int:
+				n, convErr := strconv.Atoi(text)
+				field.SetInt(int64(n))
+				err = convErr
+			case reflect.Bool:
+				field.SetBool(text == "true")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+	}
+	for key, values := range in.Raw {
+		tags.Set(key, values...)
+	}
+	return tags, nil
+}
+
+func FuzzTags_MarshalJSON(f *testing.F) {
+	f.Add("Title", 1, true, "TITLE", "value")
+	f.Add("a=b; c", 0, false, "KEY=WITH=EQUALS", "line\nbreak")
+	f.Add("日本語 é́ \"quoted\" \\", -5, true, "©nam", "\x00\x1f\u2028")
+	f.Add("", 1<<30, false, "", "")
+
+	f.Fuzz(func(t *testing.T, text string, number int, flag bool, rawKey, rawValue string) {
+		// Invalid UTF-8 is replaced rather than escaped
+		if !utf8.ValidString(text) || !utf8.ValidString(rawKey) || !utf8.ValidString(rawValue) {
+			t.Skip()
+		}
+		original := fuzzTags(text, number, flag, rawKey, rawValue)
+
+		data, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		got, err := unmarshalTags(data)
+		if err != nil {
+			t.Fatalf("unmarshalTags failed: %v\n%s", err, data)
+		}
+		if !got.Equal(original) {
+			t.Fatalf("JSON round trip changed tags\n got: %+v\nwant: %+v", got, original)
+		}
+	})
+}
@@ -6,6 +6,21 @@ import (
 	"testing"
 )
 
+func TestNewTags(t *testing.T) {
+	tags := NewTags()
+	if tags.raw == nil {
+		t.Fatal("expected raw map to be allocated")
+	}
+	if !tags.Equal(&Tags{}) {
+		t.Error("expected NewTags to equal the zero Tags")
+	}
+
+	tags.Set("ENCODER", "test")
+	if got := tags.GetFirst("ENCODER"); got != "test" {
+		t.Errorf("GetFirst(ENCODER) = %q, want %q", got, "test")
+	}
+}
+
 func TestTags_All(t *testing.T) {
 	tags := &Tags{}
 	tags.Set("TITLE", "Test Song")
@@ -261,6 +276,84 @@ func TestTags_Merge(t *testing.T) {
 	})
 }
 
+func TestTags_MergeWith(t *testing.T) {
+	newTags := func() (*Tags, *Tags) {
+		tags := &Tags{
+			Title:  "Abbey Road",
+			Album:  "Abbey Road",
+			Year:   1969,
+			Genres: []string{"Rock"},
+			URLs:   map[string]string{"WOAR": "https://a.example"},
+		}
+		tags.Set("CUSTOM", "long original value")
+
+		other := &Tags{
+			Title:       "Come Together",
+			Album:       "Abbey Road (Remastered)",
+			AlbumArtist: "The Beatles",
+			Year:        2019,
+			Genres:      []string{"Pop", "rock"},
+			URLs:        map[string]string{"WOAR": "https://b.example"},
+		}
+		other.Set("CUSTOM", "short")
+		return tags, other
+	}
+
+	tests := []struct {
+		name       string
+		strategy   MergeStrategy
+		wantTitle  string
+		wantAlbum  string
+		wantYear   int
+		wantGenres []string
+		wantURL    string
+		wantCustom string
+	}{
+		{"prefer existing", PreferExisting, "Abbey Road", "Abbey Road", 1969, []string{"Rock", "Pop"}, "https://a.example", "short"},
+		{"prefer other", PreferOther, "Come Together", "Abbey Road (Remastered)", 2019, []string{"Pop", "rock"}, "https://b.example", "short"},
+		{"prefer longest", PreferLongest, "Come Together", "Abbey Road (Remastered)", 1969, []string{"Rock", "Pop"}, "https://a.example", "long original value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, other := newTags()
+			tags.MergeWith(other, tt.strategy)
+
+			if tags.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", tags.Title, tt.wantTitle)
+			}
+			if tags.Album != tt.wantAlbum {
+				t.Errorf("Album = %q, want %q", tags.Album, tt.wantAlbum)
+			}
+			if tags.AlbumArtist != "The Beatles" {
+				t.Errorf("AlbumArtist = %q, want it filled from other", tags.AlbumArtist)
+			}
+			if tags.Year != tt.wantYear {
+				t.Errorf("Year = %d, want %d", tags.Year, tt.wantYear)
+			}
+			if !slices.Equal(tags.Genres, tt.wantGenres) {
+				t.Errorf("Genres = %v, want %v", tags.Genres, tt.wantGenres)
+			}
+			if got := tags.URLs["WOAR"]; got != tt.wantURL {
+				t.Errorf("URLs[WOAR] = %q, want %q", got, tt.wantURL)
+			}
+			if got := tags.GetFirst("CUSTOM"); got != tt.wantCustom {
+				t.Errorf("CUSTOM = %q, want %q", got, tt.wantCustom)
+			}
+		})
+	}
+
+	t.Run("merge matches default strategy", func(t *testing.T) {
+		a, other := newTags()
+		b, _ := newTags()
+		a.Merge(other)
+		b.MergeWith(other, PreferExisting)
+		if !a.Equal(b) {
+			t.Error("Merge and MergeWith(PreferExisting) disagree")
+		}
+	})
+}
+
 func TestTags_Clone(t *testing.T) {
 	original := &Tags{
 		Title:       "Test Title",
@@ -311,6 +404,47 @@ func TestTags_Clone_Nil(t *testing.T) {
 	}
 }
 
+func TestTags_CopyFrom(t *testing.T) {
+	tags := &Tags{
+		Title:    "Old Title",
+		Comment:  "Only in the target",
+		Genres:   []string{"Jazz"},
+		Narrator: "Old Narrator",
+	}
+	tags.Set("STALE", "Value")
+
+	other := &Tags{
+		Title:  "New Title",
+		Artist: "New Artist",
+		Genres: []string{"Rock", "Pop"},
+	}
+	other.Set("CUSTOM", "Value")
+
+	tags.CopyFrom(other)
+
+	if !tags.Equal(other) {
+		t.Errorf("CopyFrom() left %+v, want %+v", tags, other)
+	}
+	if tags.Comment != "" || tags.Narrator != "" {
+		t.Error("CopyFrom() should clear fields other leaves empty")
+	}
+	if tags.GetFirst("STALE") != "" {
+		t.Error("CopyFrom() should replace raw tags")
+	}
+
+	// The copy must be deep
+	tags.Genres[0] = "Modified"
+	tags.Set("CUSTOM", "Modified")
+	if other.Genres[0] != "Rock" || other.GetFirst("CUSTOM") != "Value" {
+		t.Error("modifying the copy affected other")
+	}
+
+	tags.CopyFrom(nil)
+	if !tags.Equal(&Tags{}) {
+		t.Errorf("CopyFrom(nil) left %+v, want zero Tags", tags)
+	}
+}
+
 func TestTags_Equal(t *testing.T) {
 	tests := []struct {
 		name string
@@ -430,6 +564,118 @@ func TestTags_Filter_NilRaw(t *testing.T) {
 	}
 }
 
+func TestTags_DisplayArtist(t *testing.T) {
+	tests := []struct {
+		name string
+		tags Tags
+		want string
+	}{
+		{"album artist first", Tags{AlbumArtist: "Various Artists", Artist: "Björk"}, "Various Artists"},
+		{"artist", Tags{Artist: "Björk", Artists: []string{"Björk"}}, "Björk"},
+		{"first of artists", Tags{Artists: []string{"Björk", "Thom Yorke"}}, "Björk"},
+		{"none", Tags{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tags.DisplayArtist(); got != tt.want {
+				t.Errorf("DisplayArtist() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTags_AllArtists(t *testing.T) {
+	tests := []struct {
+		name string
+		tags Tags
+		want []string
+	}{
+		{
+			name: "featured artists only in Artists",
+			tags: Tags{Artist: "Daft Punk", Artists: []string{"daft punk", "Pharrell Williams", "Nile Rodgers"}},
+			want: []string{"Daft Punk", "Pharrell Williams", "Nile Rodgers"},
+		},
+		{
+			name: "album artist",
+			tags: Tags{Artist: "Björk", AlbumArtist: "Various Artists", Artists: []string{"Björk"}},
+			want: []string{"Björk", "Various Artists"},
+		},
+		{
+			name: "artists only",
+			tags: Tags{Artists: []string{"A", "B", "a"}},
+			want: []string{"A", "B"},
+		},
+		{name: "none", tags: Tags{}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tags.AllArtists(); !slices.Equal(got, tt.want) {
+				t.Errorf("AllArtists() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTags_SeriesPartFloat(t *testing.T) {
+	tests := []struct {
+		part   string
+		want   float64
+		wantOK bool
+	}{
+		{"1", 1, true},
+		{"2.5", 2.5, true},
+		{" 03 ", 3, true},
+		{"0", 0, true},
+		{"Book 3", 3, true},
+		{"#4", 4, true},
+		{"Vol. 2", 2, true},
+		{"", 0, false},
+		{"Prequel", 0, false},
+	}
+
+	for _, tt := range tests {
+		tags := Tags{SeriesPart: tt.part}
+		got, ok := tags.SeriesPartFloat()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("SeriesPartFloat() for %q = %v, %v; want %v, %v", tt.part, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestTags_Sanitize(t *testing.T) {
+	tags := &Tags{
+		Title:   "Hello\x00World",
+		Artist:  "Clean Artist",
+		Lyrics:  "Line one\nLine\ttwo\r",
+		Genres:  []string{"Rock\x01"},
+		Comment: "\x1fNote",
+	}
+	tags.Set("CUSTOM", "a\x00b", "ok")
+
+	tags.Sanitize()
+
+	if tags.Title != "HelloWorld" {
+		t.Errorf("Title = %q, want %q", tags.Title, "HelloWorld")
+	}
+	if tags.Artist != "Clean Artist" {
+		t.Errorf("Artist = %q, want unchanged", tags.Artist)
+	}
+	if tags.Lyrics != "Line one\nLine\ttwo" {
+		t.Errorf("Lyrics = %q, want tab and newline preserved", tags.Lyrics)
+	}
+	if tags.Comment != "Note" {
+		t.Errorf("Comment = %q, want %q", tags.Comment, "Note")
+	}
+	if !slices.Equal(tags.Genres, []string{"Rock"}) {
+		t.Errorf("Genres = %v, want [Rock]", tags.Genres)
+	}
+	if got := tags.Get("CUSTOM"); !slices.Equal(got, []string{"ab", "ok"}) {
+		t.Errorf("raw CUSTOM = %q, want [ab ok]", got)
+	}
+}
+
 func TestMergeUnique(t *testing.T) {
 	tests := []struct {
 		name string
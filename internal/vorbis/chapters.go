@@ -1,8 +1,8 @@
 package vorbis
 
 import (
-	"cmp"
 	"fmt"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -26,7 +26,14 @@ import (
 //	CHAPTER001NAME=Introduction
 //	CHAPTER002=00:05:23.500
 //	CHAPTER002NAME=Chapter 1: The Beginning
-func ParseChapters(comments []string, fileDuration time.Duration) []types.Chapter { //nolint:gocyclo // Chapter parsing requires handling multiple tag formats and cases
+//
+// Chapters are ordered by number, whatever the order of the comments, and
+// indexed from 1 with gaps in the numbering closed. A chapter without a
+// valid timestamp is left out, and one without a name is called
+// "Chapter n" after its number. Each chapter ends where the next starts
+// and the last with the file; fileDuration may be 0 if not yet known, in
+// which case the last chapter's EndTime is 0 for the caller to fill.
+func ParseChapters(comments []string, fileDuration time.Duration) []types.Chapter {
 	// Map to collect chapter data by chapter number
 	type chapterData struct {
 		timestamp string
@@ -38,97 +45,61 @@ func ParseChapters(comments []string, fileDuration time.Duration) []types.Chapte
 
 	// Scan all comments for CHAPTERxxx tags
 	for _, comment := range comments {
-		// Find '=' separator
-		eq := strings.IndexByte(comment, '=')
-		if eq < 0 {
+		key, value, ok := strings.Cut(comment, "=")
+		if !ok {
 			continue
 		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
 
-		key := strings.ToUpper(strings.TrimSpace(comment[:eq]))
-		value := strings.TrimSpace(comment[eq+1:])
-
-		if !strings.HasPrefix(key, "CHAPTER") {
+		numStr, isChapter := strings.CutPrefix(key, "CHAPTER")
+		if !isChapter {
 			continue
 		}
+		numStr, isName := strings.CutSuffix(numStr, "NAME")
+
+		num, err := strconv.Atoi(numStr)
+		if err != nil || num < 0 {
+			continue // Invalid chapter number, or another CHAPTER... comment
+		}
 
-		if strings.HasSuffix(key, "NAME") { //nolint:nestif // Chapter tag parsing requires nested conditionals for different formats
-			// CHAPTERxxxNAME - extract chapter number
-			numStr := strings.TrimSuffix(strings.TrimPrefix(key, "CHAPTER"), "NAME")
-			num, err := strconv.Atoi(numStr)
-			if err != nil {
-				continue // Invalid chapter number
-			}
-
-			if chaptersMap[num] == nil {
-				chaptersMap[num] = &chapterData{number: num}
-			}
+		if chaptersMap[num] == nil {
+			chaptersMap[num] = &chapterData{number: num}
+		}
+		if isName {
 			chaptersMap[num].title = value
 		} else {
-			// CHAPTERxxx - extract chapter number and timestamp
-			numStr := strings.TrimPrefix(key, "CHAPTER")
-			num, err := strconv.Atoi(numStr)
-			if err != nil {
-				continue // Invalid chapter number
-			}
-
-			if chaptersMap[num] == nil {
-				chaptersMap[num] = &chapterData{number: num}
-			}
 			chaptersMap[num].timestamp = value
 		}
 	}
 
-	if len(chaptersMap) == 0 {
-		return nil
-	}
-
-	// Convert map to sorted slice
-	var chapterList []chapterData
-	for _, chap := range chaptersMap {
-		if chap.timestamp != "" { // Must have timestamp
-			chapterList = append(chapterList, *chap)
-		}
-	}
-
-	if len(chapterList) == 0 {
-		return nil
-	}
-
-	// Sort by chapter number
-	slices.SortFunc(chapterList, func(a, b chapterData) int {
-		return cmp.Compare(a.number, b.number)
-	})
-
-	// Convert to types.Chapter
-	chapters := make([]types.Chapter, len(chapterList))
-	for i, chap := range chapterList {
+	// Sort by chapter number those with a usable timestamp
+	var chapters []types.Chapter
+	for _, num := range slices.Sorted(maps.Keys(chaptersMap)) {
+		chap := chaptersMap[num]
 		startTime, err := parseChapterTimestamp(chap.timestamp)
 		if err != nil {
-			// Invalid timestamp - skip this chapter
 			continue
 		}
 
-		// Calculate end time
-		var endTime time.Duration
-		if i < len(chapterList)-1 {
-			// End at start of next chapter (ignore error, endTime stays 0 if invalid)
-			endTime, _ = parseChapterTimestamp(chapterList[i+1].timestamp)
-		} else if fileDuration > 0 {
-			// Last chapter: use file duration
-			endTime = fileDuration
-		}
-		// If no duration and last chapter, endTime stays 0
-
 		title := chap.title
 		if title == "" {
 			title = fmt.Sprintf("Chapter %d", chap.number)
 		}
 
-		chapters[i] = types.Chapter{
-			Index:     i + 1,
+		chapters = append(chapters, types.Chapter{
+			Index:     len(chapters) + 1,
 			Title:     title,
 			StartTime: startTime,
-			EndTime:   endTime,
+		})
+	}
+
+	// Calculate end times: the next chapter's start, or the file's end
+	for i := range chapters {
+		if i+1 < len(chapters) {
+			chapters[i].EndTime = max(chapters[i+1].StartTime, chapters[i].StartTime)
+		} else if fileDuration > 0 {
+			chapters[i].EndTime = max(fileDuration, chapters[i].StartTime)
 		}
 	}
 
@@ -146,7 +117,7 @@ func parseChapterTimestamp(ts string) (time.Duration, error) {
 	parts := strings.Split(ts, ":")
 
 	var hours, minutes int
-	var seconds float64
+	var seconds time.Duration
 	var err error
 
 	switch len(parts) {
@@ -160,7 +131,7 @@ func parseChapterTimestamp(ts string) (time.Duration, error) {
 		if err != nil {
 			return 0, fmt.Errorf("invalid minutes in timestamp: %s", ts)
 		}
-		seconds, err = strconv.ParseFloat(parts[2], 64)
+		seconds, err = parseSeconds(parts[2])
 		if err != nil {
 			return 0, fmt.Errorf("invalid seconds in timestamp: %s", ts)
 		}
@@ -171,14 +142,14 @@ func parseChapterTimestamp(ts string) (time.Duration, error) {
 		if err != nil {
 			return 0, fmt.Errorf("invalid minutes in timestamp: %s", ts)
 		}
-		seconds, err = strconv.ParseFloat(parts[1], 64)
+		seconds, err = parseSeconds(parts[1])
 		if err != nil {
 			return 0, fmt.Errorf("invalid seconds in timestamp: %s", ts)
 		}
 
 	case 1:
 		// SS.mmm
-		seconds, err = strconv.ParseFloat(parts[0], 64)
+		seconds, err = parseSeconds(parts[0])
 		if err != nil {
 			return 0, fmt.Errorf("invalid seconds in timestamp: %s", ts)
 		}
@@ -188,11 +159,19 @@ func parseChapterTimestamp(ts string) (time.Duration, error) {
 	}
 
 	// Validate ranges
-	if hours < 0 || minutes < 0 || minutes >= 60 || seconds < 0 || seconds >= 60 {
+	if hours < 0 || minutes < 0 || minutes >= 60 || seconds < 0 || seconds >= time.Minute {
 		return 0, fmt.Errorf("timestamp values out of range: %s", ts)
 	}
 
 	// Convert to duration
-	totalSeconds := float64(hours*3600+minutes*60) + seconds
-	return time.Duration(totalSeconds * float64(time.Second)), nil
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + seconds, nil
+}
+
+// parseSeconds parses decimal seconds ("05.123") exactly, where a float
+// would round 5.123s to 5.122999999s.
+func parseSeconds(s string) (time.Duration, error) {
+	if s == "" || strings.Trim(s, "0123456789.") != "" {
+		return 0, fmt.Errorf("invalid seconds: %q", s)
+	}
+	return time.ParseDuration(s + "s")
 }
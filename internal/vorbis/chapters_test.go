@@ -0,0 +1,101 @@
+package vorbis
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestParseChapters(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []string
+		duration time.Duration
+		want     []types.Chapter
+	}{
+		{
+			name: "in order",
+			comments: []string{
+				"TITLE=Episode 12",
+				"CHAPTER001=00:00:00.000", "CHAPTER001NAME=Intro",
+				"CHAPTER002=00:05:23.500", "CHAPTER002NAME=Interview",
+			},
+			duration: 30 * time.Minute,
+			want: []types.Chapter{
+				{Index: 1, Title: "Intro", EndTime: 5*time.Minute + 23500*time.Millisecond},
+				{Index: 2, Title: "Interview", StartTime: 5*time.Minute + 23500*time.Millisecond, EndTime: 30 * time.Minute},
+			},
+		},
+		{
+			name: "out of order and sparse",
+			comments: []string{
+				"CHAPTER010NAME=Outro", "CHAPTER010=1:02:03.25",
+				"chapter003=00:10:00", "CHAPTER003NAME=Middle",
+				"CHAPTER000=00:00:00.000",
+			},
+			duration: 2 * time.Hour,
+			want: []types.Chapter{
+				{Index: 1, Title: "Chapter 0", EndTime: 10 * time.Minute},
+				{Index: 2, Title: "Middle", StartTime: 10 * time.Minute, EndTime: time.Hour + 2*time.Minute + 3250*time.Millisecond},
+				{Index: 3, Title: "Outro", StartTime: time.Hour + 2*time.Minute + 3250*time.Millisecond, EndTime: 2 * time.Hour},
+			},
+		},
+		{
+			name: "invalid timestamps and names left out",
+			comments: []string{
+				"CHAPTER001=00:00:00.000",
+				"CHAPTER002=later", "CHAPTER002NAME=Broken",
+				"CHAPTER003NAME=No Time",
+				"CHAPTER004=00:01:00.123",
+				"CHAPTERS=2",
+			},
+			want: []types.Chapter{
+				{Index: 1, Title: "Chapter 1", EndTime: time.Minute + 123*time.Millisecond},
+				{Index: 2, Title: "Chapter 4", StartTime: time.Minute + 123*time.Millisecond},
+			},
+		},
+		{
+			name:     "none",
+			comments: []string{"TITLE=Song", "CHAPTER001NAME=Untimed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseChapters(tt.comments, tt.duration)
+			if !slices.EqualFunc(got, tt.want, func(a, b types.Chapter) bool {
+				return a.Index == b.Index && a.Title == b.Title && a.StartTime == b.StartTime && a.EndTime == b.EndTime
+			}) {
+				t.Errorf("ParseChapters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChapterTimestamp(t *testing.T) {
+	tests := []struct {
+		ts      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"01:02:03.456", time.Hour + 2*time.Minute + 3456*time.Millisecond, false},
+		{"05:23.5", 5*time.Minute + 23500*time.Millisecond, false},
+		{"42.001", 42*time.Second + time.Millisecond, false},
+		{"00:00:05.123", 5123 * time.Millisecond, false},
+		{"00:60:00", 0, true},
+		{"00:00:60", 0, true},
+		{"-5", 0, true},
+		{"1m5", 0, true},
+		{"", 0, true},
+		{"1:2:3:4", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseChapterTimestamp(tt.ts)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseChapterTimestamp(%q) = %v, %v; want %v, error %v", tt.ts, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
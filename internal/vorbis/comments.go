@@ -6,9 +6,8 @@ package vorbis
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 
+	"github.com/simonhull/audiometa/internal/parsing"
 	"github.com/simonhull/audiometa/internal/types"
 )
 
@@ -16,7 +15,8 @@ import (
 // and populates the appropriate fields in the File struct.
 //
 // Vorbis comment field names are case-insensitive but typically uppercase.
-// The comment is also stored in the raw tags map.
+// The comment is also stored in the raw tags map, and recorded with
+// File.AddRawTag.
 //
 // Returns an error if the comment is not in valid "KEY=VALUE" format.
 func ParseComment(comment string, file *types.File) error { //nolint:gocyclo // Complexity from many simple field mappings - intentionally kept together
@@ -35,6 +35,7 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 
 	key := comment[:eq]
 	value := comment[eq+1:]
+	addRawComment(key, value, file)
 
 	// Map Vorbis comments to standard Tags fields
 	// Vorbis comment field names are case-insensitive, but typically uppercase
@@ -43,6 +44,8 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 		tags.Title = value
 	case "SUBTITLE":
 		tags.Subtitle = value
+	case "DISCSUBTITLE":
+		tags.DiscSubtitle = value
 	case "ARTIST":
 		tags.Artist = value
 		tags.Artists = append(tags.Artists, value)
@@ -59,6 +62,16 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 				tags.Year = year
 			}
 		}
+	case "TITLESORT":
+		tags.SortTitle = value
+	case "ARTISTSORT":
+		tags.SortArtist = value
+	case "ALBUMSORT":
+		tags.SortAlbum = value
+	case "ALBUMARTISTSORT":
+		tags.SortAlbumArtist = value
+	case "COMPOSERSORT":
+		tags.SortComposer = value
 	case "ORIGINALDATE":
 		tags.OriginalDate = value
 	case "TRACKNUMBER":
@@ -69,6 +82,10 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 		_, _ = fmt.Sscanf(value, "%d", &tags.DiscNumber)
 	case "DISCTOTAL", "TOTALDISCS":
 		_, _ = fmt.Sscanf(value, "%d", &tags.DiscTotal)
+	case "BPM":
+		if bpm := parsing.ParseBPM(value); bpm > 0 {
+			tags.BPM = bpm
+		}
 	case "GENRE":
 		tags.Genres = append(tags.Genres, value)
 	case "COMPOSER":
@@ -76,9 +93,15 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 	case "PERFORMER":
 		tags.Performers = append(tags.Performers, value)
 	case "COMMENT":
-		tags.Comment = value
+		tags.Comments = append(tags.Comments, types.Comment{Text: value})
+		if tags.Comment == "" {
+			tags.Comment = value
+		}
 	case "LYRICS":
 		tags.Lyrics = value
+		if synced := types.ParseLRC(value); synced != nil {
+			tags.SyncedLyrics = synced
+		}
 	case "NARRATOR":
 		tags.Narrator = value
 	case "PUBLISHER":
@@ -125,28 +148,19 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 		tags.Label = value
 	case "COPYRIGHT":
 		tags.Copyright = value
-
-	// ReplayGain tags
-	case "REPLAYGAIN_TRACK_GAIN":
-		if file.Audio.ReplayGain == nil {
-			file.Audio.ReplayGain = &types.ReplayGainInfo{}
-		}
-		file.Audio.ReplayGain.TrackGain = parseReplayGainValue(value)
-	case "REPLAYGAIN_TRACK_PEAK":
-		if file.Audio.ReplayGain == nil {
-			file.Audio.ReplayGain = &types.ReplayGainInfo{}
-		}
-		file.Audio.ReplayGain.TrackPeak = parseReplayGainPeak(value)
-	case "REPLAYGAIN_ALBUM_GAIN":
-		if file.Audio.ReplayGain == nil {
-			file.Audio.ReplayGain = &types.ReplayGainInfo{}
+	case "ENCODER":
+		tags.Encoder = value
+	case "ENCODED-BY", "ENCODEDBY", "ENCODED_BY":
+		// ENCODER names the software and is preferred when both are present
+		if tags.Encoder == "" {
+			tags.Encoder = value
 		}
-		file.Audio.ReplayGain.AlbumGain = parseReplayGainValue(value)
-	case "REPLAYGAIN_ALBUM_PEAK":
-		if file.Audio.ReplayGain == nil {
-			file.Audio.ReplayGain = &types.ReplayGainInfo{}
-		}
-		file.Audio.ReplayGain.AlbumPeak = parseReplayGainPeak(value)
+	case "METADATA_BLOCK_PICTURE", "COVERART":
+		// Artwork is decoded lazily via ExtractArtwork(); only record presence
+		file.HasEmbeddedArtwork = true
+
+	case "REPLAYGAIN_TRACK_GAIN", "REPLAYGAIN_TRACK_PEAK", "REPLAYGAIN_ALBUM_GAIN", "REPLAYGAIN_ALBUM_PEAK":
+		types.SetReplayGain(&file.Audio, key, value)
 	}
 
 	// Store in raw tags as well
@@ -154,19 +168,3 @@ func ParseComment(comment string, file *types.File) error { //nolint:gocyclo //
 
 	return nil
 }
-
-// parseReplayGainValue parses a ReplayGain gain value like "-6.50 dB" or "-6.50".
-func parseReplayGainValue(s string) float64 {
-	s = strings.TrimSpace(s)
-	s = strings.TrimSuffix(s, " dB")
-	s = strings.TrimSuffix(s, "dB")
-	s = strings.TrimSpace(s)
-	val, _ := strconv.ParseFloat(s, 64)
-	return val
-}
-
-// parseReplayGainPeak parses a ReplayGain peak value like "0.988127".
-func parseReplayGainPeak(s string) float64 {
-	val, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
-	return val
-}
@@ -1,8 +1,10 @@
 package vorbis
 
 import (
+	"encoding/base64"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/simonhull/audiometa/internal/types"
 )
@@ -23,6 +25,16 @@ func TestParseComment(t *testing.T) {
 		{"album", "ALBUM=Test Album", func(f *types.File) bool { return f.Tags.Album == "Test Album" }},
 		{"album artist", "ALBUMARTIST=Various Artists", func(f *types.File) bool { return f.Tags.AlbumArtist == "Various Artists" }},
 
+		// Sort fields
+		{"title sort", "TITLESORT=Wall, The", func(f *types.File) bool { return f.Tags.SortTitle == "Wall, The" }},
+		{"artist sort", "ARTISTSORT=Beatles, The", func(f *types.File) bool { return f.Tags.SortArtist == "Beatles, The" }},
+		{"album sort", "ALBUMSORT=White Album, The", func(f *types.File) bool { return f.Tags.SortAlbum == "White Album, The" }},
+		{"encoder", "ENCODER=Lavf60.3.100", func(f *types.File) bool { return f.Tags.Encoder == "Lavf60.3.100" }},
+		{"encoded-by", "ENCODED-BY=Jane Doe", func(f *types.File) bool { return f.Tags.Encoder == "Jane Doe" }},
+		{"picture presence", "METADATA_BLOCK_PICTURE=AAAAAw==", func(f *types.File) bool { return f.HasEmbeddedArtwork }},
+		{"album artist sort", "ALBUMARTISTSORT=Various", func(f *types.File) bool { return f.Tags.SortAlbumArtist == "Various" }},
+		{"composer sort", "COMPOSERSORT=Bach, Johann Sebastian", func(f *types.File) bool { return f.Tags.SortComposer == "Bach, Johann Sebastian" }},
+
 		// Date handling
 		{"date full", "DATE=2024-05-15", func(f *types.File) bool { return f.Tags.Date == "2024-05-15" && f.Tags.Year == 2024 }},
 		{"date year only", "DATE=2024", func(f *types.File) bool { return f.Tags.Date == "2024" && f.Tags.Year == 2024 }},
@@ -30,10 +42,13 @@ func TestParseComment(t *testing.T) {
 
 		// Track/disc numbers
 		{"track number", "TRACKNUMBER=5", func(f *types.File) bool { return f.Tags.TrackNumber == 5 }},
+		{"bpm", "BPM=128", func(f *types.File) bool { return f.Tags.BPM == 128 }},
+		{"fractional bpm", "BPM=128.5", func(f *types.File) bool { return f.Tags.BPM == 129 }},
 		{"track total", "TRACKTOTAL=12", func(f *types.File) bool { return f.Tags.TrackTotal == 12 }},
 		{"totaltracks", "TOTALTRACKS=15", func(f *types.File) bool { return f.Tags.TrackTotal == 15 }},
 		{"disc number", "DISCNUMBER=2", func(f *types.File) bool { return f.Tags.DiscNumber == 2 }},
 		{"disc total", "DISCTOTAL=3", func(f *types.File) bool { return f.Tags.DiscTotal == 3 }},
+		{"disc subtitle", "DISCSUBTITLE=The Return of the King", func(f *types.File) bool { return f.Tags.DiscSubtitle == "The Return of the King" }},
 		{"totaldiscs", "TOTALDISCS=4", func(f *types.File) bool { return f.Tags.DiscTotal == 4 }},
 
 		// Multi-value fields
@@ -49,7 +64,10 @@ func TestParseComment(t *testing.T) {
 
 		// Text fields
 		{"comment", "COMMENT=Great album!", func(f *types.File) bool { return f.Tags.Comment == "Great album!" }},
-		{"lyrics", "LYRICS=La la la", func(f *types.File) bool { return f.Tags.Lyrics == "La la la" }},
+		{"lyrics", "LYRICS=La la la", func(f *types.File) bool { return f.Tags.Lyrics == "La la la" && f.Tags.SyncedLyrics == nil }},
+		{"lrc lyrics", "LYRICS=[00:01.00]La\n[00:02.00]La", func(f *types.File) bool {
+			return f.Tags.Lyrics != "" && len(f.Tags.SyncedLyrics) == 2 && f.Tags.SyncedLyrics[1].Time == 2*time.Second
+		}},
 		{"description", "DESCRIPTION=A detailed description", func(f *types.File) bool { return f.Tags.Description == "A detailed description" }},
 
 		// Audiobook fields
@@ -196,6 +214,20 @@ func TestParseComment_MultipleGenres(t *testing.T) {
 	}
 }
 
+func TestParseComment_MultipleComments(t *testing.T) {
+	file := &types.File{}
+
+	_ = ParseComment("COMMENT=First", file)
+	_ = ParseComment("COMMENT=Second", file)
+
+	if file.Tags.Comment != "First" {
+		t.Errorf("Comment = %q, want %q (first value)", file.Tags.Comment, "First")
+	}
+	if len(file.Tags.Comments) != 2 || file.Tags.Comments[1].Text != "Second" {
+		t.Errorf("Comments = %+v, want both entries", file.Tags.Comments)
+	}
+}
+
 func TestParseComment_MultipleArtists(t *testing.T) {
 	file := &types.File{}
 
@@ -324,43 +356,32 @@ func TestParseComment_InvalidNumbers(t *testing.T) {
 	}
 }
 
-func TestParseReplayGainValue(t *testing.T) {
-	tests := []struct {
-		input string
-		want  float64
-	}{
-		{"-6.50 dB", -6.50},
-		{"-6.50dB", -6.50},
-		{"-6.50", -6.50},
-		{"  -6.50 dB  ", -6.50},
-		{"+3.20 dB", 3.20},
-		{"0", 0.0},
-		{"invalid", 0.0},
-	}
-
-	for _, tc := range tests {
-		got := parseReplayGainValue(tc.input)
-		if math.Abs(got-tc.want) > 0.001 {
-			t.Errorf("parseReplayGainValue(%q) = %v, want %v", tc.input, got, tc.want)
+func TestParseComment_RawTags(t *testing.T) {
+	file := &types.File{}
+	file.EnableRawTags()
+	for _, comment := range []string{
+		"Title=Song",
+		"artist=A",
+		"ARTIST=B",
+		"METADATA_BLOCK_PICTURE=" + base64.StdEncoding.EncodeToString([]byte("\x00\x00\x00\x03block")),
+		"metadata_block_picture=not base64!",
+	} {
+		if err := ParseComment(comment, file); err != nil {
+			t.Fatalf("ParseComment(%q): %v", comment, err)
 		}
 	}
-}
 
-func TestParseReplayGainPeak(t *testing.T) {
-	tests := []struct {
-		input string
-		want  float64
-	}{
-		{"0.988127", 0.988127},
-		{"1.0", 1.0},
-		{"  0.5  ", 0.5},
-		{"invalid", 0.0},
+	raw := file.RawTags()
+	if got := raw["Title"]; len(got) != 1 || string(got[0].Value) != "Song" || got[0].Encoding != "UTF-8" {
+		t.Errorf("Title = %+v, want its value with the key's case kept", got)
 	}
-
-	for _, tc := range tests {
-		got := parseReplayGainPeak(tc.input)
-		if math.Abs(got-tc.want) > 0.000001 {
-			t.Errorf("parseReplayGainPeak(%q) = %v, want %v", tc.input, got, tc.want)
-		}
+	if len(raw["artist"]) != 1 || len(raw["ARTIST"]) != 1 {
+		t.Errorf("artist keys = %v, %v; want one entry each", raw["artist"], raw["ARTIST"])
+	}
+	if got := raw["METADATA_BLOCK_PICTURE"]; len(got) != 1 || string(got[0].Value) != "\x00\x00\x00\x03block" || got[0].Type != types.RawTagImage {
+		t.Errorf("METADATA_BLOCK_PICTURE = %+v, want the decoded block", got)
+	}
+	if got := raw["metadata_block_picture"]; len(got) != 1 || got[0].Type != types.RawTagText {
+		t.Errorf("undecodable picture = %+v, want it kept as text", got)
 	}
 }
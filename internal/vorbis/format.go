@@ -0,0 +1,180 @@
+package vorbis
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// fieldKeys are the comment keys ParseComment maps into standard Tags
+// fields, aliases included. FormatComments writes these from the fields and
+// never from the raw map, so an edited field can't be shadowed by the raw
+// comment it was parsed from.
+var fieldKeys = map[string]bool{
+	"TITLE": true, "SUBTITLE": true, "DISCSUBTITLE": true,
+	"ARTIST": true, "ALBUM": true, "ALBUMARTIST": true,
+	"DATE": true, "ORIGINALDATE": true,
+	"TITLESORT": true, "ARTISTSORT": true, "ALBUMSORT": true, "ALBUMARTISTSORT": true, "COMPOSERSORT": true,
+	"TRACKNUMBER": true, "TRACKTOTAL": true, "TOTALTRACKS": true,
+	"DISCNUMBER": true, "DISCTOTAL": true, "TOTALDISCS": true,
+	"BPM": true, "GENRE": true, "COMPOSER": true, "PERFORMER": true,
+	"COMMENT": true, "LYRICS": true, "DESCRIPTION": true,
+	"NARRATOR": true, "PUBLISHER": true, "GROUPING": true,
+	"SERIES": true, "SHOW": true, "MVNM": true,
+	"SERIESPART": true, "SERIES-PART": true, "SERIES_PART": true, "SERIES PART": true, "PART": true,
+	"EPISODE_ID": true, "MVIN": true,
+	"ISBN": true, "ASIN": true, "AUDIBLE_ASIN": true, "LANGUAGE": true, "LANG": true,
+	"MUSICBRAINZ_TRACKID": true, "MUSICBRAINZ_ALBUMID": true, "MUSICBRAINZ_ARTISTID": true,
+	"ISRC": true, "BARCODE": true, "CATALOGNUMBER": true, "LABEL": true, "COPYRIGHT": true,
+	"ENCODER": true, "ENCODED-BY": true, "ENCODEDBY": true, "ENCODED_BY": true,
+}
+
+// stringComments maps single-value string fields to the key they are
+// written under, in output order.
+var stringComments = []struct {
+	key string
+	get func(*types.Tags) string
+}{
+	{"TITLE", func(t *types.Tags) string { return t.Title }},
+	{"SUBTITLE", func(t *types.Tags) string { return t.Subtitle }},
+	{"ALBUM", func(t *types.Tags) string { return t.Album }},
+	{"ALBUMARTIST", func(t *types.Tags) string { return t.AlbumArtist }},
+	{"DISCSUBTITLE", func(t *types.Tags) string { return t.DiscSubtitle }},
+	{"ORIGINALDATE", func(t *types.Tags) string { return t.OriginalDate }},
+	{"DESCRIPTION", func(t *types.Tags) string { return t.Description }},
+	{"NARRATOR", func(t *types.Tags) string { return t.Narrator }},
+	{"PUBLISHER", func(t *types.Tags) string { return t.Publisher }},
+	{"SERIES", func(t *types.Tags) string { return t.Series }},
+	{"SERIESPART", func(t *types.Tags) string { return t.SeriesPart }},
+	{"GROUPING", func(t *types.Tags) string { return t.Grouping }},
+	{"ISBN", func(t *types.Tags) string { return t.ISBN }},
+	{"ASIN", func(t *types.Tags) string { return t.ASIN }},
+	{"LANGUAGE", func(t *types.Tags) string { return t.Language }},
+	{"TITLESORT", func(t *types.Tags) string { return t.SortTitle }},
+	{"ARTISTSORT", func(t *types.Tags) string { return t.SortArtist }},
+	{"ALBUMSORT", func(t *types.Tags) string { return t.SortAlbum }},
+	{"ALBUMARTISTSORT", func(t *types.Tags) string { return t.SortAlbumArtist }},
+	{"COMPOSERSORT", func(t *types.Tags) string { return t.SortComposer }},
+	{"MUSICBRAINZ_TRACKID", func(t *types.Tags) string { return t.MusicBrainzTrackID }},
+	{"MUSICBRAINZ_ALBUMID", func(t *types.Tags) string { return t.MusicBrainzAlbumID }},
+	{"MUSICBRAINZ_ARTISTID", func(t *types.Tags) string { return t.MusicBrainzArtistID }},
+	{"ISRC", func(t *types.Tags) string { return t.ISRC }},
+	{"BARCODE", func(t *types.Tags) string { return t.Barcode }},
+	{"CATALOGNUMBER", func(t *types.Tags) string { return t.CatalogNumber }},
+	{"LABEL", func(t *types.Tags) string { return t.Label }},
+	{"COPYRIGHT", func(t *types.Tags) string { return t.Copyright }},
+	{"ENCODER", func(t *types.Tags) string { return t.Encoder }},
+}
+
+// FormatComments renders tags as "KEY=VALUE" comments that ParseComment
+// reads back into the same field values.
+//
+// Standard fields come first, under their canonical keys. Raw tags follow,
+// sorted by key, for every key ParseComment does not map to a field:
+// custom tags, ReplayGain, embedded pictures. Raw values under a mapped
+// key (TITLE, or an alias such as TOTALTRACKS) are dropped in favor of the
+// field.
+func FormatComments(tags *types.Tags) []string {
+	var comments []string
+	add := func(key string, values ...string) {
+		for _, v := range values {
+			if v != "" {
+				comments = append(comments, key+"="+v)
+			}
+		}
+	}
+	addInt := func(key string, n int) {
+		if n > 0 {
+			add(key, strconv.Itoa(n))
+		}
+	}
+
+	for _, c := range stringComments {
+		add(c.key, c.get(tags))
+	}
+
+	// ParseComment keeps the last ARTIST as Artist, so it is written last
+	artists := slices.DeleteFunc(slices.Clone(tags.Artists), func(a string) bool { return a == tags.Artist })
+	add("ARTIST", append(artists, tags.Artist)...)
+
+	// Year is derived from DATE; a year edited on its own replaces the date
+	date := tags.Date
+	if tags.Year > 0 && !strings.HasPrefix(date, strconv.Itoa(tags.Year)) {
+		date = strconv.Itoa(tags.Year)
+	}
+	add("DATE", date)
+
+	addInt("TRACKNUMBER", tags.TrackNumber)
+	addInt("TRACKTOTAL", tags.TrackTotal)
+	addInt("DISCNUMBER", tags.DiscNumber)
+	addInt("DISCTOTAL", tags.DiscTotal)
+	addInt("BPM", tags.BPM)
+
+	add("GENRE", tags.Genres...)
+	add("COMPOSER", tags.Composers...)
+	add("PERFORMER", tags.Performers...)
+
+	// ParseComment keeps the first COMMENT as Comment, so it is written first
+	add("COMMENT", tags.Comment)
+	for _, c := range tags.Comments {
+		if c.Text != tags.Comment {
+			add("COMMENT", c.Text)
+		}
+	}
+
+	lyrics := tags.Lyrics
+	if lyrics == "" && len(tags.SyncedLyrics) > 0 {
+		lines := make([]string, len(tags.SyncedLyrics))
+		for i, line := range tags.SyncedLyrics {
+			lines[i] = line.String()
+		}
+		lyrics = strings.Join(lines, "\n")
+	}
+	add("LYRICS", lyrics)
+
+	raw := maps.Collect(tags.All())
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		if key != "" && !fieldKeys[key] && !strings.Contains(key, "=") {
+			add(key, raw[key]...)
+		}
+	}
+
+	return comments
+}
+
+// MarshalComments encodes a vendor string and comments as a Vorbis comment
+// header body, the layout FLAC stores in its VORBIS_COMMENT block.
+//
+// Layout (little-endian):
+//
+//	[4 bytes] vendor length
+//	[n bytes] vendor string
+//	[4 bytes] comment count
+//	for each comment:
+//	  [4 bytes] comment length
+//	  [n bytes] comment
+func MarshalComments(vendor string, comments []string) ([]byte, error) {
+	size := 8 + len(vendor)
+	for _, c := range comments {
+		size += 4 + len(c)
+	}
+	if int64(size) > math.MaxUint32 {
+		return nil, fmt.Errorf("vorbis comments too large: %d bytes", size)
+	}
+
+	data := make([]byte, 0, size)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(vendor)))
+	data = append(data, vendor...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(comments)))
+	for _, c := range comments {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(c)))
+		data = append(data, c...)
+	}
+	return data, nil
+}
@@ -0,0 +1,127 @@
+package vorbis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// parseComments runs every comment through ParseComment into a new file.
+func parseComments(t *testing.T, comments []string) *types.File {
+	t.Helper()
+	file := &types.File{}
+	for _, c := range comments {
+		if err := ParseComment(c, file); err != nil {
+			t.Fatalf("ParseComment(%q) error: %v", c, err)
+		}
+	}
+	return file
+}
+
+func TestFormatComments_RoundTrip(t *testing.T) {
+	original := parseComments(t, []string{
+		"TITLE=Song", "ARTIST=First", "ARTIST=Second", "ALBUM=Album",
+		"DATE=2021-05-04", "TRACKNUMBER=3", "TRACKTOTAL=12", "DISCNUMBER=1",
+		"GENRE=Rock", "GENRE=Pop", "COMPOSER=Writer", "BPM=120",
+		"COMMENT=Primary", "COMMENT=Secondary", "NARRATOR=Reader",
+		"MUSICBRAINZ_TRACKID=abc", "ISRC=USRC17607839", "LYRICS=[00:01.00]Hello",
+		"MOOD=Mellow", "REPLAYGAIN_TRACK_GAIN=-6.50 dB",
+	})
+
+	reparsed := parseComments(t, FormatComments(&original.Tags))
+
+	if got, want := maps.Collect(reparsed.Tags.Fields()), maps.Collect(original.Tags.Fields()); !maps.Equal(got, want) {
+		t.Errorf("round trip changed fields:\n got %v\nwant %v", got, want)
+	}
+	if got := reparsed.Tags.GetFirst("MOOD"); got != "Mellow" {
+		t.Errorf("raw MOOD = %q, want it passed through", got)
+	}
+	if reparsed.Audio.ReplayGain == nil || reparsed.Audio.ReplayGain.TrackGain != -6.5 {
+		t.Errorf("ReplayGain = %+v, want the raw tag passed through", reparsed.Audio.ReplayGain)
+	}
+}
+
+func TestFormatComments_FieldsWin(t *testing.T) {
+	tags := parseComments(t, []string{"TITLE=Old", "TOTALTRACKS=9"}).Tags
+	tags.Title = "New"
+	tags.TrackTotal = 10
+	tags.Year = 1999 // No DATE, so the year is written as one
+
+	comments := FormatComments(&tags)
+	want := []string{"TITLE=New", "DATE=1999", "TRACKTOTAL=10"}
+	if !slices.Equal(comments, want) {
+		t.Errorf("FormatComments() = %q, want %q", comments, want)
+	}
+}
+
+func TestFieldKeys_MatchParseComment(t *testing.T) {
+	// Every key FormatComments treats as a field must set a field when
+	// parsed, or its raw value would be silently dropped
+	for key := range fieldKeys {
+		file := parseComments(t, []string{key + "=1"})
+		if len(maps.Collect(file.Tags.Fields())) == 0 {
+			t.Errorf("%s is listed in fieldKeys but ParseComment sets no field", key)
+		}
+	}
+}
+
+func TestMarshalComments(t *testing.T) {
+	data, err := MarshalComments("vendor", []string{"A=1", "BB=22"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &bytes.Buffer{}
+	binary.Write(want, binary.LittleEndian, uint32(6))
+	want.WriteString("vendor")
+	binary.Write(want, binary.LittleEndian, uint32(2))
+	binary.Write(want, binary.LittleEndian, uint32(3))
+	want.WriteString("A=1")
+	binary.Write(want, binary.LittleEndian, uint32(5))
+	want.WriteString("BB=22")
+
+	if !bytes.Equal(data, want.Bytes()) {
+		t.Errorf("MarshalComments() = %q, want %q", data, want.Bytes())
+	}
+}
+
+func FuzzFormatComments_RoundTrip(f *testing.F) {
+	f.Add("Song", 3, "MOOD", "Mellow")
+	f.Add("a=b", 0, "KEY=WITH=EQUALS", "line\nbreak")
+	f.Add("日本語 é́", 2024, "©nam", "\x00\x1f")
+	f.Add("", -1, "", "")
+
+	f.Fuzz(func(t *testing.T, text string, number int, rawKey, rawValue string) {
+		original := &types.Tags{
+			Title:       text,
+			Artist:      text,
+			Artists:     []string{"Other", text},
+			Genres:      []string{text, "Rock"},
+			Comment:     text,
+			Narrator:    text,
+			Year:        number,
+			TrackNumber: number,
+		}
+		original.Set(rawKey, rawValue)
+
+		once := parseComments(t, FormatComments(original)).Tags
+		if once.Title != text || once.Comment != text || once.Narrator != text {
+			t.Fatalf("Title, Comment, Narrator = %q, %q, %q; want %q", once.Title, once.Comment, once.Narrator, text)
+		}
+		if rawKey != "" && rawValue != "" && !fieldKeys[rawKey] && !strings.Contains(rawKey, "=") {
+			if got := once.GetFirst(rawKey); got != rawValue {
+				t.Fatalf("raw %q = %q, want %q", rawKey, got, rawValue)
+			}
+		}
+
+		twice := parseComments(t, FormatComments(&once)).Tags
+		if !twice.Equal(&once) {
+			t.Fatalf("second round trip changed tags\n got: %+v\nwant: %+v", twice, once)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package vorbis
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// addRawComment records a comment for File.RawTags. A
+// METADATA_BLOCK_PICTURE value is stored as the picture block its base64
+// text encodes, or as text if it does not decode.
+func addRawComment(key, value string, file *types.File) {
+	if !file.RawTagsEnabled() {
+		return
+	}
+
+	if strings.EqualFold(key, "METADATA_BLOCK_PICTURE") {
+		if picture, err := base64.StdEncoding.DecodeString(value); err == nil {
+			file.AddRawTag(types.RawTag{Key: key, Value: picture, Type: types.RawTagImage})
+			return
+		}
+	}
+	file.AddRawTag(types.RawTag{Key: key, Encoding: "UTF-8", Value: []byte(value), Type: types.RawTagText})
+}
@@ -0,0 +1,73 @@
+// Package wav provides WAV (RIFF WAVE) audio file parsing.
+package wav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// acidChunkSize is the size of an acid chunk payload.
+const acidChunkSize = 24
+
+// acid chunk flag bits.
+const (
+	acidFlagOneShot  = 0x01
+	acidFlagRootNote = 0x02
+	acidFlagStretch  = 0x04
+)
+
+// parseAcidChunk decodes an ACID loop chunk.
+//
+// acid structure (little-endian):
+//
+//	[4 bytes] flags (one-shot, root note set, stretch, disk-based, ...)
+//	[2 bytes] root note (MIDI note number)
+//	[2 bytes] unknown
+//	[4 bytes] unknown (float)
+//	[4 bytes] number of beats
+//	[2 bytes] meter denominator
+//	[2 bytes] meter numerator
+//	[4 bytes] tempo (32-bit float, BPM)
+func parseAcidChunk(sr *binutil.SafeReader, offset, size int64) (*types.LoopInfo, error) {
+	if size < acidChunkSize {
+		return nil, fmt.Errorf("acid chunk too small: %d bytes (need %d)", size, acidChunkSize)
+	}
+
+	data := make([]byte, acidChunkSize)
+	if err := sr.ReadAt(data, offset, "acid chunk"); err != nil {
+		return nil, err
+	}
+
+	flags := binary.LittleEndian.Uint32(data[0:4])
+	tempo := math.Float32frombits(binary.LittleEndian.Uint32(data[20:24]))
+
+	loop := &types.LoopInfo{
+		Beats:       int(binary.LittleEndian.Uint32(data[12:16])),
+		MeterDenom:  int(binary.LittleEndian.Uint16(data[16:18])),
+		MeterNumer:  int(binary.LittleEndian.Uint16(data[18:20])),
+		OneShot:     flags&acidFlagOneShot != 0,
+		HasRootNote: flags&acidFlagRootNote != 0,
+		Stretch:     flags&acidFlagStretch != 0,
+	}
+	if loop.HasRootNote {
+		loop.RootNote = int(binary.LittleEndian.Uint16(data[4:6]))
+	}
+	if t := float64(tempo); !math.IsNaN(t) && !math.IsInf(t, 0) && t > 0 {
+		loop.Tempo = t
+	}
+
+	return loop, nil
+}
+
+// applyLoopInfo stores loop metadata on the file. The loop tempo also
+// fills Tags.BPM when no tag supplied one.
+func applyLoopInfo(loop *types.LoopInfo, file *types.File) {
+	file.Audio.LoopInfo = loop
+	if file.Tags.BPM == 0 && loop.Tempo > 0 {
+		file.Tags.BPM = int(math.Round(loop.Tempo))
+	}
+}
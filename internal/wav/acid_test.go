@@ -0,0 +1,86 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// createAcidData creates an acid chunk payload.
+func createAcidData(flags uint32, rootNote uint16, beats uint32, numer, denom uint16, tempo float32) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, flags)
+	binary.Write(buf, binary.LittleEndian, rootNote)
+	binary.Write(buf, binary.LittleEndian, uint16(0x8000)) // unknown
+	binary.Write(buf, binary.LittleEndian, float32(0))     // unknown
+	binary.Write(buf, binary.LittleEndian, beats)
+	binary.Write(buf, binary.LittleEndian, denom)
+	binary.Write(buf, binary.LittleEndian, numer)
+	binary.Write(buf, binary.LittleEndian, math.Float32bits(tempo))
+	return buf.Bytes()
+}
+
+func TestParseAcidChunk(t *testing.T) {
+	data := createAcidData(acidFlagRootNote|acidFlagStretch, 60, 8, 4, 4, 127.5)
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "loop.wav")
+
+	loop, err := parseAcidChunk(sr, 0, int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := types.LoopInfo{
+		Tempo:       127.5,
+		Beats:       8,
+		RootNote:    60,
+		MeterNumer:  4,
+		MeterDenom:  4,
+		HasRootNote: true,
+		Stretch:     true,
+	}
+	if *loop != want {
+		t.Errorf("parseAcidChunk() = %+v, want %+v", *loop, want)
+	}
+
+	file := &types.File{}
+	applyLoopInfo(loop, file)
+	if file.Audio.LoopInfo != loop {
+		t.Error("expected LoopInfo on file")
+	}
+	if file.Tags.BPM != 128 {
+		t.Errorf("expected BPM 128 from loop tempo, got %d", file.Tags.BPM)
+	}
+}
+
+func TestParseAcidChunk_NoRootNote(t *testing.T) {
+	data := createAcidData(acidFlagOneShot, 60, 1, 4, 4, 90)
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "hit.wav")
+
+	loop, err := parseAcidChunk(sr, 0, int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !loop.OneShot || loop.HasRootNote || loop.RootNote != 0 {
+		t.Errorf("unexpected flags: %+v", *loop)
+	}
+
+	// An existing tag BPM wins over the loop tempo
+	file := &types.File{Tags: types.Tags{BPM: 120}}
+	applyLoopInfo(loop, file)
+	if file.Tags.BPM != 120 {
+		t.Errorf("expected tag BPM to be kept, got %d", file.Tags.BPM)
+	}
+}
+
+func TestParseAcidChunk_TooSmall(t *testing.T) {
+	data := make([]byte, 10)
+	sr := binutil.NewSafeReader(bytes.NewReader(data), int64(len(data)), "loop.wav")
+
+	if _, err := parseAcidChunk(sr, 0, int64(len(data))); err == nil {
+		t.Error("expected error for short acid chunk")
+	}
+}
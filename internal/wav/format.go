@@ -0,0 +1,39 @@
+package wav
+
+import "github.com/simonhull/audiometa/internal/types"
+
+// WAVE format tags (wFormatTag in the fmt chunk).
+const (
+	waveFormatPCM        = 0x0001
+	waveFormatIEEEFloat  = 0x0003
+	waveFormatExtensible = 0xFFFE
+)
+
+// applySampleFormat sets the PCM sample format from a fmt chunk.
+//
+// WAVE_FORMAT_EXTENSIBLE defers to its SubFormat GUID, whose first two bytes
+// are the plain format tag; subFormat is ignored for other tags. WAV samples
+// are little-endian, and 8-bit PCM is unsigned where wider PCM is signed.
+func applySampleFormat(formatTag, subFormat uint16, bitsPerSample int, audio *types.AudioInfo) {
+	if formatTag == waveFormatExtensible {
+		formatTag = subFormat
+	}
+
+	switch formatTag {
+	case waveFormatPCM:
+		if bitsPerSample <= 8 {
+			audio.SampleFormat = types.SampleUnsignedInt
+			audio.Endianness = types.EndianUnknown
+			return
+		}
+		audio.SampleFormat = types.SampleSignedInt
+	case waveFormatIEEEFloat:
+		audio.SampleFormat = types.SampleFloat
+	default:
+		// Compressed formats (ADPCM, A-law, MP3 in WAV) have no PCM layout
+		audio.SampleFormat = types.SampleFormatUnknown
+		audio.Endianness = types.EndianUnknown
+		return
+	}
+	audio.Endianness = types.EndianLittle
+}
@@ -0,0 +1,39 @@
+package wav
+
+import (
+	"testing"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestApplySampleFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		formatTag  uint16
+		subFormat  uint16
+		bits       int
+		wantFormat types.SampleFormat
+		wantEndian types.Endianness
+	}{
+		{"pcm 16-bit", waveFormatPCM, 0, 16, types.SampleSignedInt, types.EndianLittle},
+		{"pcm 8-bit", waveFormatPCM, 0, 8, types.SampleUnsignedInt, types.EndianUnknown},
+		{"float", waveFormatIEEEFloat, 0, 32, types.SampleFloat, types.EndianLittle},
+		{"extensible pcm", waveFormatExtensible, waveFormatPCM, 24, types.SampleSignedInt, types.EndianLittle},
+		{"extensible float", waveFormatExtensible, waveFormatIEEEFloat, 64, types.SampleFloat, types.EndianLittle},
+		{"adpcm", 0x0002, 0, 4, types.SampleFormatUnknown, types.EndianUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var audio types.AudioInfo
+			applySampleFormat(tt.formatTag, tt.subFormat, tt.bits, &audio)
+
+			if audio.SampleFormat != tt.wantFormat {
+				t.Errorf("expected sample format %v, got %v", tt.wantFormat, audio.SampleFormat)
+			}
+			if audio.Endianness != tt.wantEndian {
+				t.Errorf("expected endianness %v, got %v", tt.wantEndian, audio.Endianness)
+			}
+		})
+	}
+}
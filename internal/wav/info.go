@@ -0,0 +1,87 @@
+package wav
+
+import (
+	"fmt"
+	"strconv"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/riff"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// infoFields maps LIST INFO item IDs to the Tags fields they set.
+var infoFields = map[string]func(*types.Tags, string){
+	"INAM": func(t *types.Tags, v string) { t.Title = v },
+	"IART": func(t *types.Tags, v string) {
+		t.Artist = v
+		t.Artists = append(t.Artists, v)
+	},
+	"IPRD": func(t *types.Tags, v string) { t.Album = v },
+	"ICMT": func(t *types.Tags, v string) {
+		t.Comment = v
+		t.Comments = append(t.Comments, types.Comment{Text: v})
+	},
+	"ICRD": func(t *types.Tags, v string) {
+		t.Date = v
+		if len(v) >= 4 {
+			if year, err := strconv.Atoi(v[:4]); err == nil && year > 0 {
+				t.Year = year
+			}
+		}
+	},
+	"IGNR": func(t *types.Tags, v string) { t.Genres = append(t.Genres, v) },
+	"ITRK": func(t *types.Tags, v string) { t.TrackNumber, _ = strconv.Atoi(v) },
+	"IPRT": func(t *types.Tags, v string) {
+		if t.TrackNumber == 0 {
+			t.TrackNumber, _ = strconv.Atoi(v)
+		}
+	},
+	"ICOP": func(t *types.Tags, v string) { t.Copyright = v },
+	"ISFT": func(t *types.Tags, v string) { t.Encoder = v },
+}
+
+// parseInfoList reads a LIST chunk of type INFO into tags. Returns nil tags
+// for LIST chunks of other types (adtl, for cue labels).
+//
+// LIST INFO layout:
+//
+//	[4 bytes] list type "INFO"
+//	then chunks, each an item ID such as "INAM" and a NUL-terminated
+//	string in the legacy 8-bit charset (UTF-8 from newer writers)
+//
+// Every item is also stored raw under its ID.
+func parseInfoList(sr *binutil.SafeReader, chunk riff.Chunk, charset types.Charset) (*types.Tags, error) {
+	if chunk.Size < 4 {
+		return nil, fmt.Errorf("LIST chunk too small: %d bytes", chunk.Size)
+	}
+	listType := make([]byte, 4)
+	if err := sr.ReadAt(listType, chunk.Offset, "LIST type"); err != nil {
+		return nil, err
+	}
+	if string(listType) != "INFO" {
+		return nil, nil
+	}
+
+	tags := types.NewTags()
+	items := riff.NewScanner(sr, chunk.Offset+4, chunk.Offset+chunk.Size, binutil.LittleEndian)
+	for item := range items.All() {
+		if item.Size == 0 {
+			continue
+		}
+		data := make([]byte, item.Size)
+		if err := sr.ReadAt(data, item.Offset, "INFO item "+item.ID); err != nil {
+			return tags, err
+		}
+		value := riff.DecodeText(data, charset)
+		if value == "" {
+			continue
+		}
+
+		if set, ok := infoFields[item.ID]; ok {
+			set(tags, value)
+		}
+		tags.Set(item.ID, value)
+	}
+
+	return tags, items.Err()
+}
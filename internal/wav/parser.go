@@ -0,0 +1,249 @@
+package wav
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/mp3"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/riff"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Container name.
+const containerName = "WAV"
+
+// fmtChunkMinSize is the size of a WAVEFORMAT structure; PCM and float
+// fmt chunks add bits per sample (16) and WAVE_FORMAT_EXTENSIBLE adds the
+// sub-format (40).
+const (
+	fmtChunkMinSize        = 14
+	fmtChunkPCMSize        = 16
+	fmtChunkExtensibleSize = 40
+)
+
+// codecNames names the WAVE format tags seen in the wild. Other tags are
+// reported by number.
+var codecNames = map[uint16]string{
+	waveFormatPCM:       "PCM",
+	waveFormatIEEEFloat: "PCM",
+	0x0002:              "MS ADPCM",
+	0x0006:              "A-law",
+	0x0007:              "µ-law",
+	0x0011:              "IMA ADPCM",
+	0x0050:              "MPEG",
+	0x0055:              "MP3",
+}
+
+// parser implements the audiometa.FormatParser interface for WAV files.
+type parser struct{}
+
+// Parse parses a WAV file and extracts metadata.
+//
+// Audio properties come from the fmt chunk, with the duration computed from
+// the data chunk size. Tags come from LIST INFO and an id3 chunk, merged by
+// riff.MergeTags, and an acid chunk fills Audio.LoopInfo.
+func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path string) (*types.File, error) { //nolint:gocyclo // Chunk dispatch requires multiple conditional branches
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+	form, err := riff.ReadForm(sr)
+	if err != nil {
+		return nil, err
+	}
+	if form.ID != "RIFF" || form.Type != "WAVE" {
+		return nil, &types.CorruptedFileError{
+			Path:   path,
+			Reason: fmt.Sprintf("not a WAVE file: form %q type %q", form.ID, form.Type),
+		}
+	}
+
+	file := &types.File{
+		Path:   path,
+		Format: types.FormatWAV,
+		Size:   size,
+	}
+	file.Audio.Container = containerName
+
+	charset := registry.LegacyCharset(ctx)
+	log := registry.Logger(ctx)
+	var fmtChunk *riff.Chunk
+	var dataSize int64 = -1
+	var id3Tags, infoTags *types.Tags
+	var loop *types.LoopInfo
+
+	scanner := form.Chunks(sr)
+	if form.Size == 0 {
+		// Written by a streaming encoder that never patched the header
+		scanner = riff.NewScanner(sr, 12, size, form.Order)
+	}
+chunks:
+	for chunk := range scanner.All() {
+		log.LogAttrs(ctx, slog.LevelDebug, "chunk",
+			slog.String("format", "WAV"), slog.String("key", chunk.ID),
+			slog.Int64("offset", chunk.Offset), slog.Int64("size", chunk.Size))
+		switch chunk.ID {
+		case "fmt ":
+			fmtChunk = &chunk
+
+		case "data":
+			dataSize = chunk.Size
+			if chunk.Size == 0 && chunk.Offset < size {
+				// Streaming writers leave the size for a header patch that
+				// never came; the audio runs to the end of the file
+				dataSize = size - chunk.Offset
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "technical",
+					Message: fmt.Sprintf("data chunk declares 0 bytes but %d follow; assuming audio runs to end of file", dataSize),
+					Offset:  chunk.Offset - riff.HeaderSize,
+				})
+				break chunks
+			}
+
+		case "LIST":
+			tags, err := parseInfoList(sr, chunk, charset)
+			if err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "metadata",
+					Message: fmt.Sprintf("failed to parse LIST INFO: %v", err),
+					Err:     err,
+					Offset:  chunk.Offset,
+				})
+			}
+			if tags != nil {
+				infoTags = tags
+			}
+
+		case "id3 ", "ID3 ":
+			scratch := &types.File{Path: path, Format: types.FormatWAV, Size: size}
+			if err := mp3.ReadID3v2(ctx, sr, chunk.Offset, chunk.Size, scratch, charset); err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "metadata",
+					Message: fmt.Sprintf("failed to parse id3 chunk: %v", err),
+					Err:     err,
+					Offset:  chunk.Offset,
+				})
+				continue
+			}
+			id3Tags = &scratch.Tags
+			file.Chapters, file.ChapterSource = scratch.Chapters, scratch.ChapterSource
+			file.Audio.ReplayGain = scratch.Audio.ReplayGain
+			file.Warnings = append(file.Warnings, scratch.Warnings...)
+
+		case "acid":
+			l, err := parseAcidChunk(sr, chunk.Offset, chunk.Size)
+			if err != nil {
+				file.Warnings = append(file.Warnings, types.Warning{
+					Stage:   "metadata",
+					Message: fmt.Sprintf("failed to parse acid chunk: %v", err),
+					Err:     err,
+					Offset:  chunk.Offset,
+				})
+				continue
+			}
+			loop = l
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:    "technical",
+			Message:  fmt.Sprintf("truncated file: %v", err),
+			Severity: types.SeverityError,
+			Err:      err,
+		})
+	}
+
+	if fmtChunk == nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: "missing fmt chunk; audio properties unknown",
+		})
+	} else if err := parseFmtChunk(sr, *fmtChunk, dataSize, file); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: fmt.Sprintf("failed to parse fmt chunk: %v", err),
+			Err:     err,
+			Offset:  fmtChunk.Offset,
+		})
+	}
+
+	riff.MergeTags(file, id3Tags, infoTags)
+	if loop != nil {
+		applyLoopInfo(loop, file)
+	}
+
+	return file, nil
+}
+
+// parseFmtChunk reads the audio format and, given the data chunk size (-1
+// if there is none), the duration.
+//
+// fmt structure (little-endian):
+//
+//	[2 bytes] format tag
+//	[2 bytes] channels
+//	[4 bytes] sample rate
+//	[4 bytes] average bytes per second
+//	[2 bytes] block align
+//	[2 bytes] bits per sample
+//	WAVE_FORMAT_EXTENSIBLE continues:
+//	[2 bytes] extension size (22)
+//	[2 bytes] valid bits per sample
+//	[4 bytes] channel mask
+//	[16 bytes] sub-format GUID, starting with the format tag
+func parseFmtChunk(sr *binutil.SafeReader, chunk riff.Chunk, dataSize int64, file *types.File) error {
+	if chunk.Size < fmtChunkMinSize {
+		return fmt.Errorf("fmt chunk too small: %d bytes (need %d)", chunk.Size, fmtChunkMinSize)
+	}
+
+	data := make([]byte, min(chunk.Size, fmtChunkExtensibleSize))
+	if err := sr.ReadAt(data, chunk.Offset, "fmt chunk"); err != nil {
+		return err
+	}
+
+	formatTag := binary.LittleEndian.Uint16(data[0:2])
+	audio := &file.Audio
+	audio.Channels = int(binary.LittleEndian.Uint16(data[2:4]))
+	audio.SampleRate = int(binary.LittleEndian.Uint32(data[4:8]))
+	byteRate := int64(binary.LittleEndian.Uint32(data[8:12]))
+
+	if len(data) >= fmtChunkPCMSize {
+		audio.BitDepth = int(binary.LittleEndian.Uint16(data[14:16]))
+	}
+	var subFormat uint16
+	if formatTag == waveFormatExtensible && len(data) >= fmtChunkExtensibleSize {
+		if valid := int(binary.LittleEndian.Uint16(data[18:20])); valid > 0 {
+			audio.BitDepth = valid
+		}
+		subFormat = binary.LittleEndian.Uint16(data[24:26])
+	}
+	applySampleFormat(formatTag, subFormat, audio.BitDepth, audio)
+
+	codecTag := formatTag
+	if formatTag == waveFormatExtensible {
+		codecTag = subFormat
+	}
+	audio.Codec = codecNames[codecTag]
+	if audio.Codec == "" {
+		audio.Codec = fmt.Sprintf("WAVE format 0x%04X", codecTag)
+	}
+	audio.Lossless = codecTag == waveFormatPCM || codecTag == waveFormatIEEEFloat
+
+	audio.Bitrate = int(byteRate * 8)
+	if dataSize > 0 && byteRate > 0 {
+		audio.Duration = time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+	}
+
+	return nil
+}
+
+func init() {
+	registry.Register(types.FormatWAV, &parser{})
+}
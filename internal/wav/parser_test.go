@@ -0,0 +1,276 @@
+package wav
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// chunk encodes a RIFF chunk, padding odd payloads.
+func chunk(id string, payload []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(payload)))
+	buf.Write(payload)
+	if len(payload)%2 == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// createWAV wraps chunks in a RIFF WAVE header.
+func createWAV(chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(4+len(body)))
+	buf.WriteString("WAVE")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// fmtChunk encodes a 16-byte fmt chunk.
+func fmtChunk(formatTag uint16, channels, sampleRate, bits int) []byte {
+	blockAlign := channels * bits / 8
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, formatTag)
+	binary.Write(buf, binary.LittleEndian, uint16(channels))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(buf, binary.LittleEndian, uint16(bits))
+	return chunk("fmt ", buf.Bytes())
+}
+
+// extensibleFmtChunk encodes a WAVE_FORMAT_EXTENSIBLE fmt chunk with
+// container bits and valid bits.
+func extensibleFmtChunk(subFormat uint16, channels, sampleRate, bits, validBits int) []byte {
+	payload := fmtChunk(waveFormatExtensible, channels, sampleRate, bits)[8:]
+	buf := bytes.NewBuffer(payload)
+	binary.Write(buf, binary.LittleEndian, uint16(22))
+	binary.Write(buf, binary.LittleEndian, uint16(validBits))
+	binary.Write(buf, binary.LittleEndian, uint32(0x3)) // front left and right
+	binary.Write(buf, binary.LittleEndian, subFormat)
+	buf.WriteString("\x00\x00\x00\x00\x10\x00\x80\x00\x00\xAA\x00\x38\x9B\x71")
+	return chunk("fmt ", buf.Bytes())
+}
+
+// infoList encodes a LIST INFO chunk from ID, value pairs.
+func infoList(items ...string) []byte {
+	buf := bytes.NewBufferString("INFO")
+	for i := 0; i+1 < len(items); i += 2 {
+		buf.Write(chunk(items[i], append([]byte(items[i+1]), 0)))
+	}
+	return chunk("LIST", buf.Bytes())
+}
+
+func parseWAV(t *testing.T, data []byte) *types.File {
+	t.Helper()
+	file, err := (&parser{}).Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.wav")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	return file
+}
+
+func TestParse_AudioProperties(t *testing.T) {
+	tests := []struct {
+		name         string
+		fmt          []byte
+		dataSize     int
+		wantChannels int
+		wantRate     int
+		wantBits     int
+		wantFormat   types.SampleFormat
+		wantDuration time.Duration
+		wantLossless bool
+		wantCodec    string
+	}{
+		{
+			name:         "16-bit PCM",
+			fmt:          fmtChunk(waveFormatPCM, 2, 44100, 16),
+			dataSize:     44100 * 4 * 2,
+			wantChannels: 2, wantRate: 44100, wantBits: 16,
+			wantFormat: types.SampleSignedInt, wantDuration: 2 * time.Second,
+			wantLossless: true, wantCodec: "PCM",
+		},
+		{
+			name:         "32-bit float",
+			fmt:          fmtChunk(waveFormatIEEEFloat, 1, 48000, 32),
+			dataSize:     48000 * 4 / 2,
+			wantChannels: 1, wantRate: 48000, wantBits: 32,
+			wantFormat: types.SampleFloat, wantDuration: 500 * time.Millisecond,
+			wantLossless: true, wantCodec: "PCM",
+		},
+		{
+			name:         "extensible 24 in 32",
+			fmt:          extensibleFmtChunk(waveFormatPCM, 2, 96000, 32, 24),
+			dataSize:     96000 * 8,
+			wantChannels: 2, wantRate: 96000, wantBits: 24,
+			wantFormat: types.SampleSignedInt, wantDuration: time.Second,
+			wantLossless: true, wantCodec: "PCM",
+		},
+		{
+			name:         "A-law",
+			fmt:          fmtChunk(0x0006, 1, 8000, 8),
+			dataSize:     8000,
+			wantChannels: 1, wantRate: 8000, wantBits: 8,
+			wantFormat: types.SampleFormatUnknown, wantDuration: time.Second,
+			wantLossless: false, wantCodec: "A-law",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := parseWAV(t, createWAV(tt.fmt, chunk("data", make([]byte, tt.dataSize))))
+
+			a := file.Audio
+			if a.Channels != tt.wantChannels || a.SampleRate != tt.wantRate || a.BitDepth != tt.wantBits {
+				t.Errorf("got %d ch, %d Hz, %d bits; want %d ch, %d Hz, %d bits",
+					a.Channels, a.SampleRate, a.BitDepth, tt.wantChannels, tt.wantRate, tt.wantBits)
+			}
+			if a.SampleFormat != tt.wantFormat {
+				t.Errorf("SampleFormat = %v, want %v", a.SampleFormat, tt.wantFormat)
+			}
+			if a.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", a.Duration, tt.wantDuration)
+			}
+			if a.Lossless != tt.wantLossless || a.Codec != tt.wantCodec {
+				t.Errorf("Lossless = %v, Codec = %q; want %v, %q", a.Lossless, a.Codec, tt.wantLossless, tt.wantCodec)
+			}
+			if a.Container != "WAV" || file.Format != types.FormatWAV {
+				t.Errorf("Container = %q, Format = %v", a.Container, file.Format)
+			}
+			if len(file.Warnings) > 0 {
+				t.Errorf("unexpected warnings: %v", file.Warnings)
+			}
+		})
+	}
+}
+
+func TestParse_InfoTags(t *testing.T) {
+	data := createWAV(
+		fmtChunk(waveFormatPCM, 2, 44100, 16),
+		infoList(
+			"INAM", "Song", "IART", "Artist", "IPRD", "Album",
+			"ICMT", "A comment", "ICRD", "2019-06-01", "IGNR", "Ambient",
+			"ITRK", "7", "ISFT", "Lavf58",
+		),
+		chunk("data", make([]byte, 16)),
+	)
+	tags := parseWAV(t, data).Tags
+
+	if tags.Title != "Song" || tags.Artist != "Artist" || tags.Album != "Album" {
+		t.Errorf("Title/Artist/Album = %q/%q/%q", tags.Title, tags.Artist, tags.Album)
+	}
+	if tags.Comment != "A comment" {
+		t.Errorf("Comment = %q, want %q", tags.Comment, "A comment")
+	}
+	if tags.Date != "2019-06-01" || tags.Year != 2019 {
+		t.Errorf("Date = %q, Year = %d", tags.Date, tags.Year)
+	}
+	if len(tags.Genres) != 1 || tags.Genres[0] != "Ambient" || tags.TrackNumber != 7 || tags.Encoder != "Lavf58" {
+		t.Errorf("Genres = %v, TrackNumber = %d, Encoder = %q", tags.Genres, tags.TrackNumber, tags.Encoder)
+	}
+	if got := tags.GetFirst("INAM"); got != "Song" {
+		t.Errorf("raw INAM = %q, want Song", got)
+	}
+}
+
+func TestParse_InfoLegacyCharset(t *testing.T) {
+	// "Café" in Latin-1 is not valid UTF-8
+	data := createWAV(fmtChunk(waveFormatPCM, 1, 8000, 8), infoList("INAM", "Caf\xE9"))
+	if got := parseWAV(t, data).Tags.Title; got != "Café" {
+		t.Errorf("Title = %q, want %q", got, "Café")
+	}
+}
+
+func TestParse_ID3Chunk(t *testing.T) {
+	// v2.3 tag with a TIT2 frame
+	tag := []byte("ID3\x03\x00\x00\x00\x00\x00\x14" +
+		"TIT2\x00\x00\x00\x0A\x00\x00\x00ID3 Title")
+	data := createWAV(
+		fmtChunk(waveFormatPCM, 2, 44100, 16),
+		infoList("INAM", "INFO Title", "IART", "INFO Artist"),
+		chunk("data", make([]byte, 16)),
+		chunk("id3 ", tag),
+	)
+	file := parseWAV(t, data)
+
+	if file.Tags.Title != "ID3 Title" {
+		t.Errorf("Title = %q, want the ID3 value", file.Tags.Title)
+	}
+	if file.Tags.Artist != "INFO Artist" {
+		t.Errorf("Artist = %q, want the INFO value filling the gap", file.Tags.Artist)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "metadata" {
+		t.Errorf("expected one metadata warning for the Title conflict, got %v", file.Warnings)
+	}
+}
+
+func TestParse_ID3ChunkReplayGain(t *testing.T) {
+	// v2.3 tag with a TXXX:REPLAYGAIN_TRACK_GAIN frame
+	payload := "\x00REPLAYGAIN_TRACK_GAIN\x00-6.50 dB"
+	frame := "TXXX\x00\x00\x00" + string(rune(len(payload))) + "\x00\x00" + payload
+	tag := "ID3\x03\x00\x00\x00\x00\x00" + string(rune(len(frame))) + frame
+	data := createWAV(
+		fmtChunk(waveFormatPCM, 2, 44100, 16),
+		chunk("data", make([]byte, 16)),
+		chunk("id3 ", []byte(tag)),
+	)
+	file := parseWAV(t, data)
+
+	if rg := file.Audio.ReplayGain; rg == nil || rg.TrackGain != -6.5 {
+		t.Errorf("ReplayGain = %+v, want track gain -6.5 from the id3 chunk", rg)
+	}
+}
+
+func TestParse_ZeroLengthData(t *testing.T) {
+	// A streaming writer that never patched the data size
+	data := createWAV(fmtChunk(waveFormatPCM, 1, 8000, 16), chunk("data", nil))
+	data = append(data, make([]byte, 16000)...)
+	file := parseWAV(t, data)
+
+	if file.Audio.Duration != time.Second {
+		t.Errorf("Duration = %v, want 1s from the bytes that follow", file.Audio.Duration)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "technical" {
+		t.Errorf("expected one technical warning, got %v", file.Warnings)
+	}
+}
+
+func TestParse_Truncated(t *testing.T) {
+	data := createWAV(fmtChunk(waveFormatPCM, 2, 44100, 16), chunk("data", make([]byte, 44100*4)))
+	data = data[:len(data)-44100*2]
+	file := parseWAV(t, data)
+
+	if got := file.Audio.Duration; got < 490*time.Millisecond || got > 510*time.Millisecond {
+		t.Errorf("Duration = %v, want about 0.5s from the bytes present", got)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "technical" {
+		t.Errorf("expected one technical warning, got %v", file.Warnings)
+	}
+}
+
+func TestParse_MissingFmt(t *testing.T) {
+	file := parseWAV(t, createWAV(chunk("data", make([]byte, 16))))
+	if len(file.Warnings) != 1 || file.Audio.SampleRate != 0 {
+		t.Errorf("expected a warning and no audio properties, got %v", file.Warnings)
+	}
+}
+
+func TestParse_NotWAVE(t *testing.T) {
+	data := createWAV()
+	copy(data[8:12], "AVI ")
+	_, err := (&parser{}).Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.avi")
+
+	var corrupted *types.CorruptedFileError
+	if !errors.As(err, &corrupted) {
+		t.Errorf("Parse() error = %v, want CorruptedFileError", err)
+	}
+}
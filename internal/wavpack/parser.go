@@ -0,0 +1,284 @@
+// Package wavpack provides WavPack (.wv) audio file parsing.
+package wavpack
+
+import (
+	"cmp"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/apev2"
+	binutil "github.com/simonhull/audiometa/internal/binary"
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Codec and container name.
+const codecName = "WavPack"
+
+// blockHeaderSize is the size of a WavPack block header.
+const blockHeaderSize = 32
+
+// maxGroupBlocks bounds the walk over the blocks of the first sample group.
+// Each block carries one or two channels, so this covers far more channels
+// than any real file has.
+const maxGroupBlocks = 64
+
+// Block header flag bits.
+const (
+	flagBytesPerSample = 0x3 // Bytes per sample minus one
+	flagMono           = 0x4
+	flagHybrid         = 0x8 // Lossy unless a correction file is present
+	flagFloat          = 0x80
+	flagInitialBlock   = 0x800
+	flagFinalBlock     = 0x1000
+	flagShiftMask      = 0x3E000 // Bits of padding in each sample
+	flagShiftLSB       = 13
+	flagSampleRateMask = 0x7800000
+	flagSampleRateLSB  = 23
+)
+
+// Metadata sub-block IDs, masked with subBlockIDMask.
+const (
+	subBlockIDMask     = 0x3F
+	subBlockChannels   = 0x0D // ID_CHANNEL_INFO
+	subBlockSampleRate = 0x27 // ID_SAMPLE_RATE
+	subBlockLarge      = 0x80 // Size field is 3 bytes instead of 1
+	subBlockOddSize    = 0x40 // Payload is one byte shorter than its words
+)
+
+// sampleRates maps the 4-bit sample rate index in the flags. Index 15
+// means the rate is stored in an ID_SAMPLE_RATE sub-block.
+var sampleRates = [15]int{
+	6000, 8000, 9600, 11025, 12000, 16000, 22050, 24000,
+	32000, 44100, 48000, 64000, 88200, 96000, 192000,
+}
+
+// blockHeader is the fixed header that starts every WavPack block.
+//
+// Block header layout (little-endian):
+//
+//	[4 bytes] "wvpk"
+//	[4 bytes] block size, excluding the first 8 bytes
+//	[2 bytes] stream version
+//	[1 byte]  block index, upper 8 bits
+//	[1 byte]  total samples, upper 8 bits
+//	[4 bytes] total samples, lower 32 bits (all ones if unknown)
+//	[4 bytes] block index, lower 32 bits
+//	[4 bytes] samples in this block
+//	[4 bytes] flags
+//	[4 bytes] CRC
+type blockHeader struct {
+	size         int64 // Whole block, header included
+	totalSamples int64 // -1 if unknown
+	flags        uint32
+}
+
+// parser implements the audiometa.FormatParser interface for WavPack files.
+type parser struct{}
+
+// Parse parses a WavPack file and extracts its audio properties.
+//
+// Audio properties come from the blocks of the first sample group: the
+// initial block through the one flagged final, each carrying one or two
+// channels.
+func (p *parser) Parse(ctx context.Context, r io.ReaderAt, size int64, path string) (*types.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sr := binutil.NewSafeReader(r, size, path)
+
+	first, err := readBlockHeader(sr, 0)
+	if err != nil {
+		return nil, err
+	}
+	if first.flags&flagInitialBlock == 0 {
+		return nil, &types.CorruptedFileError{
+			Path:   path,
+			Reason: "first WavPack block does not start a sample group",
+		}
+	}
+
+	file := &types.File{
+		Path:   path,
+		Format: types.FormatWavPack,
+		Size:   size,
+	}
+	file.Audio.Container = codecName
+	file.Audio.Codec = codecName
+
+	if err := parseAudioInfo(sr, first, file); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "technical",
+			Message: fmt.Sprintf("failed to read WavPack audio properties: %v", err),
+			Err:     err,
+		})
+	}
+
+	// WavPack files are tagged with a trailing APEv2 tag
+	if err := apev2.Parse(sr, size, file); err != nil {
+		file.Warnings = append(file.Warnings, types.Warning{
+			Stage:   "metadata",
+			Message: fmt.Sprintf("failed to parse APEv2 tag: %v", err),
+			Err:     err,
+		})
+	}
+
+	return file, nil
+}
+
+// parseAudioInfo fills file.Audio from the first sample group. The channel
+// count is the sum over the group's blocks unless an ID_CHANNEL_INFO
+// sub-block states it.
+func parseAudioInfo(sr *binutil.SafeReader, first blockHeader, file *types.File) error {
+	flags := first.flags
+	audio := &file.Audio
+
+	audio.Lossless = flags&flagHybrid == 0
+	audio.BitDepth = (int(flags&flagBytesPerSample)+1)*8 - int(flags&flagShiftMask)>>flagShiftLSB
+	audio.SampleFormat = types.SampleSignedInt
+	if flags&flagFloat != 0 {
+		audio.SampleFormat = types.SampleFloat
+	}
+	audio.Endianness = types.EndianLittle
+	if index := (flags & flagSampleRateMask) >> flagSampleRateLSB; int(index) < len(sampleRates) {
+		audio.SampleRate = sampleRates[index]
+	}
+
+	var stated, summed int
+	var err error
+	offset, block := int64(0), first
+	for range maxGroupBlocks {
+		var rate, channels int
+		if rate, channels, err = readSubBlocks(sr, offset, block); err != nil {
+			break
+		}
+		audio.SampleRate = cmp.Or(audio.SampleRate, rate)
+		stated = cmp.Or(stated, channels)
+		if block.flags&flagMono != 0 {
+			summed++
+		} else {
+			summed += 2
+		}
+
+		if block.flags&flagFinalBlock != 0 {
+			break
+		}
+		offset += block.size
+		if block, err = readBlockHeader(sr, offset); err != nil {
+			break
+		}
+	}
+	audio.Channels = cmp.Or(stated, summed)
+
+	if first.totalSamples > 0 && audio.SampleRate > 0 {
+		seconds := float64(first.totalSamples) / float64(audio.SampleRate)
+		audio.Duration = time.Duration(seconds * float64(time.Second))
+		audio.Bitrate = int(float64(file.Size) * 8 / seconds)
+	}
+
+	return err
+}
+
+// readBlockHeader reads and validates the block header at offset.
+func readBlockHeader(sr *binutil.SafeReader, offset int64) (blockHeader, error) {
+	data := make([]byte, blockHeaderSize)
+	if err := sr.ReadAt(data, offset, "WavPack block header"); err != nil {
+		return blockHeader{}, err
+	}
+	if string(data[0:4]) != "wvpk" {
+		return blockHeader{}, &types.CorruptedFileError{
+			Path:   sr.Path(),
+			Offset: offset,
+			Reason: "invalid WavPack block magic",
+		}
+	}
+
+	size := int64(binary.LittleEndian.Uint32(data[4:8])) + 8
+	if size < blockHeaderSize {
+		return blockHeader{}, &types.CorruptedFileError{
+			Path:   sr.Path(),
+			Offset: offset,
+			Reason: fmt.Sprintf("WavPack block size %d is smaller than its header", size),
+		}
+	}
+
+	header := blockHeader{
+		size:         size,
+		totalSamples: -1,
+		flags:        binary.LittleEndian.Uint32(data[24:28]),
+	}
+	if low := binary.LittleEndian.Uint32(data[12:16]); low != 0xFFFFFFFF {
+		header.totalSamples = int64(data[11])<<32 | int64(low)
+	}
+	return header, nil
+}
+
+// readSubBlocks scans the metadata sub-blocks of a block for an explicit
+// sample rate and channel count, returning zero for either when absent.
+//
+// Sub-block layout:
+//
+//	[1 byte]       ID, with large and odd-size flags
+//	[1 or 3 bytes] payload size in 16-bit words
+//	[n bytes]      payload
+func readSubBlocks(sr *binutil.SafeReader, blockOffset int64, block blockHeader) (rate, channels int, err error) {
+	end := blockOffset + block.size
+	offset := blockOffset + blockHeaderSize
+
+	for offset+2 <= end {
+		header := make([]byte, 4)
+		if err := sr.ReadAt(header[:2], offset, "WavPack sub-block header"); err != nil {
+			return 0, 0, err
+		}
+		id := header[0]
+		size := int64(header[1]) * 2
+		offset += 2
+		if id&subBlockLarge != 0 {
+			if err := sr.ReadAt(header[2:4], offset, "WavPack sub-block size"); err != nil {
+				return 0, 0, err
+			}
+			size = (int64(header[1]) | int64(header[2])<<8 | int64(header[3])<<16) * 2
+			offset += 2
+		}
+		length := size
+		if id&subBlockOddSize != 0 {
+			length--
+		}
+		if offset+size > end || length < 0 {
+			return 0, 0, fmt.Errorf("WavPack sub-block 0x%02X at offset %d overruns its block", id, offset)
+		}
+
+		switch id & subBlockIDMask {
+		case subBlockSampleRate:
+			if length >= 3 {
+				data := make([]byte, min(length, 4))
+				if err := sr.ReadAt(data, offset, "WavPack sample rate"); err != nil {
+					return 0, 0, err
+				}
+				for i, b := range data {
+					rate |= int(b) << (8 * i)
+				}
+			}
+		case subBlockChannels:
+			if length >= 1 {
+				count, err := binutil.Read[uint8](sr, offset, "WavPack channel count")
+				if err != nil {
+					return 0, 0, err
+				}
+				channels = int(count)
+			}
+		}
+
+		offset += size
+	}
+
+	return rate, channels, nil
+}
+
+func init() {
+	registry.Register(types.FormatWavPack, &parser{})
+}
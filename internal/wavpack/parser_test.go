@@ -0,0 +1,210 @@
+package wavpack
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// createBlock builds a WavPack block header followed by the given
+// sub-block bytes.
+func createBlock(flags, totalSamples uint32, subBlocks []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("wvpk")
+	binary.Write(buf, binary.LittleEndian, uint32(blockHeaderSize-8+len(subBlocks)))
+	binary.Write(buf, binary.LittleEndian, uint16(0x410)) // Version
+	buf.WriteByte(0)                                      // Block index, upper bits
+	buf.WriteByte(0)                                      // Total samples, upper bits
+	binary.Write(buf, binary.LittleEndian, totalSamples)
+	binary.Write(buf, binary.LittleEndian, uint32(0))     // Block index
+	binary.Write(buf, binary.LittleEndian, uint32(22050)) // Block samples
+	binary.Write(buf, binary.LittleEndian, flags)
+	binary.Write(buf, binary.LittleEndian, uint32(0)) // CRC
+	buf.Write(subBlocks)
+	return buf.Bytes()
+}
+
+// rateIndex returns the flag bits selecting sampleRates[index].
+func rateIndex(index uint32) uint32 {
+	return index << flagSampleRateLSB
+}
+
+func TestParse_AudioInfo(t *testing.T) {
+	const group = flagInitialBlock | flagFinalBlock
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantRate     int
+		wantChannels int
+		wantDepth    int
+		wantFormat   types.SampleFormat
+		wantLossless bool
+		wantDuration time.Duration
+	}{
+		{
+			name:         "16-bit stereo lossless",
+			data:         createBlock(group|1|rateIndex(9), 441000, nil),
+			wantRate:     44100,
+			wantChannels: 2,
+			wantDepth:    16,
+			wantFormat:   types.SampleSignedInt,
+			wantLossless: true,
+			wantDuration: 10 * time.Second,
+		},
+		{
+			name:         "24-bit mono hybrid",
+			data:         createBlock(group|2|flagMono|flagHybrid|rateIndex(13), 96000, nil),
+			wantRate:     96000,
+			wantChannels: 1,
+			wantDepth:    24,
+			wantFormat:   types.SampleSignedInt,
+			wantDuration: time.Second,
+		},
+		{
+			name:         "20-bit packed in 3 bytes",
+			data:         createBlock(group|2|4<<flagShiftLSB|rateIndex(10), 48000, nil),
+			wantRate:     48000,
+			wantChannels: 2,
+			wantDepth:    20,
+			wantFormat:   types.SampleSignedInt,
+			wantLossless: true,
+			wantDuration: time.Second,
+		},
+		{
+			name:         "float",
+			data:         createBlock(group|3|flagFloat|rateIndex(10), 48000, nil),
+			wantRate:     48000,
+			wantChannels: 2,
+			wantDepth:    32,
+			wantFormat:   types.SampleFloat,
+			wantLossless: true,
+			wantDuration: time.Second,
+		},
+		{
+			name: "custom rate and channel info sub-blocks",
+			data: createBlock(group|1|rateIndex(15), 0xFFFFFFFF, []byte{
+				subBlockSampleRate | subBlockOddSize, 2, 0x00, 0x77, 0x01, 0, // 96000
+				subBlockChannels | subBlockOddSize, 1, 6, 0,
+			}),
+			wantRate:     96000,
+			wantChannels: 6,
+			wantDepth:    16,
+			wantFormat:   types.SampleSignedInt,
+			wantLossless: true,
+		},
+		{
+			name: "multichannel group",
+			data: append(append(
+				createBlock(flagInitialBlock|1|rateIndex(9), 44100, nil),
+				createBlock(1|rateIndex(9), 44100, nil)...),
+				createBlock(flagFinalBlock|1|flagMono|rateIndex(9), 44100, nil)...),
+			wantRate:     44100,
+			wantChannels: 5,
+			wantDepth:    16,
+			wantFormat:   types.SampleSignedInt,
+			wantLossless: true,
+			wantDuration: time.Second,
+		},
+	}
+
+	p := &parser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := p.Parse(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "test.wv")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(file.Warnings) > 0 {
+				t.Errorf("unexpected warnings: %v", file.Warnings)
+			}
+
+			audio := file.Audio
+			if audio.SampleRate != tt.wantRate {
+				t.Errorf("SampleRate = %d, want %d", audio.SampleRate, tt.wantRate)
+			}
+			if audio.Channels != tt.wantChannels {
+				t.Errorf("Channels = %d, want %d", audio.Channels, tt.wantChannels)
+			}
+			if audio.BitDepth != tt.wantDepth {
+				t.Errorf("BitDepth = %d, want %d", audio.BitDepth, tt.wantDepth)
+			}
+			if audio.SampleFormat != tt.wantFormat {
+				t.Errorf("SampleFormat = %v, want %v", audio.SampleFormat, tt.wantFormat)
+			}
+			if audio.Lossless != tt.wantLossless {
+				t.Errorf("Lossless = %v, want %v", audio.Lossless, tt.wantLossless)
+			}
+			if audio.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", audio.Duration, tt.wantDuration)
+			}
+			if audio.Codec != codecName {
+				t.Errorf("Codec = %q, want %q", audio.Codec, codecName)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad magic", append([]byte("wvpX"), make([]byte, 28)...)},
+		{"truncated header", []byte("wvpk\x00\x00")},
+		{"not an initial block", createBlock(flagFinalBlock|1, 44100, nil)},
+	}
+
+	p := &parser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.Parse(context.Background(), bytes.NewReader(tt.data), int64(len(tt.data)), "test.wv"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParse_TruncatedGroup(t *testing.T) {
+	// The group never reaches its final block
+	data := createBlock(flagInitialBlock|1|rateIndex(9), 44100, nil)
+
+	file, err := (&parser{}).Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.wv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(file.Warnings) != 1 || file.Warnings[0].Stage != "technical" {
+		t.Errorf("expected one technical warning, got %v", file.Warnings)
+	}
+	if file.Audio.Channels != 2 || file.Audio.SampleRate != 44100 {
+		t.Errorf("expected properties from the first block, got %+v", file.Audio)
+	}
+}
+
+func TestParse_APEv2Tag(t *testing.T) {
+	items := &bytes.Buffer{}
+	binary.Write(items, binary.LittleEndian, uint32(len("Song")))
+	binary.Write(items, binary.LittleEndian, uint32(0))
+	items.WriteString("Title\x00Song")
+
+	footer := &bytes.Buffer{}
+	footer.WriteString("APETAGEX")
+	binary.Write(footer, binary.LittleEndian, [4]uint32{2000, uint32(items.Len() + 32), 1, 0})
+	footer.Write(make([]byte, 8))
+
+	data := createBlock(flagInitialBlock|flagFinalBlock|1|rateIndex(9), 44100, nil)
+	data = append(data, items.Bytes()...)
+	data = append(data, footer.Bytes()...)
+
+	file, err := (&parser{}).Parse(context.Background(), bytes.NewReader(data), int64(len(data)), "test.wv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Tags.Title != "Song" || len(file.Warnings) > 0 {
+		t.Errorf("Title = %q, warnings %v; want the APEv2 title", file.Tags.Title, file.Warnings)
+	}
+}
@@ -0,0 +1,40 @@
+package audiometa
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// RestoreModTime resets the file's modification and access times to the
+// values they had when Open read it.
+//
+// Rewriting tags bumps the mtime, which makes incremental library scanners
+// and backup tools treat the file as changed audio. Call RestoreModTime
+// after modifying the file with another tool to keep the original
+// timestamp; File.SavePreservingModTime, or Save with WithPreserveModTime,
+// does this automatically. Where the platform's access time isn't
+// available, it is left as it is.
+//
+// Returns an error for a File that was not produced by Open.
+//
+// Example:
+//
+//	file, err := audiometa.Open("song.flac")
+//	...
+//	if err := exec.Command("metaflac", "--set-tag=GENRE=Jazz", file.Path).Run(); err != nil {
+//		return err
+//	}
+//	if err := file.RestoreModTime(); err != nil {
+//		return err
+//	}
+func (f *File) RestoreModTime() error {
+	if f.modTime.IsZero() {
+		return errors.New("restore mod time: file was not opened from a path")
+	}
+	// A zero access time tells Chtimes to leave it unchanged
+	if err := os.Chtimes(f.Path, f.accessTime, f.modTime); err != nil {
+		return fmt.Errorf("restore mod time: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package audiometa_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+)
+
+func TestFile_RestoreModTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.m4b")
+	if err := os.WriteFile(path, createSimpleM4B(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	original := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, original, original); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := audiometa.Open(path, audiometa.WithPreserveModTime())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+
+	// Simulate a write by another tool
+	if err := os.Chtimes(path, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.RestoreModTime(); err != nil {
+		t.Fatalf("RestoreModTime failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(original) {
+		t.Errorf("mod time = %v, want %v", info.ModTime(), original)
+	}
+}
+
+func TestFile_RestoreModTime_NotOpened(t *testing.T) {
+	file := &audiometa.File{}
+	if err := file.RestoreModTime(); err == nil {
+		t.Error("expected error for a File not produced by Open")
+	}
+}
@@ -1,5 +1,10 @@
 package audiometa
 
+import (
+	"log/slog"
+	"slices"
+)
+
 // Option configures behavior when opening audio files.
 //
 // Options use the functional options pattern for clean, extensible APIs.
@@ -14,10 +19,22 @@ type Option func(*openOptions)
 
 // openOptions holds configuration for opening files.
 type openOptions struct {
-	strictParsing  bool // Fail on any warning
-	preloadArtwork bool // Load artwork immediately instead of lazily
-	ignoreWarnings bool // Suppress all warnings
-	maxArtworkSize int  // Maximum artwork size in bytes (0 = no limit)
+	strictParsing  bool     // Fail on any warning
+	strictStages   []string // Fail on warnings from these stages
+	preloadArtwork bool     // Load artwork immediately instead of lazily
+	ignoreWarnings bool     // Suppress all warnings
+	maxArtworkSize int      // Maximum artwork size in bytes (0 = no limit)
+	maxWarnings    int      // Maximum distinct warnings kept (0 = no limit)
+	maxFileSize    int64    // Maximum file size in bytes (0 = no limit)
+	streamSize     int64    // Length of an OpenStream source (0 = unknown)
+
+	artworkMetadataOnly bool         // ExtractArtwork returns Data == nil
+	legacyCharset       Charset      // Decoding for ID3 encoding-0 and ID3v1 text
+	artistSeparators    []string     // Splitting of single-string artist fields (nil = defaults)
+	preserveModTime     bool         // Saving restores the mtime recorded at Open
+	rawTags             bool         // Parsers record every tag item for File.RawTags
+	logger              *slog.Logger // Debug output from detection and parsing (nil = none)
+	chaptersOnly        bool         // Parsers that can read only chapters do (ParseChapters)
 }
 
 // defaultOptions returns the default configuration.
@@ -27,6 +44,9 @@ func defaultOptions() *openOptions {
 		preloadArtwork: false,
 		ignoreWarnings: false,
 		maxArtworkSize: 0, // No limit
+		maxWarnings:    0, // No limit
+		maxFileSize:    0, // No limit
+		legacyCharset:  Latin1,
 	}
 }
 
@@ -36,7 +56,11 @@ func defaultOptions() *openOptions {
 // like invalid tag encodings or corrupted artwork, returning warnings
 // alongside the parsed data.
 //
-// With strict parsing enabled, any warning becomes a fatal error.
+// With strict parsing enabled, any warning becomes a fatal error, and
+// implausible technical values reported by AudioInfo.Validate count as
+// warnings too. SeverityInfo notes, which describe something unusual but
+// harmless, stay in File.Warnings and never fail the open. It is
+// WithStrictStages with every stage named.
 //
 // Example:
 //
@@ -48,6 +72,30 @@ func WithStrictParsing() Option {
 	}
 }
 
+// WithStrictStages treats warnings from the given stages as fatal errors
+// and keeps the others as warnings.
+//
+// Stages are the Warning.Stage values: "detect", "metadata", "technical",
+// "chapters", "artwork" and "validation". Naming "validation" also runs
+// AudioInfo.Validate, as WithStrictParsing does. Repeated calls add to the
+// stages already named. As with WithStrictParsing, SeverityInfo notes are
+// never fatal.
+//
+// Example:
+//
+//	// Reject corrupted tags, but accept odd technical values
+//	file, err := audiometa.Open("song.mp3", audiometa.WithStrictStages("metadata", "chapters"))
+func WithStrictStages(stages ...string) Option {
+	return func(o *openOptions) {
+		o.strictStages = append(o.strictStages, stages...)
+	}
+}
+
+// strict reports whether warnings from stage are fatal.
+func (o *openOptions) strict(stage string) bool {
+	return o.strictParsing || slices.Contains(o.strictStages, stage)
+}
+
 // WithArtworkPreload loads artwork immediately instead of lazily.
 //
 // By default, artwork is only loaded when ExtractArtwork() is called.
@@ -86,8 +134,10 @@ func WithIgnoreWarnings() Option {
 
 // WithMaxArtworkSize sets a maximum size limit for artwork extraction.
 //
-// If artwork exceeds this size (in bytes), it will be skipped with a warning.
-// This protects against excessively large embedded images.
+// ExtractArtwork skips any image whose declared data length exceeds this
+// size (in bytes), adding a warning to File.Warnings, and returns the
+// others. The declared length is checked before the image is read, so this
+// protects against excessively large embedded images.
 //
 // Default is 0 (no limit).
 //
@@ -102,3 +152,186 @@ func WithMaxArtworkSize(bytes int) Option {
 		o.maxArtworkSize = bytes
 	}
 }
+
+// WithMaxWarnings caps the number of distinct warnings kept in File.Warnings.
+//
+// Identical warnings (same stage and message) are always collapsed into a
+// single entry with Warning.Count set to the number of occurrences. This
+// option additionally bounds the list for pathological files that produce
+// many different warnings; warnings beyond the first n are dropped.
+//
+// Default is 0 (no limit).
+//
+// Example:
+//
+//	file, err := audiometa.Open("damaged.mp3", audiometa.WithMaxWarnings(20))
+//	for _, w := range file.Warnings {
+//	    fmt.Println(w) // "metadata: bad frame (repeated 812 times)"
+//	}
+func WithMaxWarnings(n int) Option {
+	return func(o *openOptions) {
+		o.maxWarnings = n
+	}
+}
+
+// WithMaxFileSize rejects files larger than the given size in bytes.
+//
+// Open returns a *FileTooLargeError before any parsing when the file's
+// size exceeds the cap. Since parsers never read past the file's size,
+// even when a structure in the file claims more, this bounds every read
+// as well. Use this to bound resource use when processing untrusted
+// uploads.
+//
+// Default is 0 (no limit).
+//
+// Example:
+//
+//	// Refuse anything over 2GB
+//	file, err := audiometa.Open(path, audiometa.WithMaxFileSize(2<<30))
+//	var tooLarge *audiometa.FileTooLargeError
+//	if errors.As(err, &tooLarge) {
+//	    return fmt.Errorf("upload rejected: %w", err)
+//	}
+func WithMaxFileSize(bytes int64) Option {
+	return func(o *openOptions) {
+		o.maxFileSize = bytes
+	}
+}
+
+// WithStreamSize tells OpenStream the length of its stream, such as an
+// HTTP Content-Length or the ContentLength of an S3 object.
+//
+// Parsers need the length of their input, so without it OpenStream reads
+// the whole stream to learn it. With it, the stream is read only as far
+// as the metadata goes, which for FLAC or a faststart M4B audiobook is
+// the first few megabytes of it. A stream that ends before size is an
+// error; a size of 0 or less, like the -1 of an unknown Content-Length, is
+// ignored. Open and OpenReaderAt ignore this option.
+//
+// Example:
+//
+//	resp, err := http.Get(url)
+//	...
+//	file, err := audiometa.OpenStream(resp.Body, audiometa.FormatUnknown,
+//	    audiometa.WithStreamSize(resp.ContentLength))
+func WithStreamSize(size int64) Option {
+	return func(o *openOptions) {
+		o.streamSize = size
+	}
+}
+
+// WithArtworkMetadataOnly makes ExtractArtwork describe images without
+// loading them.
+//
+// Each returned Artwork has MIMEType, Type, Description, Width, Height and
+// Size set, but Data is nil. Where the container doesn't record dimensions
+// they are read from the image header (JPEG SOF, PNG IHDR) rather than the
+// whole payload. Use this when cataloging many files, where keeping every
+// cover in memory would dominate memory use.
+//
+// Example:
+//
+//	file, err := audiometa.Open("song.m4a", audiometa.WithArtworkMetadataOnly())
+//	artwork, _ := file.ExtractArtwork()
+//	for _, a := range artwork {
+//	    fmt.Println(a) // "Front cover (1400x1400 JPEG, 240KB)"
+//	}
+func WithArtworkMetadataOnly() Option {
+	return func(o *openOptions) {
+		o.artworkMetadataOnly = true
+	}
+}
+
+// WithLegacyCharset sets how legacy 8-bit tag text is decoded.
+//
+// ID3v2 text frames with encoding 0 and ID3v1 fields are ISO-8859-1 by
+// specification, but many Windows taggers wrote Windows-1252 instead, so
+// smart quotes and dashes (bytes 0x80-0x9F) come out as control characters
+// when decoded as Latin-1. Pass Windows1252 for libraries tagged that way.
+// Encoding-0 ID3v2 text that is valid UTF-8 is read as UTF-8 whatever the
+// charset, since taggers often mislabel it.
+//
+// Default is Latin1.
+//
+// Example:
+//
+//	file, err := audiometa.Open("song.mp3", audiometa.WithLegacyCharset(audiometa.Windows1252))
+//	fmt.Println(file.Tags.Title) // “Quoted” rather than \u0093Quoted\u0094
+func WithLegacyCharset(charset Charset) Option {
+	return func(o *openOptions) {
+		o.legacyCharset = charset
+	}
+}
+
+// WithArtistSeparators sets the separators used to split a single-string
+// artist field into Tags.Artists.
+//
+// M4A stores all artists in one ©ART string, so "A; B" becomes the two
+// Artists entries "A" and "B" while Tags.Artist keeps the full display
+// string. The defaults are ";" and " / "; a bare "/" is not split so names
+// such as "AC/DC" stay whole. Calling WithArtistSeparators() with no
+// arguments disables splitting.
+//
+// Example:
+//
+//	// Also split iTunes-style "A, B & C" lists
+//	file, err := audiometa.Open("song.m4a", audiometa.WithArtistSeparators(";", " / ", ", ", " & "))
+func WithArtistSeparators(separators ...string) Option {
+	return func(o *openOptions) {
+		o.artistSeparators = append([]string{}, separators...)
+	}
+}
+
+// WithPreserveModTime keeps the file's modification time when it is saved.
+//
+// The mtime seen at Open is restored after each File.Save, as if by
+// calling File.RestoreModTime, so re-tagging doesn't make library scanners
+// and backup tools re-sync the file.
+//
+// Example:
+//
+//	file, err := audiometa.Open("song.flac", audiometa.WithPreserveModTime())
+func WithPreserveModTime() Option {
+	return func(o *openOptions) {
+		o.preserveModTime = true
+	}
+}
+
+// WithRawTags records every tag item as stored, for File.RawTags.
+//
+// Tags maps the items audiometa understands and keeps the rest as text.
+// With this option each ID3v2 frame, MP4 atom and Vorbis comment is also
+// kept with its original key and bytes, binary items included, for
+// inspecting what the Tags fields leave out. Pictures are read in full,
+// so this costs the memory of all embedded artwork.
+//
+// Example:
+//
+//	file, err := audiometa.Open("song.mp3", audiometa.WithRawTags())
+//	for _, frame := range file.RawTags()["PRIV"] {
+//	    fmt.Printf("%d bytes\n", len(frame.Value))
+//	}
+func WithRawTags() Option {
+	return func(o *openOptions) {
+		o.rawTags = true
+	}
+}
+
+// WithLogger sends debug output from detection and parsing to logger.
+//
+// At slog.LevelDebug, parsers log the structures they walk (FLAC metadata
+// blocks, ID3v2 frames, MP4 atoms, Ogg pages, RIFF and DSF chunks,
+// Matroska elements) and every warning, with the attributes
+// "format", "key" (block type, frame ID, atom or chunk name), "offset"
+// and "size". Use it to see why a particular file parses as it does.
+// Without this option nothing is logged and logging costs nothing.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	file, err := audiometa.Open("book.m4b", audiometa.WithLogger(logger))
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *openOptions) {
+		o.logger = logger
+	}
+}
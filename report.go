@@ -0,0 +1,247 @@
+package audiometa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// ReportVersion is the version of the MetadataReport shape. It is bumped
+// whenever a field is renamed, removed or changes type; new fields do not
+// change it.
+const ReportVersion = 2
+
+// MetadataReport is the canonical machine-readable view of a File.
+//
+// It aggregates tags, technical info, chapters, artwork summaries and
+// warnings into a stable structure with documented JSON field names, so
+// CLIs and services don't each invent their own. Artwork is summarized
+// without image bytes.
+type MetadataReport struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	Format  string `json:"format"`
+	Size    int64  `json:"size"`
+
+	// Tags holds every non-empty standard field, keyed by the names
+	// accepted by Tags.Field. Multi-value fields are string slices,
+	// Comments and SyncedLyrics are slices of objects ("text", "language"
+	// and "description"; "time", "time_text" and "text"), and the rest
+	// are the text Tags.Field returns.
+	Tags map[string]any `json:"tags"`
+
+	// URLs holds link frames keyed as in Tags.URLs.
+	URLs map[string]string `json:"urls,omitempty"`
+
+	// Raw holds the unmapped, format-specific tags from Tags.All.
+	Raw map[string][]string `json:"raw,omitempty"`
+
+	Audio         AudioReport      `json:"audio"`
+	Chapters      []Chapter        `json:"chapters"`
+	ChapterSource string           `json:"chapter_source,omitempty"`
+	ChapterTree   []Chapter        `json:"chapter_tree,omitempty"`
+	Artwork       []ArtworkSummary `json:"artwork"`
+	Warnings      []WarningReport  `json:"warnings"`
+
+	// HasArtwork reports File.HasArtwork. It is set even when Artwork is
+	// empty because the images were not loaded, as with File.MarshalJSON.
+	HasArtwork bool `json:"has_artwork"`
+}
+
+// AudioReport is the technical section of a MetadataReport. It embeds
+// AudioInfo and is encoded by AudioInfo.MarshalJSON, so the two share one
+// JSON schema: durations in nanoseconds, with "duration_text" repeating
+// them for people reading the output.
+type AudioReport struct {
+	AudioInfo
+}
+
+// ArtworkSummary describes one embedded image. Data is only filled in
+// with WithArtworkData, and is base64-encoded in JSON.
+type ArtworkSummary struct {
+	Type        string `json:"type"`
+	MIMEType    string `json:"mime_type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+	Size        int    `json:"size"`
+	Data        []byte `json:"data,omitempty"`
+}
+
+// WarningReport is the serializable form of a Warning.
+type WarningReport struct {
+	Stage    string `json:"stage"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	Offset   int64  `json:"offset,omitempty"`
+	Count    int    `json:"count,omitempty"`
+}
+
+// ReportOption configures File.Report.
+type ReportOption func(*reportOptions)
+
+// reportOptions holds configuration for building a MetadataReport.
+type reportOptions struct {
+	artworkData   bool // Include image bytes in artwork summaries
+	loadedArtwork bool // Summarize only artwork already loaded, reading nothing
+}
+
+// WithArtworkData includes each image's bytes in the report's artwork
+// summaries, which JSON encodes as base64. Reports leave them out by
+// default, since they dwarf the rest of the metadata.
+//
+// Example:
+//
+//	data, err := json.Marshal(file.Report(audiometa.WithArtworkData()))
+func WithArtworkData() ReportOption {
+	return func(o *reportOptions) {
+		o.artworkData = true
+	}
+}
+
+// Report builds the structured MetadataReport for the file.
+//
+// Artwork summaries are taken from loaded artwork, or else described from
+// the image headers where the parser can, without reading the image data.
+// Report leaves the file as it was: nothing is cached and no warnings are
+// added to it. If extraction fails the error is recorded as an "artwork"
+// warning in the report rather than returned.
+//
+// Example:
+//
+//	report := file.Report()
+//	fmt.Println(report.Tags["Title"], len(report.Artwork))
+func (f *File) Report(opts ...ReportOption) MetadataReport {
+	var options reportOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	report := MetadataReport{
+		Version:       ReportVersion,
+		Path:          f.Path,
+		Format:        f.Format.String(),
+		Size:          f.Size,
+		Tags:          types.TagFields(&f.Tags),
+		URLs:          maps.Clone(f.Tags.URLs),
+		Audio:         AudioReport{AudioInfo: f.Audio},
+		Chapters:      make([]Chapter, len(f.Chapters)),
+		ChapterSource: f.ChapterSource,
+		ChapterTree:   slices.Clone(f.ChapterTree),
+		Artwork:       []ArtworkSummary{},
+		Warnings:      make([]WarningReport, 0, len(f.Warnings)),
+		HasArtwork:    f.HasArtwork(),
+	}
+	copy(report.Chapters, f.Chapters)
+
+	// Copies, so the report shares no memory with the file
+	if rg := f.Audio.ReplayGain; rg != nil {
+		rgCopy := *rg
+		report.Audio.ReplayGain = &rgCopy
+	}
+	if loop := f.Audio.LoopInfo; loop != nil {
+		loopCopy := *loop
+		report.Audio.LoopInfo = &loopCopy
+	}
+
+	for key, values := range f.Tags.All() {
+		if report.Raw == nil {
+			report.Raw = make(map[string][]string)
+		}
+		report.Raw[key] = slices.Clone(values)
+	}
+
+	for _, w := range f.Warnings {
+		report.Warnings = append(report.Warnings, WarningReport{
+			Stage:    w.Stage,
+			Message:  w.Message,
+			Severity: w.Severity.String(),
+			Offset:   w.Offset,
+			Count:    w.Count,
+		})
+	}
+
+	artwork := f.artwork
+	if !options.loadedArtwork {
+		var err error
+		if artwork, err = f.reportArtwork(options.artworkData); err != nil {
+			report.Warnings = append(report.Warnings, WarningReport{
+				Stage:    "artwork",
+				Message:  err.Error(),
+				Severity: SeverityWarning.String(),
+			})
+		}
+	}
+	for _, a := range artwork {
+		summary := ArtworkSummary{
+			Type:        a.Type.String(),
+			MIMEType:    a.MIMEType,
+			Description: a.Description,
+			Width:       a.Width,
+			Height:      a.Height,
+			Size:        a.Size,
+		}
+		if options.artworkData {
+			summary.Data = a.Data
+		}
+		report.Artwork = append(report.Artwork, summary)
+	}
+
+	return report
+}
+
+// reportArtwork returns the images Report summarizes, without touching
+// the file's artwork cache or warnings: the loaded artwork if any, else
+// descriptions from the parser's ArtworkMetadataExtractor. Image data is
+// only read with withData, or from parsers that cannot describe images
+// from their headers, and is then dropped unless withData is set. Images
+// over the WithMaxArtworkSize limit are left out, as in ExtractArtwork.
+func (f *File) reportArtwork(withData bool) ([]Artwork, error) {
+	if f.artwork != nil {
+		return f.artwork, nil
+	}
+
+	ctx := registry.WithLegacyCharset(context.Background(), f.legacyCharset)
+	ctx = registry.WithMaxArtworkSize(ctx, f.maxArtworkSize)
+
+	var images []Artwork
+	var err error
+	if extractor, ok := f.parser.(ArtworkMetadataExtractor); ok && !withData {
+		images, err = extractor.ExtractArtworkMetadata(ctx, f.reader, f.Size, f.Path)
+	} else if extractor, ok := f.parser.(ArtworkExtractor); ok {
+		images, err = extractor.ExtractArtwork(ctx, f.reader, f.Size, f.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extract artwork: %w", err)
+	}
+
+	images = slices.DeleteFunc(images, func(a Artwork) bool {
+		return f.maxArtworkSize > 0 && a.Size > f.maxArtworkSize
+	})
+	for i := range images {
+		if images[i].Size == 0 {
+			images[i].Size = len(images[i].Data)
+		}
+		if !withData {
+			images[i].Data = nil
+		}
+	}
+	return images, nil
+}
+
+// MarshalJSON encodes the file as its MetadataReport, without artwork
+// data. The runtime state of File (reader, parser, caches) is never
+// encoded.
+//
+// Marshalling reads nothing from the file: artwork is summarized only if
+// it was already loaded (WithArtworkPreload, ExtractArtwork), and
+// "has_artwork" reports its presence either way. Use Report for summaries
+// that load the artwork if needed.
+func (f *File) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Report(func(o *reportOptions) { o.loadedArtwork = true }))
+}
@@ -0,0 +1,194 @@
+package audiometa_test
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestFile_Report(t *testing.T) {
+	file := &audiometa.File{File: types.File{
+		Path:   "song.flac",
+		Format: types.FormatFLAC,
+		Size:   1234,
+		Tags: types.Tags{
+			Title:   "Song",
+			Artists: []string{"A", "B"},
+		},
+		Audio: types.AudioInfo{
+			Codec:      "FLAC",
+			Duration:   3 * time.Second,
+			Lossless:   true,
+			ReplayGain: &types.ReplayGainInfo{TrackGain: -6.5},
+		},
+		Chapters: []types.Chapter{{Index: 1, Title: "Intro"}},
+		Warnings: []types.Warning{{Stage: "metadata", Message: "odd tag", Offset: 42}},
+	}}
+	file.Tags.Set("CUSTOM", "value")
+
+	report := file.Report()
+
+	if report.Version != audiometa.ReportVersion {
+		t.Errorf("Version = %d, want %d", report.Version, audiometa.ReportVersion)
+	}
+	if report.Format != "FLAC" {
+		t.Errorf("Format = %q, want FLAC", report.Format)
+	}
+	if artists, _ := report.Tags["Artists"].([]string); report.Tags["Title"] != "Song" || !slices.Equal(artists, []string{"A", "B"}) {
+		t.Errorf("Tags = %v", report.Tags)
+	}
+	if _, ok := report.Tags["Album"]; ok {
+		t.Error("empty fields should be omitted from Tags")
+	}
+	if got := report.Raw["CUSTOM"]; len(got) != 1 || got[0] != "value" {
+		t.Errorf("Raw[CUSTOM] = %v", got)
+	}
+	if report.Audio.Duration != 3*time.Second || !report.Audio.Lossless {
+		t.Errorf("Audio = %+v", report.Audio)
+	}
+	if report.Audio.ReplayGain == nil || report.Audio.ReplayGain.TrackGain != -6.5 {
+		t.Errorf("ReplayGain = %+v", report.Audio.ReplayGain)
+	}
+	if len(report.Chapters) != 1 || report.Chapters[0].Title != "Intro" {
+		t.Errorf("Chapters = %v", report.Chapters)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Offset != 42 {
+		t.Errorf("Warnings = %v", report.Warnings)
+	}
+
+	// The audio section is AudioInfo's own encoding
+	reportAudio, err := json.Marshal(report.Audio)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if fileAudio, _ := json.Marshal(file.Audio); string(reportAudio) != string(fileAudio) {
+		t.Errorf("report audio = %s, want %s", reportAudio, fileAudio)
+	}
+	if !strings.Contains(string(reportAudio), `"duration_text":"3s"`) {
+		t.Errorf("report audio = %s, want duration_text 3s", reportAudio)
+	}
+
+	// Mutating the report must not affect the file
+	report.Audio.ReplayGain.TrackGain = 0
+	report.Chapters[0].Title = "changed"
+	report.Raw["CUSTOM"][0] = "changed"
+	if file.Chapters[0].Title != "Intro" || file.Tags.GetFirst("CUSTOM") != "value" || file.Audio.ReplayGain.TrackGain != -6.5 {
+		t.Error("report shares memory with the file")
+	}
+}
+
+func TestFile_MarshalJSON(t *testing.T) {
+	file := &audiometa.File{File: types.File{
+		Format: types.FormatMP3,
+		Tags:   types.Tags{Title: "Song"},
+	}}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	for _, key := range []string{"version", "path", "format", "size", "tags", "audio", "chapters", "artwork", "warnings"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("missing key %q in %s", key, data)
+		}
+	}
+	for _, key := range []string{"File", "Tags", "Audio"} {
+		if _, ok := decoded[key]; ok {
+			t.Errorf("unexpected struct field %q leaked into JSON", key)
+		}
+	}
+}
+
+func TestFile_MarshalJSON_NoArtworkIO(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+
+	// Any read would now fail and show up as an artwork warning
+	file.Close()
+
+	var report audiometa.MetadataReport
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !report.HasArtwork || len(report.Artwork) != 0 || len(report.Warnings) != 0 {
+		t.Errorf("report = %s, want has_artwork without summaries or warnings", data)
+	}
+}
+
+func TestFile_MarshalJSON_LoadedArtwork(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover),
+		audiometa.WithArtworkPreload())
+
+	var report audiometa.MetadataReport
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(report.Artwork) != 1 || report.Artwork[0].Data != nil {
+		t.Errorf("artwork = %v, want one summary of the preloaded image without data", report.Artwork)
+	}
+}
+
+func TestFile_Report_ArtworkData(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+
+	if report := file.Report(); len(report.Artwork) != 1 || report.Artwork[0].Data != nil {
+		t.Errorf("default report artwork = %v, want one summary without data", report.Artwork)
+	}
+
+	report := file.Report(audiometa.WithArtworkData())
+	if len(report.Artwork) != 1 || string(report.Artwork[0].Data) != "\x89PNG" {
+		t.Fatalf("report artwork = %v, want the image bytes", report.Artwork)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"data":"iVBORw=="`) {
+		t.Errorf("expected base64 image data in %s", data)
+	}
+}
+
+func TestFile_Report_LeavesFileUnchanged(t *testing.T) {
+	file := openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover, audiometa.ArtworkBackCover),
+		audiometa.WithMaxArtworkSize(1))
+
+	report := file.Report(audiometa.WithArtworkData())
+	if len(report.Artwork) != 0 {
+		t.Errorf("report artwork = %v, want images over the limit left out", report.Artwork)
+	}
+	if len(file.Warnings) != 0 {
+		t.Errorf("Report added file warnings: %v", file.Warnings)
+	}
+
+	file = openTestFile(t, "song.flac", createFLACWithPictures(audiometa.ArtworkFrontCover))
+	if report := file.Report(); len(report.Artwork) != 1 || report.Artwork[0].Size != 4 {
+		t.Fatalf("report artwork = %v, want one 4-byte summary", report.Artwork)
+	}
+
+	// Nothing was cached, so extracting after Close finds no images
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if artwork, _ := file.ExtractArtwork(); len(artwork) != 0 {
+		t.Errorf("ExtractArtwork after Close = %v, want Report to have left the cache empty", artwork)
+	}
+}
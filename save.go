@@ -0,0 +1,212 @@
+package audiometa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/simonhull/audiometa/internal/registry"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// TagWriter is an alias to registry.TagWriter.
+// Parsers implementing it support File.Save.
+type TagWriter = registry.TagWriter
+
+// ArtworkWriter is an alias to registry.ArtworkWriter.
+// Parsers implementing it can save artwork changed with SetArtwork,
+// AddArtwork or RemoveArtwork.
+type ArtworkWriter = registry.ArtworkWriter
+
+// Save writes f.Tags back to the file at f.Path.
+//
+// Standard fields are written under each format's canonical keys; raw tags
+// set with Tags.Set are written for keys no standard field covers. Other
+// metadata (stream info, pictures, seek tables, cue sheets) and the audio
+// data are kept byte for byte.
+//
+// When the new tags fit in the space the old ones and their padding used,
+// the file is patched in place. Otherwise it is rewritten to a temporary
+// file next to the original, which then replaces it, so a failed save
+// leaves the original intact; a "write" warning is added to f.Warnings in
+// that case, since the audio data moved. With WithPreserveModTime the
+// original modification time is restored afterwards.
+//
+// Artwork changed with SetArtwork, AddArtwork or RemoveArtwork replaces the
+// embedded images. FLAC stores them as PICTURE blocks, and MP3 as ID3v2
+// APIC frames.
+//
+// Tags are currently written for FLAC only, and artwork for FLAC and MP3.
+// Other formats return an UnsupportedFormatError, as does an MP3 file
+// whose tags were changed; nothing is saved in that case, rather than
+// silently dropping part of the change.
+//
+// Example:
+//
+//	file, err := audiometa.Open("song.flac")
+//	if err != nil {
+//		return err
+//	}
+//	defer file.Close()
+//	file.Tags.Genres = []string{"Jazz"}
+//	file.Tags.Set("MOOD", "Mellow")
+//	if err := file.Save(); err != nil {
+//		return err
+//	}
+func (f *File) Save() error {
+	if err := f.save(context.Background()); err != nil {
+		return err
+	}
+	if f.preserveModTime {
+		return f.RestoreModTime()
+	}
+	return nil
+}
+
+// SavePreservingModTime is like Save but always restores the modification
+// time the file had when it was opened, as if WithPreserveModTime had been
+// given to Open.
+func (f *File) SavePreservingModTime() error {
+	if err := f.save(context.Background()); err != nil {
+		return err
+	}
+	return f.RestoreModTime()
+}
+
+func (f *File) save(ctx context.Context) error {
+	if f.reader == nil || f.modTime.IsZero() {
+		return errors.New("save: file was not opened from a path")
+	}
+	edit, err := f.planSave(ctx)
+	if err != nil {
+		return err
+	}
+
+	if edit.InPlace() {
+		err = patchFile(f.Path, edit)
+	} else {
+		err = f.rewriteFile(edit)
+	}
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+
+	f.Warnings = append(f.Warnings, edit.Warnings...)
+	f.artworkDirty = false
+	return nil
+}
+
+// planSave asks the parser for the edit that stores f.Tags and, if it was
+// changed, f's artwork.
+func (f *File) planSave(ctx context.Context) (*registry.TagEdit, error) {
+	tagWriter, writesTags := f.parser.(TagWriter)
+	unsupported := func(what string) error {
+		return &types.UnsupportedFormatError{
+			Path:   f.Path,
+			Reason: fmt.Sprintf("writing %s is not supported for %s", what, f.Format),
+		}
+	}
+
+	if !f.artworkDirty {
+		if !writesTags {
+			return nil, unsupported("tags")
+		}
+		edit, err := tagWriter.WriteTags(ctx, f.reader, f.Size, f.Path, &f.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("save: %w", err)
+		}
+		return edit, nil
+	}
+
+	artWriter, ok := f.parser.(ArtworkWriter)
+	if !ok {
+		return nil, unsupported("artwork")
+	}
+	tags := &f.Tags
+	if !writesTags {
+		// Only the artwork can be saved; refuse rather than drop tag edits
+		if f.savedTags == nil || !f.Tags.Equal(f.savedTags) {
+			return nil, unsupported("tags")
+		}
+		tags = nil
+	}
+	edit, err := artWriter.WriteArtwork(ctx, f.reader, f.Size, f.Path, tags, f.artwork)
+	if err != nil {
+		return nil, fmt.Errorf("save: %w", err)
+	}
+	return edit, nil
+}
+
+// patchFile overwrites the edited range of the file at path.
+func patchFile(path string, edit *registry.TagEdit) error {
+	out, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := out.WriteAt(edit.Data, edit.Offset); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// rewriteFile writes the edited file to a temporary file in the same
+// directory and renames it over the original, then reopens it so later
+// reads through f see the new contents.
+func (f *File) rewriteFile(edit *registry.TagEdit) (err error) {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	tail := edit.Offset + edit.Length
+	if _, err = io.Copy(tmp, io.NewSectionReader(f.reader, 0, edit.Offset)); err != nil {
+		return err
+	}
+	if _, err = tmp.Write(edit.Data); err != nil {
+		return err
+	}
+	if _, err = io.Copy(tmp, io.NewSectionReader(f.reader, tail, f.Size-tail)); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), f.Path); err != nil {
+		return err
+	}
+
+	// The old reader still points at the replaced file
+	reopened, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	f.reader = reopened
+	f.Size = f.Size - edit.Length + int64(len(edit.Data))
+	return nil
+}
@@ -0,0 +1,231 @@
+package audiometa_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+)
+
+// createSimpleFLAC builds a FLAC file with STREAMINFO, a VORBIS_COMMENT
+// holding title, a PADDING block of the given size and a few audio bytes.
+func createSimpleFLAC(title string, padding int) []byte {
+	block := func(buf *bytes.Buffer, header byte, payload []byte) {
+		buf.WriteByte(header)
+		buf.Write([]byte{byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload))})
+		buf.Write(payload)
+	}
+
+	streamInfo := make([]byte, 34)
+	binary.BigEndian.PutUint64(streamInfo[10:18], 44100<<44|1<<41|15<<36|44100)
+
+	comment := &bytes.Buffer{}
+	binary.Write(comment, binary.LittleEndian, uint32(6))
+	comment.WriteString("vendor")
+	binary.Write(comment, binary.LittleEndian, uint32(1))
+	binary.Write(comment, binary.LittleEndian, uint32(len("TITLE=")+len(title)))
+	comment.WriteString("TITLE=" + title)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("fLaC")
+	block(buf, 0x00, streamInfo)
+	block(buf, 0x04, comment.Bytes())
+	block(buf, 0x81, make([]byte, padding))
+	buf.WriteString("\xFF\xF8audio")
+	return buf.Bytes()
+}
+
+func TestFile_Save(t *testing.T) {
+	tests := []struct {
+		name    string
+		padding int
+		title   string
+		inPlace bool
+	}{
+		{"fits in padding", 512, "New Title", true},
+		{"grows past padding", 0, strings.Repeat("Much longer title ", 10), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "song.flac")
+			data := createSimpleFLAC("Old Title", tt.padding)
+			if err := os.WriteFile(path, data, 0o640); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := audiometa.Open(path)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			defer file.Close()
+
+			file.Tags.Title = tt.title
+			file.Tags.Set("MOOD", "Mellow")
+			if err := file.Save(); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Size() == int64(len(data)); got != tt.inPlace {
+				t.Errorf("size %d -> %d, in place = %v, want %v", len(data), info.Size(), got, tt.inPlace)
+			}
+			if info.Mode().Perm() != 0o640 {
+				t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+			}
+			if hasWarning := len(file.Warnings) > 0; hasWarning == tt.inPlace {
+				t.Errorf("Warnings = %v, want a warning only when audio moves", file.Warnings)
+			}
+
+			reopened, err := audiometa.Open(path)
+			if err != nil {
+				t.Fatalf("reopen failed: %v", err)
+			}
+			defer reopened.Close()
+			if reopened.Tags.Title != tt.title || reopened.Tags.GetFirst("MOOD") != "Mellow" {
+				t.Errorf("reopened tags = %q, MOOD %q", reopened.Tags.Title, reopened.Tags.GetFirst("MOOD"))
+			}
+			if reopened.Audio.SampleRate != 44100 {
+				t.Errorf("SampleRate = %d after save, want 44100", reopened.Audio.SampleRate)
+			}
+
+			// A second save must see the rewritten file
+			file.Tags.Title = "Third"
+			if err := file.Save(); err != nil {
+				t.Fatalf("second Save failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestFile_SavePreservingModTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.flac")
+	if err := os.WriteFile(path, createSimpleFLAC("Old", 512), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	original := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	accessed := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	if err := os.Chtimes(path, accessed, original); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := audiometa.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+
+	file.Tags.Title = "New"
+	if err := file.SavePreservingModTime(); err != nil {
+		t.Fatalf("SavePreservingModTime failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(original) {
+		t.Errorf("mod time = %v, want %v", info.ModTime(), original)
+	}
+	if atime := audiometa.AccessTime(info); !atime.IsZero() && !atime.Equal(accessed) {
+		t.Errorf("access time = %v, want %v", atime, accessed)
+	}
+}
+
+func TestFile_Save_Unsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.m4b")
+	if err := os.WriteFile(path, createSimpleM4B(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := audiometa.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+
+	var unsupported *audiometa.UnsupportedFormatError
+	if err := file.Save(); !errors.As(err, &unsupported) {
+		t.Errorf("Save() error = %v, want UnsupportedFormatError", err)
+	}
+
+	if err := (&audiometa.File{}).Save(); err == nil {
+		t.Error("expected error for a File not produced by Open")
+	}
+}
+
+// createSimpleMP3 builds an MP3 file with an ID3v2.4 tag holding a title
+// and 256 bytes of padding, followed by one audio frame header.
+func createSimpleMP3(title string) []byte {
+	frame := []byte("TIT2")
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(title)+1))
+	frame = append(frame, 0, 0, 0x00)
+	frame = append(frame, title...)
+
+	size := len(frame) + 256
+	data := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, byte(size >> 7), byte(size & 0x7F)}
+	data = append(data, frame...)
+	data = append(data, make([]byte, 256)...)
+	return append(data, 0xFF, 0xFB, 0x90, 0x00, 0, 0, 0, 0)
+}
+
+func TestFile_Save_MP3Artwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.mp3")
+	data := createSimpleMP3("Title")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := audiometa.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+
+	var unsupported *audiometa.UnsupportedFormatError
+	if err := file.Save(); !errors.As(err, &unsupported) {
+		t.Errorf("Save() without artwork changes = %v, want UnsupportedFormatError", err)
+	}
+
+	cover := audiometa.Artwork{MIMEType: "image/jpeg", Type: audiometa.ArtworkFrontCover, Data: []byte("\xFF\xD8jpeg")}
+	if err := file.AddArtwork(cover); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != int64(len(data)) {
+		t.Errorf("file size changed, want the cover written into the padding")
+	}
+
+	reopened, err := audiometa.Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	art, err := reopened.ExtractArtwork()
+	if err != nil || len(art) != 1 || !bytes.Equal(art[0].Data, cover.Data) {
+		t.Errorf("ExtractArtwork() = %v, %v; want the new cover", art, err)
+	}
+	if reopened.Tags.Title != "Title" {
+		t.Errorf("Title = %q after saving artwork, want it kept", reopened.Tags.Title)
+	}
+
+	// Tag edits can't be written for MP3, so they block saving the artwork
+	reopened.Tags.Title = "New"
+	if _, err := reopened.RemoveArtwork(func(audiometa.Artwork) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Save(); !errors.As(err, &unsupported) {
+		t.Errorf("Save() with edited tags = %v, want UnsupportedFormatError", err)
+	}
+}
@@ -0,0 +1,213 @@
+package audiometa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+// Stream buffer sizes. Metadata lives at the front of most formats, so up
+// to streamHeadSize of the front is kept, enough for tags with embedded
+// artwork; it grows as reads reach further in. Past the head, only the
+// last streamTailSize bytes read are kept: ID3v1 and APEv2 tags, the last
+// Ogg page for the duration, and an M4A moov atom written after mdat.
+const (
+	streamHeadSize = 16 << 20
+	streamTailSize = 8 << 20
+	streamReadSize = 64 << 10
+)
+
+// ErrSeekRequired is returned by OpenStream when the metadata of a stream
+// lies outside the regions it buffers, typically an M4A file whose moov
+// atom follows a large mdat. Open the file by path, or buffer the stream
+// yourself and use a reader that supports seeking.
+var ErrSeekRequired = errors.New("metadata requires seeking in the stream")
+
+// OpenStream reads metadata from a non-seekable source, such as a pipe, an
+// HTTP response body or a tar entry.
+//
+// The stream is read only as far as the parser reads. Of what is read,
+// the first 16 MiB and the last 8 MiB are kept in memory, which covers the
+// tags of FLAC, MP3, Ogg, WAV and AIFF files as well as the trailing tags
+// and duration data of most. The audio in between is discarded. If the
+// parser needs anything from the discarded region, OpenStream fails with
+// ErrSeekRequired.
+//
+// Parsers need the length of the input, so without WithStreamSize the
+// whole stream is read to learn it. With the length known, a format whose
+// metadata is all at the front, such as FLAC or an M4A file with moov
+// before mdat, is parsed from its first bytes and the rest of the stream
+// is left unread. Formats with trailing metadata (ID3v1 and APEv2 tags,
+// the last Ogg page) still read to the end.
+//
+// hint skips format detection when the format is known, for instance from
+// a Content-Type header. Pass FormatUnknown to detect it from the stream.
+// Detection by extension is unavailable, so an M4B audiobook is reported
+// as M4A unless hinted.
+//
+// The caller keeps ownership of r; Close on the returned File does not
+// close it, and nothing more is read from it once OpenStream returns.
+// Artwork is extracted from the buffered regions, and Save is not
+// supported.
+//
+// Example:
+//
+//	obj, err := s3Client.GetObject(ctx, input)
+//	if err != nil {
+//		return err
+//	}
+//	defer obj.Body.Close()
+//	file, err := audiometa.OpenStream(obj.Body, audiometa.FormatM4B,
+//		audiometa.WithStreamSize(*obj.ContentLength))
+func OpenStream(r io.Reader, hint Format, opts ...Option) (*File, error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	buf, err := bufferStream(r, options.streamSize, options.maxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := openReader(context.Background(), buf, buf.size, "", hint, options)
+	buf.r = nil // The caller may close r once we return
+	if buf.err != nil && !errors.Is(buf.err, io.EOF) {
+		return nil, buf.err
+	}
+	if buf.missed {
+		// Parsers tolerate unreadable regions as corruption; here the
+		// metadata is intact but out of reach, so don't return a partial File
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSeekRequired, err)
+		}
+		return nil, ErrSeekRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newFile(parsed, buf, options)
+}
+
+// streamBuffer is an io.ReaderAt over a stream, which it reads forward as
+// far as reads reach. The first bytes are kept in head and the most recent
+// ones past it in tail; when the stream fits in the head, nothing is
+// discarded and tail is empty.
+type streamBuffer struct {
+	r     io.Reader // nil once no more may be read
+	size  int64
+	limit int64 // Maximum bytes read from r (0 = no limit)
+
+	head  []byte
+	tail  []byte // The last len(tail) bytes read, ending at pos
+	chunk []byte // Scratch space for reading r
+	pos   int64  // Bytes read from r
+	err   error  // First error reading r, io.EOF at its end
+
+	missed bool // A read touched a discarded or unread region
+}
+
+// bufferStream prepares a streamBuffer over r. If size is positive it is
+// taken as the stream's length and r is read on demand; otherwise r is
+// read to the end first to learn it. Reading fails once more than limit
+// bytes have been read if limit is positive.
+func bufferStream(r io.Reader, size, limit int64) (*streamBuffer, error) {
+	if limit > 0 && size > limit {
+		return nil, &types.FileTooLargeError{Size: size, Limit: limit}
+	}
+	buf := &streamBuffer{r: r, size: size, limit: limit}
+	if size > 0 {
+		return buf, nil
+	}
+
+	buf.fill(math.MaxInt64)
+	if !errors.Is(buf.err, io.EOF) {
+		return nil, buf.err
+	}
+	buf.size = buf.pos
+	return buf, nil
+}
+
+// fill reads r until end bytes have been read, r is exhausted or reading
+// fails, recording the error in b.err.
+func (b *streamBuffer) fill(end int64) {
+	for b.pos < end && b.err == nil && b.r != nil {
+		if b.chunk == nil {
+			b.chunk = make([]byte, streamReadSize)
+		}
+		n, err := b.r.Read(b.chunk)
+		data := b.chunk[:n]
+		b.pos += int64(n)
+		if limit := b.limit; limit > 0 && b.pos > limit {
+			b.err = &types.FileTooLargeError{Size: b.pos, Limit: limit}
+			return
+		}
+
+		// The head takes bytes until it is full, the tail what follows
+		if room := streamHeadSize - len(b.head); room > 0 && len(b.tail) == 0 {
+			k := min(room, len(data))
+			b.head = append(b.head, data[:k]...)
+			data = data[k:]
+		}
+		b.tail = append(b.tail, data...)
+		if len(b.tail) > 2*streamTailSize {
+			// Slide the window down rather than growing without bound
+			b.tail = append(b.tail[:0], b.tail[len(b.tail)-streamTailSize:]...)
+		}
+
+		if errors.Is(err, io.EOF) {
+			b.err = io.EOF
+		} else if err != nil {
+			b.err = fmt.Errorf("read stream: %w", err)
+		}
+	}
+
+	if len(b.tail) > streamTailSize {
+		b.tail = b.tail[len(b.tail)-streamTailSize:]
+	}
+}
+
+// ReadAt implements io.ReaderAt.
+func (b *streamBuffer) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= b.size {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(p)), b.size)
+	b.fill(end)
+	if b.pos < end && errors.Is(b.err, io.EOF) {
+		b.err = fmt.Errorf("read stream: %w after %d of %d bytes", io.ErrUnexpectedEOF, b.pos, b.size)
+	}
+	if b.pos < end && b.err != nil && !errors.Is(b.err, io.EOF) {
+		return 0, b.err
+	}
+
+	headEnd := int64(len(b.head))
+	tailStart := b.pos - int64(len(b.tail))
+
+	var n int
+	if off < headEnd {
+		n = copy(p, b.head[off:min(end, headEnd)])
+	}
+	if pos := off + int64(n); pos < end {
+		if pos < tailStart || end > b.pos {
+			// Discarded, or past where the stream ended or may be read
+			b.missed = true
+			return n, ErrSeekRequired
+		}
+		n += copy(p[n:], b.tail[pos-tailStart:end-tailStart])
+	}
+
+	if end < off+int64(len(p)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,134 @@
+package audiometa_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/simonhull/audiometa"
+)
+
+// streamOf hides every method of r but Read, as a pipe would.
+func streamOf(data []byte) io.Reader {
+	return struct{ io.Reader }{bytes.NewReader(data)}
+}
+
+func TestOpenStream(t *testing.T) {
+	file, err := audiometa.OpenStream(streamOf(createFLACWithPictures(audiometa.ArtworkFrontCover)), audiometa.FormatUnknown)
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	defer file.Close()
+
+	if file.Format != audiometa.FormatFLAC || file.Audio.SampleRate != 44100 {
+		t.Errorf("Format = %v, SampleRate = %d; want FLAC at 44100 Hz", file.Format, file.Audio.SampleRate)
+	}
+	if art, err := file.ExtractArtwork(); err != nil || len(art) != 1 {
+		t.Errorf("ExtractArtwork() = %d images, %v; want 1", len(art), err)
+	}
+	if err := file.Save(); err == nil {
+		t.Error("expected Save to fail for a stream")
+	}
+}
+
+func TestOpenStream_SeekRequired(t *testing.T) {
+	// moov follows an mdat that runs past the buffered head, and is too
+	// large for the buffered tail
+	atom := func(buf *bytes.Buffer, typ string, size int) {
+		binary.Write(buf, binary.BigEndian, uint32(size))
+		buf.WriteString(typ)
+		buf.Write(make([]byte, size-8))
+	}
+	buf := &bytes.Buffer{}
+	buf.Write(createSimpleM4B()[:20]) // ftyp
+	atom(buf, "mdat", 20<<20)
+	atom(buf, "moov", 10<<20)
+
+	_, err := audiometa.OpenStream(streamOf(buf.Bytes()), audiometa.FormatM4B)
+	if !errors.Is(err, audiometa.ErrSeekRequired) {
+		t.Errorf("OpenStream() error = %v, want ErrSeekRequired", err)
+	}
+}
+
+func TestOpenStream_MaxFileSize(t *testing.T) {
+	data := append(createFLACWithPictures(), make([]byte, 20<<20)...)
+
+	_, err := audiometa.OpenStream(streamOf(data), audiometa.FormatUnknown, audiometa.WithMaxFileSize(1<<20))
+	var tooLarge *audiometa.FileTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Errorf("OpenStream() error = %v, want FileTooLargeError", err)
+	}
+}
+
+// countingReader is a stream that records how much of it was read.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestOpenStream_WithStreamSize(t *testing.T) {
+	// Tags at the front, then 64 MiB of audio that should be left unread
+	data := append(createFLACWithPictures(audiometa.ArtworkFrontCover), make([]byte, 64<<20)...)
+	stream := &countingReader{r: bytes.NewReader(data)}
+
+	file, err := audiometa.OpenStream(stream, audiometa.FormatUnknown, audiometa.WithStreamSize(int64(len(data))))
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	defer file.Close()
+
+	if file.Format != audiometa.FormatFLAC || file.Size != int64(len(data)) {
+		t.Errorf("Format = %v, Size = %d; want FLAC of %d bytes", file.Format, file.Size, len(data))
+	}
+	if art, err := file.ExtractArtwork(); err != nil || len(art) != 1 {
+		t.Errorf("ExtractArtwork() = %d images, %v; want 1", len(art), err)
+	}
+	if stream.read > 1<<20 {
+		t.Errorf("read %d bytes of the stream, want only the metadata at its front", stream.read)
+	}
+}
+
+func TestOpenStream_WithStreamSize_TrailingMetadata(t *testing.T) {
+	// moov after 24 MiB of mdat: the stream is read to its end
+	data := &bytes.Buffer{}
+	data.Write([]byte{0, 0, 0, 1, 0, 0, 0, 0})
+	data.WriteString("Trailing")
+	nam, ilst, meta, udta, moov := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	writeAtom(nam, "data", data.Bytes())
+	writeAtom(ilst, "\xa9nam", nam.Bytes())
+	meta.Write([]byte{0, 0, 0, 0})
+	writeAtom(meta, "ilst", ilst.Bytes())
+	writeAtom(udta, "meta", meta.Bytes())
+	writeAtom(moov, "udta", udta.Bytes())
+
+	buf := &bytes.Buffer{}
+	buf.Write(createSimpleM4B()[:20]) // ftyp
+	writeAtom(buf, "mdat", make([]byte, 24<<20))
+	writeAtom(buf, "moov", moov.Bytes())
+
+	file, err := audiometa.OpenStream(streamOf(buf.Bytes()), audiometa.FormatM4B, audiometa.WithStreamSize(int64(buf.Len())))
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	defer file.Close()
+	if file.Tags.Title != "Trailing" {
+		t.Errorf("Title = %q, want the title in the trailing moov", file.Tags.Title)
+	}
+}
+
+func TestOpenStream_WithStreamSize_Short(t *testing.T) {
+	data := createFLACWithPictures()
+
+	_, err := audiometa.OpenStream(streamOf(data[:len(data)/2]), audiometa.FormatUnknown, audiometa.WithStreamSize(int64(len(data))))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("OpenStream() error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
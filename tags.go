@@ -7,3 +7,50 @@ import (
 // Tags is an alias to types.Tags for backwards compatibility.
 // Re-exporting from internal/types to maintain public API.
 type Tags = types.Tags
+
+// LyricLine is an alias to types.LyricLine, one line of Tags.SyncedLyrics.
+type LyricLine = types.LyricLine
+
+// Comment is an alias to types.Comment, one entry of File.Comments.
+type Comment = types.Comment
+
+// NewTags returns empty Tags, with the raw tag map already allocated, for
+// building metadata from scratch rather than from a parsed file. The zero
+// Tags value works too; NewTags just makes the entry point explicit.
+//
+// To replace all of a file's tags, fill in new Tags and assign them before
+// Save.
+//
+// Example:
+//
+//	tags := audiometa.NewTags()
+//	tags.Title = "Chapter One"
+//	tags.Set("ENCODER", "my-encoder 1.0")
+//	file.Tags = *tags
+//	if err := file.Save(); err != nil {
+//		return err
+//	}
+func NewTags() *Tags {
+	return types.NewTags()
+}
+
+// MergeStrategy is an alias to types.MergeStrategy.
+// Re-exported so callers can pass strategies to Tags.MergeWith.
+type MergeStrategy = types.MergeStrategy
+
+// Re-export merge strategies.
+const (
+	PreferExisting = types.PreferExisting
+	PreferOther    = types.PreferOther
+	PreferLongest  = types.PreferLongest
+)
+
+// Charset is an alias to types.Charset.
+// Re-exported so callers can choose a charset with WithLegacyCharset.
+type Charset = types.Charset
+
+// Re-export legacy charsets.
+const (
+	Latin1      = types.Latin1
+	Windows1252 = types.Windows1252
+)
@@ -0,0 +1,67 @@
+package audiometa
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// vttEscaper escapes the characters WebVTT cue text reserves for markup.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteWebVTT writes the file's chapters as a WebVTT chapter track.
+//
+// The output is a WEBVTT header followed by one cue per chapter, timed
+// from StartTime to EndTime with the title as the cue text, ready to load
+// as a <track kind="chapters"> in a browser player. A chapter without an
+// EndTime runs to the next chapter's start, or to the file's duration for
+// the last one.
+//
+// A file without chapters produces just the header.
+//
+// Example:
+//
+//	out, err := os.Create("audiobook.vtt")
+//	if err != nil {
+//		return err
+//	}
+//	defer out.Close()
+//	if err := file.WriteWebVTT(out); err != nil {
+//		return err
+//	}
+func (f *File) WriteWebVTT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n")
+
+	for i, ch := range f.Chapters {
+		end := ch.EndTime
+		if end <= ch.StartTime {
+			if i+1 < len(f.Chapters) {
+				end = f.Chapters[i+1].StartTime
+			} else {
+				end = max(f.Audio.Duration, ch.StartTime)
+			}
+		}
+
+		// A line break would end the cue early, and a blank line end it
+		// without text; "-->" is escaped with the markup characters
+		title := strings.Join(strings.Fields(ch.Title), " ")
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+
+		fmt.Fprintf(bw, "\n%s --> %s\n%s\n", vttTimestamp(ch.StartTime), vttTimestamp(end), vttEscaper.Replace(title))
+	}
+
+	return bw.Flush()
+}
+
+// vttTimestamp formats d as a WebVTT timestamp (hh:mm:ss.ttt). Hours are
+// always included so every cue line has the same shape.
+func vttTimestamp(d time.Duration) string {
+	d = max(d, 0)
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000)
+}
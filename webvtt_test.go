@@ -0,0 +1,70 @@
+package audiometa_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/simonhull/audiometa"
+	"github.com/simonhull/audiometa/internal/types"
+)
+
+func TestWriteWebVTT(t *testing.T) {
+	file := &audiometa.File{File: types.File{
+		Audio: types.AudioInfo{Duration: 90 * time.Minute},
+		Chapters: []audiometa.Chapter{
+			{Title: "Opening Credits", StartTime: 0, EndTime: 10 * time.Minute},
+			// No EndTime: runs to the next chapter
+			{Title: "Q&A: <Live>", StartTime: 10 * time.Minute},
+			// No title or EndTime: numbered, runs to the file duration
+			{StartTime: time.Hour + 2*time.Minute + 3456*time.Millisecond},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := file.WriteWebVTT(&buf); err != nil {
+		t.Fatalf("WriteWebVTT failed: %v", err)
+	}
+
+	want, err := os.ReadFile("testdata/chapters.vtt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("WebVTT output mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteWebVTT_CueBreakingTitles(t *testing.T) {
+	file := &audiometa.File{File: types.File{
+		Chapters: []audiometa.Chapter{
+			{Title: "Part 1 --> Part 2", StartTime: 0, EndTime: time.Minute},
+			{Title: "Intro\n\nBlank line", StartTime: time.Minute, EndTime: 2 * time.Minute},
+			{Title: " \n\n ", StartTime: 2 * time.Minute, EndTime: 3 * time.Minute},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := file.WriteWebVTT(&buf); err != nil {
+		t.Fatalf("WriteWebVTT failed: %v", err)
+	}
+
+	want := "WEBVTT\n" +
+		"\n00:00:00.000 --> 00:01:00.000\nPart 1 --&gt; Part 2\n" +
+		"\n00:01:00.000 --> 00:02:00.000\nIntro Blank line\n" +
+		"\n00:02:00.000 --> 00:03:00.000\nChapter 3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WebVTT output mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteWebVTT_NoChapters(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&audiometa.File{}).WriteWebVTT(&buf); err != nil {
+		t.Fatalf("WriteWebVTT failed: %v", err)
+	}
+	if buf.String() != "WEBVTT\n" {
+		t.Errorf("expected header only, got %q", buf.String())
+	}
+}